@@ -0,0 +1,409 @@
+// Command loadtest hammers a running API with concurrent purchases and
+// fails when more units were sold than there was stock. Flash sales are set
+// up with an admin account.
+//
+//	go run ./cmd/loadtest -base http://localhost:8080/api/v1 -users 200 -requests 5000 \
+//		-admin-email admin@example.com -admin-password secret
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"math/rand"
+	"net/http"
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+type options struct {
+	base     string
+	users    int
+	requests int
+	stock    int
+	limit    int
+	quantity int
+	retries  int
+
+	adminEmail    string
+	adminPassword string
+}
+
+type client struct {
+	http *http.Client
+	base string
+}
+
+type result struct {
+	user     int
+	status   int
+	code     string
+	quantity int
+	latency  time.Duration
+}
+
+func main() {
+	var o options
+	flag.StringVar(&o.base, "base", "http://localhost:8080/api/v1", "API base URL")
+	flag.IntVar(&o.users, "users", 100, "customers taking part")
+	flag.IntVar(&o.requests, "requests", 2000, "purchase requests fired at once per scenario")
+	flag.IntVar(&o.stock, "stock", 50, "units on sale")
+	flag.IntVar(&o.limit, "limit", 2, "flash sale per-customer limit")
+	flag.IntVar(&o.quantity, "quantity", 1, "units per purchase request")
+	flag.IntVar(&o.retries, "retries", 3, "retries of a request shed with 429")
+	flag.StringVar(&o.adminEmail, "admin-email", "", "admin account that sets up flash sales")
+	flag.StringVar(&o.adminPassword, "admin-password", "", "password of the admin account")
+	flag.Parse()
+
+	c := &client{
+		http: &http.Client{
+			Timeout:   30 * time.Second,
+			Transport: &http.Transport{MaxIdleConnsPerHost: o.requests},
+		},
+		base: o.base,
+	}
+
+	tokens, err := c.signUp(o.users)
+	if err != nil {
+		log.Fatalf("sign up: %v", err)
+	}
+
+	ok := true
+	for _, scenario := range []func(*client, options, []string) (bool, error){productPurchase, flashSalePurchase} {
+		passed, err := scenario(c, o, tokens)
+		if err != nil {
+			log.Fatal(err)
+		}
+		ok = ok && passed
+	}
+
+	if !ok {
+		os.Exit(1)
+	}
+}
+
+// productPurchase races plain purchases of a product with o.stock units
+func productPurchase(c *client, o options, tokens []string) (bool, error) {
+	productID, err := c.createProduct(o.stock)
+	if err != nil {
+		return false, fmt.Errorf("create product: %w", err)
+	}
+
+	results := fire(o, func(user int) (int, string) {
+		return c.post("/products/purchase", "", map[string]any{"product_id": productID, "quantity": o.quantity}, nil)
+	})
+
+	var product struct {
+		Stock int `json:"stock"`
+		Sold  int `json:"sold_quantity"`
+	}
+	if status, code := c.get("/products/"+productID, "", &product); status != http.StatusOK {
+		return false, fmt.Errorf("read product: %d %s", status, code)
+	}
+
+	sold := soldUnits(results)
+	report("POST /products/purchase", results)
+
+	passed := check(product.Stock >= 0, "stock is %d", product.Stock) &&
+		check(sold == o.stock-product.Stock, "%d units bought but stock went from %d to %d", sold, o.stock, product.Stock) &&
+		check(product.Sold == sold, "sold quantity is %d, want %d", product.Sold, sold)
+
+	return passed, nil
+}
+
+// flashSalePurchase races flash sale purchases of o.users customers for a
+// sale of o.stock units
+func flashSalePurchase(c *client, o options, tokens []string) (bool, error) {
+	productID, err := c.createProduct(o.stock)
+	if err != nil {
+		return false, fmt.Errorf("create product: %w", err)
+	}
+
+	admin, err := c.login(o.adminEmail, o.adminPassword)
+	if err != nil {
+		return false, fmt.Errorf("admin login: %w", err)
+	}
+
+	saleID, err := c.createFlashSale(admin, productID, o)
+	if err != nil {
+		return false, fmt.Errorf("create flash sale: %w", err)
+	}
+	defer c.delete(fmt.Sprintf("/flash-sales/%d", saleID), admin)
+
+	path := fmt.Sprintf("/flash-sales/%d/purchase", saleID)
+	results := fire(o, func(user int) (int, string) {
+		return c.post(path, tokens[user], map[string]any{"quantity": o.quantity}, nil)
+	})
+
+	var sale struct {
+		Allocation int `json:"allocation"`
+		Sold       int `json:"sold"`
+	}
+	if status, code := c.get(fmt.Sprintf("/flash-sales/%d", saleID), "", &sale); status != http.StatusOK {
+		return false, fmt.Errorf("read flash sale: %d %s", status, code)
+	}
+
+	perUser := make(map[int]int)
+	for _, r := range results {
+		if r.status == http.StatusCreated {
+			perUser[r.user] += r.quantity
+		}
+	}
+
+	over := 0
+	for _, n := range perUser {
+		if n > o.limit {
+			over++
+		}
+	}
+
+	sold := soldUnits(results)
+	report(path, results)
+
+	passed := check(sale.Sold <= sale.Allocation, "sold %d of an allocation of %d", sale.Sold, sale.Allocation) &&
+		check(sale.Sold == sold, "sale counts %d sold, customers bought %d", sale.Sold, sold) &&
+		check(over == 0, "%d customers bought more than %d", over, o.limit)
+
+	return passed, nil
+}
+
+// fire sends o.requests requests released at the same moment, customers
+// take turns. Requests shed with 429 are retried after a short backoff.
+func fire(o options, send func(user int) (int, string)) []result {
+	results := make([]result, o.requests)
+	start := make(chan struct{})
+
+	var wg sync.WaitGroup
+	for i := 0; i < o.requests; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			<-start
+
+			r := result{user: i % o.users, quantity: o.quantity}
+			begin := time.Now()
+			for attempt := 0; ; attempt++ {
+				r.status, r.code = send(r.user)
+				if r.status != http.StatusTooManyRequests || attempt == o.retries {
+					break
+				}
+				time.Sleep(time.Duration(100+rand.Intn(400)) * time.Millisecond)
+			}
+			r.latency = time.Since(begin)
+
+			results[i] = r
+		}(i)
+	}
+
+	close(start)
+	wg.Wait()
+
+	return results
+}
+
+func soldUnits(results []result) int {
+	sold := 0
+	for _, r := range results {
+		if r.status == http.StatusOK || r.status == http.StatusCreated {
+			sold += r.quantity
+		}
+	}
+	return sold
+}
+
+func report(name string, results []result) {
+	outcomes := make(map[string]int)
+	latencies := make([]time.Duration, len(results))
+
+	for i, r := range results {
+		key := strconv.Itoa(r.status)
+		if r.code != "" {
+			key += " " + r.code
+		}
+		outcomes[key]++
+		latencies[i] = r.latency
+	}
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+
+	percentile := func(p int) time.Duration {
+		return latencies[(len(latencies)-1)*p/100]
+	}
+
+	fmt.Printf("%s: %d requests, p50 %s p95 %s p99 %s\n", name, len(results), percentile(50), percentile(95), percentile(99))
+
+	keys := make([]string, 0, len(outcomes))
+	for k := range outcomes {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Printf("  %-40s %d\n", k, outcomes[k])
+	}
+}
+
+func check(ok bool, format string, args ...any) bool {
+	if !ok {
+		fmt.Printf("  FAIL: "+format+"\n", args...)
+	}
+	return ok
+}
+
+// signUp registers n throwaway customers and returns their access tokens
+func (c *client) signUp(n int) ([]string, error) {
+	run := time.Now().UnixNano()
+	tokens := make([]string, n)
+	errs := make(chan error, n)
+	slots := make(chan struct{}, 16)
+
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			slots <- struct{}{}
+			defer func() { <-slots }()
+
+			email := fmt.Sprintf("loadtest-%d-%d@example.com", run, i)
+			user := map[string]any{
+				"email":      email,
+				"password":   "loadtest",
+				"first_name": "Load",
+				"last_name":  strconv.Itoa(i),
+				"address":    "loadtest",
+			}
+			if status, code := c.post("/auth/register", "", user, nil); status != http.StatusCreated {
+				errs <- fmt.Errorf("register %s: %d %s", email, status, code)
+				return
+			}
+
+			token, err := c.login(email, "loadtest")
+			if err != nil {
+				errs <- err
+				return
+			}
+			tokens[i] = token
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+
+	return tokens, <-errs
+}
+
+func (c *client) login(email, password string) (string, error) {
+	var login struct {
+		AccessToken string `json:"access_token"`
+	}
+	if status, code := c.post("/auth/login", "", map[string]any{"email": email, "password": password}, &login); status != http.StatusOK {
+		return "", fmt.Errorf("login %s: %d %s", email, status, code)
+	}
+
+	return login.AccessToken, nil
+}
+
+func (c *client) createProduct(stock int) (string, error) {
+	req := map[string]any{
+		"title":       fmt.Sprintf("Load test %d", time.Now().UnixNano()),
+		"description": "load test",
+		"price":       "100.00",
+		"stock":       stock,
+	}
+
+	var product struct {
+		ID string `json:"id"`
+	}
+	if status, code := c.post("/products/", "", req, &product); status != http.StatusCreated {
+		return "", fmt.Errorf("%d %s", status, code)
+	}
+
+	return product.ID, nil
+}
+
+func (c *client) createFlashSale(token, productID string, o options) (int, error) {
+	now := time.Now()
+	req := map[string]any{
+		"name":               "Load test",
+		"product_id":         productID,
+		"sale_price":         "49.00",
+		"allocation":         o.stock,
+		"per_customer_limit": o.limit,
+		"starts_at":          now.Add(-time.Minute),
+		"ends_at":            now.Add(time.Hour),
+	}
+
+	var sale struct {
+		ID int `json:"id"`
+	}
+	if status, code := c.post("/flash-sales/", token, req, &sale); status != http.StatusCreated {
+		return 0, fmt.Errorf("%d %s", status, code)
+	}
+
+	return sale.ID, nil
+}
+
+func (c *client) get(path, token string, out any) (int, string) {
+	return c.do(http.MethodGet, path, token, nil, out)
+}
+
+func (c *client) post(path, token string, body, out any) (int, string) {
+	return c.do(http.MethodPost, path, token, body, out)
+}
+
+func (c *client) delete(path, token string) (int, string) {
+	return c.do(http.MethodDelete, path, token, nil, nil)
+}
+
+// do sends the request and decodes {"data": ...} into out, it returns the
+// status and the problem code of an error response. A transport error is
+// reported as status 0.
+func (c *client) do(method, path, token string, body, out any) (int, string) {
+	var payload io.Reader
+	if body != nil {
+		b, err := json.Marshal(body)
+		if err != nil {
+			return 0, err.Error()
+		}
+		payload = bytes.NewReader(b)
+	}
+
+	req, err := http.NewRequest(method, c.base+path, payload)
+	if err != nil {
+		return 0, err.Error()
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return 0, "transport_error"
+	}
+	defer resp.Body.Close()
+
+	var envelope struct {
+		Data json.RawMessage `json:"data"`
+		Code string          `json:"code"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&envelope); err != nil {
+		return resp.StatusCode, "invalid_response"
+	}
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		return resp.StatusCode, envelope.Code
+	}
+
+	if out != nil && envelope.Data != nil {
+		if err := json.Unmarshal(envelope.Data, out); err != nil {
+			return resp.StatusCode, "invalid_response"
+		}
+	}
+
+	return resp.StatusCode, ""
+}
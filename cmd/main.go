@@ -5,6 +5,9 @@ import (
 
 	"github.com/codepnw/react_go_ecom/config"
 	"github.com/codepnw/react_go_ecom/pkg/database"
+	"github.com/codepnw/react_go_ecom/pkg/media"
+	"github.com/codepnw/react_go_ecom/pkg/pdf"
+	"github.com/codepnw/react_go_ecom/pkg/scheduler"
 )
 
 const envFile = "dev.env"
@@ -18,8 +21,42 @@ func main() {
 	}
 	defer db.Close()
 
+	blobs, err := media.NewLocalStore(cfg.MediaConfig.Dir, cfg.MediaConfig.BaseURL)
+	if err != nil {
+		log.Fatalf("cant init media storage: %v", err)
+	}
+
+	// Carrier labels are kept apart from the public media, they are only
+	// served by handlers that check who is asking
+	documents, err := media.NewLocalStore(cfg.MediaConfig.PrivateDir, "")
+	if err != nil {
+		log.Fatalf("cant init document storage: %v", err)
+	}
+
+	renditions, err := media.ParseRenditions(cfg.MediaConfig.Renditions)
+	if err != nil {
+		log.Fatalf("cant parse media renditions: %v", err)
+	}
+
+	processor := media.NewProcessor(blobs, renditions, cfg.MediaConfig.Workers, 100)
+	processor.Start()
+	defer processor.Stop()
+
+	// Invoices use Helvetica unless a font is configured
+	var font *pdf.Font
+	if cfg.InvoiceConfig.InvoiceFont != "" {
+		if font, err = pdf.LoadFont(cfg.InvoiceConfig.InvoiceFont); err != nil {
+			log.Fatalf("cant load invoice font: %v", err)
+		}
+	}
+
+	jobs := scheduler.New()
+
 	// API Routes
-	r := apiRoutes(db, *cfg)
+	r := apiRoutes(db, blobs, documents, processor, jobs, font, *cfg)
+
+	jobs.Start()
+	defer jobs.Stop()
 
 	port := cfg.AppConfig.AppPort
 	log.Println("server is running at port", port)
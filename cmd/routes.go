@@ -5,14 +5,22 @@ import (
 	"net/http"
 
 	"github.com/codepnw/react_go_ecom/config"
+	"github.com/codepnw/react_go_ecom/internal/middleware"
 	"github.com/codepnw/react_go_ecom/internal/storage"
+	"github.com/codepnw/react_go_ecom/internal/utils"
+	"github.com/codepnw/react_go_ecom/pkg/media"
+	"github.com/codepnw/react_go_ecom/pkg/pdf"
+	"github.com/codepnw/react_go_ecom/pkg/scheduler"
 	"github.com/gin-gonic/gin"
 )
 
-func apiRoutes(db *sql.DB, cfg config.Config) *gin.Engine {
+func apiRoutes(db *sql.DB, blobs, documents media.BlobStore, processor *media.Processor, jobs *scheduler.Scheduler, font *pdf.Font, cfg config.Config) *gin.Engine {
 	r := gin.Default()
 
-	store := storage.NewStorage(db, cfg)
+	store := storage.NewStorage(db, blobs, documents, processor, jobs, font, cfg)
+	m := middleware.InitMiddleware(*cfg.JWTConfig)
+	r.Use(m.RequestIDMiddleware(), m.LanguageMiddleware())
+	utils.SetupValidator()
 
 	router := r.Group("/api/" + cfg.AppVersion)
 	router.GET("/", func(c *gin.Context) {
@@ -30,22 +38,191 @@ func apiRoutes(db *sql.DB, cfg config.Config) *gin.Engine {
 	// midRouter := router.Use(m.AuthMiddleware())
 	// midRouter.GET("/users/profile", store.User.Profile)
 
+	// Users Routes
+	userRouter := router.Group("/users", m.AuthMiddleware())
+	userRouter.POST("/avatar", store.Media.UploadAvatar)
+
+	// Media Routes
+	router.GET("/media/*key", store.Media.Serve)
+
+	// Search Routes
+	searchRouter := router.Group("/search")
+	searchRouter.GET("/suggest", store.Search.Suggest)
+	searchRouter.POST("/clicks", store.Search.RecordClick)
+
+	analyticsRouter := searchRouter.Group("/analytics", m.AuthMiddleware(), m.AdminMiddleware(db))
+	analyticsRouter.GET("/top-queries", store.Search.TopQueries)
+	analyticsRouter.GET("/zero-results", store.Search.ZeroResultQueries)
+	analyticsRouter.GET("/click-through", store.Search.ClickThrough)
+
+	synonymRouter := searchRouter.Group("/synonyms", m.AuthMiddleware(), m.AdminMiddleware(db))
+	synonymRouter.GET("/", store.Synonym.ListSynonyms)
+	synonymRouter.POST("/", store.Synonym.CreateSynonym)
+	synonymRouter.PUT("/:id", store.Synonym.UpdateSynonym)
+	synonymRouter.DELETE("/:id", store.Synonym.DeleteSynonym)
+
+	stopWordRouter := searchRouter.Group("/stop-words", m.AuthMiddleware(), m.AdminMiddleware(db))
+	stopWordRouter.GET("/", store.Synonym.ListStopWords)
+	stopWordRouter.POST("/", store.Synonym.AddStopWord)
+	stopWordRouter.DELETE("/:word", store.Synonym.DeleteStopWord)
+
+	// Pricing Routes
+	rateRouter := router.Group("/exchange-rates", m.AuthMiddleware())
+	rateRouter.GET("/", store.Pricing.ListRates)
+	rateRouter.POST("/", m.AdminMiddleware(db), store.Pricing.CreateRate)
+	rateRouter.DELETE("/:id", m.AdminMiddleware(db), store.Pricing.DeleteRate)
+
+	// Promotions Routes
+	promoRouter := router.Group("/promotions", m.AuthMiddleware(), m.AdminMiddleware(db))
+	promoRouter.GET("/", store.Promotion.List)
+	promoRouter.POST("/", store.Promotion.Create)
+	promoRouter.GET("/:id", store.Promotion.Get)
+	promoRouter.PUT("/:id", store.Promotion.Update)
+	promoRouter.DELETE("/:id", store.Promotion.Delete)
+
+	// Flash Sales Routes
+	flashSaleRouter := router.Group("/flash-sales")
+	flashSaleRouter.GET("/", store.FlashSale.List)
+	flashSaleRouter.GET("/:id", store.FlashSale.Get)
+	flashSaleRouter.POST("/", m.AuthMiddleware(), m.AdminMiddleware(db), store.FlashSale.Create)
+	flashSaleRouter.DELETE("/:id", m.AuthMiddleware(), m.AdminMiddleware(db), store.FlashSale.Close)
+	flashSaleRouter.POST("/:id/purchase", m.AuthMiddleware(), store.FlashSale.Purchase)
+
+	// Taxes Routes
+	taxClassRouter := router.Group("/tax-classes", m.AuthMiddleware(), m.AdminMiddleware(db))
+	taxClassRouter.GET("/", store.Tax.ListClasses)
+	taxClassRouter.POST("/", store.Tax.CreateClass)
+	taxClassRouter.DELETE("/:id", store.Tax.DeleteClass)
+
+	taxZoneRouter := router.Group("/tax-zones", m.AuthMiddleware(), m.AdminMiddleware(db))
+	taxZoneRouter.GET("/", store.Tax.ListZones)
+	taxZoneRouter.POST("/", store.Tax.CreateZone)
+	taxZoneRouter.GET("/:id", store.Tax.GetZone)
+	taxZoneRouter.PUT("/:id", store.Tax.UpdateZone)
+	taxZoneRouter.DELETE("/:id", store.Tax.DeleteZone)
+	taxZoneRouter.PUT("/:id/rates/:classId", store.Tax.SetRate)
+	taxZoneRouter.DELETE("/:id/rates/:classId", store.Tax.DeleteRate)
+
+	// Shipping Routes
+	shippingZoneRouter := router.Group("/shipping-zones", m.AuthMiddleware(), m.AdminMiddleware(db))
+	shippingZoneRouter.GET("/", store.Shipping.ListZones)
+	shippingZoneRouter.POST("/", store.Shipping.CreateZone)
+	shippingZoneRouter.GET("/:id", store.Shipping.GetZone)
+	shippingZoneRouter.PUT("/:id", store.Shipping.UpdateZone)
+	shippingZoneRouter.DELETE("/:id", store.Shipping.DeleteZone)
+	shippingZoneRouter.POST("/:id/methods", store.Shipping.CreateMethod)
+
+	shippingMethodRouter := router.Group("/shipping-methods", m.AuthMiddleware(), m.AdminMiddleware(db))
+	shippingMethodRouter.GET("/:id", store.Shipping.GetMethod)
+	shippingMethodRouter.PUT("/:id", store.Shipping.UpdateMethod)
+	shippingMethodRouter.DELETE("/:id", store.Shipping.DeleteMethod)
+
 	// Categories Routes
 	catRouter := router.Group("/categories")
 	catRouter.POST("/", store.Category.Create)
 	catRouter.GET("/", store.Category.List)
 	catRouter.DELETE("/:id", store.Category.Delete)
+	catRouter.POST("/:id/attributes", m.AuthMiddleware(), m.AdminMiddleware(db), store.Attribute.AttachToCategory)
+	catRouter.GET("/:id/attributes", store.Attribute.ListCategoryAttributes)
+	catRouter.DELETE("/:id/attributes/:attributeId", m.AuthMiddleware(), m.AdminMiddleware(db), store.Attribute.DetachFromCategory)
+	catRouter.GET("/:id/translations", store.Translation.ListCategoryTranslations)
+	catRouter.PUT("/:id/translations/:locale", m.AuthMiddleware(), m.AdminMiddleware(db), store.Translation.SetCategoryTranslation)
+	catRouter.DELETE("/:id/translations/:locale", m.AuthMiddleware(), m.AdminMiddleware(db), store.Translation.DeleteCategoryTranslation)
+
+	// Attributes Routes
+	attrRouter := router.Group("/attributes")
+	attrRouter.POST("/", m.AuthMiddleware(), m.AdminMiddleware(db), store.Attribute.Create)
+	attrRouter.GET("/", store.Attribute.List)
+	attrRouter.DELETE("/:id", m.AuthMiddleware(), m.AdminMiddleware(db), store.Attribute.Delete)
+	attrRouter.GET("/:id/translations", store.Translation.ListAttributeTranslations)
+	attrRouter.PUT("/:id/translations/:locale", m.AuthMiddleware(), m.AdminMiddleware(db), store.Translation.SetAttributeTranslation)
+	attrRouter.DELETE("/:id/translations/:locale", m.AuthMiddleware(), m.AdminMiddleware(db), store.Translation.DeleteAttributeTranslation)
 
 	// Products Routes
 	proRouter := router.Group("/products")
 	proRouter.POST("/", store.Product.Create)
-	proRouter.GET("/", store.Product.List)
+	proRouter.GET("/", m.OptionalAuthMiddleware(), store.Product.List)
 	proRouter.GET("/:id", store.Product.GetByID)
-	proRouter.PATCH("/:id", store.Product.Update)
+	proRouter.PATCH("/:id", m.AuthMiddleware(), m.AdminMiddleware(db), store.Product.Update)
 	proRouter.DELETE("/:id", store.Product.Delete)
 	proRouter.POST("/purchase", store.Product.ProductPurchase)
 	proRouter.GET("/out-of-stock", store.Product.CheckOutOfStock)
 	proRouter.PUT("/restock", store.Product.RestockProduct)
+	proRouter.POST("/:id/images", m.AuthMiddleware(), m.AdminMiddleware(db), store.Media.UploadProductImages)
+	proRouter.GET("/:id/images", store.Media.ListProductImages)
+	proRouter.PUT("/:id/images/order", m.AuthMiddleware(), m.AdminMiddleware(db), store.Media.ReorderProductImages)
+	proRouter.DELETE("/:id/images/:imageId", m.AuthMiddleware(), m.AdminMiddleware(db), store.Media.DeleteProductImage)
+	proRouter.PUT("/:id/attributes", m.AuthMiddleware(), m.AdminMiddleware(db), store.Attribute.SetProductAttributes)
+	proRouter.GET("/:id/attributes", store.Attribute.ListProductAttributes)
+	proRouter.GET("/:id/translations", store.Translation.ListProductTranslations)
+	proRouter.PUT("/:id/translations/:locale", m.AuthMiddleware(), m.AdminMiddleware(db), store.Translation.SetProductTranslation)
+	proRouter.DELETE("/:id/translations/:locale", m.AuthMiddleware(), m.AdminMiddleware(db), store.Translation.DeleteProductTranslation)
+	proRouter.GET("/:id/prices", store.Pricing.ListProductPrices)
+	proRouter.PUT("/:id/prices/:currency", m.AuthMiddleware(), m.AdminMiddleware(db), store.Pricing.SetProductPrice)
+	proRouter.DELETE("/:id/prices/:currency", m.AuthMiddleware(), m.AdminMiddleware(db), store.Pricing.DeleteProductPrice)
+	proRouter.GET("/:id/price-history", m.AuthMiddleware(), m.AdminMiddleware(db), store.PriceHistory.ListHistory)
+	proRouter.GET("/:id/scheduled-prices", m.AuthMiddleware(), m.AdminMiddleware(db), store.PriceHistory.ListSchedules)
+	proRouter.POST("/:id/scheduled-prices", m.AuthMiddleware(), m.AdminMiddleware(db), store.PriceHistory.SchedulePrice)
+	proRouter.DELETE("/:id/scheduled-prices/:scheduleId", m.AuthMiddleware(), m.AdminMiddleware(db), store.PriceHistory.CancelSchedule)
+
+	// Cart Routes
+	cartRouter := router.Group("/cart", m.AuthMiddleware())
+	cartRouter.GET("/", store.Cart.Get)
+	cartRouter.GET("/shipping-rates", store.Cart.ShippingRates)
+	cartRouter.POST("/items", store.Cart.AddItem)
+	cartRouter.PATCH("/items/:productId", store.Cart.UpdateItem)
+	cartRouter.DELETE("/items/:productId", store.Cart.RemoveItem)
+
+	// Orders Routes
+	orderRouter := router.Group("/orders", m.AuthMiddleware())
+	orderRouter.POST("/", store.Order.Checkout)
+	orderRouter.GET("/", store.Order.ListOrders)
+	orderRouter.GET("/:id", store.Order.GetOrder)
+	orderRouter.GET("/:id/tracking", store.Shipment.OrderTracking)
+	orderRouter.GET("/:id/shipments", m.AdminMiddleware(db), store.Shipment.ListShipments)
+	orderRouter.POST("/:id/shipments", m.AdminMiddleware(db), store.Shipment.CreateShipment)
+	orderRouter.GET("/:id/returns", store.Return.ListOrderReturns)
+	orderRouter.POST("/:id/returns", store.Return.RequestReturn)
+	orderRouter.POST("/:id/returns/:returnId/photos", store.Return.UploadPhoto)
+	orderRouter.POST("/:id/invoice", store.Invoice.IssueInvoice)
+	orderRouter.GET("/:id/invoices", store.Invoice.ListOrderInvoices)
+	orderRouter.GET("/:id/invoices/:invoiceId/pdf", store.Invoice.OrderInvoicePDF)
+
+	// Shipments Routes
+	shipmentRouter := router.Group("/shipments", m.AuthMiddleware(), m.AdminMiddleware(db))
+	shipmentRouter.GET("/:id", store.Shipment.GetShipment)
+	shipmentRouter.GET("/:id/label", store.Shipment.Label)
+	shipmentRouter.POST("/:id/refresh", store.Shipment.RefreshTracking)
+
+	// Returns Routes
+	returnRouter := router.Group("/returns", m.AuthMiddleware())
+	returnRouter.GET("/", m.RoleMiddleware(db), store.Return.ListReturns)
+	returnRouter.GET("/:id", m.RoleMiddleware(db), store.Return.GetReturn)
+	returnRouter.GET("/:id/photos/:photoId", m.RoleMiddleware(db), store.Return.Photo)
+	returnRouter.POST("/:id/approve", m.AdminMiddleware(db), store.Return.Approve)
+	returnRouter.POST("/:id/reject", m.AdminMiddleware(db), store.Return.Reject)
+	returnRouter.POST("/:id/receive", m.AdminMiddleware(db), store.Return.Receive)
+	returnRouter.POST("/:id/refund", m.AdminMiddleware(db), store.Return.Refund)
+
+	// Invoices Routes
+	invoiceRouter := router.Group("/invoices", m.AuthMiddleware())
+	invoiceRouter.GET("/", m.AdminMiddleware(db), store.Invoice.ListInvoices)
+	invoiceRouter.GET("/:id", m.RoleMiddleware(db), store.Invoice.GetInvoice)
+	invoiceRouter.GET("/:id/pdf", m.RoleMiddleware(db), store.Invoice.InvoicePDF)
+	invoiceRouter.POST("/:id/credit-notes", m.AdminMiddleware(db), store.Invoice.IssueCreditNote)
+
+	// Gift Cards Routes
+	giftCardRouter := router.Group("/gift-cards", m.AuthMiddleware(), m.AdminMiddleware(db))
+	giftCardRouter.GET("/", store.GiftCard.ListGiftCards)
+	giftCardRouter.POST("/", store.GiftCard.IssueGiftCard)
+	giftCardRouter.GET("/:id", store.GiftCard.GetGiftCard)
+	giftCardRouter.POST("/:id/adjust", store.GiftCard.AdjustGiftCard)
+
+	// Store Credit Routes
+	creditRouter := router.Group("/store-credit", m.AuthMiddleware())
+	creditRouter.GET("/", store.StoreCredit.MyStoreCredit)
+	creditRouter.GET("/:userId", m.RoleMiddleware(db), store.StoreCredit.GetStoreCredit)
+	creditRouter.POST("/:userId/adjust", m.AdminMiddleware(db), store.StoreCredit.AdjustStoreCredit)
 
 	return r
 }
@@ -4,6 +4,7 @@ import (
 	"log"
 	"os"
 	"strconv"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -12,11 +13,20 @@ type Config struct {
 	*AppConfig
 	*DBConfig
 	*JWTConfig
+	*MediaConfig
+	*SchedulerConfig
+	*FlashSaleConfig
+	*CarrierConfig
+	*InvoiceConfig
 }
 
 type AppConfig struct {
 	AppPort    string
 	AppVersion string
+
+	// DefaultCountry is where carts are taxed and shipped to until the
+	// customer gives a country
+	DefaultCountry string
 }
 
 type DBConfig struct {
@@ -32,6 +42,47 @@ type JWTConfig struct {
 	RefreshTokenExpire int
 }
 
+// SchedulerConfig holds the intervals of background jobs, zero disables a job
+type SchedulerConfig struct {
+	PriceInterval     time.Duration
+	FlashSaleInterval time.Duration
+	TrackingInterval  time.Duration
+}
+
+// FlashSaleConfig bounds the purchase transactions of a flash sale in
+// flight at once, a purchase waits at most FlashSaleQueueWait for a slot
+type FlashSaleConfig struct {
+	FlashSaleSlots     int
+	FlashSaleQueueWait time.Duration
+}
+
+// CarrierConfig sets up the carriers, the fake carrier moves a parcel one
+// step of its journey every FakeCarrierStep
+type CarrierConfig struct {
+	FakeCarrierStep time.Duration
+}
+
+// InvoiceConfig is the seller printed on tax invoices. The fiscal year
+// starts on the first of FiscalYearStart, InvoiceFont is a TrueType font
+// with the glyphs of the documents, Thai needs one.
+type InvoiceConfig struct {
+	CompanyName     string
+	CompanyAddress  string
+	CompanyTaxID    string
+	CompanyBranch   string
+	FiscalYearStart int
+	InvoiceFont     string
+}
+
+type MediaConfig struct {
+	Dir         string
+	PrivateDir  string
+	BaseURL     string
+	MaxUploadMB int
+	Renditions  string
+	Workers     int
+}
+
 func LoadConfig(envPath string) *Config {
 	if err := godotenv.Load(envPath); err != nil {
 		log.Fatal("cant loading .env file:", err)
@@ -39,8 +90,9 @@ func LoadConfig(envPath string) *Config {
 
 	return &Config{
 		&AppConfig{
-			AppPort:    getEnv("APP_PORT", "8080"),
-			AppVersion: getEnv("APP_VERSION", "v1"),
+			AppPort:        getEnv("APP_PORT", "8080"),
+			AppVersion:     getEnv("APP_VERSION", "v1"),
+			DefaultCountry: getEnv("DEFAULT_COUNTRY", "TH"),
 		},
 		&DBConfig{
 			DBAddr:       getEnv("DB_URL", ""),
@@ -53,6 +105,34 @@ func LoadConfig(envPath string) *Config {
 			AccessTokenExpire:  getEnvInt("JWT_ACCESS_EXPIRE", 15),
 			RefreshTokenExpire: getEnvInt("JWT_REFRESH_EXPIRE", 1440),
 		},
+		&MediaConfig{
+			Dir:         getEnv("MEDIA_DIR", "./uploads"),
+			PrivateDir:  getEnv("MEDIA_PRIVATE_DIR", "./private"),
+			BaseURL:     getEnv("MEDIA_BASE_URL", "/api/v1/media"),
+			MaxUploadMB: getEnvInt("MEDIA_MAX_UPLOAD_MB", 5),
+			Renditions:  getEnv("MEDIA_RENDITIONS", "thumbnail:160,card:480,zoom:1400"),
+			Workers:     getEnvInt("MEDIA_WORKERS", 2),
+		},
+		&SchedulerConfig{
+			PriceInterval:     getEnvDuration("SCHEDULER_PRICE_INTERVAL", time.Minute),
+			FlashSaleInterval: getEnvDuration("SCHEDULER_FLASH_SALE_INTERVAL", time.Minute),
+			TrackingInterval:  getEnvDuration("SCHEDULER_TRACKING_INTERVAL", 5*time.Minute),
+		},
+		&FlashSaleConfig{
+			FlashSaleSlots:     getEnvInt("FLASH_SALE_SLOTS", 4),
+			FlashSaleQueueWait: getEnvDuration("FLASH_SALE_QUEUE_WAIT", 2*time.Second),
+		},
+		&CarrierConfig{
+			FakeCarrierStep: getEnvDuration("CARRIER_FAKE_STEP", time.Minute),
+		},
+		&InvoiceConfig{
+			CompanyName:     getEnv("COMPANY_NAME", ""),
+			CompanyAddress:  getEnv("COMPANY_ADDRESS", ""),
+			CompanyTaxID:    getEnv("COMPANY_TAX_ID", ""),
+			CompanyBranch:   getEnv("COMPANY_BRANCH", "00000"),
+			FiscalYearStart: getEnvInt("FISCAL_YEAR_START", 1),
+			InvoiceFont:     getEnv("INVOICE_FONT", ""),
+		},
 	}
 }
 
@@ -70,3 +150,11 @@ func getEnvInt(key string, defaultValue int) int {
 	}
 	return defaultValue
 }
+
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	if v, exists := os.LookupEnv(key); exists {
+		value, _ := time.ParseDuration(v)
+		return value
+	}
+	return defaultValue
+}
@@ -0,0 +1,59 @@
+package entities
+
+import "time"
+
+const (
+	AttributeText    = "text"
+	AttributeNumber  = "number"
+	AttributeBoolean = "boolean"
+	AttributeEnum    = "enum"
+)
+
+type Attribute struct {
+	ID        int       `json:"id"`
+	Code      string    `json:"code"`
+	Label     string    `json:"label"`
+	Type      string    `json:"type"`
+	Unit      string    `json:"unit,omitempty"`
+	Options   []string  `json:"options,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type AttributeReq struct {
+	Code    string   `json:"code" binding:"required"`
+	Label   string   `json:"label" binding:"required"`
+	Type    string   `json:"type" binding:"required,oneof=text number boolean enum"`
+	Unit    string   `json:"unit"`
+	Options []string `json:"options"`
+}
+
+type CategoryAttribute struct {
+	Attribute
+	CategoryID int  `json:"category_id"`
+	Required   bool `json:"required"`
+	Position   int  `json:"position"`
+}
+
+type CategoryAttributeReq struct {
+	AttributeID int  `json:"attribute_id" binding:"required"`
+	Required    bool `json:"required"`
+	Position    int  `json:"position"`
+}
+
+// ProductAttribute is a value of a product joined with its definition.
+// Value holds a string, float64 or bool depending on Type.
+type ProductAttribute struct {
+	ProductID   string `json:"-"`
+	AttributeID int    `json:"attribute_id"`
+	Code        string `json:"code"`
+	Label       string `json:"label"`
+	Type        string `json:"type"`
+	Unit        string `json:"unit,omitempty"`
+	Value       any    `json:"value"`
+}
+
+// ProductAttributeReq replaces every attribute value of a product, keyed by
+// attribute code.
+type ProductAttributeReq struct {
+	Values map[string]any `json:"values" binding:"required"`
+}
@@ -0,0 +1,65 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/codepnw/react_go_ecom/pkg/money"
+)
+
+// Cart is priced on every read in the requested currency, nothing is
+// locked in until checkout
+type Cart struct {
+	Currency         string            `json:"currency"`
+	Items            []*CartItem       `json:"items"`
+	ItemCount        int               `json:"item_count"`
+	Subtotal         money.Money       `json:"subtotal"`
+	Discounts        []*Discount       `json:"discounts"`
+	DiscountTotal    money.Money       `json:"discount_total"`
+	FreeShipping     bool              `json:"free_shipping"`
+	TaxCountry       string            `json:"tax_country"`
+	TaxRegion        string            `json:"tax_region,omitempty"`
+	PricesIncludeTax bool              `json:"prices_include_tax"`
+	Taxes            []*TaxSummary     `json:"taxes"`
+	TaxTotal         money.Money       `json:"tax_total"`
+	WeightGrams      int               `json:"weight_grams"`
+	Shipping         *ShippingQuote    `json:"shipping"`
+	ShippingTotal    money.Money       `json:"shipping_total"`
+	Total            money.Money       `json:"total"`
+	RejectedCoupons  []*RejectedCoupon `json:"rejected_coupons,omitempty"`
+}
+
+// CartQuery is how the cart is priced, bound from the URL. The country and
+// region select the tax and shipping zones, the default country when empty.
+// The shipping method, when given, is charged in the totals.
+type CartQuery struct {
+	Currency         string   `form:"currency"`
+	Coupons          []string `form:"coupon"`
+	Country          string   `form:"country"`
+	Region           string   `form:"region" binding:"max=50"`
+	ShippingMethodID *int     `form:"shipping_method"`
+
+	Locale string `form:"-"`
+}
+
+type CartItem struct {
+	ProductID   string      `json:"product_id"`
+	Title       string      `json:"title"`
+	CategoryID  *int        `json:"category_id"`
+	TaxClassID  *int        `json:"tax_class_id"`
+	WeightGrams int         `json:"weight_grams"`
+	Quantity    int         `json:"quantity"`
+	Stock       int         `json:"stock"`
+	UnitPrice   money.Money `json:"unit_price"`
+	LineTotal   money.Money `json:"line_total"`
+	Tax         *LineTax    `json:"tax"`
+	UpdatedAt   *time.Time  `json:"updated_at"`
+}
+
+type CartItemReq struct {
+	ProductID string `json:"product_id" binding:"required"`
+	Quantity  int    `json:"quantity" binding:"required,min=1"`
+}
+
+type CartItemQuantityReq struct {
+	Quantity int `json:"quantity" binding:"required,min=1"`
+}
@@ -1,11 +1,31 @@
 package entities
 
-import "time"
+import (
+	"time"
+
+	"github.com/codepnw/react_go_ecom/pkg/pagination"
+)
 
 type Category struct {
 	ID        int       `json:"id"`
 	Title     string    `json:"title"`
 	CreatedAt time.Time `json:"created_at"`
+
+	SortValues []string `json:"-"`
+}
+
+type CategoryFilter struct {
+	Sort   string `form:"sort"`
+	Locale string `form:"locale"`
+	PageReq
+
+	SortKeys []*SortKey         `form:"-"`
+	After    *pagination.Cursor `form:"-"`
+}
+
+type CategoryListResult struct {
+	Items    []*Category
+	PageInfo *PageInfo
 }
 
 type CategoryReq struct {
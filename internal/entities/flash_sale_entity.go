@@ -0,0 +1,65 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/codepnw/react_go_ecom/pkg/money"
+)
+
+const (
+	FlashSaleUpcoming = "upcoming"
+	FlashSaleLive     = "live"
+	FlashSaleEnded    = "ended"
+)
+
+// FlashSale sells Allocation units of a product at SalePrice between
+// StartsAt and EndsAt, at most PerCustomerLimit to each customer. ClosedAt
+// is set once the unsold units went back to the product.
+type FlashSale struct {
+	ID               int         `json:"id"`
+	Name             string      `json:"name"`
+	ProductID        string      `json:"product_id"`
+	SalePrice        money.Money `json:"sale_price"`
+	Allocation       int         `json:"allocation"`
+	Sold             int         `json:"sold"`
+	Remaining        int         `json:"remaining"`
+	PerCustomerLimit int         `json:"per_customer_limit"`
+	StartsAt         time.Time   `json:"starts_at"`
+	EndsAt           time.Time   `json:"ends_at"`
+	Status           string      `json:"status"`
+	ClosedAt         *time.Time  `json:"closed_at"`
+	CreatedAt        time.Time   `json:"created_at"`
+}
+
+// FlashSaleReq takes the sale price in the base currency
+type FlashSaleReq struct {
+	Name             string      `json:"name" binding:"required,max=100"`
+	ProductID        string      `json:"product_id" binding:"required"`
+	SalePrice        money.Money `json:"sale_price"`
+	Allocation       int         `json:"allocation" binding:"required,min=1"`
+	PerCustomerLimit int         `json:"per_customer_limit" binding:"required,min=1"`
+	StartsAt         time.Time   `json:"starts_at" binding:"required"`
+	EndsAt           time.Time   `json:"ends_at" binding:"required"`
+}
+
+type FlashSaleFilter struct {
+	ProductID string `form:"product_id"`
+	Status    string `form:"status" binding:"omitempty,oneof=upcoming live ended"`
+}
+
+type FlashSalePurchaseReq struct {
+	Quantity int `json:"quantity" binding:"required,min=1"`
+}
+
+// FlashSalePurchase is units bought at the sale price, Remaining is what
+// the sale had left right after
+type FlashSalePurchase struct {
+	ID          int64       `json:"id"`
+	FlashSaleID int         `json:"flash_sale_id"`
+	UserID      string      `json:"user_id"`
+	Quantity    int         `json:"quantity"`
+	UnitPrice   money.Money `json:"unit_price"`
+	Total       money.Money `json:"total"`
+	Remaining   int         `json:"remaining"`
+	CreatedAt   time.Time   `json:"created_at"`
+}
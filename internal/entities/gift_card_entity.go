@@ -0,0 +1,59 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/codepnw/react_go_ecom/pkg/money"
+)
+
+const (
+	GiftCardIssue  = "issue"
+	GiftCardRedeem = "redeem"
+	GiftCardAdjust = "adjust"
+)
+
+// GiftCard is spent at checkout until its balance runs out, a card without
+// ExpiresAt never expires
+type GiftCard struct {
+	ID            int64                  `json:"id"`
+	Code          string                 `json:"code"`
+	Currency      string                 `json:"currency"`
+	InitialAmount money.Money            `json:"initial_amount"`
+	Balance       money.Money            `json:"balance"`
+	ExpiresAt     *time.Time             `json:"expires_at"`
+	Note          string                 `json:"note"`
+	Transactions  []*GiftCardTransaction `json:"transactions,omitempty"`
+	CreatedAt     time.Time              `json:"created_at"`
+	UpdatedAt     *time.Time             `json:"updated_at"`
+}
+
+// GiftCardTransaction is one change of a gift card balance, Amount is
+// negative when the balance went down
+type GiftCardTransaction struct {
+	ID           int64       `json:"id"`
+	Kind         string      `json:"kind"`
+	Amount       money.Money `json:"amount"`
+	BalanceAfter money.Money `json:"balance_after"`
+	OrderID      *int64      `json:"order_id"`
+	Note         string      `json:"note"`
+	CreatedAt    time.Time   `json:"created_at"`
+}
+
+// GiftCardReq issues a card worth Amount, a random code is generated when
+// Code is empty
+type GiftCardReq struct {
+	Code      string      `json:"code" binding:"omitempty,min=8,max=32,alphanum"`
+	Amount    money.Money `json:"amount"`
+	ExpiresAt *time.Time  `json:"expires_at"`
+	Note      string      `json:"note" binding:"max=1000"`
+}
+
+// GiftCardAdjustReq changes a balance by Amount, negative to take off
+type GiftCardAdjustReq struct {
+	Amount money.Money `json:"amount"`
+	Note   string      `json:"note" binding:"required,max=1000"`
+}
+
+type GiftCardFilter struct {
+	Code string `form:"code"`
+}
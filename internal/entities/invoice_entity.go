@@ -0,0 +1,91 @@
+package entities
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/codepnw/react_go_ecom/pkg/money"
+)
+
+const (
+	InvoiceKindInvoice    = "invoice"
+	InvoiceKindCreditNote = "credit_note"
+)
+
+// invoicePrefixes start the document numbers of each kind
+var invoicePrefixes = map[string]string{
+	InvoiceKindInvoice:    "INV",
+	InvoiceKindCreditNote: "CN",
+}
+
+// InvoiceNumber is the printed number, e.g. INV-2026-000042
+func InvoiceNumber(kind string, fiscalYear, sequence int) string {
+	return fmt.Sprintf("%s-%d-%06d", invoicePrefixes[kind], fiscalYear, sequence)
+}
+
+// Invoice is a tax invoice or a credit note against one. Amounts are in the
+// order's currency, Subtotal is before tax.
+type Invoice struct {
+	ID         int64          `json:"id"`
+	Kind       string         `json:"kind"`
+	Number     string         `json:"number"`
+	FiscalYear int            `json:"fiscal_year"`
+	OrderID    int64          `json:"order_id"`
+	InvoiceID  *int64         `json:"invoice_id,omitempty"`
+	RefundID   *int64         `json:"refund_id,omitempty"`
+	Currency   string         `json:"currency"`
+	Seller     Party          `json:"seller"`
+	Buyer      Party          `json:"buyer"`
+	Reason     string         `json:"reason,omitempty"`
+	Lines      []*InvoiceLine `json:"lines,omitempty"`
+	Taxes      []*TaxSummary  `json:"taxes,omitempty"`
+	Subtotal   money.Money    `json:"subtotal"`
+	TaxTotal   money.Money    `json:"tax_total"`
+	Total      money.Money    `json:"total"`
+	IssuedAt   time.Time      `json:"issued_at"`
+
+	Sequence int     `json:"-"`
+	PDFKey   *string `json:"-"`
+}
+
+// Party is the seller or the buyer as printed on a document
+type Party struct {
+	Name    string `json:"name"`
+	TaxID   string `json:"tax_id"`
+	Branch  string `json:"branch"`
+	Address string `json:"address"`
+}
+
+// InvoiceLine amounts are before tax
+type InvoiceLine struct {
+	Description string      `json:"description"`
+	Quantity    int         `json:"quantity"`
+	UnitPrice   money.Money `json:"unit_price"`
+	Amount      money.Money `json:"amount"`
+	TaxName     string      `json:"tax_name,omitempty"`
+	TaxRate     *string     `json:"tax_rate,omitempty"`
+	TaxAmount   money.Money `json:"tax_amount"`
+}
+
+// InvoiceReq names the buyer of a tax invoice, the shipping address fills
+// what is left out
+type InvoiceReq struct {
+	Name    string `json:"name" binding:"max=255"`
+	TaxID   string `json:"tax_id" binding:"omitempty,max=20"`
+	Branch  string `json:"branch" binding:"max=50"`
+	Address string `json:"address" binding:"max=1000"`
+}
+
+// CreditNoteReq credits Amount, or the refund's amount for a refund, in the
+// invoice's currency
+type CreditNoteReq struct {
+	Reason   string       `json:"reason" binding:"required,max=1000"`
+	RefundID *int64       `json:"refund_id"`
+	Amount   *money.Money `json:"amount"`
+}
+
+type InvoiceFilter struct {
+	Kind       string `form:"kind" binding:"omitempty,oneof=invoice credit_note"`
+	FiscalYear int    `form:"fiscal_year"`
+	OrderID    int64  `form:"order_id"`
+}
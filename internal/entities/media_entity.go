@@ -0,0 +1,30 @@
+package entities
+
+import "time"
+
+type ProductImage struct {
+	ID          int               `json:"id"`
+	ProductID   string            `json:"product_id"`
+	Key         string            `json:"-"`
+	URL         string            `json:"url"`
+	ContentType string            `json:"content_type"`
+	Size        int64             `json:"size"`
+	Position    int               `json:"position"`
+	Renditions  []*ImageRendition `json:"renditions"`
+	SrcSet      string            `json:"srcset"`
+	CreatedAt   time.Time         `json:"created_at"`
+}
+
+type ImageRendition struct {
+	ImageID     int    `json:"-"`
+	Name        string `json:"name"`
+	Key         string `json:"-"`
+	URL         string `json:"url"`
+	ContentType string `json:"content_type"`
+	Width       int    `json:"width"`
+	Height      int    `json:"height"`
+}
+
+type ProductImageOrderReq struct {
+	ImageIDs []int `json:"image_ids" binding:"required"`
+}
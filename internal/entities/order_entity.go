@@ -0,0 +1,109 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/codepnw/react_go_ecom/pkg/money"
+	"github.com/codepnw/react_go_ecom/pkg/pagination"
+)
+
+const (
+	OrderPending   = "pending"
+	OrderPaid      = "paid"
+	OrderShipped   = "shipped"
+	OrderDelivered = "delivered"
+	OrderCompleted = "completed"
+	OrderCanceled  = "canceled"
+
+	OrderPartiallyRefunded = "partially_refunded"
+	OrderRefunded          = "refunded"
+)
+
+const (
+	PaymentGiftCard    = "gift_card"
+	PaymentStoreCredit = "store_credit"
+)
+
+// Order amounts are in the order's currency, locked in at checkout together
+// with the exchange rate from the base currency
+type Order struct {
+	ID               int64          `json:"id"`
+	UserID           string         `json:"user_id"`
+	Status           string         `json:"status"`
+	Currency         string         `json:"currency"`
+	ExchangeRate     *string        `json:"exchange_rate"`
+	ExchangeRateID   *int           `json:"exchange_rate_id,omitempty"`
+	Subtotal         money.Money    `json:"subtotal"`
+	DiscountTotal    money.Money    `json:"discount_total"`
+	FreeShipping     bool           `json:"free_shipping"`
+	TaxCountry       *string        `json:"tax_country"`
+	TaxRegion        string         `json:"tax_region,omitempty"`
+	PricesIncludeTax bool           `json:"prices_include_tax"`
+	Taxes            []*TaxSummary  `json:"taxes,omitempty"`
+	TaxTotal         money.Money    `json:"tax_total"`
+	Shipping         *ShippingQuote `json:"shipping"`
+	ShippingTotal    money.Money    `json:"shipping_total"`
+	WeightGrams      int            `json:"weight_grams"`
+	Total            money.Money    `json:"total"`
+	RefundedTotal    money.Money    `json:"refunded_total"`
+	Payments         []*Payment     `json:"payments,omitempty"`
+	GatewayTotal     money.Money    `json:"gateway_total"`
+	ShippingAddress  *Address       `json:"shipping_address"`
+	Items            []*OrderItem   `json:"items,omitempty"`
+	Discounts        []*Discount    `json:"discounts,omitempty"`
+	History          []*OrderEvent  `json:"history,omitempty"`
+	CreatedAt        time.Time      `json:"created_at"`
+	UpdatedAt        *time.Time     `json:"updated_at"`
+
+	SortValues []string `json:"-"`
+
+	// Gift card codes and store credit to pay with at checkout
+	GiftCards      []string `json:"-"`
+	UseStoreCredit bool     `json:"-"`
+}
+
+// OrderItem keeps the title and prices of the checkout, BaseUnitPrice is
+// the price in the base currency at that time
+type OrderItem struct {
+	ID            int64       `json:"id"`
+	ProductID     *string     `json:"product_id"`
+	Title         string      `json:"title"`
+	Quantity      int         `json:"quantity"`
+	UnitPrice     money.Money `json:"unit_price"`
+	BaseUnitPrice money.Money `json:"base_unit_price"`
+	LineTotal     money.Money `json:"line_total"`
+	Tax           *LineTax    `json:"tax"`
+}
+
+// Payment is a part of an order paid by gift card or store credit, the
+// payment gateway is charged the rest. GiftCard is the end of the code.
+type Payment struct {
+	Method    string      `json:"method"`
+	GiftCard  string      `json:"gift_card,omitempty"`
+	Amount    money.Money `json:"amount"`
+	CreatedAt time.Time   `json:"created_at"`
+}
+
+// CheckoutReq needs a full address unless the shipping method is a local
+// pickup, the country alone then selects the tax zone
+type CheckoutReq struct {
+	Currency         string   `json:"currency"`
+	Coupons          []string `json:"coupons"`
+	ShippingMethodID int      `json:"shipping_method_id" binding:"required"`
+	ShippingAddress  Address  `json:"shipping_address"`
+	GiftCards        []string `json:"gift_cards" binding:"max=5,dive,max=40"`
+	UseStoreCredit   bool     `json:"use_store_credit"`
+}
+
+type OrderFilter struct {
+	Status string `form:"status" binding:"omitempty,oneof=pending paid shipped delivered completed canceled partially_refunded refunded"`
+	PageReq
+
+	UserID string             `form:"-"`
+	After  *pagination.Cursor `form:"-"`
+}
+
+type OrderListResult struct {
+	Items    []*Order
+	PageInfo *PageInfo
+}
@@ -0,0 +1,18 @@
+package entities
+
+// PageReq is embedded in list filters. Cursor is next_cursor or prev_cursor
+// of a previous page, total asks for the full count which costs a query.
+type PageReq struct {
+	Cursor    string `form:"cursor"`
+	Limit     int    `form:"limit"`
+	WithTotal bool   `form:"total"`
+}
+
+type PageInfo struct {
+	Limit      int    `json:"limit"`
+	HasNext    bool   `json:"has_next"`
+	HasPrev    bool   `json:"has_prev"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	PrevCursor string `json:"prev_cursor,omitempty"`
+	Total      *int   `json:"total,omitempty"`
+}
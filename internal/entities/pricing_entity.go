@@ -0,0 +1,106 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/codepnw/react_go_ecom/pkg/money"
+	"github.com/codepnw/react_go_ecom/pkg/pagination"
+)
+
+// ExchangeRate converts base into quote, 1 base = Rate quote, from its
+// effective date until a later rate of the pair
+type ExchangeRate struct {
+	ID            int       `json:"id"`
+	Base          string    `json:"base"`
+	Quote         string    `json:"quote"`
+	Rate          string    `json:"rate"`
+	EffectiveFrom time.Time `json:"effective_from"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+type ExchangeRateReq struct {
+	Base          string     `json:"base" binding:"required,len=3"`
+	Quote         string     `json:"quote" binding:"required,len=3"`
+	Rate          string     `json:"rate" binding:"required"`
+	EffectiveFrom *time.Time `json:"effective_from"`
+}
+
+type ExchangeRateFilter struct {
+	Base  string `form:"base"`
+	Quote string `form:"quote"`
+}
+
+// ProductPrice is an explicit price of a product in a currency other than
+// the base one
+type ProductPrice struct {
+	Price     money.Money `json:"price"`
+	UpdatedAt *time.Time  `json:"updated_at"`
+}
+
+type ProductPriceReq struct {
+	Amount string `json:"amount" binding:"required"`
+	Reason string `json:"reason" binding:"max=255"`
+}
+
+const (
+	SchedulePending  = "pending"
+	ScheduleApplied  = "applied"
+	ScheduleCanceled = "canceled"
+)
+
+// PriceAudit says who changed a price and why. Actor is always the signed
+// in user, scheduled changes carry whoever scheduled them instead.
+type PriceAudit struct {
+	Actor  string
+	Reason string
+}
+
+// PriceChange is one entry of a product's price history. OldPrice is nil
+// for a first explicit price, NewPrice for a removed one.
+type PriceChange struct {
+	ID               int64        `json:"id"`
+	ProductID        string       `json:"product_id"`
+	Currency         string       `json:"currency"`
+	OldPrice         *money.Money `json:"old_price"`
+	NewPrice         *money.Money `json:"new_price"`
+	Actor            *string      `json:"actor"`
+	Reason           string       `json:"reason"`
+	ScheduledPriceID *int64       `json:"scheduled_price_id,omitempty"`
+	ChangedAt        time.Time    `json:"changed_at"`
+
+	SortValues []string `json:"-"`
+}
+
+type PriceHistoryFilter struct {
+	Currency string `form:"currency"`
+	PageReq
+
+	ProductID string             `form:"-"`
+	After     *pagination.Cursor `form:"-"`
+}
+
+type PriceHistoryResult struct {
+	Items    []*PriceChange
+	PageInfo *PageInfo
+}
+
+// ScheduledPrice is a price change the scheduler applies at EffectiveAt
+type ScheduledPrice struct {
+	ID          int64       `json:"id"`
+	ProductID   string      `json:"product_id"`
+	Price       money.Money `json:"price"`
+	EffectiveAt time.Time   `json:"effective_at"`
+	Reason      string      `json:"reason"`
+	CreatedBy   *string     `json:"created_by"`
+	Status      string      `json:"status"`
+	AppliedAt   *time.Time  `json:"applied_at"`
+	CreatedAt   time.Time   `json:"created_at"`
+}
+
+// ScheduledPriceReq takes the price in the base currency, or another
+// currency in the object form {"amount": "9.99", "currency": "USD"}
+type ScheduledPriceReq struct {
+	Price       money.Money `json:"price"`
+	EffectiveAt time.Time   `json:"effective_at" binding:"required"`
+	Reason      string      `json:"reason" binding:"max=255"`
+}
@@ -1,26 +1,142 @@
 package entities
 
-import "time"
+import (
+	"time"
+
+	"github.com/codepnw/react_go_ecom/pkg/money"
+	"github.com/codepnw/react_go_ecom/pkg/pagination"
+)
 
 type Product struct {
-	ID          string     `json:"id"`
-	Title       string     `json:"title"`
-	Description string     `json:"description"`
-	Price       float32    `json:"price"`
-	Stock       int        `json:"stock"`
-	Quantity    int        `json:"sold_quantity"`
-	CreatedAt   time.Time  `json:"created_at"`
-	UpdatedAt   *time.Time `json:"updated_at"`
+	ID          string              `json:"id"`
+	Title       string              `json:"title"`
+	Description string              `json:"description"`
+	Price       money.Money         `json:"price"`
+	Stock       int                 `json:"stock"`
+	Quantity    int                 `json:"sold_quantity"`
+	CategoryID  *int                `json:"category_id"`
+	TaxClassID  *int                `json:"tax_class_id"`
+	WeightGrams int                 `json:"weight_grams" binding:"min=0"`
+	LengthMM    int                 `json:"length_mm" binding:"min=0"`
+	WidthMM     int                 `json:"width_mm" binding:"min=0"`
+	HeightMM    int                 `json:"height_mm" binding:"min=0"`
+	Images      []*ProductImage     `json:"images"`
+	Attributes  []*ProductAttribute `json:"attributes"`
+	Highlights  *ProductHighlights  `json:"highlights,omitempty"`
+	CreatedAt   time.Time           `json:"created_at"`
+	UpdatedAt   *time.Time          `json:"updated_at"`
+
+	// PriceReason goes to the price history when an update changes the price
+	PriceReason string `json:"price_reason,omitempty" binding:"max=255"`
+
+	// SortValues is the row's key in the listing order, used for cursors
+	SortValues []string `json:"-"`
+}
+
+// ProductHighlights are search snippets with matches wrapped in <mark>
+type ProductHighlights struct {
+	Title       string `json:"title"`
+	Description string `json:"description"`
 }
 
 type ProductPayloadReq struct {
-	Title       string  `json:"title" binding:"required"`
-	Description string  `json:"description"`
-	Price       float32 `json:"price" binding:"required"`
-	Stock       int     `json:"stock" binding:"required"`
+	Title       string      `json:"title" binding:"required"`
+	Description string      `json:"description"`
+	Price       money.Money `json:"price"`
+	Stock       int         `json:"stock" binding:"required"`
+	CategoryID  *int        `json:"category_id"`
+	TaxClassID  *int        `json:"tax_class_id"`
+	WeightGrams int         `json:"weight_grams" binding:"min=0"`
+	LengthMM    int         `json:"length_mm" binding:"min=0"`
+	WidthMM     int         `json:"width_mm" binding:"min=0"`
+	HeightMM    int         `json:"height_mm" binding:"min=0"`
 }
 
 type ProductStock struct {
-	ProductID string `json:"product_id"`
-	Quantity  int    `json:"quantity"`
+	ProductID string `json:"product_id" binding:"required"`
+	Quantity  int    `json:"quantity" binding:"required,min=1"`
+}
+
+// ProductFilter is the query of the product list, bound from the URL.
+// Attribute filters come from attr[code]=value, e.g. attr[color]=red,blue
+// or attr[weight]=1..5 for number ranges.
+type ProductFilter struct {
+	Search      string       `form:"search"`
+	CategoryID  *int         `form:"category_id"`
+	MinPrice    *money.Money `form:"min_price"`
+	MaxPrice    *money.Money `form:"max_price"`
+	InStock     *bool        `form:"in_stock"`
+	CreatedFrom *time.Time   `form:"created_from" time_format:"2006-01-02"`
+	CreatedTo   *time.Time   `form:"created_to" time_format:"2006-01-02"`
+	Sort        string       `form:"sort"`
+	Facets      *bool        `form:"facets"`
+	Locale      string       `form:"locale"`
+	Currency    string       `form:"currency"`
+	PageReq
+
+	Attributes  []*AttributeFilter `form:"-"`
+	SortKeys    []*SortKey         `form:"-"`
+	SearchMode  string             `form:"-"`
+	SearchTerms []*SearchTerm      `form:"-"`
+	After       *pagination.Cursor `form:"-"`
+	UserID      string             `form:"-"`
+	SessionID   string             `form:"-"`
+}
+
+const (
+	SearchFullText = "fulltext"
+	SearchFuzzy    = "fuzzy"
+)
+
+type AttributeFilter struct {
+	Code   string
+	Type   string
+	Values []string
+	Min    *float64
+	Max    *float64
+}
+
+type SortKey struct {
+	Field string
+	Desc  bool
+}
+
+// ProductListResult is written as a list response, the search and facet
+// fields go to its meta object
+type ProductListResult struct {
+	Items     []*Product     `json:"-"`
+	PageInfo  *PageInfo      `json:"-"`
+	MatchMode string         `json:"match_mode,omitempty"`
+	SearchID  int64          `json:"search_id,omitempty"`
+	Facets    *ProductFacets `json:"facets,omitempty"`
+}
+
+type ProductFacets struct {
+	Categories []*FacetCount     `json:"categories"`
+	Price      *PriceRange       `json:"price"`
+	InStock    int               `json:"in_stock"`
+	Attributes []*AttributeFacet `json:"attributes"`
+}
+
+type FacetCount struct {
+	Value string `json:"value"`
+	Label string `json:"label,omitempty"`
+	Count int    `json:"count"`
+}
+
+type PriceRange struct {
+	Min money.Money `json:"min"`
+	Max money.Money `json:"max"`
+}
+
+// AttributeFacet has value counts for enum and boolean attributes and a
+// min/max range for numbers.
+type AttributeFacet struct {
+	Code   string        `json:"code"`
+	Label  string        `json:"label"`
+	Type   string        `json:"type"`
+	Unit   string        `json:"unit,omitempty"`
+	Values []*FacetCount `json:"values,omitempty"`
+	Min    *float64      `json:"min,omitempty"`
+	Max    *float64      `json:"max,omitempty"`
 }
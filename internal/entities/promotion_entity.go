@@ -0,0 +1,83 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/codepnw/react_go_ecom/pkg/money"
+)
+
+const (
+	PromotionPercentage   = "percentage"
+	PromotionFixedAmount  = "fixed_amount"
+	PromotionFreeShipping = "free_shipping"
+	PromotionBuyXGetY     = "buy_x_get_y"
+)
+
+// Promotion is a discount rule. Without a code it applies automatically,
+// with one it is a coupon. ProductIDs and CategoryIDs narrow it to the
+// matching cart lines, empty applies to every line.
+type Promotion struct {
+	ID           int          `json:"id"`
+	Name         string       `json:"name"`
+	Code         *string      `json:"code"`
+	Kind         string       `json:"kind"`
+	Percent      *string      `json:"percent,omitempty"`
+	Amount       *money.Money `json:"amount,omitempty"`
+	BuyQuantity  *int         `json:"buy_quantity,omitempty"`
+	GetQuantity  *int         `json:"get_quantity,omitempty"`
+	Currency     string       `json:"currency"`
+	ProductIDs   []string     `json:"product_ids"`
+	CategoryIDs  []int64      `json:"category_ids"`
+	MinSpend     *money.Money `json:"min_spend,omitempty"`
+	StartsAt     time.Time    `json:"starts_at"`
+	EndsAt       *time.Time   `json:"ends_at"`
+	UsageLimit   *int         `json:"usage_limit"`
+	PerUserLimit *int         `json:"per_user_limit"`
+	UsedCount    int          `json:"used_count"`
+	Stackable    bool         `json:"stackable"`
+	Priority     int          `json:"priority"`
+	Active       bool         `json:"active"`
+	CreatedAt    time.Time    `json:"created_at"`
+	UpdatedAt    *time.Time   `json:"updated_at"`
+}
+
+// PromotionReq takes amounts as decimals in Currency, the base currency
+// when empty
+type PromotionReq struct {
+	Name         string     `json:"name" binding:"required,max=100"`
+	Code         string     `json:"code" binding:"max=50"`
+	Kind         string     `json:"kind" binding:"required,oneof=percentage fixed_amount free_shipping buy_x_get_y"`
+	Percent      string     `json:"percent"`
+	Amount       string     `json:"amount"`
+	BuyQuantity  int        `json:"buy_quantity" binding:"min=0"`
+	GetQuantity  int        `json:"get_quantity" binding:"min=0"`
+	Currency     string     `json:"currency"`
+	ProductIDs   []string   `json:"product_ids"`
+	CategoryIDs  []int64    `json:"category_ids"`
+	MinSpend     string     `json:"min_spend"`
+	StartsAt     *time.Time `json:"starts_at"`
+	EndsAt       *time.Time `json:"ends_at"`
+	UsageLimit   *int       `json:"usage_limit" binding:"omitempty,min=1"`
+	PerUserLimit *int       `json:"per_user_limit" binding:"omitempty,min=1"`
+	Stackable    bool       `json:"stackable"`
+	Priority     int        `json:"priority"`
+	Active       *bool      `json:"active"`
+}
+
+// Discount is one line of the itemised breakdown. ProductID is empty for
+// free shipping, which has no amount of its own.
+type Discount struct {
+	PromotionID int         `json:"promotion_id"`
+	Code        *string     `json:"code,omitempty"`
+	Name        string      `json:"name"`
+	Kind        string      `json:"kind"`
+	ProductID   string      `json:"product_id,omitempty"`
+	Amount      money.Money `json:"amount"`
+}
+
+// RejectedCoupon explains why an entered coupon was not applied
+type RejectedCoupon struct {
+	Code    string `json:"code"`
+	Reason  string `json:"reason"`
+	Message string `json:"message"`
+}
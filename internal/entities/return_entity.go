@@ -0,0 +1,132 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/codepnw/react_go_ecom/pkg/money"
+)
+
+const (
+	ReturnRequested = "requested"
+	ReturnApproved  = "approved"
+	ReturnRejected  = "rejected"
+	ReturnReceived  = "received"
+	ReturnRefunded  = "refunded"
+)
+
+const (
+	RefundPending   = "pending"
+	RefundSucceeded = "succeeded"
+	RefundFailed    = "failed"
+)
+
+// Order history events besides the status changes
+const (
+	OrderEventStatusChanged   = "status_changed"
+	OrderEventReturnRequested = "return_requested"
+	OrderEventReturnApproved  = "return_approved"
+	OrderEventReturnRejected  = "return_rejected"
+	OrderEventReturnReceived  = "return_received"
+	OrderEventRefunded        = "refunded"
+)
+
+// Return is a customer's request to send back some units of an order
+type Return struct {
+	ID        int64          `json:"id"`
+	OrderID   int64          `json:"order_id"`
+	UserID    string         `json:"user_id"`
+	Status    string         `json:"status"`
+	Reason    string         `json:"reason"`
+	Comment   string         `json:"comment"`
+	AdminNote string         `json:"admin_note"`
+	Items     []*ReturnItem  `json:"items"`
+	Photos    []*ReturnPhoto `json:"photos"`
+	Refunds   []*Refund      `json:"refunds"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt *time.Time     `json:"updated_at"`
+}
+
+// ReturnItem is one order line of a return, AcceptedQuantity is unset
+// until the parcel was inspected
+type ReturnItem struct {
+	OrderItemID       int64   `json:"order_item_id"`
+	ProductID         *string `json:"product_id"`
+	Title             string  `json:"title"`
+	Quantity          int     `json:"quantity"`
+	AcceptedQuantity  *int    `json:"accepted_quantity"`
+	RestockedQuantity int     `json:"restocked_quantity"`
+}
+
+// ReturnPhoto is kept private, the customer and admins read it from
+// GET /returns/:id/photos/:photoId
+type ReturnPhoto struct {
+	ID          int64     `json:"id"`
+	ContentType string    `json:"content_type"`
+	CreatedAt   time.Time `json:"created_at"`
+
+	Key string `json:"-"`
+}
+
+type Refund struct {
+	ID          int64       `json:"id"`
+	OrderID     int64       `json:"order_id"`
+	ReturnID    *int64      `json:"return_id"`
+	Amount      money.Money `json:"amount"`
+	Provider    string      `json:"provider"`
+	ProviderRef *string     `json:"provider_ref"`
+	Status      string      `json:"status"`
+	Note        string      `json:"note"`
+	CreatedAt   time.Time   `json:"created_at"`
+	UpdatedAt   *time.Time  `json:"updated_at"`
+}
+
+type ReturnReq struct {
+	Reason  string           `json:"reason" binding:"required,oneof=damaged defective wrong_item not_as_described no_longer_needed other"`
+	Comment string           `json:"comment" binding:"max=2000"`
+	Items   []*ReturnItemReq `json:"items" binding:"required,min=1,dive"`
+}
+
+type ReturnItemReq struct {
+	OrderItemID int64 `json:"order_item_id" binding:"required"`
+	Quantity    int   `json:"quantity" binding:"required,min=1"`
+}
+
+type ReturnDecisionReq struct {
+	Note string `json:"note" binding:"max=2000"`
+}
+
+// ReturnReceiptReq is the inspection of a received return. Lines left out
+// are accepted in full, Restock puts the accepted units back into stock.
+type ReturnReceiptReq struct {
+	Note  string                `json:"note" binding:"max=2000"`
+	Items []*ReturnInspectedReq `json:"items" binding:"dive"`
+}
+
+type ReturnInspectedReq struct {
+	OrderItemID      int64 `json:"order_item_id" binding:"required"`
+	AcceptedQuantity int   `json:"accepted_quantity" binding:"min=0"`
+	Restock          bool  `json:"restock"`
+}
+
+// RefundReq refunds Amount in the order's currency, without an amount the
+// accepted units are refunded at what was paid for them. StoreCredit pays
+// the refund as store credit instead of through the payment provider.
+type RefundReq struct {
+	Amount      *money.Money `json:"amount"`
+	Note        string       `json:"note" binding:"max=2000"`
+	StoreCredit bool         `json:"store_credit"`
+}
+
+type ReturnFilter struct {
+	Status string `form:"status" binding:"omitempty,oneof=requested approved rejected received refunded"`
+
+	UserID string `form:"-"`
+}
+
+// OrderEvent is one entry of an order's history
+type OrderEvent struct {
+	Event     string    `json:"event"`
+	Status    string    `json:"status"`
+	Note      string    `json:"note,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
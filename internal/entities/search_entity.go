@@ -0,0 +1,100 @@
+package entities
+
+import "time"
+
+type SuggestItem struct {
+	ID     string `json:"id,omitempty"`
+	Text   string `json:"text"`
+	Weight int    `json:"-"`
+}
+
+type Suggestions struct {
+	Query      string         `json:"query"`
+	Products   []*SuggestItem `json:"products"`
+	Categories []*SuggestItem `json:"categories"`
+	Queries    []*SuggestItem `json:"queries"`
+}
+
+// SearchQuery is one logged product search, the ID is handed to the client
+// so it can report which result was clicked.
+type SearchQuery struct {
+	ID          int64     `json:"id"`
+	Query       string    `json:"query"`
+	RawQuery    string    `json:"raw_query"`
+	ResultCount int       `json:"result_count"`
+	MatchMode   string    `json:"match_mode"`
+	UserID      string    `json:"user_id,omitempty"`
+	SessionID   string    `json:"session_id,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type SearchClickReq struct {
+	SearchID  int64  `json:"search_id" binding:"required"`
+	ProductID string `json:"product_id" binding:"required"`
+}
+
+// SearchReportFilter covers whole days, To is inclusive. Without dates the
+// last 30 days are reported.
+type SearchReportFilter struct {
+	From  *time.Time `form:"from" time_format:"2006-01-02"`
+	To    *time.Time `form:"to" time_format:"2006-01-02"`
+	Limit int        `form:"limit"`
+}
+
+type SearchQueryStat struct {
+	Query       string  `json:"query"`
+	Searches    int     `json:"searches"`
+	AvgResults  float64 `json:"avg_results"`
+	ZeroResults int     `json:"zero_results"`
+	Clicks      int     `json:"clicks"`
+	CTR         float64 `json:"ctr"`
+}
+
+type ZeroResultQuery struct {
+	Query          string    `json:"query"`
+	Searches       int       `json:"searches"`
+	LastSearchedAt time.Time `json:"last_searched_at"`
+}
+
+type ClickThroughReport struct {
+	From        string             `json:"from"`
+	To          string             `json:"to"`
+	Searches    int                `json:"searches"`
+	Clicks      int                `json:"clicks"`
+	ZeroResults int                `json:"zero_results"`
+	CTR         float64            `json:"ctr"`
+	Days        []*ClickThroughDay `json:"days"`
+}
+
+type ClickThroughDay struct {
+	Date     string  `json:"date"`
+	Searches int     `json:"searches"`
+	Clicks   int     `json:"clicks"`
+	CTR      float64 `json:"ctr"`
+}
+
+// SearchTerm is one position of a query after synonym expansion, any of
+// the alternatives matches
+type SearchTerm struct {
+	Alternatives []string
+	Negate       bool
+}
+
+type SynonymGroup struct {
+	ID        int       `json:"id"`
+	Terms     []string  `json:"terms"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type SynonymGroupReq struct {
+	Terms []string `json:"terms" binding:"required,min=2,dive,required,max=50"`
+}
+
+type StopWord struct {
+	Word      string    `json:"word"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type StopWordReq struct {
+	Word string `json:"word" binding:"required,max=50"`
+}
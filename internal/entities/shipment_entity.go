@@ -0,0 +1,62 @@
+package entities
+
+import "time"
+
+// Shipment statuses, past pending they are the tracking statuses of the
+// carrier
+const (
+	ShipmentPending        = "pending"
+	ShipmentLabelCreated   = "label_created"
+	ShipmentInTransit      = "in_transit"
+	ShipmentOutForDelivery = "out_for_delivery"
+	ShipmentDelivered      = "delivered"
+	ShipmentException      = "exception"
+)
+
+// Shipment carries some or all units of an order's lines
+type Shipment struct {
+	ID             int64            `json:"id"`
+	OrderID        int64            `json:"order_id"`
+	Carrier        string           `json:"carrier"`
+	TrackingNumber *string          `json:"tracking_number"`
+	Status         string           `json:"status"`
+	WeightGrams    int              `json:"weight_grams"`
+	Items          []*ShipmentItem  `json:"items"`
+	Events         []*TrackingEvent `json:"events"`
+	DeliveredAt    *time.Time       `json:"delivered_at"`
+	CreatedAt      time.Time        `json:"created_at"`
+	UpdatedAt      *time.Time       `json:"updated_at"`
+
+	LabelKey *string `json:"-"`
+}
+
+type ShipmentItem struct {
+	OrderItemID int64   `json:"order_item_id"`
+	ProductID   *string `json:"product_id"`
+	Title       string  `json:"title"`
+	Quantity    int     `json:"quantity"`
+}
+
+type ShipmentReq struct {
+	Carrier string             `json:"carrier" binding:"required,max=30"`
+	Items   []*ShipmentItemReq `json:"items" binding:"required,min=1,dive"`
+}
+
+type ShipmentItemReq struct {
+	OrderItemID int64 `json:"order_item_id" binding:"required"`
+	Quantity    int   `json:"quantity" binding:"required,min=1"`
+}
+
+type TrackingEvent struct {
+	Status      string    `json:"status"`
+	Description string    `json:"description"`
+	Location    string    `json:"location"`
+	OccurredAt  time.Time `json:"occurred_at"`
+}
+
+// OrderTracking is where every parcel of an order is
+type OrderTracking struct {
+	OrderID   int64       `json:"order_id"`
+	Status    string      `json:"status"`
+	Shipments []*Shipment `json:"shipments"`
+}
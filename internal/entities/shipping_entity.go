@@ -0,0 +1,106 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/codepnw/react_go_ecom/pkg/money"
+)
+
+const (
+	ShippingFlatRate    = "flat_rate"
+	ShippingWeightTable = "weight_table"
+	ShippingFreeOver    = "free_over"
+	ShippingLocalPickup = "local_pickup"
+)
+
+// ShippingZone groups the locations served by the same methods
+type ShippingZone struct {
+	ID        int                 `json:"id"`
+	Name      string              `json:"name"`
+	Locations []*ShippingLocation `json:"locations"`
+	Methods   []*ShippingMethod   `json:"methods"`
+	CreatedAt time.Time           `json:"created_at"`
+	UpdatedAt *time.Time          `json:"updated_at"`
+}
+
+// ShippingLocation is a country, or one region of it when Region is set
+type ShippingLocation struct {
+	Country string `json:"country" binding:"required,len=2"`
+	Region  string `json:"region" binding:"max=50"`
+}
+
+type ShippingZoneReq struct {
+	Name      string              `json:"name" binding:"required,max=100"`
+	Locations []*ShippingLocation `json:"locations" binding:"required,min=1,dive"`
+}
+
+// ShippingMethod amounts are in the base currency. Flat rate and local
+// pickup charge Rate, a weight table the first bracket holding the cart
+// weight and free over is free once the discounted subtotal reaches
+// Threshold.
+type ShippingMethod struct {
+	ID          int           `json:"id"`
+	ZoneID      int           `json:"zone_id"`
+	Name        string        `json:"name"`
+	Kind        string        `json:"kind"`
+	Rate        money.Money   `json:"rate"`
+	Threshold   *money.Money  `json:"threshold,omitempty"`
+	WeightRates []*WeightRate `json:"weight_rates,omitempty"`
+	Active      bool          `json:"active"`
+	Position    int           `json:"position"`
+	CreatedAt   time.Time     `json:"created_at"`
+	UpdatedAt   *time.Time    `json:"updated_at"`
+}
+
+// WeightRate charges Rate for carts weighing up to UpToGrams
+type WeightRate struct {
+	UpToGrams int         `json:"up_to_grams" binding:"required,min=1"`
+	Rate      money.Money `json:"rate"`
+}
+
+type ShippingMethodReq struct {
+	Name        string        `json:"name" binding:"required,max=100"`
+	Kind        string        `json:"kind" binding:"required,oneof=flat_rate weight_table free_over local_pickup"`
+	Rate        money.Money   `json:"rate"`
+	Threshold   *money.Money  `json:"threshold"`
+	WeightRates []*WeightRate `json:"weight_rates" binding:"dive"`
+	Active      *bool         `json:"active"`
+	Position    int           `json:"position"`
+}
+
+// ShippingQuote is what a method costs for a cart, in the cart currency
+type ShippingQuote struct {
+	MethodID int         `json:"method_id"`
+	Name     string      `json:"name"`
+	Kind     string      `json:"kind"`
+	Cost     money.Money `json:"cost"`
+}
+
+// ShippingQuotes answers a rate quote, only the methods able to ship the
+// cart to the destination are listed
+type ShippingQuotes struct {
+	Country     string           `json:"country"`
+	Region      string           `json:"region,omitempty"`
+	Currency    string           `json:"currency"`
+	WeightGrams int              `json:"weight_grams"`
+	Methods     []*ShippingQuote `json:"methods"`
+}
+
+// Destination is where the goods go, it selects the tax and shipping zones
+type Destination struct {
+	Country string
+	Region  string
+}
+
+// Address is where an order is delivered, the country and region also
+// select its tax and shipping zones
+type Address struct {
+	Name       string `json:"name" binding:"max=100"`
+	Phone      string `json:"phone" binding:"max=30"`
+	Line1      string `json:"line1" binding:"max=255"`
+	Line2      string `json:"line2" binding:"max=255"`
+	City       string `json:"city" binding:"max=100"`
+	Region     string `json:"region" binding:"max=50"`
+	PostalCode string `json:"postal_code" binding:"max=20"`
+	Country    string `json:"country" binding:"max=2"`
+}
@@ -0,0 +1,41 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/codepnw/react_go_ecom/pkg/money"
+)
+
+const (
+	CreditAdjustment = "adjustment"
+	CreditRefund     = "refund"
+	CreditRedemption = "redemption"
+)
+
+// StoreCredit is what a user can spend at checkout, one balance per
+// currency, with the ledger behind it newest first
+type StoreCredit struct {
+	UserID   string              `json:"user_id"`
+	Balances []money.Money       `json:"balances"`
+	Entries  []*StoreCreditEntry `json:"entries"`
+}
+
+// StoreCreditEntry is an immutable ledger entry, Amount is negative when
+// credit was spent
+type StoreCreditEntry struct {
+	ID           int64       `json:"id"`
+	UserID       string      `json:"user_id"`
+	Kind         string      `json:"kind"`
+	Amount       money.Money `json:"amount"`
+	BalanceAfter money.Money `json:"balance_after"`
+	OrderID      *int64      `json:"order_id"`
+	RefundID     *int64      `json:"refund_id"`
+	Note         string      `json:"note"`
+	CreatedAt    time.Time   `json:"created_at"`
+}
+
+// StoreCreditAdjustReq changes a balance by Amount, negative to take off
+type StoreCreditAdjustReq struct {
+	Amount money.Money `json:"amount"`
+	Note   string      `json:"note" binding:"required,max=1000"`
+}
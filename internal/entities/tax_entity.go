@@ -0,0 +1,75 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/codepnw/react_go_ecom/pkg/money"
+)
+
+// TaxClassStandard taxes products that have no tax class
+const TaxClassStandard = "standard"
+
+type TaxClass struct {
+	ID        int       `json:"id"`
+	Code      string    `json:"code"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type TaxClassReq struct {
+	Code string `json:"code" binding:"required,max=30"`
+	Name string `json:"name" binding:"required,max=100"`
+}
+
+// TaxZone covers a country, or one region of it when Region is set. With
+// PricesIncludeTax the shown prices already contain the tax, otherwise it
+// is added on top.
+type TaxZone struct {
+	ID               int        `json:"id"`
+	Name             string     `json:"name"`
+	Country          string     `json:"country"`
+	Region           string     `json:"region"`
+	PricesIncludeTax bool       `json:"prices_include_tax"`
+	Rates            []*TaxRate `json:"rates"`
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        *time.Time `json:"updated_at"`
+}
+
+type TaxZoneReq struct {
+	Name             string `json:"name" binding:"required,max=100"`
+	Country          string `json:"country" binding:"required,len=2"`
+	Region           string `json:"region" binding:"max=50"`
+	PricesIncludeTax bool   `json:"prices_include_tax"`
+}
+
+// TaxRate is the percentage a tax class pays in a zone
+type TaxRate struct {
+	TaxClassID   int    `json:"tax_class_id"`
+	TaxClassCode string `json:"tax_class"`
+	Name         string `json:"name"`
+	Rate         string `json:"rate"`
+}
+
+type TaxRateReq struct {
+	Name string `json:"name" binding:"required,max=50"`
+	Rate string `json:"rate" binding:"required"`
+}
+
+// LineTax is the tax of one cart or order line after discounts. Taxable is
+// the net amount the rate applies to, with inclusive prices it is the line
+// total less the tax in it.
+type LineTax struct {
+	Name    string      `json:"name"`
+	Rate    string      `json:"rate"`
+	Taxable money.Money `json:"taxable"`
+	Amount  money.Money `json:"amount"`
+}
+
+// TaxSummary adds up the lines taxed at the same rate, as an invoice
+// lists them
+type TaxSummary struct {
+	Name    string      `json:"name"`
+	Rate    string      `json:"rate"`
+	Taxable money.Money `json:"taxable"`
+	Amount  money.Money `json:"amount"`
+}
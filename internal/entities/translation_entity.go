@@ -0,0 +1,38 @@
+package entities
+
+import "time"
+
+// ProductTranslation is the content of a product in one locale, the
+// product's own fields are the default locale. Categories and attributes
+// are translated the same way.
+type ProductTranslation struct {
+	Locale      string     `json:"locale"`
+	Title       string     `json:"title"`
+	Description string     `json:"description"`
+	UpdatedAt   *time.Time `json:"updated_at"`
+}
+
+type ProductTranslationReq struct {
+	Title       string `json:"title" binding:"required,max=255"`
+	Description string `json:"description"`
+}
+
+type CategoryTranslation struct {
+	Locale    string     `json:"locale"`
+	Title     string     `json:"title"`
+	UpdatedAt *time.Time `json:"updated_at"`
+}
+
+type CategoryTranslationReq struct {
+	Title string `json:"title" binding:"required,max=255"`
+}
+
+type AttributeTranslation struct {
+	Locale    string     `json:"locale"`
+	Label     string     `json:"label"`
+	UpdatedAt *time.Time `json:"updated_at"`
+}
+
+type AttributeTranslationReq struct {
+	Label string `json:"label" binding:"required,max=100"`
+}
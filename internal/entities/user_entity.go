@@ -7,6 +7,13 @@ import (
 	"golang.org/x/crypto/bcrypt"
 )
 
+// Roles seeded with the users table
+const (
+	RoleAdmin    = 1
+	RoleSeller   = 2
+	RoleCustomer = 3
+)
+
 type User struct {
 	ID        string     `json:"id"`
 	Email     string     `json:"email"`
@@ -16,6 +23,7 @@ type User struct {
 	RoleID    int        `json:"role_id"`
 	Enabled   bool       `json:"enabled"`
 	Address   string     `json:"address"`
+	Avatar    string     `json:"avatar"`
 	CreatedAt time.Time  `json:"created_at"`
 	UpdatedAt *time.Time `json:"updated_at"`
 }
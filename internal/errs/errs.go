@@ -0,0 +1,118 @@
+package errs
+
+import (
+	"errors"
+	"fmt"
+)
+
+type Kind string
+
+const (
+	KindNotFound          Kind = "not_found"
+	KindConflict          Kind = "conflict"
+	KindValidation        Kind = "validation"
+	KindUnauthorized      Kind = "unauthorized"
+	KindForbidden         Kind = "forbidden"
+	KindInsufficientStock Kind = "insufficient_stock"
+	KindTooManyRequests   Kind = "too_many_requests"
+)
+
+// Sentinels for errors.Is, they match any error of their kind
+var (
+	ErrNotFound          = &Error{Kind: KindNotFound}
+	ErrConflict          = &Error{Kind: KindConflict}
+	ErrValidation        = &Error{Kind: KindValidation}
+	ErrUnauthorized      = &Error{Kind: KindUnauthorized}
+	ErrForbidden         = &Error{Kind: KindForbidden}
+	ErrInsufficientStock = &Error{Kind: KindInsufficientStock}
+	ErrTooManyRequests   = &Error{Kind: KindTooManyRequests}
+)
+
+// Error is a domain error. The kind decides the HTTP status, the code is
+// stable for clients to key on and selects the localized message, which is
+// formatted with Args like Message was.
+type Error struct {
+	Kind    Kind
+	Code    string
+	Message string
+	Args    []any
+	Fields  []FieldError
+	Err     error
+}
+
+// FieldError points a validation failure at one request field, named as it
+// appears in the JSON body or query
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Is matches a sentinel by kind, or another error by kind and code
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	if !ok || t.Kind != e.Kind {
+		return false
+	}
+	return t.Code == "" || t.Code == e.Code
+}
+
+// Wrap keeps the underlying error for logs and errors.As
+func (e *Error) Wrap(err error) *Error {
+	wrapped := *e
+	wrapped.Err = err
+	return &wrapped
+}
+
+func (e *Error) WithFields(fields ...FieldError) *Error {
+	withFields := *e
+	withFields.Fields = append(append([]FieldError{}, e.Fields...), fields...)
+	return &withFields
+}
+
+func newError(kind Kind, code, format string, args ...any) *Error {
+	return &Error{Kind: kind, Code: code, Message: fmt.Sprintf(format, args...), Args: args}
+}
+
+func NotFound(code, format string, args ...any) *Error {
+	return newError(KindNotFound, code, format, args...)
+}
+
+func Conflict(code, format string, args ...any) *Error {
+	return newError(KindConflict, code, format, args...)
+}
+
+func Validation(code, format string, args ...any) *Error {
+	return newError(KindValidation, code, format, args...)
+}
+
+func Unauthorized(code, format string, args ...any) *Error {
+	return newError(KindUnauthorized, code, format, args...)
+}
+
+func Forbidden(code, format string, args ...any) *Error {
+	return newError(KindForbidden, code, format, args...)
+}
+
+func InsufficientStock(code, format string, args ...any) *Error {
+	return newError(KindInsufficientStock, code, format, args...)
+}
+
+// TooManyRequests is load shedding, the client may retry shortly
+func TooManyRequests(code, format string, args ...any) *Error {
+	return newError(KindTooManyRequests, code, format, args...)
+}
+
+// As returns the domain error in err's chain, if any
+func As(err error) (*Error, bool) {
+	var e *Error
+	ok := errors.As(err, &e)
+	return e, ok
+}
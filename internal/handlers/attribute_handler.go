@@ -0,0 +1,161 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/codepnw/react_go_ecom/internal/entities"
+	"github.com/codepnw/react_go_ecom/internal/errs"
+	"github.com/codepnw/react_go_ecom/internal/usecases"
+	"github.com/codepnw/react_go_ecom/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type AttributeHandler interface {
+	Create(c *gin.Context)
+	List(c *gin.Context)
+	Delete(c *gin.Context)
+	AttachToCategory(c *gin.Context)
+	DetachFromCategory(c *gin.Context)
+	ListCategoryAttributes(c *gin.Context)
+	SetProductAttributes(c *gin.Context)
+	ListProductAttributes(c *gin.Context)
+}
+
+type attributeHandler struct {
+	uc usecases.AttributeUsecase
+}
+
+func NewAttributeHandler(uc usecases.AttributeUsecase) AttributeHandler {
+	return &attributeHandler{uc: uc}
+}
+
+func (h *attributeHandler) Create(c *gin.Context) {
+	var req entities.AttributeReq
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.NewResponse(c).Error(http.StatusBadRequest, err)
+		return
+	}
+
+	attr, err := h.uc.CreateAttribute(c.Request.Context(), &req)
+	if err != nil {
+		utils.NewResponse(c).Error(http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.NewResponse(c).Success(http.StatusCreated, attr)
+}
+
+func (h *attributeHandler) List(c *gin.Context) {
+	attributes, err := h.uc.ListAttributes(c.Request.Context())
+	if err != nil {
+		utils.NewResponse(c).Error(http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.NewResponse(c).List(http.StatusOK, attributes, nil, nil)
+}
+
+func (h *attributeHandler) Delete(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.NewResponse(c).Error(http.StatusBadRequest, errs.Validation("invalid_attribute_id", "invalid attribute id"))
+		return
+	}
+
+	if err := h.uc.DeleteAttribute(c.Request.Context(), id); err != nil {
+		utils.NewResponse(c).Error(http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.NewResponse(c).Success(http.StatusOK, fmt.Sprintf("attribute id %d deleted", id))
+}
+
+func (h *attributeHandler) AttachToCategory(c *gin.Context) {
+	categoryID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.NewResponse(c).Error(http.StatusBadRequest, errs.Validation("invalid_category_id", "invalid category id"))
+		return
+	}
+
+	var req entities.CategoryAttributeReq
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.NewResponse(c).Error(http.StatusBadRequest, err)
+		return
+	}
+
+	attributes, err := h.uc.AttachToCategory(c.Request.Context(), categoryID, &req)
+	if err != nil {
+		utils.NewResponse(c).Error(http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.NewResponse(c).Success(http.StatusOK, attributes)
+}
+
+func (h *attributeHandler) DetachFromCategory(c *gin.Context) {
+	categoryID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.NewResponse(c).Error(http.StatusBadRequest, errs.Validation("invalid_category_id", "invalid category id"))
+		return
+	}
+
+	attributeID, err := strconv.Atoi(c.Param("attributeId"))
+	if err != nil {
+		utils.NewResponse(c).Error(http.StatusBadRequest, errs.Validation("invalid_attribute_id", "invalid attribute id"))
+		return
+	}
+
+	if err := h.uc.DetachFromCategory(c.Request.Context(), categoryID, attributeID); err != nil {
+		utils.NewResponse(c).Error(http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.NewResponse(c).Success(http.StatusOK, fmt.Sprintf("attribute id %d removed from category id %d", attributeID, categoryID))
+}
+
+func (h *attributeHandler) ListCategoryAttributes(c *gin.Context) {
+	categoryID, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.NewResponse(c).Error(http.StatusBadRequest, errs.Validation("invalid_category_id", "invalid category id"))
+		return
+	}
+
+	attributes, err := h.uc.ListCategoryAttributes(c.Request.Context(), categoryID, utils.Locale(c))
+	if err != nil {
+		utils.NewResponse(c).Error(http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.NewResponse(c).List(http.StatusOK, attributes, nil, nil)
+}
+
+func (h *attributeHandler) SetProductAttributes(c *gin.Context) {
+	var req entities.ProductAttributeReq
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.NewResponse(c).Error(http.StatusBadRequest, err)
+		return
+	}
+
+	attributes, err := h.uc.SetProductAttributes(c.Request.Context(), c.Param("id"), req.Values)
+	if err != nil {
+		utils.NewResponse(c).Error(http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.NewResponse(c).Success(http.StatusOK, attributes)
+}
+
+func (h *attributeHandler) ListProductAttributes(c *gin.Context) {
+	attributes, err := h.uc.ListProductAttributes(c.Request.Context(), c.Param("id"), utils.Locale(c))
+	if err != nil {
+		utils.NewResponse(c).Error(http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.NewResponse(c).List(http.StatusOK, attributes, nil, nil)
+}
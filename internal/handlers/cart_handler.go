@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/codepnw/react_go_ecom/internal/entities"
+	"github.com/codepnw/react_go_ecom/internal/usecases"
+	"github.com/codepnw/react_go_ecom/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type CartHandler interface {
+	Get(c *gin.Context)
+	ShippingRates(c *gin.Context)
+	AddItem(c *gin.Context)
+	UpdateItem(c *gin.Context)
+	RemoveItem(c *gin.Context)
+}
+
+type cartHandler struct {
+	uc usecases.CartUsecase
+}
+
+func NewCartHandler(uc usecases.CartUsecase) CartHandler {
+	return &cartHandler{uc: uc}
+}
+
+func (h *cartHandler) Get(c *gin.Context) {
+	h.respondCart(c, http.StatusOK)
+}
+
+// ShippingRates quotes the shipping methods for the cart and the country
+// and region query params
+func (h *cartHandler) ShippingRates(c *gin.Context) {
+	var q entities.CartQuery
+
+	if err := c.ShouldBindQuery(&q); err != nil {
+		utils.NewResponse(c).Error(http.StatusBadRequest, err)
+		return
+	}
+
+	quotes, err := h.uc.QuoteShipping(c.Request.Context(), c.GetString("user_id"), &q)
+	if err != nil {
+		utils.NewResponse(c).Error(http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.NewResponse(c).Success(http.StatusOK, quotes)
+}
+
+func (h *cartHandler) AddItem(c *gin.Context) {
+	var req entities.CartItemReq
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.NewResponse(c).Error(http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.uc.AddItem(c.Request.Context(), c.GetString("user_id"), &req); err != nil {
+		utils.NewResponse(c).Error(http.StatusInternalServerError, err)
+		return
+	}
+
+	h.respondCart(c, http.StatusOK)
+}
+
+func (h *cartHandler) UpdateItem(c *gin.Context) {
+	var req entities.CartItemQuantityReq
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.NewResponse(c).Error(http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.uc.UpdateItem(c.Request.Context(), c.GetString("user_id"), c.Param("productId"), &req); err != nil {
+		utils.NewResponse(c).Error(http.StatusInternalServerError, err)
+		return
+	}
+
+	h.respondCart(c, http.StatusOK)
+}
+
+func (h *cartHandler) RemoveItem(c *gin.Context) {
+	if err := h.uc.RemoveItem(c.Request.Context(), c.GetString("user_id"), c.Param("productId")); err != nil {
+		utils.NewResponse(c).Error(http.StatusInternalServerError, err)
+		return
+	}
+
+	h.respondCart(c, http.StatusOK)
+}
+
+// respondCart answers with the whole cart priced as the query params ask,
+// every change returns the new totals
+func (h *cartHandler) respondCart(c *gin.Context, status int) {
+	var q entities.CartQuery
+
+	if err := c.ShouldBindQuery(&q); err != nil {
+		utils.NewResponse(c).Error(http.StatusBadRequest, err)
+		return
+	}
+	q.Locale = utils.Locale(c)
+
+	cart, err := h.uc.Get(c.Request.Context(), c.GetString("user_id"), &q)
+	if err != nil {
+		utils.NewResponse(c).Error(http.StatusInternalServerError, err)
+		return
+	}
+
+	for _, r := range cart.RejectedCoupons {
+		r.Message = utils.Message(c, r.Reason, r.Code)
+	}
+
+	utils.NewResponse(c).Success(status, cart)
+}
@@ -6,7 +6,9 @@ import (
 	"strconv"
 
 	"github.com/codepnw/react_go_ecom/internal/entities"
+	"github.com/codepnw/react_go_ecom/internal/errs"
 	"github.com/codepnw/react_go_ecom/internal/usecases"
+	"github.com/codepnw/react_go_ecom/internal/utils"
 	"github.com/gin-gonic/gin"
 )
 
@@ -28,35 +30,47 @@ func (h *categoryHandler) Create(c *gin.Context) {
 	var payload entities.CategoryReq
 
 	if err := c.ShouldBindJSON(&payload); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		utils.NewResponse(c).Error(http.StatusBadRequest, err)
 		return
 	}
 
 	if err := h.uc.CreateCategory(c.Request.Context(), payload.Title); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		utils.NewResponse(c).Error(http.StatusInternalServerError, err)
 		return
 	}
 
-	c.JSON(http.StatusCreated, gin.H{"message": "added category"})
+	utils.NewResponse(c).Success(http.StatusCreated, "added category")
 }
 
 func (h *categoryHandler) List(c *gin.Context) {
-	categories, err := h.uc.ListCategory(c.Request.Context())
+	var filter entities.CategoryFilter
+
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		utils.NewResponse(c).Error(http.StatusBadRequest, err)
+		return
+	}
+	filter.Locale = utils.Locale(c)
+
+	result, err := h.uc.ListCategory(c.Request.Context(), &filter)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		utils.NewResponse(c).Error(http.StatusInternalServerError, err)
 		return
 	}
 
-	c.JSON(http.StatusOK, categories)
+	utils.NewResponse(c).List(http.StatusOK, result.Items, result.PageInfo, nil)
 }
 
 func (h *categoryHandler) Delete(c *gin.Context) {
-	id, _ := strconv.Atoi(c.Param("id"))
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.NewResponse(c).Error(http.StatusBadRequest, errs.Validation("invalid_category_id", "invalid category id"))
+		return
+	}
 
 	if err := h.uc.DeleteCategory(c.Request.Context(), id); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		utils.NewResponse(c).Error(http.StatusInternalServerError, err)
 		return
 	}
 
-	c.JSON(http.StatusNoContent, gin.H{"message": fmt.Sprintf("category id %v deleted", id)})
+	utils.NewResponse(c).Success(http.StatusOK, fmt.Sprintf("category id %v deleted", id))
 }
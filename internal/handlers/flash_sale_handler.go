@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/codepnw/react_go_ecom/internal/entities"
+	"github.com/codepnw/react_go_ecom/internal/errs"
+	"github.com/codepnw/react_go_ecom/internal/usecases"
+	"github.com/codepnw/react_go_ecom/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type FlashSaleHandler interface {
+	Create(c *gin.Context)
+	List(c *gin.Context)
+	Get(c *gin.Context)
+	Close(c *gin.Context)
+	Purchase(c *gin.Context)
+}
+
+type flashSaleHandler struct {
+	uc usecases.FlashSaleUsecase
+}
+
+func NewFlashSaleHandler(uc usecases.FlashSaleUsecase) FlashSaleHandler {
+	return &flashSaleHandler{uc: uc}
+}
+
+func (h *flashSaleHandler) Create(c *gin.Context) {
+	var req entities.FlashSaleReq
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.NewResponse(c).Error(http.StatusBadRequest, err)
+		return
+	}
+
+	sale, err := h.uc.CreateFlashSale(c.Request.Context(), &req)
+	if err != nil {
+		utils.NewResponse(c).Error(http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.NewResponse(c).Success(http.StatusCreated, sale)
+}
+
+func (h *flashSaleHandler) List(c *gin.Context) {
+	var f entities.FlashSaleFilter
+
+	if err := c.ShouldBindQuery(&f); err != nil {
+		utils.NewResponse(c).Error(http.StatusBadRequest, err)
+		return
+	}
+
+	sales, err := h.uc.ListFlashSales(c.Request.Context(), &f)
+	if err != nil {
+		utils.NewResponse(c).Error(http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.NewResponse(c).List(http.StatusOK, sales, nil, nil)
+}
+
+func (h *flashSaleHandler) Get(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.NewResponse(c).Error(http.StatusBadRequest, errs.Validation("invalid_flash_sale_id", "invalid flash sale id"))
+		return
+	}
+
+	sale, err := h.uc.GetFlashSale(c.Request.Context(), id)
+	if err != nil {
+		utils.NewResponse(c).Error(http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.NewResponse(c).Success(http.StatusOK, sale)
+}
+
+func (h *flashSaleHandler) Close(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.NewResponse(c).Error(http.StatusBadRequest, errs.Validation("invalid_flash_sale_id", "invalid flash sale id"))
+		return
+	}
+
+	if err := h.uc.CloseFlashSale(c.Request.Context(), id); err != nil {
+		utils.NewResponse(c).Error(http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.NewResponse(c).Success(http.StatusOK, fmt.Sprintf("flash sale id %d closed", id))
+}
+
+func (h *flashSaleHandler) Purchase(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.NewResponse(c).Error(http.StatusBadRequest, errs.Validation("invalid_flash_sale_id", "invalid flash sale id"))
+		return
+	}
+
+	var req entities.FlashSalePurchaseReq
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.NewResponse(c).Error(http.StatusBadRequest, err)
+		return
+	}
+
+	purchase, err := h.uc.Purchase(c.Request.Context(), c.GetString("user_id"), id, &req)
+	if err != nil {
+		if errors.Is(err, errs.ErrTooManyRequests) {
+			c.Header("Retry-After", "1")
+		}
+		utils.NewResponse(c).Error(http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.NewResponse(c).Success(http.StatusCreated, purchase)
+}
@@ -0,0 +1,107 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/codepnw/react_go_ecom/internal/entities"
+	"github.com/codepnw/react_go_ecom/internal/errs"
+	"github.com/codepnw/react_go_ecom/internal/usecases"
+	"github.com/codepnw/react_go_ecom/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type GiftCardHandler interface {
+	IssueGiftCard(c *gin.Context)
+	ListGiftCards(c *gin.Context)
+	GetGiftCard(c *gin.Context)
+	AdjustGiftCard(c *gin.Context)
+}
+
+type giftCardHandler struct {
+	uc usecases.GiftCardUsecase
+}
+
+func NewGiftCardHandler(uc usecases.GiftCardUsecase) GiftCardHandler {
+	return &giftCardHandler{uc: uc}
+}
+
+func (h *giftCardHandler) IssueGiftCard(c *gin.Context) {
+	var req entities.GiftCardReq
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.NewResponse(c).Error(http.StatusBadRequest, err)
+		return
+	}
+
+	card, err := h.uc.IssueGiftCard(c.Request.Context(), &req)
+	if err != nil {
+		utils.NewResponse(c).Error(http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.NewResponse(c).Success(http.StatusCreated, card)
+}
+
+func (h *giftCardHandler) ListGiftCards(c *gin.Context) {
+	var filter entities.GiftCardFilter
+
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		utils.NewResponse(c).Error(http.StatusBadRequest, err)
+		return
+	}
+
+	cards, err := h.uc.ListGiftCards(c.Request.Context(), &filter)
+	if err != nil {
+		utils.NewResponse(c).Error(http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.NewResponse(c).List(http.StatusOK, cards, nil, nil)
+}
+
+func (h *giftCardHandler) GetGiftCard(c *gin.Context) {
+	id, ok := giftCardID(c)
+	if !ok {
+		return
+	}
+
+	card, err := h.uc.GetGiftCard(c.Request.Context(), id)
+	if err != nil {
+		utils.NewResponse(c).Error(http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.NewResponse(c).Success(http.StatusOK, card)
+}
+
+func (h *giftCardHandler) AdjustGiftCard(c *gin.Context) {
+	id, ok := giftCardID(c)
+	if !ok {
+		return
+	}
+
+	var req entities.GiftCardAdjustReq
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.NewResponse(c).Error(http.StatusBadRequest, err)
+		return
+	}
+
+	card, err := h.uc.AdjustGiftCard(c.Request.Context(), id, &req)
+	if err != nil {
+		utils.NewResponse(c).Error(http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.NewResponse(c).Success(http.StatusOK, card)
+}
+
+func giftCardID(c *gin.Context) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.NewResponse(c).Error(http.StatusBadRequest, errs.Validation("invalid_gift_card_id", "invalid gift card id"))
+		return 0, false
+	}
+	return id, true
+}
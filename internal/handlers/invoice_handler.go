@@ -0,0 +1,189 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/codepnw/react_go_ecom/internal/entities"
+	"github.com/codepnw/react_go_ecom/internal/errs"
+	"github.com/codepnw/react_go_ecom/internal/usecases"
+	"github.com/codepnw/react_go_ecom/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type InvoiceHandler interface {
+	IssueInvoice(c *gin.Context)
+	ListOrderInvoices(c *gin.Context)
+	OrderInvoicePDF(c *gin.Context)
+	ListInvoices(c *gin.Context)
+	GetInvoice(c *gin.Context)
+	InvoicePDF(c *gin.Context)
+	IssueCreditNote(c *gin.Context)
+}
+
+type invoiceHandler struct {
+	uc usecases.InvoiceUsecase
+}
+
+func NewInvoiceHandler(uc usecases.InvoiceUsecase) InvoiceHandler {
+	return &invoiceHandler{uc: uc}
+}
+
+func (h *invoiceHandler) IssueInvoice(c *gin.Context) {
+	orderID, ok := orderID(c)
+	if !ok {
+		return
+	}
+
+	var req entities.InvoiceReq
+
+	// Without a body the buyer is taken from the shipping address
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.NewResponse(c).Error(http.StatusBadRequest, err)
+			return
+		}
+	}
+
+	inv, err := h.uc.IssueInvoice(c.Request.Context(), c.GetString("user_id"), orderID, &req)
+	if err != nil {
+		utils.NewResponse(c).Error(http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.NewResponse(c).Success(http.StatusCreated, inv)
+}
+
+func (h *invoiceHandler) ListOrderInvoices(c *gin.Context) {
+	orderID, ok := orderID(c)
+	if !ok {
+		return
+	}
+
+	invoices, err := h.uc.ListOrderInvoices(c.Request.Context(), c.GetString("user_id"), orderID)
+	if err != nil {
+		utils.NewResponse(c).Error(http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.NewResponse(c).List(http.StatusOK, invoices, nil, nil)
+}
+
+func (h *invoiceHandler) OrderInvoicePDF(c *gin.Context) {
+	orderID, ok := orderID(c)
+	if !ok {
+		return
+	}
+
+	id, err := strconv.ParseInt(c.Param("invoiceId"), 10, 64)
+	if err != nil {
+		utils.NewResponse(c).Error(http.StatusBadRequest, errs.Validation("invalid_invoice_id", "invalid invoice id"))
+		return
+	}
+
+	inv, data, err := h.uc.OrderInvoicePDF(c.Request.Context(), c.GetString("user_id"), orderID, id)
+	if err != nil {
+		utils.NewResponse(c).Error(http.StatusInternalServerError, err)
+		return
+	}
+
+	servePDF(c, inv, data)
+}
+
+func (h *invoiceHandler) ListInvoices(c *gin.Context) {
+	var filter entities.InvoiceFilter
+
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		utils.NewResponse(c).Error(http.StatusBadRequest, err)
+		return
+	}
+
+	invoices, err := h.uc.ListInvoices(c.Request.Context(), &filter)
+	if err != nil {
+		utils.NewResponse(c).Error(http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.NewResponse(c).List(http.StatusOK, invoices, nil, nil)
+}
+
+func (h *invoiceHandler) GetInvoice(c *gin.Context) {
+	id, ok := invoiceID(c)
+	if !ok {
+		return
+	}
+
+	// Admins read any document
+	userID := c.GetString("user_id")
+	if utils.IsAdmin(c) {
+		userID = ""
+	}
+
+	inv, err := h.uc.GetInvoice(c.Request.Context(), userID, id)
+	if err != nil {
+		utils.NewResponse(c).Error(http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.NewResponse(c).Success(http.StatusOK, inv)
+}
+
+func (h *invoiceHandler) InvoicePDF(c *gin.Context) {
+	id, ok := invoiceID(c)
+	if !ok {
+		return
+	}
+
+	// Admins read any document
+	userID := c.GetString("user_id")
+	if utils.IsAdmin(c) {
+		userID = ""
+	}
+
+	inv, data, err := h.uc.InvoicePDF(c.Request.Context(), userID, id)
+	if err != nil {
+		utils.NewResponse(c).Error(http.StatusInternalServerError, err)
+		return
+	}
+
+	servePDF(c, inv, data)
+}
+
+func (h *invoiceHandler) IssueCreditNote(c *gin.Context) {
+	id, ok := invoiceID(c)
+	if !ok {
+		return
+	}
+
+	var req entities.CreditNoteReq
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.NewResponse(c).Error(http.StatusBadRequest, err)
+		return
+	}
+
+	note, err := h.uc.IssueCreditNote(c.Request.Context(), id, &req)
+	if err != nil {
+		utils.NewResponse(c).Error(http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.NewResponse(c).Success(http.StatusCreated, note)
+}
+
+// servePDF sends a document as a download named after its number
+func servePDF(c *gin.Context, inv *entities.Invoice, data []byte) {
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.pdf"`, inv.Number))
+	c.Header("Cache-Control", "private, no-store")
+	c.Data(http.StatusOK, "application/pdf", data)
+}
+
+func invoiceID(c *gin.Context) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.NewResponse(c).Error(http.StatusBadRequest, errs.Validation("invalid_invoice_id", "invalid invoice id"))
+		return 0, false
+	}
+	return id, true
+}
@@ -0,0 +1,210 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/codepnw/react_go_ecom/internal/entities"
+	"github.com/codepnw/react_go_ecom/internal/errs"
+	"github.com/codepnw/react_go_ecom/internal/usecases"
+	"github.com/codepnw/react_go_ecom/internal/utils"
+	"github.com/codepnw/react_go_ecom/pkg/media"
+	"github.com/gin-gonic/gin"
+)
+
+type MediaHandler interface {
+	UploadProductImages(c *gin.Context)
+	ListProductImages(c *gin.Context)
+	DeleteProductImage(c *gin.Context)
+	ReorderProductImages(c *gin.Context)
+	UploadAvatar(c *gin.Context)
+	Serve(c *gin.Context)
+}
+
+type mediaHandler struct {
+	uc      usecases.MediaUsecase
+	maxSize int64
+}
+
+func NewMediaHandler(uc usecases.MediaUsecase, maxUploadMB int) MediaHandler {
+	return &mediaHandler{
+		uc:      uc,
+		maxSize: int64(maxUploadMB) << 20,
+	}
+}
+
+func (h *mediaHandler) UploadProductImages(c *gin.Context) {
+	id := c.Param("id")
+
+	form, err := multipartForm(c, h.maxSize)
+	if err != nil {
+		uploadError(c, h.maxSize, err)
+		return
+	}
+
+	files := append(form.File["images"], form.File["image"]...)
+	if len(files) == 0 {
+		utils.NewResponse(c).Error(http.StatusBadRequest, errs.Validation("images_required", "images is required"))
+		return
+	}
+
+	readers := make([]io.Reader, len(files))
+	for i, fh := range files {
+		if fh.Size > h.maxSize {
+			uploadError(c, h.maxSize, &http.MaxBytesError{Limit: h.maxSize})
+			return
+		}
+
+		file, err := fh.Open()
+		if err != nil {
+			uploadError(c, h.maxSize, err)
+			return
+		}
+		defer file.Close()
+		readers[i] = file
+	}
+
+	images, err := h.uc.UploadProductImages(c.Request.Context(), id, readers)
+	if err != nil {
+		uploadError(c, h.maxSize, err)
+		return
+	}
+
+	utils.NewResponse(c).Success(http.StatusCreated, images)
+}
+
+func (h *mediaHandler) ListProductImages(c *gin.Context) {
+	images, err := h.uc.ListProductImages(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		utils.NewResponse(c).Error(http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.NewResponse(c).List(http.StatusOK, images, nil, nil)
+}
+
+func (h *mediaHandler) DeleteProductImage(c *gin.Context) {
+	id := c.Param("id")
+
+	imageID, err := strconv.Atoi(c.Param("imageId"))
+	if err != nil {
+		utils.NewResponse(c).Error(http.StatusBadRequest, errs.Validation("invalid_image_id", "invalid image id"))
+		return
+	}
+
+	if err := h.uc.DeleteProductImage(c.Request.Context(), id, imageID); err != nil {
+		utils.NewResponse(c).Error(http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.NewResponse(c).Success(http.StatusOK, fmt.Sprintf("image_id %d deleted", imageID))
+}
+
+func (h *mediaHandler) ReorderProductImages(c *gin.Context) {
+	var req entities.ProductImageOrderReq
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.NewResponse(c).Error(http.StatusBadRequest, err)
+		return
+	}
+
+	images, err := h.uc.ReorderProductImages(c.Request.Context(), c.Param("id"), req.ImageIDs)
+	if err != nil {
+		utils.NewResponse(c).Error(http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.NewResponse(c).Success(http.StatusOK, images)
+}
+
+func (h *mediaHandler) UploadAvatar(c *gin.Context) {
+	userID, ok := c.Get("user_id")
+	if !ok {
+		utils.NewResponse(c).Error(http.StatusBadRequest, errs.Unauthorized("unauthorized", "user_id not found"))
+		return
+	}
+
+	form, err := multipartForm(c, h.maxSize)
+	if err != nil {
+		uploadError(c, h.maxSize, err)
+		return
+	}
+
+	files := form.File["image"]
+	if len(files) != 1 {
+		utils.NewResponse(c).Error(http.StatusBadRequest, errs.Validation("single_image_required", "exactly one image is required"))
+		return
+	}
+
+	if files[0].Size > h.maxSize {
+		uploadError(c, h.maxSize, &http.MaxBytesError{Limit: h.maxSize})
+		return
+	}
+
+	file, err := files[0].Open()
+	if err != nil {
+		utils.NewResponse(c).Error(http.StatusBadRequest, err)
+		return
+	}
+	defer file.Close()
+
+	url, err := h.uc.UploadAvatar(c.Request.Context(), userID.(string), file)
+	if err != nil {
+		uploadError(c, h.maxSize, err)
+		return
+	}
+
+	utils.NewResponse(c).Success(http.StatusOK, map[string]string{"avatar": url})
+}
+
+func (h *mediaHandler) Serve(c *gin.Context) {
+	key := strings.TrimPrefix(c.Param("key"), "/")
+
+	blob, err := h.uc.Open(c.Request.Context(), key)
+	if err != nil {
+		if errors.Is(err, media.ErrNotFound) || errors.Is(err, media.ErrInvalidKey) {
+			utils.NewResponse(c).Error(http.StatusNotFound, media.ErrNotFound)
+			return
+		}
+		utils.NewResponse(c).Error(http.StatusInternalServerError, err)
+		return
+	}
+	defer blob.Close()
+
+	// Keys are random and never reused, so the content behind a URL never changes
+	c.DataFromReader(http.StatusOK, -1, media.ContentType(key), blob, map[string]string{
+		"Cache-Control":          "public, max-age=31536000, immutable",
+		"X-Content-Type-Options": "nosniff",
+	})
+}
+
+// multipartForm caps the whole request body before parsing it, leaving room
+// for several files and the form overhead. Each file is checked separately.
+func multipartForm(c *gin.Context, maxSize int64) (*multipart.Form, error) {
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxSize*4)
+	return c.MultipartForm()
+}
+
+// uploadError reports a failed upload of a file of at most maxSize bytes,
+// telling the client why the file was rejected
+func uploadError(c *gin.Context, maxSize int64, err error) {
+	var maxErr *http.MaxBytesError
+
+	switch {
+	case errors.As(err, &maxErr):
+		err = errs.Validation("file_too_large", "file is larger than %d MB", maxSize>>20).Wrap(err)
+	case errors.Is(err, media.ErrTooLarge):
+		err = errs.Validation("image_too_large", "image dimensions are too large").Wrap(err)
+	case errors.Is(err, media.ErrUnsupportedType):
+		err = errs.Validation("unsupported_image_type", "unsupported image type").Wrap(err)
+	case errors.Is(err, http.ErrNotMultipart), errors.Is(err, multipart.ErrMessageTooLarge):
+		err = errs.Validation("invalid_upload", "the request is not a valid multipart upload").Wrap(err)
+	}
+
+	utils.NewResponse(c).Error(http.StatusInternalServerError, err)
+}
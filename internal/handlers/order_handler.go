@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/codepnw/react_go_ecom/internal/entities"
+	"github.com/codepnw/react_go_ecom/internal/errs"
+	"github.com/codepnw/react_go_ecom/internal/usecases"
+	"github.com/codepnw/react_go_ecom/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type OrderHandler interface {
+	Checkout(c *gin.Context)
+	GetOrder(c *gin.Context)
+	ListOrders(c *gin.Context)
+}
+
+type orderHandler struct {
+	uc usecases.OrderUsecase
+}
+
+func NewOrderHandler(uc usecases.OrderUsecase) OrderHandler {
+	return &orderHandler{uc: uc}
+}
+
+func (h *orderHandler) Checkout(c *gin.Context) {
+	var req entities.CheckoutReq
+
+	// The body is optional, checkout defaults to the base currency
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.NewResponse(c).Error(http.StatusBadRequest, err)
+			return
+		}
+	}
+
+	order, err := h.uc.Checkout(c.Request.Context(), c.GetString("user_id"), &req)
+	if err != nil {
+		utils.NewResponse(c).Error(http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.NewResponse(c).Success(http.StatusCreated, order)
+}
+
+func (h *orderHandler) GetOrder(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.NewResponse(c).Error(http.StatusBadRequest, errs.Validation("invalid_order_id", "invalid order id"))
+		return
+	}
+
+	order, err := h.uc.GetOrder(c.Request.Context(), c.GetString("user_id"), id)
+	if err != nil {
+		utils.NewResponse(c).Error(http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.NewResponse(c).Success(http.StatusOK, order)
+}
+
+func (h *orderHandler) ListOrders(c *gin.Context) {
+	var filter entities.OrderFilter
+
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		utils.NewResponse(c).Error(http.StatusBadRequest, err)
+		return
+	}
+	filter.UserID = c.GetString("user_id")
+
+	result, err := h.uc.ListOrders(c.Request.Context(), &filter)
+	if err != nil {
+		utils.NewResponse(c).Error(http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.NewResponse(c).List(http.StatusOK, result.Items, result.PageInfo, nil)
+}
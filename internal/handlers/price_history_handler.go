@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/codepnw/react_go_ecom/internal/entities"
+	"github.com/codepnw/react_go_ecom/internal/errs"
+	"github.com/codepnw/react_go_ecom/internal/usecases"
+	"github.com/codepnw/react_go_ecom/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type PriceHistoryHandler interface {
+	ListHistory(c *gin.Context)
+	SchedulePrice(c *gin.Context)
+	ListSchedules(c *gin.Context)
+	CancelSchedule(c *gin.Context)
+}
+
+type priceHistoryHandler struct {
+	uc usecases.PriceHistoryUsecase
+}
+
+func NewPriceHistoryHandler(uc usecases.PriceHistoryUsecase) PriceHistoryHandler {
+	return &priceHistoryHandler{uc: uc}
+}
+
+func (h *priceHistoryHandler) ListHistory(c *gin.Context) {
+	var filter entities.PriceHistoryFilter
+
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		utils.NewResponse(c).Error(http.StatusBadRequest, err)
+		return
+	}
+	filter.ProductID = c.Param("id")
+
+	result, err := h.uc.ListHistory(c.Request.Context(), &filter)
+	if err != nil {
+		utils.NewResponse(c).Error(http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.NewResponse(c).List(http.StatusOK, result.Items, result.PageInfo, nil)
+}
+
+func (h *priceHistoryHandler) SchedulePrice(c *gin.Context) {
+	var req entities.ScheduledPriceReq
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.NewResponse(c).Error(http.StatusBadRequest, err)
+		return
+	}
+
+	schedule, err := h.uc.SchedulePrice(c.Request.Context(), c.GetString("user_id"), c.Param("id"), &req)
+	if err != nil {
+		utils.NewResponse(c).Error(http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.NewResponse(c).Success(http.StatusCreated, schedule)
+}
+
+func (h *priceHistoryHandler) ListSchedules(c *gin.Context) {
+	schedules, err := h.uc.ListSchedules(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		utils.NewResponse(c).Error(http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.NewResponse(c).List(http.StatusOK, schedules, nil, nil)
+}
+
+func (h *priceHistoryHandler) CancelSchedule(c *gin.Context) {
+	id, err := strconv.ParseInt(c.Param("scheduleId"), 10, 64)
+	if err != nil {
+		utils.NewResponse(c).Error(http.StatusBadRequest, errs.Validation("invalid_schedule_id", "invalid scheduled price id"))
+		return
+	}
+
+	if err := h.uc.CancelSchedule(c.Request.Context(), c.Param("id"), id); err != nil {
+		utils.NewResponse(c).Error(http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.NewResponse(c).Success(http.StatusOK, fmt.Sprintf("scheduled price id %d canceled", id))
+}
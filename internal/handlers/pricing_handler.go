@@ -0,0 +1,117 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/codepnw/react_go_ecom/internal/entities"
+	"github.com/codepnw/react_go_ecom/internal/errs"
+	"github.com/codepnw/react_go_ecom/internal/usecases"
+	"github.com/codepnw/react_go_ecom/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type PricingHandler interface {
+	CreateRate(c *gin.Context)
+	ListRates(c *gin.Context)
+	DeleteRate(c *gin.Context)
+	ListProductPrices(c *gin.Context)
+	SetProductPrice(c *gin.Context)
+	DeleteProductPrice(c *gin.Context)
+}
+
+type pricingHandler struct {
+	uc usecases.PricingUsecase
+}
+
+func NewPricingHandler(uc usecases.PricingUsecase) PricingHandler {
+	return &pricingHandler{uc: uc}
+}
+
+func (h *pricingHandler) CreateRate(c *gin.Context) {
+	var req entities.ExchangeRateReq
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.NewResponse(c).Error(http.StatusBadRequest, err)
+		return
+	}
+
+	rate, err := h.uc.CreateRate(c.Request.Context(), &req)
+	if err != nil {
+		utils.NewResponse(c).Error(http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.NewResponse(c).Success(http.StatusCreated, rate)
+}
+
+func (h *pricingHandler) ListRates(c *gin.Context) {
+	var filter entities.ExchangeRateFilter
+
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		utils.NewResponse(c).Error(http.StatusBadRequest, err)
+		return
+	}
+
+	rates, err := h.uc.ListRates(c.Request.Context(), &filter)
+	if err != nil {
+		utils.NewResponse(c).Error(http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.NewResponse(c).List(http.StatusOK, rates, nil, nil)
+}
+
+func (h *pricingHandler) DeleteRate(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.NewResponse(c).Error(http.StatusBadRequest, errs.Validation("invalid_rate_id", "invalid exchange rate id"))
+		return
+	}
+
+	if err := h.uc.DeleteRate(c.Request.Context(), id); err != nil {
+		utils.NewResponse(c).Error(http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.NewResponse(c).Success(http.StatusOK, fmt.Sprintf("exchange rate id %d deleted", id))
+}
+
+func (h *pricingHandler) ListProductPrices(c *gin.Context) {
+	prices, err := h.uc.ListProductPrices(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		utils.NewResponse(c).Error(http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.NewResponse(c).List(http.StatusOK, prices, nil, nil)
+}
+
+func (h *pricingHandler) SetProductPrice(c *gin.Context) {
+	var req entities.ProductPriceReq
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.NewResponse(c).Error(http.StatusBadRequest, err)
+		return
+	}
+
+	price, err := h.uc.SetProductPrice(c.Request.Context(), c.GetString("user_id"), c.Param("id"), c.Param("currency"), &req)
+	if err != nil {
+		utils.NewResponse(c).Error(http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.NewResponse(c).Success(http.StatusOK, price)
+}
+
+func (h *pricingHandler) DeleteProductPrice(c *gin.Context) {
+	id, currency := c.Param("id"), c.Param("currency")
+
+	if err := h.uc.DeleteProductPrice(c.Request.Context(), c.GetString("user_id"), id, currency); err != nil {
+		utils.NewResponse(c).Error(http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.NewResponse(c).Success(http.StatusOK, fmt.Sprintf("product_id %s price in %s deleted", id, currency))
+}
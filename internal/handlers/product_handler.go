@@ -43,7 +43,7 @@ func (h *productHandler) Create(c *gin.Context) {
 		return
 	}
 
-	product, err := h.uc.GetByID(c.Request.Context(), id)
+	product, err := h.uc.GetByID(c.Request.Context(), id, utils.Locale(c), c.Query("currency"))
 	if err != nil {
 		utils.NewResponse(c).Error(http.StatusInternalServerError, err)
 		return
@@ -55,7 +55,7 @@ func (h *productHandler) Create(c *gin.Context) {
 func (h *productHandler) GetByID(c *gin.Context) {
 	id := c.Param("id")
 
-	product, err := h.uc.GetByID(c.Request.Context(), id)
+	product, err := h.uc.GetByID(c.Request.Context(), id, utils.Locale(c), c.Query("currency"))
 	if err != nil {
 		utils.NewResponse(c).Error(http.StatusInternalServerError, err)
 		return
@@ -65,28 +65,25 @@ func (h *productHandler) GetByID(c *gin.Context) {
 }
 
 func (h *productHandler) List(c *gin.Context) {
-	search := c.Query("search")
-	limit := c.Query("limit")
-	offset := c.Query("offset")
-
-	if search != "" {
-		products, err := h.uc.Search(c.Request.Context(), search)
-		if err != nil {
-			utils.NewResponse(c).Error(http.StatusInternalServerError, err)
-			return
-		}
-
-		utils.NewResponse(c).Success(http.StatusOK, products)
+	var filter entities.ProductFilter
+
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		utils.NewResponse(c).Error(http.StatusBadRequest, err)
 		return
 	}
 
-	products, err := h.uc.List(c.Request.Context(), limit, offset)
+	// Searches are attributed to the signed in user and the client session
+	filter.UserID = c.GetString("user_id")
+	filter.SessionID = c.GetHeader("X-Session-ID")
+	filter.Locale = utils.Locale(c)
+
+	result, err := h.uc.List(c.Request.Context(), &filter, c.QueryMap("attr"))
 	if err != nil {
 		utils.NewResponse(c).Error(http.StatusInternalServerError, err)
 		return
 	}
 
-	utils.NewResponse(c).Success(http.StatusOK, products)
+	utils.NewResponse(c).List(http.StatusOK, result.Items, result.PageInfo, result)
 }
 
 func (h *productHandler) Update(c *gin.Context) {
@@ -99,12 +96,12 @@ func (h *productHandler) Update(c *gin.Context) {
 		return
 	}
 
-	if err := h.uc.Update(c.Request.Context(), id, req); err != nil {
+	if err := h.uc.Update(c.Request.Context(), c.GetString("user_id"), id, req); err != nil {
 		utils.NewResponse(c).Error(http.StatusInternalServerError, err)
 		return
 	}
 
-	product, err := h.uc.GetByID(c.Request.Context(), id)
+	product, err := h.uc.GetByID(c.Request.Context(), id, utils.Locale(c), c.Query("currency"))
 	if err != nil {
 		utils.NewResponse(c).Error(http.StatusInternalServerError, err)
 		return
@@ -132,7 +129,7 @@ func (h *productHandler) ProductPurchase(c *gin.Context) {
 		return
 	}
 
-	if err := h.uc.PurchaseProduct(&req); err != nil {
+	if err := h.uc.PurchaseProduct(c.Request.Context(), &req); err != nil {
 		utils.NewResponse(c).Error(http.StatusInternalServerError, err)
 		return
 	}
@@ -147,7 +144,7 @@ func (h *productHandler) CheckOutOfStock(c *gin.Context) {
 		return
 	}
 
-	utils.NewResponse(c).Success(http.StatusOK, products)
+	utils.NewResponse(c).List(http.StatusOK, products, nil, nil)
 }
 
 func (h *productHandler) RestockProduct(c *gin.Context) {
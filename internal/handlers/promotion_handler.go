@@ -0,0 +1,110 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/codepnw/react_go_ecom/internal/entities"
+	"github.com/codepnw/react_go_ecom/internal/errs"
+	"github.com/codepnw/react_go_ecom/internal/usecases"
+	"github.com/codepnw/react_go_ecom/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type PromotionHandler interface {
+	Create(c *gin.Context)
+	List(c *gin.Context)
+	Get(c *gin.Context)
+	Update(c *gin.Context)
+	Delete(c *gin.Context)
+}
+
+type promotionHandler struct {
+	uc usecases.PromotionUsecase
+}
+
+func NewPromotionHandler(uc usecases.PromotionUsecase) PromotionHandler {
+	return &promotionHandler{uc: uc}
+}
+
+func (h *promotionHandler) Create(c *gin.Context) {
+	var req entities.PromotionReq
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.NewResponse(c).Error(http.StatusBadRequest, err)
+		return
+	}
+
+	promo, err := h.uc.CreatePromotion(c.Request.Context(), &req)
+	if err != nil {
+		utils.NewResponse(c).Error(http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.NewResponse(c).Success(http.StatusCreated, promo)
+}
+
+func (h *promotionHandler) List(c *gin.Context) {
+	promos, err := h.uc.ListPromotions(c.Request.Context())
+	if err != nil {
+		utils.NewResponse(c).Error(http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.NewResponse(c).List(http.StatusOK, promos, nil, nil)
+}
+
+func (h *promotionHandler) Get(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.NewResponse(c).Error(http.StatusBadRequest, errs.Validation("invalid_promotion_id", "invalid promotion id"))
+		return
+	}
+
+	promo, err := h.uc.GetPromotion(c.Request.Context(), id)
+	if err != nil {
+		utils.NewResponse(c).Error(http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.NewResponse(c).Success(http.StatusOK, promo)
+}
+
+func (h *promotionHandler) Update(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.NewResponse(c).Error(http.StatusBadRequest, errs.Validation("invalid_promotion_id", "invalid promotion id"))
+		return
+	}
+
+	var req entities.PromotionReq
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.NewResponse(c).Error(http.StatusBadRequest, err)
+		return
+	}
+
+	promo, err := h.uc.UpdatePromotion(c.Request.Context(), id, &req)
+	if err != nil {
+		utils.NewResponse(c).Error(http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.NewResponse(c).Success(http.StatusOK, promo)
+}
+
+func (h *promotionHandler) Delete(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.NewResponse(c).Error(http.StatusBadRequest, errs.Validation("invalid_promotion_id", "invalid promotion id"))
+		return
+	}
+
+	if err := h.uc.DeletePromotion(c.Request.Context(), id); err != nil {
+		utils.NewResponse(c).Error(http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.NewResponse(c).Success(http.StatusOK, fmt.Sprintf("promotion id %d deleted", id))
+}
@@ -0,0 +1,283 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	"github.com/codepnw/react_go_ecom/internal/entities"
+	"github.com/codepnw/react_go_ecom/internal/errs"
+	"github.com/codepnw/react_go_ecom/internal/usecases"
+	"github.com/codepnw/react_go_ecom/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type ReturnHandler interface {
+	RequestReturn(c *gin.Context)
+	ListOrderReturns(c *gin.Context)
+	UploadPhoto(c *gin.Context)
+	ListReturns(c *gin.Context)
+	GetReturn(c *gin.Context)
+	Photo(c *gin.Context)
+	Approve(c *gin.Context)
+	Reject(c *gin.Context)
+	Receive(c *gin.Context)
+	Refund(c *gin.Context)
+}
+
+type returnHandler struct {
+	uc      usecases.ReturnUsecase
+	maxSize int64
+}
+
+func NewReturnHandler(uc usecases.ReturnUsecase, maxUploadMB int) ReturnHandler {
+	return &returnHandler{
+		uc:      uc,
+		maxSize: int64(maxUploadMB) << 20,
+	}
+}
+
+func (h *returnHandler) RequestReturn(c *gin.Context) {
+	orderID, ok := orderID(c)
+	if !ok {
+		return
+	}
+
+	var req entities.ReturnReq
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.NewResponse(c).Error(http.StatusBadRequest, err)
+		return
+	}
+
+	ret, err := h.uc.RequestReturn(c.Request.Context(), c.GetString("user_id"), orderID, &req)
+	if err != nil {
+		utils.NewResponse(c).Error(http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.NewResponse(c).Success(http.StatusCreated, ret)
+}
+
+func (h *returnHandler) ListOrderReturns(c *gin.Context) {
+	orderID, ok := orderID(c)
+	if !ok {
+		return
+	}
+
+	returns, err := h.uc.ListOrderReturns(c.Request.Context(), c.GetString("user_id"), orderID)
+	if err != nil {
+		utils.NewResponse(c).Error(http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.NewResponse(c).List(http.StatusOK, returns, nil, nil)
+}
+
+func (h *returnHandler) UploadPhoto(c *gin.Context) {
+	orderID, ok := orderID(c)
+	if !ok {
+		return
+	}
+
+	id, err := strconv.ParseInt(c.Param("returnId"), 10, 64)
+	if err != nil {
+		utils.NewResponse(c).Error(http.StatusBadRequest, errs.Validation("invalid_return_id", "invalid return id"))
+		return
+	}
+
+	form, err := multipartForm(c, h.maxSize)
+	if err != nil {
+		uploadError(c, h.maxSize, err)
+		return
+	}
+
+	files := form.File["photo"]
+	if len(files) != 1 {
+		utils.NewResponse(c).Error(http.StatusBadRequest, errs.Validation("single_image_required", "exactly one image is required"))
+		return
+	}
+
+	if files[0].Size > h.maxSize {
+		uploadError(c, h.maxSize, &http.MaxBytesError{Limit: h.maxSize})
+		return
+	}
+
+	file, err := files[0].Open()
+	if err != nil {
+		utils.NewResponse(c).Error(http.StatusBadRequest, err)
+		return
+	}
+	defer file.Close()
+
+	photo, err := h.uc.AddPhoto(c.Request.Context(), c.GetString("user_id"), orderID, id, file)
+	if err != nil {
+		uploadError(c, h.maxSize, err)
+		return
+	}
+
+	utils.NewResponse(c).Success(http.StatusCreated, photo)
+}
+
+func (h *returnHandler) ListReturns(c *gin.Context) {
+	var filter entities.ReturnFilter
+
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		utils.NewResponse(c).Error(http.StatusBadRequest, err)
+		return
+	}
+
+	if !utils.IsAdmin(c) {
+		filter.UserID = c.GetString("user_id")
+	}
+
+	returns, err := h.uc.ListReturns(c.Request.Context(), &filter)
+	if err != nil {
+		utils.NewResponse(c).Error(http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.NewResponse(c).List(http.StatusOK, returns, nil, nil)
+}
+
+func (h *returnHandler) GetReturn(c *gin.Context) {
+	id, ok := returnID(c)
+	if !ok {
+		return
+	}
+
+	// Admins read any return
+	userID := c.GetString("user_id")
+	if utils.IsAdmin(c) {
+		userID = ""
+	}
+
+	ret, err := h.uc.GetReturn(c.Request.Context(), userID, id)
+	if err != nil {
+		utils.NewResponse(c).Error(http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.NewResponse(c).Success(http.StatusOK, ret)
+}
+
+func (h *returnHandler) Photo(c *gin.Context) {
+	id, ok := returnID(c)
+	if !ok {
+		return
+	}
+
+	photoID, err := strconv.ParseInt(c.Param("photoId"), 10, 64)
+	if err != nil {
+		utils.NewResponse(c).Error(http.StatusBadRequest, errs.Validation("invalid_photo_id", "invalid photo id"))
+		return
+	}
+
+	// Admins read any photo
+	userID := c.GetString("user_id")
+	if utils.IsAdmin(c) {
+		userID = ""
+	}
+
+	photo, data, err := h.uc.Photo(c.Request.Context(), userID, id, photoID)
+	if err != nil {
+		utils.NewResponse(c).Error(http.StatusInternalServerError, err)
+		return
+	}
+
+	c.Header("Cache-Control", "private, no-store")
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.Data(http.StatusOK, photo.ContentType, data)
+}
+
+func (h *returnHandler) Approve(c *gin.Context) {
+	h.decide(c, h.uc.Approve)
+}
+
+func (h *returnHandler) Reject(c *gin.Context) {
+	h.decide(c, h.uc.Reject)
+}
+
+func (h *returnHandler) decide(c *gin.Context, decide func(ctx context.Context, id int64, req *entities.ReturnDecisionReq) (*entities.Return, error)) {
+	id, ok := returnID(c)
+	if !ok {
+		return
+	}
+
+	var req entities.ReturnDecisionReq
+
+	// The note is optional
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.NewResponse(c).Error(http.StatusBadRequest, err)
+			return
+		}
+	}
+
+	ret, err := decide(c.Request.Context(), id, &req)
+	if err != nil {
+		utils.NewResponse(c).Error(http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.NewResponse(c).Success(http.StatusOK, ret)
+}
+
+func (h *returnHandler) Receive(c *gin.Context) {
+	id, ok := returnID(c)
+	if !ok {
+		return
+	}
+
+	var req entities.ReturnReceiptReq
+
+	// Without a body every unit is accepted and nothing is restocked
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.NewResponse(c).Error(http.StatusBadRequest, err)
+			return
+		}
+	}
+
+	ret, err := h.uc.Receive(c.Request.Context(), id, &req)
+	if err != nil {
+		utils.NewResponse(c).Error(http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.NewResponse(c).Success(http.StatusOK, ret)
+}
+
+func (h *returnHandler) Refund(c *gin.Context) {
+	id, ok := returnID(c)
+	if !ok {
+		return
+	}
+
+	var req entities.RefundReq
+
+	// Without a body the accepted units are refunded in full
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			utils.NewResponse(c).Error(http.StatusBadRequest, err)
+			return
+		}
+	}
+
+	ret, err := h.uc.Refund(c.Request.Context(), id, &req)
+	if err != nil {
+		utils.NewResponse(c).Error(http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.NewResponse(c).Success(http.StatusOK, ret)
+}
+
+func returnID(c *gin.Context) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.NewResponse(c).Error(http.StatusBadRequest, errs.Validation("invalid_return_id", "invalid return id"))
+		return 0, false
+	}
+	return id, true
+}
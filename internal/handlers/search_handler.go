@@ -0,0 +1,108 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/codepnw/react_go_ecom/internal/entities"
+	"github.com/codepnw/react_go_ecom/internal/usecases"
+	"github.com/codepnw/react_go_ecom/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type SearchHandler interface {
+	Suggest(c *gin.Context)
+	RecordClick(c *gin.Context)
+	TopQueries(c *gin.Context)
+	ZeroResultQueries(c *gin.Context)
+	ClickThrough(c *gin.Context)
+}
+
+type searchHandler struct {
+	uc usecases.SearchUsecase
+}
+
+func NewSearchHandler(uc usecases.SearchUsecase) SearchHandler {
+	return &searchHandler{uc: uc}
+}
+
+func (h *searchHandler) Suggest(c *gin.Context) {
+	limit, _ := strconv.Atoi(c.Query("limit"))
+
+	suggestions, err := h.uc.Suggest(c.Request.Context(), c.Query("q"), limit)
+	if err != nil {
+		utils.NewResponse(c).Error(http.StatusInternalServerError, err)
+		return
+	}
+
+	// Typeahead fires on every keystroke, let the browser reuse answers briefly
+	c.Header("Cache-Control", "public, max-age=60")
+	utils.NewResponse(c).Success(http.StatusOK, suggestions)
+}
+
+func (h *searchHandler) RecordClick(c *gin.Context) {
+	var req entities.SearchClickReq
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.NewResponse(c).Error(http.StatusBadRequest, err)
+		return
+	}
+
+	if err := h.uc.RecordClick(c.Request.Context(), &req); err != nil {
+		utils.NewResponse(c).Error(http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.NewResponse(c).Success(http.StatusOK, "click recorded")
+}
+
+func (h *searchHandler) TopQueries(c *gin.Context) {
+	var filter entities.SearchReportFilter
+
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		utils.NewResponse(c).Error(http.StatusBadRequest, err)
+		return
+	}
+
+	stats, err := h.uc.TopQueries(c.Request.Context(), &filter)
+	if err != nil {
+		utils.NewResponse(c).Error(http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.NewResponse(c).List(http.StatusOK, stats, nil, nil)
+}
+
+func (h *searchHandler) ZeroResultQueries(c *gin.Context) {
+	var filter entities.SearchReportFilter
+
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		utils.NewResponse(c).Error(http.StatusBadRequest, err)
+		return
+	}
+
+	queries, err := h.uc.ZeroResultQueries(c.Request.Context(), &filter)
+	if err != nil {
+		utils.NewResponse(c).Error(http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.NewResponse(c).List(http.StatusOK, queries, nil, nil)
+}
+
+func (h *searchHandler) ClickThrough(c *gin.Context) {
+	var filter entities.SearchReportFilter
+
+	if err := c.ShouldBindQuery(&filter); err != nil {
+		utils.NewResponse(c).Error(http.StatusBadRequest, err)
+		return
+	}
+
+	report, err := h.uc.ClickThrough(c.Request.Context(), &filter)
+	if err != nil {
+		utils.NewResponse(c).Error(http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.NewResponse(c).Success(http.StatusOK, report)
+}
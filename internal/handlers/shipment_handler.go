@@ -0,0 +1,146 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/codepnw/react_go_ecom/internal/entities"
+	"github.com/codepnw/react_go_ecom/internal/errs"
+	"github.com/codepnw/react_go_ecom/internal/usecases"
+	"github.com/codepnw/react_go_ecom/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type ShipmentHandler interface {
+	CreateShipment(c *gin.Context)
+	ListShipments(c *gin.Context)
+	GetShipment(c *gin.Context)
+	RefreshTracking(c *gin.Context)
+	Label(c *gin.Context)
+	OrderTracking(c *gin.Context)
+}
+
+type shipmentHandler struct {
+	uc usecases.ShipmentUsecase
+}
+
+func NewShipmentHandler(uc usecases.ShipmentUsecase) ShipmentHandler {
+	return &shipmentHandler{uc: uc}
+}
+
+func (h *shipmentHandler) CreateShipment(c *gin.Context) {
+	orderID, ok := orderID(c)
+	if !ok {
+		return
+	}
+
+	var req entities.ShipmentReq
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.NewResponse(c).Error(http.StatusBadRequest, err)
+		return
+	}
+
+	shipment, err := h.uc.CreateShipment(c.Request.Context(), orderID, &req)
+	if err != nil {
+		utils.NewResponse(c).Error(http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.NewResponse(c).Success(http.StatusCreated, shipment)
+}
+
+func (h *shipmentHandler) ListShipments(c *gin.Context) {
+	orderID, ok := orderID(c)
+	if !ok {
+		return
+	}
+
+	shipments, err := h.uc.ListShipments(c.Request.Context(), orderID)
+	if err != nil {
+		utils.NewResponse(c).Error(http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.NewResponse(c).List(http.StatusOK, shipments, nil, nil)
+}
+
+func (h *shipmentHandler) GetShipment(c *gin.Context) {
+	id, ok := shipmentID(c)
+	if !ok {
+		return
+	}
+
+	shipment, err := h.uc.GetShipment(c.Request.Context(), id)
+	if err != nil {
+		utils.NewResponse(c).Error(http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.NewResponse(c).Success(http.StatusOK, shipment)
+}
+
+func (h *shipmentHandler) RefreshTracking(c *gin.Context) {
+	id, ok := shipmentID(c)
+	if !ok {
+		return
+	}
+
+	shipment, err := h.uc.RefreshTracking(c.Request.Context(), id)
+	if err != nil {
+		utils.NewResponse(c).Error(http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.NewResponse(c).Success(http.StatusOK, shipment)
+}
+
+func (h *shipmentHandler) Label(c *gin.Context) {
+	id, ok := shipmentID(c)
+	if !ok {
+		return
+	}
+
+	contentType, data, err := h.uc.Label(c.Request.Context(), id)
+	if err != nil {
+		utils.NewResponse(c).Error(http.StatusInternalServerError, err)
+		return
+	}
+
+	c.Header("Cache-Control", "private, no-store")
+	c.Header("X-Content-Type-Options", "nosniff")
+	c.Data(http.StatusOK, contentType, data)
+}
+
+func (h *shipmentHandler) OrderTracking(c *gin.Context) {
+	orderID, ok := orderID(c)
+	if !ok {
+		return
+	}
+
+	tracking, err := h.uc.OrderTracking(c.Request.Context(), c.GetString("user_id"), orderID)
+	if err != nil {
+		utils.NewResponse(c).Error(http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.NewResponse(c).Success(http.StatusOK, tracking)
+}
+
+func orderID(c *gin.Context) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.NewResponse(c).Error(http.StatusBadRequest, errs.Validation("invalid_order_id", "invalid order id"))
+		return 0, false
+	}
+	return id, true
+}
+
+func shipmentID(c *gin.Context) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil {
+		utils.NewResponse(c).Error(http.StatusBadRequest, errs.Validation("invalid_shipment_id", "invalid shipment id"))
+		return 0, false
+	}
+	return id, true
+}
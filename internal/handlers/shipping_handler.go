@@ -0,0 +1,202 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/codepnw/react_go_ecom/internal/entities"
+	"github.com/codepnw/react_go_ecom/internal/errs"
+	"github.com/codepnw/react_go_ecom/internal/usecases"
+	"github.com/codepnw/react_go_ecom/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type ShippingHandler interface {
+	CreateZone(c *gin.Context)
+	ListZones(c *gin.Context)
+	GetZone(c *gin.Context)
+	UpdateZone(c *gin.Context)
+	DeleteZone(c *gin.Context)
+	CreateMethod(c *gin.Context)
+	GetMethod(c *gin.Context)
+	UpdateMethod(c *gin.Context)
+	DeleteMethod(c *gin.Context)
+}
+
+type shippingHandler struct {
+	uc usecases.ShippingUsecase
+}
+
+func NewShippingHandler(uc usecases.ShippingUsecase) ShippingHandler {
+	return &shippingHandler{uc: uc}
+}
+
+func (h *shippingHandler) CreateZone(c *gin.Context) {
+	var req entities.ShippingZoneReq
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.NewResponse(c).Error(http.StatusBadRequest, err)
+		return
+	}
+
+	zone, err := h.uc.CreateZone(c.Request.Context(), &req)
+	if err != nil {
+		utils.NewResponse(c).Error(http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.NewResponse(c).Success(http.StatusCreated, zone)
+}
+
+func (h *shippingHandler) ListZones(c *gin.Context) {
+	zones, err := h.uc.ListZones(c.Request.Context())
+	if err != nil {
+		utils.NewResponse(c).Error(http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.NewResponse(c).List(http.StatusOK, zones, nil, nil)
+}
+
+func (h *shippingHandler) GetZone(c *gin.Context) {
+	id, ok := shippingZoneID(c)
+	if !ok {
+		return
+	}
+
+	zone, err := h.uc.GetZone(c.Request.Context(), id)
+	if err != nil {
+		utils.NewResponse(c).Error(http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.NewResponse(c).Success(http.StatusOK, zone)
+}
+
+func (h *shippingHandler) UpdateZone(c *gin.Context) {
+	id, ok := shippingZoneID(c)
+	if !ok {
+		return
+	}
+
+	var req entities.ShippingZoneReq
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.NewResponse(c).Error(http.StatusBadRequest, err)
+		return
+	}
+
+	zone, err := h.uc.UpdateZone(c.Request.Context(), id, &req)
+	if err != nil {
+		utils.NewResponse(c).Error(http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.NewResponse(c).Success(http.StatusOK, zone)
+}
+
+func (h *shippingHandler) DeleteZone(c *gin.Context) {
+	id, ok := shippingZoneID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.uc.DeleteZone(c.Request.Context(), id); err != nil {
+		utils.NewResponse(c).Error(http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.NewResponse(c).Success(http.StatusOK, fmt.Sprintf("shipping zone id %d deleted", id))
+}
+
+func (h *shippingHandler) CreateMethod(c *gin.Context) {
+	zoneID, ok := shippingZoneID(c)
+	if !ok {
+		return
+	}
+
+	var req entities.ShippingMethodReq
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.NewResponse(c).Error(http.StatusBadRequest, err)
+		return
+	}
+
+	method, err := h.uc.CreateMethod(c.Request.Context(), zoneID, &req)
+	if err != nil {
+		utils.NewResponse(c).Error(http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.NewResponse(c).Success(http.StatusCreated, method)
+}
+
+func (h *shippingHandler) GetMethod(c *gin.Context) {
+	id, ok := shippingMethodID(c)
+	if !ok {
+		return
+	}
+
+	method, err := h.uc.GetMethod(c.Request.Context(), id)
+	if err != nil {
+		utils.NewResponse(c).Error(http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.NewResponse(c).Success(http.StatusOK, method)
+}
+
+func (h *shippingHandler) UpdateMethod(c *gin.Context) {
+	id, ok := shippingMethodID(c)
+	if !ok {
+		return
+	}
+
+	var req entities.ShippingMethodReq
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.NewResponse(c).Error(http.StatusBadRequest, err)
+		return
+	}
+
+	method, err := h.uc.UpdateMethod(c.Request.Context(), id, &req)
+	if err != nil {
+		utils.NewResponse(c).Error(http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.NewResponse(c).Success(http.StatusOK, method)
+}
+
+func (h *shippingHandler) DeleteMethod(c *gin.Context) {
+	id, ok := shippingMethodID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.uc.DeleteMethod(c.Request.Context(), id); err != nil {
+		utils.NewResponse(c).Error(http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.NewResponse(c).Success(http.StatusOK, fmt.Sprintf("shipping method id %d deleted", id))
+}
+
+func shippingZoneID(c *gin.Context) (int, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.NewResponse(c).Error(http.StatusBadRequest, errs.Validation("invalid_shipping_zone_id", "invalid shipping zone id"))
+		return 0, false
+	}
+	return id, true
+}
+
+func shippingMethodID(c *gin.Context) (int, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.NewResponse(c).Error(http.StatusBadRequest, errs.Validation("invalid_shipping_method_id", "invalid shipping method id"))
+		return 0, false
+	}
+	return id, true
+}
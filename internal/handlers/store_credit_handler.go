@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/codepnw/react_go_ecom/internal/entities"
+	"github.com/codepnw/react_go_ecom/internal/errs"
+	"github.com/codepnw/react_go_ecom/internal/usecases"
+	"github.com/codepnw/react_go_ecom/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type StoreCreditHandler interface {
+	MyStoreCredit(c *gin.Context)
+	GetStoreCredit(c *gin.Context)
+	AdjustStoreCredit(c *gin.Context)
+}
+
+type storeCreditHandler struct {
+	uc usecases.StoreCreditUsecase
+}
+
+func NewStoreCreditHandler(uc usecases.StoreCreditUsecase) StoreCreditHandler {
+	return &storeCreditHandler{uc: uc}
+}
+
+func (h *storeCreditHandler) MyStoreCredit(c *gin.Context) {
+	credit, err := h.uc.GetStoreCredit(c.Request.Context(), c.GetString("user_id"))
+	if err != nil {
+		utils.NewResponse(c).Error(http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.NewResponse(c).Success(http.StatusOK, credit)
+}
+
+// GetStoreCredit reads the balance of any user for admins, others only
+// their own
+func (h *storeCreditHandler) GetStoreCredit(c *gin.Context) {
+	if !utils.IsAdmin(c) && c.Param("userId") != c.GetString("user_id") {
+		utils.NewResponse(c).Error(http.StatusForbidden, errs.Forbidden("forbidden", "forbidden"))
+		return
+	}
+
+	credit, err := h.uc.GetStoreCredit(c.Request.Context(), c.Param("userId"))
+	if err != nil {
+		utils.NewResponse(c).Error(http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.NewResponse(c).Success(http.StatusOK, credit)
+}
+
+func (h *storeCreditHandler) AdjustStoreCredit(c *gin.Context) {
+	var req entities.StoreCreditAdjustReq
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.NewResponse(c).Error(http.StatusBadRequest, err)
+		return
+	}
+
+	credit, err := h.uc.AdjustStoreCredit(c.Request.Context(), c.Param("userId"), &req)
+	if err != nil {
+		utils.NewResponse(c).Error(http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.NewResponse(c).Success(http.StatusOK, credit)
+}
@@ -0,0 +1,134 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/codepnw/react_go_ecom/internal/entities"
+	"github.com/codepnw/react_go_ecom/internal/errs"
+	"github.com/codepnw/react_go_ecom/internal/usecases"
+	"github.com/codepnw/react_go_ecom/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type SynonymHandler interface {
+	ListSynonyms(c *gin.Context)
+	CreateSynonym(c *gin.Context)
+	UpdateSynonym(c *gin.Context)
+	DeleteSynonym(c *gin.Context)
+	ListStopWords(c *gin.Context)
+	AddStopWord(c *gin.Context)
+	DeleteStopWord(c *gin.Context)
+}
+
+type synonymHandler struct {
+	uc usecases.SynonymUsecase
+}
+
+func NewSynonymHandler(uc usecases.SynonymUsecase) SynonymHandler {
+	return &synonymHandler{uc: uc}
+}
+
+func (h *synonymHandler) ListSynonyms(c *gin.Context) {
+	groups, err := h.uc.ListSynonyms(c.Request.Context())
+	if err != nil {
+		utils.NewResponse(c).Error(http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.NewResponse(c).List(http.StatusOK, groups, nil, nil)
+}
+
+func (h *synonymHandler) CreateSynonym(c *gin.Context) {
+	var req entities.SynonymGroupReq
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.NewResponse(c).Error(http.StatusBadRequest, err)
+		return
+	}
+
+	group, err := h.uc.CreateSynonym(c.Request.Context(), &req)
+	if err != nil {
+		utils.NewResponse(c).Error(http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.NewResponse(c).Success(http.StatusCreated, group)
+}
+
+func (h *synonymHandler) UpdateSynonym(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.NewResponse(c).Error(http.StatusBadRequest, errs.Validation("invalid_synonym_id", "invalid synonym id"))
+		return
+	}
+
+	var req entities.SynonymGroupReq
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.NewResponse(c).Error(http.StatusBadRequest, err)
+		return
+	}
+
+	group, err := h.uc.UpdateSynonym(c.Request.Context(), id, &req)
+	if err != nil {
+		utils.NewResponse(c).Error(http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.NewResponse(c).Success(http.StatusOK, group)
+}
+
+func (h *synonymHandler) DeleteSynonym(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.NewResponse(c).Error(http.StatusBadRequest, errs.Validation("invalid_synonym_id", "invalid synonym id"))
+		return
+	}
+
+	if err := h.uc.DeleteSynonym(c.Request.Context(), id); err != nil {
+		utils.NewResponse(c).Error(http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.NewResponse(c).Success(http.StatusOK, fmt.Sprintf("synonym id %d deleted", id))
+}
+
+func (h *synonymHandler) ListStopWords(c *gin.Context) {
+	words, err := h.uc.ListStopWords(c.Request.Context())
+	if err != nil {
+		utils.NewResponse(c).Error(http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.NewResponse(c).List(http.StatusOK, words, nil, nil)
+}
+
+func (h *synonymHandler) AddStopWord(c *gin.Context) {
+	var req entities.StopWordReq
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.NewResponse(c).Error(http.StatusBadRequest, err)
+		return
+	}
+
+	word, err := h.uc.AddStopWord(c.Request.Context(), &req)
+	if err != nil {
+		utils.NewResponse(c).Error(http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.NewResponse(c).Success(http.StatusCreated, word)
+}
+
+func (h *synonymHandler) DeleteStopWord(c *gin.Context) {
+	word := c.Param("word")
+
+	if err := h.uc.DeleteStopWord(c.Request.Context(), word); err != nil {
+		utils.NewResponse(c).Error(http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.NewResponse(c).Success(http.StatusOK, fmt.Sprintf("stop word %s deleted", word))
+}
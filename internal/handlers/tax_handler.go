@@ -0,0 +1,214 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/codepnw/react_go_ecom/internal/entities"
+	"github.com/codepnw/react_go_ecom/internal/errs"
+	"github.com/codepnw/react_go_ecom/internal/usecases"
+	"github.com/codepnw/react_go_ecom/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type TaxHandler interface {
+	CreateClass(c *gin.Context)
+	ListClasses(c *gin.Context)
+	DeleteClass(c *gin.Context)
+	CreateZone(c *gin.Context)
+	ListZones(c *gin.Context)
+	GetZone(c *gin.Context)
+	UpdateZone(c *gin.Context)
+	DeleteZone(c *gin.Context)
+	SetRate(c *gin.Context)
+	DeleteRate(c *gin.Context)
+}
+
+type taxHandler struct {
+	uc usecases.TaxUsecase
+}
+
+func NewTaxHandler(uc usecases.TaxUsecase) TaxHandler {
+	return &taxHandler{uc: uc}
+}
+
+func (h *taxHandler) CreateClass(c *gin.Context) {
+	var req entities.TaxClassReq
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.NewResponse(c).Error(http.StatusBadRequest, err)
+		return
+	}
+
+	class, err := h.uc.CreateClass(c.Request.Context(), &req)
+	if err != nil {
+		utils.NewResponse(c).Error(http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.NewResponse(c).Success(http.StatusCreated, class)
+}
+
+func (h *taxHandler) ListClasses(c *gin.Context) {
+	classes, err := h.uc.ListClasses(c.Request.Context())
+	if err != nil {
+		utils.NewResponse(c).Error(http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.NewResponse(c).List(http.StatusOK, classes, nil, nil)
+}
+
+func (h *taxHandler) DeleteClass(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.NewResponse(c).Error(http.StatusBadRequest, errs.Validation("invalid_tax_class_id", "invalid tax class id"))
+		return
+	}
+
+	if err := h.uc.DeleteClass(c.Request.Context(), id); err != nil {
+		utils.NewResponse(c).Error(http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.NewResponse(c).Success(http.StatusOK, fmt.Sprintf("tax class id %d deleted", id))
+}
+
+func (h *taxHandler) CreateZone(c *gin.Context) {
+	var req entities.TaxZoneReq
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.NewResponse(c).Error(http.StatusBadRequest, err)
+		return
+	}
+
+	zone, err := h.uc.CreateZone(c.Request.Context(), &req)
+	if err != nil {
+		utils.NewResponse(c).Error(http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.NewResponse(c).Success(http.StatusCreated, zone)
+}
+
+func (h *taxHandler) ListZones(c *gin.Context) {
+	zones, err := h.uc.ListZones(c.Request.Context())
+	if err != nil {
+		utils.NewResponse(c).Error(http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.NewResponse(c).List(http.StatusOK, zones, nil, nil)
+}
+
+func (h *taxHandler) GetZone(c *gin.Context) {
+	id, ok := taxZoneID(c)
+	if !ok {
+		return
+	}
+
+	zone, err := h.uc.GetZone(c.Request.Context(), id)
+	if err != nil {
+		utils.NewResponse(c).Error(http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.NewResponse(c).Success(http.StatusOK, zone)
+}
+
+func (h *taxHandler) UpdateZone(c *gin.Context) {
+	id, ok := taxZoneID(c)
+	if !ok {
+		return
+	}
+
+	var req entities.TaxZoneReq
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.NewResponse(c).Error(http.StatusBadRequest, err)
+		return
+	}
+
+	zone, err := h.uc.UpdateZone(c.Request.Context(), id, &req)
+	if err != nil {
+		utils.NewResponse(c).Error(http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.NewResponse(c).Success(http.StatusOK, zone)
+}
+
+func (h *taxHandler) DeleteZone(c *gin.Context) {
+	id, ok := taxZoneID(c)
+	if !ok {
+		return
+	}
+
+	if err := h.uc.DeleteZone(c.Request.Context(), id); err != nil {
+		utils.NewResponse(c).Error(http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.NewResponse(c).Success(http.StatusOK, fmt.Sprintf("tax zone id %d deleted", id))
+}
+
+func (h *taxHandler) SetRate(c *gin.Context) {
+	zoneID, classID, ok := taxRateIDs(c)
+	if !ok {
+		return
+	}
+
+	var req entities.TaxRateReq
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.NewResponse(c).Error(http.StatusBadRequest, err)
+		return
+	}
+
+	zone, err := h.uc.SetRate(c.Request.Context(), zoneID, classID, &req)
+	if err != nil {
+		utils.NewResponse(c).Error(http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.NewResponse(c).Success(http.StatusOK, zone)
+}
+
+func (h *taxHandler) DeleteRate(c *gin.Context) {
+	zoneID, classID, ok := taxRateIDs(c)
+	if !ok {
+		return
+	}
+
+	if err := h.uc.DeleteRate(c.Request.Context(), zoneID, classID); err != nil {
+		utils.NewResponse(c).Error(http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.NewResponse(c).Success(http.StatusOK, fmt.Sprintf("tax rate of class id %d in zone id %d deleted", classID, zoneID))
+}
+
+func taxZoneID(c *gin.Context) (int, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.NewResponse(c).Error(http.StatusBadRequest, errs.Validation("invalid_tax_zone_id", "invalid tax zone id"))
+		return 0, false
+	}
+	return id, true
+}
+
+func taxRateIDs(c *gin.Context) (int, int, bool) {
+	zoneID, ok := taxZoneID(c)
+	if !ok {
+		return 0, 0, false
+	}
+
+	classID, err := strconv.Atoi(c.Param("classId"))
+	if err != nil {
+		utils.NewResponse(c).Error(http.StatusBadRequest, errs.Validation("invalid_tax_class_id", "invalid tax class id"))
+		return 0, 0, false
+	}
+
+	return zoneID, classID, true
+}
@@ -0,0 +1,181 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/codepnw/react_go_ecom/internal/entities"
+	"github.com/codepnw/react_go_ecom/internal/errs"
+	"github.com/codepnw/react_go_ecom/internal/usecases"
+	"github.com/codepnw/react_go_ecom/internal/utils"
+	"github.com/gin-gonic/gin"
+)
+
+type TranslationHandler interface {
+	ListProductTranslations(c *gin.Context)
+	SetProductTranslation(c *gin.Context)
+	DeleteProductTranslation(c *gin.Context)
+	ListCategoryTranslations(c *gin.Context)
+	SetCategoryTranslation(c *gin.Context)
+	DeleteCategoryTranslation(c *gin.Context)
+	ListAttributeTranslations(c *gin.Context)
+	SetAttributeTranslation(c *gin.Context)
+	DeleteAttributeTranslation(c *gin.Context)
+}
+
+type translationHandler struct {
+	uc usecases.TranslationUsecase
+}
+
+func NewTranslationHandler(uc usecases.TranslationUsecase) TranslationHandler {
+	return &translationHandler{uc: uc}
+}
+
+func (h *translationHandler) ListProductTranslations(c *gin.Context) {
+	translations, err := h.uc.ListProductTranslations(c.Request.Context(), c.Param("id"))
+	if err != nil {
+		utils.NewResponse(c).Error(http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.NewResponse(c).List(http.StatusOK, translations, nil, nil)
+}
+
+func (h *translationHandler) SetProductTranslation(c *gin.Context) {
+	var req entities.ProductTranslationReq
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.NewResponse(c).Error(http.StatusBadRequest, err)
+		return
+	}
+
+	translation, err := h.uc.SetProductTranslation(c.Request.Context(), c.Param("id"), c.Param("locale"), &req)
+	if err != nil {
+		utils.NewResponse(c).Error(http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.NewResponse(c).Success(http.StatusOK, translation)
+}
+
+func (h *translationHandler) DeleteProductTranslation(c *gin.Context) {
+	id, locale := c.Param("id"), c.Param("locale")
+
+	if err := h.uc.DeleteProductTranslation(c.Request.Context(), id, locale); err != nil {
+		utils.NewResponse(c).Error(http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.NewResponse(c).Success(http.StatusOK, fmt.Sprintf("product_id %s translation %s deleted", id, locale))
+}
+
+func (h *translationHandler) ListCategoryTranslations(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.NewResponse(c).Error(http.StatusBadRequest, errs.Validation("invalid_category_id", "invalid category id"))
+		return
+	}
+
+	translations, err := h.uc.ListCategoryTranslations(c.Request.Context(), id)
+	if err != nil {
+		utils.NewResponse(c).Error(http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.NewResponse(c).List(http.StatusOK, translations, nil, nil)
+}
+
+func (h *translationHandler) SetCategoryTranslation(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.NewResponse(c).Error(http.StatusBadRequest, errs.Validation("invalid_category_id", "invalid category id"))
+		return
+	}
+
+	var req entities.CategoryTranslationReq
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.NewResponse(c).Error(http.StatusBadRequest, err)
+		return
+	}
+
+	translation, err := h.uc.SetCategoryTranslation(c.Request.Context(), id, c.Param("locale"), &req)
+	if err != nil {
+		utils.NewResponse(c).Error(http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.NewResponse(c).Success(http.StatusOK, translation)
+}
+
+func (h *translationHandler) DeleteCategoryTranslation(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.NewResponse(c).Error(http.StatusBadRequest, errs.Validation("invalid_category_id", "invalid category id"))
+		return
+	}
+	locale := c.Param("locale")
+
+	if err := h.uc.DeleteCategoryTranslation(c.Request.Context(), id, locale); err != nil {
+		utils.NewResponse(c).Error(http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.NewResponse(c).Success(http.StatusOK, fmt.Sprintf("category id %d translation %s deleted", id, locale))
+}
+
+func (h *translationHandler) ListAttributeTranslations(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.NewResponse(c).Error(http.StatusBadRequest, errs.Validation("invalid_attribute_id", "invalid attribute id"))
+		return
+	}
+
+	translations, err := h.uc.ListAttributeTranslations(c.Request.Context(), id)
+	if err != nil {
+		utils.NewResponse(c).Error(http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.NewResponse(c).List(http.StatusOK, translations, nil, nil)
+}
+
+func (h *translationHandler) SetAttributeTranslation(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.NewResponse(c).Error(http.StatusBadRequest, errs.Validation("invalid_attribute_id", "invalid attribute id"))
+		return
+	}
+
+	var req entities.AttributeTranslationReq
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		utils.NewResponse(c).Error(http.StatusBadRequest, err)
+		return
+	}
+
+	translation, err := h.uc.SetAttributeTranslation(c.Request.Context(), id, c.Param("locale"), &req)
+	if err != nil {
+		utils.NewResponse(c).Error(http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.NewResponse(c).Success(http.StatusOK, translation)
+}
+
+func (h *translationHandler) DeleteAttributeTranslation(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		utils.NewResponse(c).Error(http.StatusBadRequest, errs.Validation("invalid_attribute_id", "invalid attribute id"))
+		return
+	}
+	locale := c.Param("locale")
+
+	if err := h.uc.DeleteAttributeTranslation(c.Request.Context(), id, locale); err != nil {
+		utils.NewResponse(c).Error(http.StatusInternalServerError, err)
+		return
+	}
+
+	utils.NewResponse(c).Success(http.StatusOK, fmt.Sprintf("attribute id %d translation %s deleted", id, locale))
+}
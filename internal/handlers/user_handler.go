@@ -1,12 +1,12 @@
 package handlers
 
 import (
-	"errors"
 	"fmt"
 	"log"
 	"net/http"
 
 	"github.com/codepnw/react_go_ecom/internal/entities"
+	"github.com/codepnw/react_go_ecom/internal/errs"
 	"github.com/codepnw/react_go_ecom/internal/usecases"
 	"github.com/codepnw/react_go_ecom/internal/utils"
 	"github.com/gin-gonic/gin"
@@ -71,7 +71,7 @@ func (h *userHandler) Login(c *gin.Context) {
 func (h *userHandler) Profile(c *gin.Context) {
 	userID, ok := c.Get("user_id")
 	if !ok {
-		utils.NewResponse(c).Error(http.StatusBadRequest, errors.New("user_id not found"))
+		utils.NewResponse(c).Error(http.StatusBadRequest, errs.Unauthorized("unauthorized", "user_id not found"))
 		return
 	}
 
@@ -0,0 +1,244 @@
+package i18n
+
+// catalogEN has the English message of every error code, formatted with
+// the error's args
+var catalogEN = map[string]string{
+	// Generic
+	"request_failed":    "the request could not be processed",
+	"unexpected_error":  "an unexpected error occurred",
+	"validation_failed": "request has invalid fields",
+	"malformed_body":    "request body is not valid JSON",
+	"empty_body":        "request body is empty",
+	"invalid_query":     "query has a value of the wrong type",
+	"invalid_value":     "invalid value",
+	"invalid_reference": "referenced record does not exist",
+	"already_exists":    "record already exists",
+	"invalid_cursor":    "invalid cursor",
+	"invalid_sort":      "cannot sort by %s",
+
+	// Field messages, formatted with the field name
+	"field_invalid": "%s is invalid",
+	"field_string":  "%s must be a string",
+	"field_boolean": "%s must be true or false",
+	"field_list":    "%s must be a list",
+	"field_object":  "%s must be an object",
+	"field_integer": "%s must be a whole number",
+	"field_number":  "%s must be a number",
+
+	// Auth
+	"unauthorized":          "unauthorized",
+	"forbidden":             "you do not have permission to do this",
+	"missing_token":         "missing token",
+	"invalid_token":         "invalid token",
+	"invalid_token_format":  "invalid token format",
+	"invalid_credentials":   "email or password is invalid",
+	"invalid_refresh_token": "refresh token is invalid or expired",
+
+	// Users
+	"user_not_found":     "user not found",
+	"email_taken":        "email is already taken",
+	"invalid_email":      "invalid email address",
+	"password_too_short": "password must be at least 6 characters",
+
+	// Catalog
+	"product_not_found":        "product not found",
+	"category_not_found":       "category not found",
+	"invalid_category_id":      "invalid category id",
+	"insufficient_stock":       "not enough stock",
+	"product_without_category": "product has no category",
+
+	// Attributes
+	"attribute_not_found":          "attribute not found",
+	"category_attribute_not_found": "attribute is not attached to category",
+	"attribute_code_taken":         "attribute code is already taken",
+	"invalid_attribute_id":         "invalid attribute id",
+	"invalid_attribute_code":       "code must be lowercase letters, digits or underscore",
+	"enum_options_required":        "enum attribute needs at least one option",
+	"unknown_attribute":            "unknown attribute %s",
+	"attribute_required":           "attribute %s is required",
+	"attribute_not_in_category":    "attribute %s is not available for this category",
+	"attribute_value_required":     "attribute %s needs a value",
+	"invalid_attribute_range":      "invalid range for attribute %s",
+	"invalid_attribute_text":       "attribute %s must be a non-empty string",
+	"invalid_attribute_number":     "attribute %s must be a number",
+	"invalid_attribute_boolean":    "attribute %s must be true or false",
+	"invalid_attribute_option":     "attribute %s must be one of %v",
+
+	// Media
+	"image_not_found":        "image not found",
+	"invalid_image_id":       "invalid image id",
+	"images_required":        "images is required",
+	"single_image_required":  "exactly one image is required",
+	"duplicate_image_id":     "duplicate image id in image_ids",
+	"image_order_incomplete": "image_ids must contain every image of the product",
+	"file_too_large":         "file is larger than %d MB",
+	"image_too_large":        "image dimensions are too large",
+	"unsupported_image_type": "unsupported image type",
+	"invalid_upload":         "the request is not a valid multipart upload",
+
+	// Search
+	"search_not_found":       "search not found",
+	"synonym_not_found":      "synonym group not found",
+	"invalid_synonym_id":     "invalid synonym id",
+	"synonym_terms_required": "synonym group needs at least two different terms",
+	"invalid_synonym_term":   "synonym terms cannot contain quotes or start with -",
+	"invalid_stop_word":      "stop word must be a single word",
+	"invalid_date_range":     "from must not be after to",
+	"date_range_too_long":    "date range is limited to one year",
+
+	// Translations
+	"unsupported_locale":         "locale %s is not supported",
+	"default_locale_translation": "content in %s is edited on the item itself",
+	"translation_not_found":      "translation not found",
+
+	// Money
+	"invalid_price":        "price must be greater than zero",
+	"unsupported_currency": "prices are in %s",
+	"unknown_currency":     "currency is not supported",
+	"invalid_amount":       "amount is not a valid decimal for its currency",
+	"amount_too_large":     "the total is too large",
+
+	// Pricing
+	"invalid_rate":            "rate must be a positive decimal with at most %d places",
+	"invalid_rate_id":         "invalid exchange rate id",
+	"same_currency_rate":      "base and quote currency must differ",
+	"exchange_rate_not_found": "exchange rate not found",
+	"currency_unavailable":    "prices in %s are not available",
+	"base_currency_price":     "the %s price is the product's own price",
+	"exchange_rate_required":  "set an exchange rate for %s before pricing in it",
+	"exchange_rate_in_use":    "products are priced in %s, it needs a rate in effect",
+	"product_price_not_found": "product price not found",
+
+	// Cart and orders
+	"cart_empty":           "cart is empty",
+	"cart_item_not_found":  "item is not in the cart",
+	"product_out_of_stock": "not enough stock of %s",
+	"order_not_found":      "order not found",
+	"invalid_order_id":     "invalid order id",
+
+	// Price history
+	"schedule_in_past":          "effective_at must be in the future",
+	"scheduled_price_not_found": "no pending scheduled price with this id",
+	"invalid_schedule_id":       "invalid scheduled price id",
+
+	// Promotions
+	"promotion_not_found":      "promotion not found",
+	"promotion_unavailable":    "promotion %s is no longer available",
+	"coupon_code_taken":        "coupon code already exists",
+	"invalid_coupon_code":      "coupon code must be 3 to 50 letters, digits, - or _",
+	"invalid_promotion_window": "ends_at must be after starts_at",
+	"invalid_percent":          "percent must be above 0 and at most 100 with up to 2 decimals",
+	"invalid_promotion_amount": "amount must be greater than zero",
+	"invalid_buy_x_get_y":      "buy_quantity and get_quantity must be at least 1",
+	"invalid_min_spend":        "min_spend must be greater than zero",
+	"invalid_promotion_id":     "invalid promotion id",
+	"coupon_not_found":         "coupon %s does not exist",
+	"coupon_inactive":          "coupon %s is not active",
+	"coupon_not_started":       "coupon %s is not valid yet",
+	"coupon_expired":           "coupon %s has expired",
+	"coupon_exhausted":         "coupon %s has been used up",
+	"coupon_user_limit":        "you have already used coupon %s",
+	"coupon_currency":          "coupon %s is not valid in this currency",
+	"coupon_min_spend":         "the cart does not reach the minimum spend of coupon %s",
+	"coupon_not_applicable":    "coupon %s does not apply to any item in the cart",
+	"coupon_not_combinable":    "coupon %s cannot be combined with a better offer",
+
+	// Flash sales
+	"flash_sale_not_found":      "flash sale not found",
+	"flash_sale_not_started":    "flash sale has not started yet",
+	"flash_sale_ended":          "flash sale has ended",
+	"flash_sale_sold_out":       "flash sale is sold out",
+	"flash_sale_insufficient":   "only %d left in this flash sale",
+	"flash_sale_limit_reached":  "at most %d per customer in this flash sale",
+	"flash_sale_busy":           "too many buyers right now, try again shortly",
+	"flash_sale_currency":       "sale price must be in %s",
+	"flash_sale_price_too_high": "sale price must be below the regular price of %s",
+	"invalid_flash_sale_window": "ends_at must be after starts_at and in the future",
+	"invalid_flash_sale_id":     "invalid flash sale id",
+
+	// Taxes
+	"tax_class_not_found":    "tax class not found",
+	"tax_zone_not_found":     "tax zone not found",
+	"tax_rate_not_found":     "tax rate not found",
+	"standard_tax_class":     "the standard tax class cannot be removed",
+	"tax_class_code_taken":   "tax class code already exists",
+	"tax_zone_taken":         "a tax zone for this country and region already exists",
+	"invalid_country":        "country must be a 2 letter ISO 3166-1 code",
+	"invalid_tax_class_code": "tax class code must be 2 to 30 lowercase letters, digits or _",
+	"invalid_tax_rate":       "rate must be a percentage from 0 to 100 with up to 4 decimals",
+	"invalid_tax_class_id":   "invalid tax class id",
+	"invalid_tax_zone_id":    "invalid tax zone id",
+
+	// Shipping
+	"shipping_zone_not_found":     "shipping zone not found",
+	"shipping_method_not_found":   "shipping method not found",
+	"shipping_location_taken":     "the location already belongs to a shipping zone",
+	"shipping_method_unavailable": "shipping method is not available for this cart and destination",
+	"shipping_address_required":   "name, line1, city and postal_code are required for delivery",
+	"shipping_currency":           "shipping rates must be in %s",
+	"invalid_shipping_rate":       "shipping rates cannot be negative",
+	"invalid_shipping_threshold":  "free over shipping needs a threshold greater than zero",
+	"invalid_weight_rates":        "a weight table needs brackets with distinct up_to_grams",
+	"invalid_shipping_zone_id":    "invalid shipping zone id",
+	"invalid_shipping_method_id":  "invalid shipping method id",
+
+	// Shipments
+	"shipment_not_found":      "shipment not found",
+	"invalid_shipment_id":     "invalid shipment id",
+	"order_not_shippable":     "this order cannot be shipped",
+	"order_is_pickup":         "this order is picked up in store and cannot be shipped",
+	"order_item_not_found":    "the item is not part of this order",
+	"shipment_over_allocated": "more units than are left to ship",
+	"shipment_not_booked":     "the shipment has no tracking number yet",
+	"label_not_found":         "label not found",
+	"unknown_carrier":         "unknown carrier",
+	"unknown_tracking_number": "the carrier does not know this tracking number",
+	"carrier_unavailable":     "the carrier could not be reached, please try again",
+
+	// Returns
+	"return_not_found":          "return not found",
+	"invalid_return_id":         "invalid return id",
+	"return_photo_not_found":    "return photo not found",
+	"invalid_photo_id":          "invalid photo id",
+	"order_not_returnable":      "this order cannot be returned",
+	"return_over_quantity":      "more units than can be returned",
+	"return_status":             "the return cannot be changed in its current status",
+	"return_item_not_found":     "the item is not part of this return",
+	"invalid_accepted_quantity": "more units accepted than were returned",
+	"too_many_photos":           "too many photos for this return",
+	"return_not_refundable":     "this return cannot be refunded",
+	"refund_not_found":          "refund not found",
+	"refund_currency":           "the refund must be in the order currency",
+	"invalid_refund_amount":     "refund amount must be greater than zero",
+	"refund_exceeds_paid":       "the refund is more than can still be refunded",
+	"refund_declined":           "the payment provider declined the refund",
+	"payment_unavailable":       "the payment provider could not be reached, please try again",
+
+	// Invoices
+	"invoice_not_found":       "invoice not found",
+	"invalid_invoice_id":      "invalid invoice id",
+	"invoice_exists":          "this order already has a tax invoice",
+	"order_not_invoiceable":   "a tax invoice can only be issued for a paid order",
+	"not_an_invoice":          "credit notes can only be issued against a tax invoice",
+	"credit_amount_required":  "an amount or a refund is required",
+	"credit_currency":         "the credit must be in the invoice currency",
+	"invalid_credit_amount":   "credit amount must be greater than zero",
+	"credit_exceeds_invoice":  "the credit is more than is left on the invoice",
+	"refund_not_creditable":   "the refund is not a completed refund of this order",
+	"refund_already_credited": "a credit note was already issued for this refund",
+
+	// Gift cards and store credit
+	"gift_card_not_found":       "gift card not found",
+	"invalid_gift_card_id":      "invalid gift card id",
+	"gift_card_code_taken":      "gift card code already exists",
+	"invalid_gift_card_amount":  "gift card amount must be greater than zero",
+	"invalid_gift_card_expiry":  "the expiry date must be in the future",
+	"gift_card_currency":        "the gift card is in a different currency",
+	"gift_card_expired":         "the gift card has expired",
+	"gift_card_empty":           "the gift card has no balance left",
+	"gift_card_insufficient":    "the gift card balance is too low",
+	"invalid_adjustment":        "adjustment amount must not be zero",
+	"store_credit_insufficient": "the store credit balance is too low",
+	"store_credit_no_customer":  "the customer of this order no longer exists",
+	"refund_exceeds_gateway":    "the refund is more than was paid through the payment provider, refund the rest as store credit",
+}
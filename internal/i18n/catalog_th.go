@@ -0,0 +1,244 @@
+package i18n
+
+// catalogTH has the Thai message of every error code, formatted with the
+// error's args
+var catalogTH = map[string]string{
+	// Generic
+	"request_failed":    "ไม่สามารถดำเนินการตามคำขอได้",
+	"unexpected_error":  "เกิดข้อผิดพลาดที่ไม่คาดคิด",
+	"validation_failed": "คำขอมีข้อมูลที่ไม่ถูกต้อง",
+	"malformed_body":    "เนื้อหาคำขอไม่ใช่ JSON ที่ถูกต้อง",
+	"empty_body":        "เนื้อหาคำขอว่างเปล่า",
+	"invalid_query":     "พารามิเตอร์มีค่าที่ผิดประเภท",
+	"invalid_value":     "ค่าไม่ถูกต้อง",
+	"invalid_reference": "ไม่พบข้อมูลที่อ้างอิง",
+	"already_exists":    "มีข้อมูลนี้อยู่แล้ว",
+	"invalid_cursor":    "cursor ไม่ถูกต้อง",
+	"invalid_sort":      "ไม่สามารถเรียงลำดับตาม %s ได้",
+
+	// Field messages, formatted with the field name
+	"field_invalid": "%s ไม่ถูกต้อง",
+	"field_string":  "%s ต้องเป็นข้อความ",
+	"field_boolean": "%s ต้องเป็น true หรือ false",
+	"field_list":    "%s ต้องเป็นรายการ",
+	"field_object":  "%s ต้องเป็นออบเจกต์",
+	"field_integer": "%s ต้องเป็นจำนวนเต็ม",
+	"field_number":  "%s ต้องเป็นตัวเลข",
+
+	// Auth
+	"unauthorized":          "กรุณาเข้าสู่ระบบ",
+	"forbidden":             "คุณไม่มีสิทธิ์ดำเนินการนี้",
+	"missing_token":         "ไม่พบโทเคน",
+	"invalid_token":         "โทเคนไม่ถูกต้อง",
+	"invalid_token_format":  "รูปแบบโทเคนไม่ถูกต้อง",
+	"invalid_credentials":   "อีเมลหรือรหัสผ่านไม่ถูกต้อง",
+	"invalid_refresh_token": "refresh token ไม่ถูกต้องหรือหมดอายุ",
+
+	// Users
+	"user_not_found":     "ไม่พบผู้ใช้",
+	"email_taken":        "อีเมลนี้ถูกใช้งานแล้ว",
+	"invalid_email":      "อีเมลไม่ถูกต้อง",
+	"password_too_short": "รหัสผ่านต้องมีอย่างน้อย 6 ตัวอักษร",
+
+	// Catalog
+	"product_not_found":        "ไม่พบสินค้า",
+	"category_not_found":       "ไม่พบหมวดหมู่",
+	"invalid_category_id":      "รหัสหมวดหมู่ไม่ถูกต้อง",
+	"insufficient_stock":       "สินค้าในสต็อกไม่เพียงพอ",
+	"product_without_category": "สินค้ายังไม่มีหมวดหมู่",
+
+	// Attributes
+	"attribute_not_found":          "ไม่พบคุณสมบัติ",
+	"category_attribute_not_found": "คุณสมบัตินี้ไม่ได้อยู่ในหมวดหมู่",
+	"attribute_code_taken":         "รหัสคุณสมบัตินี้ถูกใช้งานแล้ว",
+	"invalid_attribute_id":         "รหัสคุณสมบัติไม่ถูกต้อง",
+	"invalid_attribute_code":       "รหัสต้องเป็นตัวอักษรพิมพ์เล็ก ตัวเลข หรือขีดล่างเท่านั้น",
+	"enum_options_required":        "คุณสมบัติแบบตัวเลือกต้องมีอย่างน้อยหนึ่งตัวเลือก",
+	"unknown_attribute":            "ไม่รู้จักคุณสมบัติ %s",
+	"attribute_required":           "ต้องระบุคุณสมบัติ %s",
+	"attribute_not_in_category":    "คุณสมบัติ %s ใช้กับหมวดหมู่นี้ไม่ได้",
+	"attribute_value_required":     "ต้องระบุค่าของคุณสมบัติ %s",
+	"invalid_attribute_range":      "ช่วงค่าของคุณสมบัติ %s ไม่ถูกต้อง",
+	"invalid_attribute_text":       "คุณสมบัติ %s ต้องเป็นข้อความที่ไม่ว่าง",
+	"invalid_attribute_number":     "คุณสมบัติ %s ต้องเป็นตัวเลข",
+	"invalid_attribute_boolean":    "คุณสมบัติ %s ต้องเป็น true หรือ false",
+	"invalid_attribute_option":     "คุณสมบัติ %s ต้องเป็นหนึ่งใน %v",
+
+	// Media
+	"image_not_found":        "ไม่พบรูปภาพ",
+	"invalid_image_id":       "รหัสรูปภาพไม่ถูกต้อง",
+	"images_required":        "ต้องแนบรูปภาพ",
+	"single_image_required":  "ต้องแนบรูปภาพหนึ่งรูปเท่านั้น",
+	"duplicate_image_id":     "image_ids มีรหัสรูปภาพซ้ำกัน",
+	"image_order_incomplete": "image_ids ต้องมีรูปภาพทุกรูปของสินค้า",
+	"file_too_large":         "ไฟล์มีขนาดเกิน %d MB",
+	"image_too_large":        "รูปภาพมีขนาดกว้างยาวเกินไป",
+	"unsupported_image_type": "ไม่รองรับไฟล์รูปภาพประเภทนี้",
+	"invalid_upload":         "คำขอไม่ใช่การอัปโหลดแบบ multipart ที่ถูกต้อง",
+
+	// Search
+	"search_not_found":       "ไม่พบการค้นหา",
+	"synonym_not_found":      "ไม่พบกลุ่มคำพ้อง",
+	"invalid_synonym_id":     "รหัสกลุ่มคำพ้องไม่ถูกต้อง",
+	"synonym_terms_required": "กลุ่มคำพ้องต้องมีอย่างน้อยสองคำที่ต่างกัน",
+	"invalid_synonym_term":   "คำพ้องต้องไม่มีเครื่องหมายคำพูดหรือขึ้นต้นด้วย -",
+	"invalid_stop_word":      "คำหยุดต้องเป็นคำเดียว",
+	"invalid_date_range":     "วันที่เริ่มต้องไม่อยู่หลังวันที่สิ้นสุด",
+	"date_range_too_long":    "ช่วงวันที่ต้องไม่เกินหนึ่งปี",
+
+	// Translations
+	"unsupported_locale":         "ไม่รองรับภาษา %s",
+	"default_locale_translation": "เนื้อหาภาษา %s ต้องแก้ไขที่ตัวรายการโดยตรง",
+	"translation_not_found":      "ไม่พบคำแปล",
+
+	// Money
+	"invalid_price":        "ราคาต้องมากกว่าศูนย์",
+	"unsupported_currency": "ราคาต้องเป็นสกุลเงิน %s",
+	"unknown_currency":     "ไม่รองรับสกุลเงินนี้",
+	"invalid_amount":       "จำนวนเงินไม่ถูกต้องสำหรับสกุลเงินนี้",
+	"amount_too_large":     "ยอดรวมสูงเกินไป",
+
+	// Pricing
+	"invalid_rate":            "อัตราแลกเปลี่ยนต้องเป็นเลขทศนิยมบวกไม่เกิน %d ตำแหน่ง",
+	"invalid_rate_id":         "รหัสอัตราแลกเปลี่ยนไม่ถูกต้อง",
+	"same_currency_rate":      "สกุลเงินต้นทางและปลายทางต้องต่างกัน",
+	"exchange_rate_not_found": "ไม่พบอัตราแลกเปลี่ยน",
+	"currency_unavailable":    "ยังไม่มีราคาในสกุลเงิน %s",
+	"base_currency_price":     "ราคาสกุลเงิน %s คือราคาของสินค้าเอง",
+	"exchange_rate_required":  "กรุณาตั้งอัตราแลกเปลี่ยนของ %s ก่อนกำหนดราคา",
+	"exchange_rate_in_use":    "มีสินค้าที่กำหนดราคาเป็น %s จึงต้องมีอัตราแลกเปลี่ยนที่มีผลอยู่",
+	"product_price_not_found": "ไม่พบราคาสินค้า",
+
+	// Cart and orders
+	"cart_empty":           "ตะกร้าสินค้าว่าง",
+	"cart_item_not_found":  "ไม่มีสินค้านี้ในตะกร้า",
+	"product_out_of_stock": "สินค้า %s ในสต็อกไม่เพียงพอ",
+	"order_not_found":      "ไม่พบคำสั่งซื้อ",
+	"invalid_order_id":     "รหัสคำสั่งซื้อไม่ถูกต้อง",
+
+	// Price history
+	"schedule_in_past":          "เวลาที่มีผลต้องเป็นเวลาในอนาคต",
+	"scheduled_price_not_found": "ไม่พบการเปลี่ยนราคาที่รอดำเนินการตามรหัสนี้",
+	"invalid_schedule_id":       "รหัสการเปลี่ยนราคาตามกำหนดไม่ถูกต้อง",
+
+	// Promotions
+	"promotion_not_found":      "ไม่พบโปรโมชัน",
+	"promotion_unavailable":    "โปรโมชัน %s ไม่สามารถใช้ได้แล้ว",
+	"coupon_code_taken":        "รหัสคูปองนี้มีอยู่แล้ว",
+	"invalid_coupon_code":      "รหัสคูปองต้องเป็นตัวอักษร ตัวเลข - หรือ _ จำนวน 3 ถึง 50 ตัว",
+	"invalid_promotion_window": "ends_at ต้องอยู่หลัง starts_at",
+	"invalid_percent":          "เปอร์เซ็นต์ต้องมากกว่า 0 และไม่เกิน 100 โดยมีทศนิยมไม่เกิน 2 ตำแหน่ง",
+	"invalid_promotion_amount": "จำนวนเงินต้องมากกว่าศูนย์",
+	"invalid_buy_x_get_y":      "buy_quantity และ get_quantity ต้องมีค่าอย่างน้อย 1",
+	"invalid_min_spend":        "min_spend ต้องมากกว่าศูนย์",
+	"invalid_promotion_id":     "รหัสโปรโมชันไม่ถูกต้อง",
+	"coupon_not_found":         "ไม่พบคูปอง %s",
+	"coupon_inactive":          "คูปอง %s ไม่ได้เปิดใช้งาน",
+	"coupon_not_started":       "คูปอง %s ยังไม่เริ่มใช้งาน",
+	"coupon_expired":           "คูปอง %s หมดอายุแล้ว",
+	"coupon_exhausted":         "คูปอง %s ถูกใช้ครบจำนวนแล้ว",
+	"coupon_user_limit":        "คุณใช้คูปอง %s ครบจำนวนแล้ว",
+	"coupon_currency":          "คูปอง %s ใช้กับสกุลเงินนี้ไม่ได้",
+	"coupon_min_spend":         "ยอดในตะกร้าไม่ถึงยอดขั้นต่ำของคูปอง %s",
+	"coupon_not_applicable":    "คูปอง %s ใช้กับสินค้าในตะกร้าไม่ได้",
+	"coupon_not_combinable":    "คูปอง %s ใช้ร่วมกับข้อเสนอที่ดีกว่าไม่ได้",
+
+	// Flash sales
+	"flash_sale_not_found":      "ไม่พบแฟลชเซล",
+	"flash_sale_not_started":    "แฟลชเซลยังไม่เริ่ม",
+	"flash_sale_ended":          "แฟลชเซลสิ้นสุดแล้ว",
+	"flash_sale_sold_out":       "สินค้าแฟลชเซลขายหมดแล้ว",
+	"flash_sale_insufficient":   "แฟลชเซลนี้เหลือสินค้าเพียง %d ชิ้น",
+	"flash_sale_limit_reached":  "แฟลชเซลนี้จำกัดไม่เกิน %d ชิ้นต่อลูกค้า",
+	"flash_sale_busy":           "มีผู้ซื้อจำนวนมากในขณะนี้ กรุณาลองใหม่อีกครั้ง",
+	"flash_sale_currency":       "ราคาแฟลชเซลต้องเป็นสกุลเงิน %s",
+	"flash_sale_price_too_high": "ราคาแฟลชเซลต้องต่ำกว่าราคาปกติ %s",
+	"invalid_flash_sale_window": "ends_at ต้องอยู่หลัง starts_at และเป็นเวลาในอนาคต",
+	"invalid_flash_sale_id":     "รหัสแฟลชเซลไม่ถูกต้อง",
+
+	// Taxes
+	"tax_class_not_found":    "ไม่พบประเภทภาษี",
+	"tax_zone_not_found":     "ไม่พบเขตภาษี",
+	"tax_rate_not_found":     "ไม่พบอัตราภาษี",
+	"standard_tax_class":     "ไม่สามารถลบประเภทภาษีมาตรฐานได้",
+	"tax_class_code_taken":   "รหัสประเภทภาษีนี้มีอยู่แล้ว",
+	"tax_zone_taken":         "มีเขตภาษีของประเทศและภูมิภาคนี้อยู่แล้ว",
+	"invalid_country":        "ประเทศต้องเป็นรหัส ISO 3166-1 สองตัวอักษร",
+	"invalid_tax_class_code": "รหัสประเภทภาษีต้องเป็นตัวพิมพ์เล็ก ตัวเลข หรือ _ ยาว 2 ถึง 30 ตัว",
+	"invalid_tax_rate":       "อัตราภาษีต้องเป็นร้อยละ 0 ถึง 100 ทศนิยมไม่เกิน 4 ตำแหน่ง",
+	"invalid_tax_class_id":   "รหัสประเภทภาษีไม่ถูกต้อง",
+	"invalid_tax_zone_id":    "รหัสเขตภาษีไม่ถูกต้อง",
+
+	// Shipping
+	"shipping_zone_not_found":     "ไม่พบเขตการจัดส่ง",
+	"shipping_method_not_found":   "ไม่พบวิธีการจัดส่ง",
+	"shipping_location_taken":     "พื้นที่นี้อยู่ในเขตการจัดส่งอื่นแล้ว",
+	"shipping_method_unavailable": "วิธีการจัดส่งนี้ใช้ไม่ได้กับตะกร้าและปลายทางนี้",
+	"shipping_address_required":   "ต้องระบุชื่อ ที่อยู่ (line1) เมือง และรหัสไปรษณีย์สำหรับการจัดส่ง",
+	"shipping_currency":           "ค่าจัดส่งต้องเป็นสกุลเงิน %s",
+	"invalid_shipping_rate":       "ค่าจัดส่งต้องไม่ติดลบ",
+	"invalid_shipping_threshold":  "การจัดส่งฟรีเมื่อซื้อครบต้องมียอดขั้นต่ำมากกว่าศูนย์",
+	"invalid_weight_rates":        "ตารางน้ำหนักต้องมีช่วงที่ up_to_grams ไม่ซ้ำกัน",
+	"invalid_shipping_zone_id":    "รหัสเขตการจัดส่งไม่ถูกต้อง",
+	"invalid_shipping_method_id":  "รหัสวิธีการจัดส่งไม่ถูกต้อง",
+
+	// Shipments
+	"shipment_not_found":      "ไม่พบการจัดส่ง",
+	"invalid_shipment_id":     "รหัสการจัดส่งไม่ถูกต้อง",
+	"order_not_shippable":     "ไม่สามารถจัดส่งคำสั่งซื้อนี้ได้",
+	"order_is_pickup":         "คำสั่งซื้อนี้รับสินค้าที่ร้านและไม่สามารถจัดส่งได้",
+	"order_item_not_found":    "สินค้านี้ไม่อยู่ในคำสั่งซื้อ",
+	"shipment_over_allocated": "จำนวนเกินกว่าที่เหลือให้จัดส่ง",
+	"shipment_not_booked":     "การจัดส่งยังไม่มีหมายเลขพัสดุ",
+	"label_not_found":         "ไม่พบใบปะหน้าพัสดุ",
+	"unknown_carrier":         "ไม่รู้จักผู้ให้บริการขนส่ง",
+	"unknown_tracking_number": "ผู้ให้บริการขนส่งไม่รู้จักหมายเลขพัสดุนี้",
+	"carrier_unavailable":     "ไม่สามารถติดต่อผู้ให้บริการขนส่งได้ กรุณาลองใหม่อีกครั้ง",
+
+	// Returns
+	"return_not_found":          "ไม่พบรายการคืนสินค้า",
+	"invalid_return_id":         "รหัสการคืนสินค้าไม่ถูกต้อง",
+	"return_photo_not_found":    "ไม่พบรูปภาพการคืนสินค้า",
+	"invalid_photo_id":          "รหัสรูปภาพไม่ถูกต้อง",
+	"order_not_returnable":      "ไม่สามารถคืนสินค้าของคำสั่งซื้อนี้ได้",
+	"return_over_quantity":      "จำนวนเกินกว่าที่สามารถคืนได้",
+	"return_status":             "ไม่สามารถเปลี่ยนแปลงการคืนสินค้าในสถานะปัจจุบันได้",
+	"return_item_not_found":     "สินค้านี้ไม่อยู่ในรายการคืนสินค้า",
+	"invalid_accepted_quantity": "จำนวนที่รับคืนเกินกว่าที่ส่งคืนมา",
+	"too_many_photos":           "จำนวนรูปภาพเกินกำหนดสำหรับการคืนสินค้านี้",
+	"return_not_refundable":     "ไม่สามารถคืนเงินสำหรับการคืนสินค้านี้ได้",
+	"refund_not_found":          "ไม่พบรายการคืนเงิน",
+	"refund_currency":           "ต้องคืนเงินในสกุลเงินของคำสั่งซื้อ",
+	"invalid_refund_amount":     "จำนวนเงินคืนต้องมากกว่าศูนย์",
+	"refund_exceeds_paid":       "จำนวนเงินคืนเกินกว่าที่ยังคืนได้",
+	"refund_declined":           "ผู้ให้บริการชำระเงินปฏิเสธการคืนเงิน",
+	"payment_unavailable":       "ไม่สามารถติดต่อผู้ให้บริการชำระเงินได้ กรุณาลองใหม่อีกครั้ง",
+
+	// Invoices
+	"invoice_not_found":       "ไม่พบใบกำกับภาษี",
+	"invalid_invoice_id":      "รหัสใบกำกับภาษีไม่ถูกต้อง",
+	"invoice_exists":          "คำสั่งซื้อนี้มีใบกำกับภาษีแล้ว",
+	"order_not_invoiceable":   "ออกใบกำกับภาษีได้เฉพาะคำสั่งซื้อที่ชำระเงินแล้ว",
+	"not_an_invoice":          "ออกใบลดหนี้ได้เฉพาะสำหรับใบกำกับภาษีเท่านั้น",
+	"credit_amount_required":  "ต้องระบุจำนวนเงินหรือรายการคืนเงิน",
+	"credit_currency":         "ใบลดหนี้ต้องเป็นสกุลเงินเดียวกับใบกำกับภาษี",
+	"invalid_credit_amount":   "จำนวนเงินลดหนี้ต้องมากกว่าศูนย์",
+	"credit_exceeds_invoice":  "จำนวนเงินลดหนี้เกินกว่ายอดที่เหลือในใบกำกับภาษี",
+	"refund_not_creditable":   "รายการคืนเงินนี้ไม่ใช่การคืนเงินที่สำเร็จของคำสั่งซื้อนี้",
+	"refund_already_credited": "ออกใบลดหนี้สำหรับรายการคืนเงินนี้แล้ว",
+
+	// Gift cards and store credit
+	"gift_card_not_found":       "ไม่พบบัตรของขวัญ",
+	"invalid_gift_card_id":      "รหัสบัตรของขวัญไม่ถูกต้อง",
+	"gift_card_code_taken":      "รหัสบัตรของขวัญนี้มีอยู่แล้ว",
+	"invalid_gift_card_amount":  "มูลค่าบัตรของขวัญต้องมากกว่าศูนย์",
+	"invalid_gift_card_expiry":  "วันหมดอายุต้องเป็นวันในอนาคต",
+	"gift_card_currency":        "บัตรของขวัญเป็นสกุลเงินอื่น",
+	"gift_card_expired":         "บัตรของขวัญหมดอายุแล้ว",
+	"gift_card_empty":           "บัตรของขวัญไม่มียอดคงเหลือ",
+	"gift_card_insufficient":    "ยอดคงเหลือในบัตรของขวัญไม่เพียงพอ",
+	"invalid_adjustment":        "จำนวนเงินที่ปรับต้องไม่เป็นศูนย์",
+	"store_credit_insufficient": "ยอดเครดิตร้านค้าไม่เพียงพอ",
+	"store_credit_no_customer":  "ไม่พบลูกค้าของคำสั่งซื้อนี้แล้ว",
+	"refund_exceeds_gateway":    "จำนวนเงินคืนเกินกว่าที่ชำระผ่านผู้ให้บริการชำระเงิน กรุณาคืนส่วนที่เหลือเป็นเครดิตร้านค้า",
+}
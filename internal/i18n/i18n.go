@@ -0,0 +1,86 @@
+package i18n
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/codepnw/react_go_ecom/internal/errs"
+)
+
+const (
+	English = "en"
+	Thai    = "th"
+
+	// Default is used when the client accepts none of the supported languages
+	Default = English
+)
+
+var catalogs = map[string]map[string]string{
+	English: catalogEN,
+	Thai:    catalogTH,
+}
+
+// Negotiate picks the supported language the Accept-Language header prefers
+// most, "th-TH,th;q=0.9,en;q=0.8" gives Thai
+func Negotiate(header string) string {
+	type choice struct {
+		lang string
+		q    float64
+	}
+
+	var choices []choice
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+
+		q := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			var err error
+			if q, err = strconv.ParseFloat(v, 64); err != nil {
+				continue
+			}
+		}
+
+		base, _, _ := strings.Cut(strings.ToLower(tag), "-")
+		if _, ok := catalogs[base]; ok && q > 0 {
+			choices = append(choices, choice{base, q})
+		}
+	}
+
+	if len(choices) == 0 {
+		return Default
+	}
+
+	sort.SliceStable(choices, func(i, j int) bool { return choices[i].q > choices[j].q })
+
+	return choices[0].lang
+}
+
+// Supported reports whether lang has a catalog
+func Supported(lang string) bool {
+	_, ok := catalogs[lang]
+	return ok
+}
+
+// Message formats the catalog entry of code, falling back to English
+func Message(lang, code string, args ...any) (string, bool) {
+	format, ok := catalogs[lang][code]
+	if !ok {
+		format, ok = catalogs[Default][code]
+	}
+	if !ok {
+		return "", false
+	}
+
+	return fmt.Sprintf(format, args...), true
+}
+
+// Error is the message of a domain error in lang, its own message when the
+// code has no catalog entry
+func Error(lang string, e *errs.Error) string {
+	if msg, ok := Message(lang, e.Code, e.Args...); ok {
+		return msg
+	}
+	return e.Message
+}
@@ -0,0 +1,122 @@
+package i18n
+
+import (
+	"reflect"
+
+	"github.com/go-playground/locales/en"
+	"github.com/go-playground/locales/th"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	en_translations "github.com/go-playground/validator/v10/translations/en"
+)
+
+var translators = ut.New(en.New(), en.New(), th.New())
+
+// Validator has no Thai translations upstream, these cover the tags the
+// request structs use. Sized tags pick a message by the field's kind.
+var thaiValidation = map[string]string{
+	"required":   "{0} จำเป็นต้องระบุ",
+	"email":      "{0} ต้องเป็นอีเมลที่ถูกต้อง",
+	"url":        "{0} ต้องเป็น URL ที่ถูกต้อง",
+	"uuid":       "{0} ต้องเป็น UUID ที่ถูกต้อง",
+	"oneof":      "{0} ต้องเป็นหนึ่งใน [{1}]",
+	"min-string": "{0} ต้องมีความยาวอย่างน้อย {1} ตัวอักษร",
+	"min-items":  "{0} ต้องมีอย่างน้อย {1} รายการ",
+	"min-number": "{0} ต้องมีค่าอย่างน้อย {1}",
+	"max-string": "{0} ต้องมีความยาวไม่เกิน {1} ตัวอักษร",
+	"max-items":  "{0} ต้องมีไม่เกิน {1} รายการ",
+	"max-number": "{0} ต้องมีค่าไม่เกิน {1}",
+	"len-string": "{0} ต้องมีความยาว {1} ตัวอักษร",
+	"len-items":  "{0} ต้องมี {1} รายการ",
+	"len-number": "{0} ต้องเท่ากับ {1}",
+	"gt-string":  "{0} ต้องมีความยาวมากกว่า {1} ตัวอักษร",
+	"gt-items":   "{0} ต้องมีมากกว่า {1} รายการ",
+	"gt-number":  "{0} ต้องมากกว่า {1}",
+	"lt-string":  "{0} ต้องมีความยาวน้อยกว่า {1} ตัวอักษร",
+	"lt-items":   "{0} ต้องมีน้อยกว่า {1} รายการ",
+	"lt-number":  "{0} ต้องน้อยกว่า {1}",
+}
+
+var thaiSizedTags = map[string]string{
+	"min": "min",
+	"gte": "min",
+	"max": "max",
+	"lte": "max",
+	"len": "len",
+	"gt":  "gt",
+	"lt":  "lt",
+}
+
+// RegisterValidator adds the English and Thai messages of validation tags
+func RegisterValidator(v *validator.Validate) error {
+	enTrans, _ := translators.GetTranslator(English)
+	if err := en_translations.RegisterDefaultTranslations(v, enTrans); err != nil {
+		return err
+	}
+
+	thTrans, _ := translators.GetTranslator(Thai)
+	for key, text := range thaiValidation {
+		if err := thTrans.Add(key, text, false); err != nil {
+			return err
+		}
+	}
+
+	noop := func(ut.Translator) error { return nil }
+
+	for _, tag := range []string{"required", "email", "url", "uuid", "oneof"} {
+		if err := v.RegisterTranslation(tag, thTrans, noop, translateThai(tag, false)); err != nil {
+			return err
+		}
+	}
+
+	for tag, key := range thaiSizedTags {
+		if err := v.RegisterTranslation(tag, thTrans, noop, translateThai(key, true)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func translateThai(key string, sized bool) validator.TranslationFunc {
+	return func(trans ut.Translator, fe validator.FieldError) string {
+		k := key
+		if sized {
+			k += "-" + sizeUnit(fe)
+		}
+
+		msg, err := trans.T(k, fe.Field(), fe.Param())
+		if err != nil {
+			return fe.Error()
+		}
+		return msg
+	}
+}
+
+func sizeUnit(fe validator.FieldError) string {
+	kind := fe.Kind()
+	if kind == reflect.Ptr {
+		kind = fe.Type().Elem().Kind()
+	}
+
+	switch kind {
+	case reflect.String:
+		return "string"
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return "items"
+	}
+	return "number"
+}
+
+// FieldMessage is the validation failure in lang, tags without a
+// translation get a generic message instead of the validator's own text
+func FieldMessage(lang string, fe validator.FieldError) string {
+	trans, _ := translators.GetTranslator(lang)
+
+	if msg := fe.Translate(trans); msg != fe.Error() {
+		return msg
+	}
+
+	msg, _ := Message(lang, "field_invalid", fe.Field())
+	return msg
+}
@@ -1,12 +1,16 @@
 package middleware
 
 import (
+	"crypto/rand"
 	"database/sql"
+	"encoding/hex"
 	"errors"
 	"net/http"
 	"strings"
 
 	"github.com/codepnw/react_go_ecom/config"
+	"github.com/codepnw/react_go_ecom/internal/errs"
+	"github.com/codepnw/react_go_ecom/internal/i18n"
 	"github.com/codepnw/react_go_ecom/internal/utils"
 	"github.com/codepnw/react_go_ecom/pkg/auth"
 	"github.com/gin-gonic/gin"
@@ -24,26 +28,116 @@ func (m *middleware) AuthMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		token := c.GetHeader("Authorization")
 		if token == "" {
-			utils.NewResponse(c).Error(http.StatusUnauthorized, errors.New("missing token"))
+			utils.NewResponse(c).Error(http.StatusUnauthorized, errs.Unauthorized("missing_token", "missing token"))
 			c.Abort()
 			return
 		}
 
 		parts := strings.Split(token, " ")
 		if len(parts) != 2 || parts[0] != "Bearer" {
-			utils.NewResponse(c).Error(http.StatusUnauthorized, errors.New("invalid token format"))
+			utils.NewResponse(c).Error(http.StatusUnauthorized, errs.Unauthorized("invalid_token_format", "invalid token format"))
 			c.Abort()
 			return
 		}
 
 		claims, err := auth.ValidateToken(parts[1], m.cfg.Secret)
 		if err != nil {
-			utils.NewResponse(c).Error(http.StatusUnauthorized, errors.New("invalid token"))
+			utils.NewResponse(c).Error(http.StatusUnauthorized, errs.Unauthorized("invalid_token", "invalid token"))
 			c.Abort()
 			return
 		}
 
-		c.Set("user_id", claims.ID)
+		c.Set("user_id", claims.UserID)
+		c.Next()
+	}
+}
+
+// OptionalAuthMiddleware sets user_id when a valid token is sent and lets
+// anonymous requests through
+func (m *middleware) OptionalAuthMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if ok {
+			if claims, err := auth.ValidateToken(token, m.cfg.Secret); err == nil {
+				c.Set("user_id", claims.UserID)
+			}
+		}
+		c.Next()
+	}
+}
+
+// AdminMiddleware lets only admins through, it runs after AuthMiddleware
+func (m *middleware) AdminMiddleware(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !loadRole(c, db) {
+			return
+		}
+
+		if !utils.IsAdmin(c) {
+			utils.NewResponse(c).Error(http.StatusForbidden, errs.Forbidden("forbidden", "forbidden"))
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// RoleMiddleware loads the role of the signed in user for routes that show
+// admins more than the owner, it runs after AuthMiddleware
+func (m *middleware) RoleMiddleware(db *sql.DB) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if loadRole(c, db) {
+			c.Next()
+		}
+	}
+}
+
+// loadRole sets role_id from the users table, a role taken away applies
+// to tokens already issued
+func loadRole(c *gin.Context, db *sql.DB) bool {
+	var roleID int
+	query := `SELECT COALESCE(role_id, 0) FROM users WHERE user_id = $1`
+
+	err := db.QueryRowContext(c.Request.Context(), query, c.GetString("user_id")).Scan(&roleID)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		utils.NewResponse(c).Error(http.StatusUnauthorized, errs.Unauthorized("unauthorized", "unauthorized"))
+		c.Abort()
+		return false
+	case err != nil:
+		utils.NewResponse(c).Error(http.StatusInternalServerError, err)
+		c.Abort()
+		return false
+	}
+
+	c.Set("role_id", roleID)
+	return true
+}
+
+// RequestIDMiddleware keeps the caller's X-Request-ID or makes a new one, it
+// is echoed in the response and in problem details
+func (m *middleware) RequestIDMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader("X-Request-ID")
+		if id == "" || len(id) > 128 {
+			b := make([]byte, 16)
+			rand.Read(b)
+			id = hex.EncodeToString(b)
+		}
+
+		c.Set("request_id", id)
+		c.Header("X-Request-ID", id)
+		c.Next()
+	}
+}
+
+// LanguageMiddleware negotiates the response language from Accept-Language,
+// handlers read it with utils.Language
+func (m *middleware) LanguageMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set("lang", i18n.Negotiate(c.GetHeader("Accept-Language")))
+		c.Header("Vary", "Accept-Language")
 		c.Next()
 	}
 }
@@ -52,7 +146,7 @@ func (m *middleware) RBACMiddleware(db *sql.DB, permission string) gin.HandlerFu
 	return func(c *gin.Context) {
 		userID, exists := c.Get("user_id")
 		if !exists {
-			utils.NewResponse(c).Error(http.StatusUnauthorized, errors.New("unauthorized"))
+			utils.NewResponse(c).Error(http.StatusUnauthorized, errs.Unauthorized("unauthorized", "unauthorized"))
 			c.Abort()
 			return
 		}
@@ -74,7 +168,7 @@ func (m *middleware) RBACMiddleware(db *sql.DB, permission string) gin.HandlerFu
 		var hasPermission bool
 		err := db.QueryRow(query, roleID, permission).Scan(&hasPermission)
 		if err != nil || !hasPermission {
-			utils.NewResponse(c).Error(http.StatusForbidden, errors.New("forbidden"))
+			utils.NewResponse(c).Error(http.StatusForbidden, errs.Forbidden("forbidden", "forbidden"))
 			c.Abort()
 			return
 		}
@@ -0,0 +1,274 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/codepnw/react_go_ecom/internal/entities"
+	"github.com/codepnw/react_go_ecom/internal/errs"
+	"github.com/lib/pq"
+)
+
+type AttributeRepository interface {
+	Create(ctx context.Context, attr *entities.Attribute) error
+	List(ctx context.Context) ([]*entities.Attribute, error)
+	Delete(ctx context.Context, id int) error
+	AttachToCategory(ctx context.Context, categoryID int, req *entities.CategoryAttributeReq) error
+	DetachFromCategory(ctx context.Context, categoryID, attributeID int) error
+	ListByCategory(ctx context.Context, categoryID int, locale string) ([]*entities.CategoryAttribute, error)
+	ReplaceProductValues(ctx context.Context, productID string, values []*entities.ProductAttribute) error
+	ListValuesByProducts(ctx context.Context, productIDs []string, locale string) (map[string][]*entities.ProductAttribute, error)
+}
+
+type attributeRepository struct {
+	db *sql.DB
+}
+
+func NewAttributeRepository(db *sql.DB) AttributeRepository {
+	return &attributeRepository{db: db}
+}
+
+func (r *attributeRepository) Create(ctx context.Context, attr *entities.Attribute) error {
+	query := `
+		INSERT INTO attributes (code, label, type, unit, options)
+		VALUES ($1, $2, $3, NULLIF($4, ''), $5)
+		RETURNING id, created_at
+	`
+	err := r.db.QueryRowContext(
+		ctx,
+		query,
+		attr.Code,
+		attr.Label,
+		attr.Type,
+		attr.Unit,
+		pq.Array(attr.Options),
+	).Scan(&attr.ID, &attr.CreatedAt)
+	if err != nil {
+		return dbError(err, nil)
+	}
+
+	return nil
+}
+
+func (r *attributeRepository) List(ctx context.Context) ([]*entities.Attribute, error) {
+	query := `
+		SELECT id, code, label, type, COALESCE(unit, ''), options, created_at
+		FROM attributes ORDER BY code
+	`
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	attributes := []*entities.Attribute{}
+	for rows.Next() {
+		var a entities.Attribute
+		if err := rows.Scan(
+			&a.ID,
+			&a.Code,
+			&a.Label,
+			&a.Type,
+			&a.Unit,
+			pq.Array(&a.Options),
+			&a.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		attributes = append(attributes, &a)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return attributes, nil
+}
+
+func (r *attributeRepository) Delete(ctx context.Context, id int) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM attributes WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return errAttrNotFound
+	}
+
+	return nil
+}
+
+func (r *attributeRepository) AttachToCategory(ctx context.Context, categoryID int, req *entities.CategoryAttributeReq) error {
+	query := `
+		INSERT INTO category_attributes (category_id, attribute_id, required, position)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (category_id, attribute_id) DO UPDATE
+		SET required = EXCLUDED.required, position = EXCLUDED.position
+	`
+	_, err := r.db.ExecContext(ctx, query, categoryID, req.AttributeID, req.Required, req.Position)
+
+	return dbError(err, nil)
+}
+
+func (r *attributeRepository) DetachFromCategory(ctx context.Context, categoryID, attributeID int) error {
+	query := `DELETE FROM category_attributes WHERE category_id = $1 AND attribute_id = $2`
+
+	result, err := r.db.ExecContext(ctx, query, categoryID, attributeID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return errs.NotFound("category_attribute_not_found", "attribute is not attached to category")
+	}
+
+	return nil
+}
+
+// ListByCategory labels the attributes in locale where translated
+func (r *attributeRepository) ListByCategory(ctx context.Context, categoryID int, locale string) ([]*entities.CategoryAttribute, error) {
+	query := `
+		SELECT a.id, a.code, COALESCE(atr.label, a.label), a.type, COALESCE(a.unit, ''), a.options, a.created_at,
+			ca.category_id, ca.required, ca.position
+		FROM category_attributes ca
+		JOIN attributes a ON a.id = ca.attribute_id
+		LEFT JOIN attribute_translations atr ON atr.attribute_id = a.id AND atr.locale = $2
+		WHERE ca.category_id = $1
+		ORDER BY ca.position, a.code
+	`
+	rows, err := r.db.QueryContext(ctx, query, categoryID, locale)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	attributes := []*entities.CategoryAttribute{}
+	for rows.Next() {
+		var a entities.CategoryAttribute
+		if err := rows.Scan(
+			&a.ID,
+			&a.Code,
+			&a.Label,
+			&a.Type,
+			&a.Unit,
+			pq.Array(&a.Options),
+			&a.CreatedAt,
+			&a.CategoryID,
+			&a.Required,
+			&a.Position,
+		); err != nil {
+			return nil, err
+		}
+		attributes = append(attributes, &a)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return attributes, nil
+}
+
+func (r *attributeRepository) ReplaceProductValues(ctx context.Context, productID string, values []*entities.ProductAttribute) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM product_attribute_values WHERE product_id = $1`, productID); err != nil {
+		return err
+	}
+
+	query := `
+		INSERT INTO product_attribute_values (product_id, attribute_id, value_text, value_number, value_bool)
+		VALUES ($1, $2, $3, $4, $5)
+	`
+	for _, v := range values {
+		var text sql.NullString
+		var number sql.NullFloat64
+		var boolean sql.NullBool
+
+		switch val := v.Value.(type) {
+		case string:
+			text = sql.NullString{String: val, Valid: true}
+		case float64:
+			number = sql.NullFloat64{Float64: val, Valid: true}
+		case bool:
+			boolean = sql.NullBool{Bool: val, Valid: true}
+		}
+
+		if _, err := tx.ExecContext(ctx, query, productID, v.AttributeID, text, number, boolean); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r *attributeRepository) ListValuesByProducts(ctx context.Context, productIDs []string, locale string) (map[string][]*entities.ProductAttribute, error) {
+	query := `
+		SELECT v.product_id, a.id, a.code, COALESCE(atr.label, a.label), a.type, COALESCE(a.unit, ''),
+			v.value_text, v.value_number, v.value_bool
+		FROM product_attribute_values v
+		JOIN attributes a ON a.id = v.attribute_id
+		LEFT JOIN attribute_translations atr ON atr.attribute_id = a.id AND atr.locale = $2
+		LEFT JOIN products p ON p.product_id = v.product_id
+		LEFT JOIN category_attributes ca ON ca.category_id = p.category_id AND ca.attribute_id = a.id
+		WHERE v.product_id = ANY($1)
+		ORDER BY v.product_id, ca.position NULLS LAST, a.code
+	`
+	rows, err := r.db.QueryContext(ctx, query, pq.Array(productIDs), locale)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	values := make(map[string][]*entities.ProductAttribute)
+	for rows.Next() {
+		var v entities.ProductAttribute
+		var text sql.NullString
+		var number sql.NullFloat64
+		var boolean sql.NullBool
+
+		if err := rows.Scan(
+			&v.ProductID,
+			&v.AttributeID,
+			&v.Code,
+			&v.Label,
+			&v.Type,
+			&v.Unit,
+			&text,
+			&number,
+			&boolean,
+		); err != nil {
+			return nil, err
+		}
+
+		switch {
+		case number.Valid:
+			v.Value = number.Float64
+		case boolean.Valid:
+			v.Value = boolean.Bool
+		case text.Valid:
+			v.Value = text.String
+		}
+		values[v.ProductID] = append(values[v.ProductID], &v)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return values, nil
+}
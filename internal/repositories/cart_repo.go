@@ -0,0 +1,121 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/codepnw/react_go_ecom/internal/entities"
+	"github.com/codepnw/react_go_ecom/internal/errs"
+)
+
+type CartRepository interface {
+	ListItems(ctx context.Context, userID, locale string) ([]*entities.CartItem, error)
+	AddItem(ctx context.Context, userID, productID string, quantity int) error
+	SetQuantity(ctx context.Context, userID, productID string, quantity int) error
+	RemoveItem(ctx context.Context, userID, productID string) error
+}
+
+type cartRepository struct {
+	db *sql.DB
+}
+
+func NewCartRepository(db *sql.DB) CartRepository {
+	return &cartRepository{db: db}
+}
+
+// ListItems reads the cart with titles in locale and unit prices in the
+// base currency, pricing is left to the caller
+func (r *cartRepository) ListItems(ctx context.Context, userID, locale string) ([]*entities.CartItem, error) {
+	query := `
+		SELECT ci.product_id, COALESCE(pt.title, p.title), p.category_id, p.tax_class_id, p.weight_grams, ci.quantity,
+			p.stock, p.price, ci.updated_at
+		FROM cart_items ci
+		JOIN products p ON p.product_id = ci.product_id
+		LEFT JOIN product_translations pt ON pt.product_id = p.product_id AND pt.locale = $2
+		WHERE ci.user_id = $1
+		ORDER BY ci.created_at, ci.product_id
+	`
+	rows, err := r.db.QueryContext(ctx, query, userID, locale)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []*entities.CartItem{}
+	for rows.Next() {
+		var item entities.CartItem
+		if err := rows.Scan(
+			&item.ProductID,
+			&item.Title,
+			&item.CategoryID,
+			&item.TaxClassID,
+			&item.WeightGrams,
+			&item.Quantity,
+			&item.Stock,
+			&item.UnitPrice,
+			&item.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, &item)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+// AddItem adds to the quantity already in the cart. The stock check is
+// part of the statement so two requests cannot both pass it, no row means
+// the product has too little stock.
+func (r *cartRepository) AddItem(ctx context.Context, userID, productID string, quantity int) error {
+	query := `
+		INSERT INTO cart_items (user_id, product_id, quantity)
+		SELECT $1, product_id, $3 FROM products WHERE product_id = $2 AND stock >= $3
+		ON CONFLICT (user_id, product_id) DO UPDATE
+		SET quantity = cart_items.quantity + EXCLUDED.quantity, updated_at = now()
+		WHERE cart_items.quantity + EXCLUDED.quantity <= (
+			SELECT stock FROM products WHERE product_id = EXCLUDED.product_id
+		)
+		RETURNING quantity
+	`
+	var total int
+
+	err := r.db.QueryRowContext(ctx, query, userID, productID, quantity).Scan(&total)
+
+	return dbError(err, errs.InsufficientStock("insufficient_stock", "not enough stock"))
+}
+
+func (r *cartRepository) SetQuantity(ctx context.Context, userID, productID string, quantity int) error {
+	query := `
+		UPDATE cart_items SET quantity = $3, updated_at = now()
+		WHERE user_id = $1 AND product_id = $2
+	`
+	return r.exec(ctx, query, userID, productID, quantity)
+}
+
+func (r *cartRepository) RemoveItem(ctx context.Context, userID, productID string) error {
+	query := `DELETE FROM cart_items WHERE user_id = $1 AND product_id = $2`
+
+	return r.exec(ctx, query, userID, productID)
+}
+
+func (r *cartRepository) exec(ctx context.Context, query string, args ...any) error {
+	result, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return dbError(err, nil)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return errCartItemNotFound
+	}
+
+	return nil
+}
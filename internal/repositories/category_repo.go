@@ -3,13 +3,17 @@ package repositories
 import (
 	"context"
 	"database/sql"
+	"fmt"
 
 	"github.com/codepnw/react_go_ecom/internal/entities"
+	"github.com/codepnw/react_go_ecom/pkg/pagination"
+	"github.com/lib/pq"
 )
 
 type CategoryRepo interface {
-	Create(ctx context.Context, title string) error
-	List(ctx context.Context) ([]*entities.Category, error)
+	Create(ctx context.Context, title string) (int, error)
+	List(ctx context.Context, f *entities.CategoryFilter) ([]*entities.Category, error)
+	Count(ctx context.Context) (int, error)
 	Delete(ctx context.Context, id int) error
 }
 
@@ -21,44 +25,102 @@ func NewCategoryRepo(db *sql.DB) CategoryRepo {
 	return &categoryRepo{db: db}
 }
 
-func (r *categoryRepo) Create(ctx context.Context, title string) error {
-	query := `INSERT INTO categories (title) VALUES ($1)`
+func (r *categoryRepo) Create(ctx context.Context, title string) (int, error) {
+	query := `INSERT INTO categories (title) VALUES ($1) RETURNING id`
 
-	_, err := r.db.ExecContext(ctx, query, title)
-	if err != nil {
-		return err
+	var id int
+	if err := r.db.QueryRowContext(ctx, query, title).Scan(&id); err != nil {
+		return 0, dbError(err, nil)
 	}
 
-	return nil
+	return id, nil
 }
 
-func (r *categoryRepo) List(ctx context.Context) ([]*entities.Category, error) {
-	query := `SELECT id, title, created_at FROM categories`
+var categorySortColumns = map[string]pagination.Column{
+	"title":      {Expr: "COALESCE(ct.title, c.title)", Type: "TEXT"},
+	"created_at": {Expr: "COALESCE(c.created_at, '-infinity')", Type: "TIMESTAMPTZ"},
+}
+
+// List reads one row past the limit so the caller can tell whether another
+// page follows. Titles are in the filter's locale where translated.
+func (r *categoryRepo) List(ctx context.Context, f *entities.CategoryFilter) ([]*entities.Category, error) {
+	var columns []pagination.Column
+	for _, k := range f.SortKeys {
+		if column, ok := categorySortColumns[k.Field]; ok {
+			column.Desc = k.Desc
+			columns = append(columns, column)
+		}
+	}
+	columns = append(columns, pagination.Column{Expr: "c.id", Type: "INT"})
 
-	rows, err := r.db.QueryContext(ctx, query)
+	var args []any
+	arg := func(v any) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	where, backward := "", false
+	if f.After != nil {
+		where = "WHERE " + pagination.Condition(columns, f.After, arg)
+		backward = f.After.Backward
+	}
+
+	query := fmt.Sprintf(`
+		SELECT c.id, COALESCE(ct.title, c.title), c.created_at, ARRAY[%s]
+		FROM categories c
+		LEFT JOIN category_translations ct ON ct.category_id = c.id AND ct.locale = %s
+		%s
+		%s
+		LIMIT %s
+	`, pagination.Keys(columns), arg(f.Locale), where, pagination.OrderBy(columns, backward), arg(f.Limit+1))
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 
-	var categories []*entities.Category
+	categories := []*entities.Category{}
 	for rows.Next() {
 		var cat entities.Category
-		if err := rows.Scan(&cat.ID, &cat.Title, &cat.CreatedAt); err != nil {
+		if err := rows.Scan(&cat.ID, &cat.Title, &cat.CreatedAt, pq.Array(&cat.SortValues)); err != nil {
 			return nil, err
 		}
 		categories = append(categories, &cat)
 	}
 
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
 	return categories, nil
 }
 
+func (r *categoryRepo) Count(ctx context.Context) (int, error) {
+	var total int
+	if err := r.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM categories`).Scan(&total); err != nil {
+		return 0, err
+	}
+
+	return total, nil
+}
+
 func (r *categoryRepo) Delete(ctx context.Context, id int) error {
 	query := `DELETE FROM categories WHERE id = $1`
 
-	if _, err := r.db.ExecContext(ctx, query, id); err != nil {
+	result, err := r.db.ExecContext(ctx, query, id)
+	if err != nil {
 		return err
 	}
 
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return errCategoryNotFound
+	}
+
 	return nil
 }
@@ -0,0 +1,96 @@
+package repositories
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/codepnw/react_go_ecom/internal/errs"
+	"github.com/lib/pq"
+)
+
+var (
+	errProductNotFound  = errs.NotFound("product_not_found", "product not found")
+	errCategoryNotFound = errs.NotFound("category_not_found", "category not found")
+	errUserNotFound     = errs.NotFound("user_not_found", "user not found")
+	errImageNotFound    = errs.NotFound("image_not_found", "image not found")
+	errAttrNotFound     = errs.NotFound("attribute_not_found", "attribute not found")
+	errTransNotFound    = errs.NotFound("translation_not_found", "translation not found")
+	errRateNotFound     = errs.NotFound("exchange_rate_not_found", "exchange rate not found")
+	errPriceNotFound    = errs.NotFound("product_price_not_found", "product price not found")
+	errCartItemNotFound = errs.NotFound("cart_item_not_found", "item is not in the cart")
+	errOrderNotFound    = errs.NotFound("order_not_found", "order not found")
+	errPromoNotFound    = errs.NotFound("promotion_not_found", "promotion not found")
+
+	errFlashSaleNotFound   = errs.NotFound("flash_sale_not_found", "flash sale not found")
+	errFlashSaleNotStarted = errs.Conflict("flash_sale_not_started", "flash sale has not started yet")
+	errFlashSaleEnded      = errs.Conflict("flash_sale_ended", "flash sale has ended")
+	errFlashSaleSoldOut    = errs.InsufficientStock("flash_sale_sold_out", "flash sale is sold out")
+
+	errTaxClassNotFound = errs.NotFound("tax_class_not_found", "tax class not found")
+	errTaxZoneNotFound  = errs.NotFound("tax_zone_not_found", "tax zone not found")
+	errTaxRateNotFound  = errs.NotFound("tax_rate_not_found", "tax rate not found")
+
+	errShippingZoneNotFound   = errs.NotFound("shipping_zone_not_found", "shipping zone not found")
+	errShippingMethodNotFound = errs.NotFound("shipping_method_not_found", "shipping method not found")
+	errShipmentNotFound       = errs.NotFound("shipment_not_found", "shipment not found")
+	errReturnNotFound         = errs.NotFound("return_not_found", "return not found")
+	errRefundNotFound         = errs.NotFound("refund_not_found", "refund not found")
+	errInvoiceNotFound        = errs.NotFound("invoice_not_found", "invoice not found")
+	errGiftCardNotFound       = errs.NotFound("gift_card_not_found", "gift card not found")
+)
+
+// errFlashSaleLimit is a purchase that would take the customer over the
+// per-customer limit of the sale
+func errFlashSaleLimit(limit int) *errs.Error {
+	return errs.Conflict("flash_sale_limit_reached", "at most %d per customer in this flash sale", limit)
+}
+
+// errPromotionUnavailable is a promotion used up between pricing the cart
+// and placing the order
+func errPromotionUnavailable(name string) *errs.Error {
+	return errs.Conflict("promotion_unavailable", "promotion %s is no longer available", name)
+}
+
+// Unique constraints with a dedicated error, others report a generic conflict
+var uniqueViolations = map[string]*errs.Error{
+	"users_email_key":              errs.Conflict("email_taken", "email is already exists"),
+	"attributes_code_key":          errs.Conflict("attribute_code_taken", "attribute code is already exists"),
+	"promotions_code_key":          errs.Conflict("coupon_code_taken", "coupon code already exists"),
+	"tax_classes_code_key":         errs.Conflict("tax_class_code_taken", "tax class code already exists"),
+	"tax_zones_country_region_key": errs.Conflict("tax_zone_taken", "a tax zone for this country and region already exists"),
+	"shipping_zone_locations_pkey": errs.Conflict("shipping_location_taken", "the location already belongs to a shipping zone"),
+	"idx_invoices_one_per_order":   errs.Conflict("invoice_exists", "the order already has a tax invoice"),
+	"invoices_refund_id_key":       errs.Conflict("refund_already_credited", "a credit note was already issued for the refund"),
+	"gift_cards_code_key":          errs.Conflict("gift_card_code_taken", "gift card code already exists"),
+}
+
+// dbError translates driver errors into domain errors. notFound is used for
+// sql.ErrNoRows, pass nil where no rows is not an error.
+func dbError(err error, notFound *errs.Error) error {
+	if err == nil {
+		return nil
+	}
+
+	if errors.Is(err, sql.ErrNoRows) && notFound != nil {
+		return notFound.Wrap(err)
+	}
+
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return err
+	}
+
+	switch pqErr.Code.Name() {
+	case "unique_violation":
+		if e, ok := uniqueViolations[pqErr.Constraint]; ok {
+			return e.Wrap(err)
+		}
+		return errs.Conflict("already_exists", "record already exists").Wrap(err)
+	case "foreign_key_violation":
+		return errs.Validation("invalid_reference", "referenced record does not exist").Wrap(err)
+	case "not_null_violation", "check_violation", "invalid_text_representation", "string_data_right_truncation", "numeric_value_out_of_range":
+		return errs.Validation("invalid_value", "invalid value").Wrap(err)
+	}
+
+	return err
+}
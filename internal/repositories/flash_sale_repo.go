@@ -0,0 +1,391 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/codepnw/react_go_ecom/internal/entities"
+	"github.com/codepnw/react_go_ecom/internal/errs"
+	"github.com/codepnw/react_go_ecom/pkg/money"
+)
+
+type FlashSaleRepository interface {
+	Create(ctx context.Context, s *entities.FlashSale) error
+	GetByID(ctx context.Context, id int) (*entities.FlashSale, error)
+	List(ctx context.Context, f *entities.FlashSaleFilter) ([]*entities.FlashSale, error)
+	Close(ctx context.Context, id int, now time.Time) error
+	CloseDue(ctx context.Context, now time.Time, limit int) ([]*entities.FlashSale, error)
+	Reserve(ctx context.Context, p *entities.FlashSalePurchase, now time.Time) error
+}
+
+type flashSaleRepository struct {
+	db *sql.DB
+}
+
+func NewFlashSaleRepository(db *sql.DB) FlashSaleRepository {
+	return &flashSaleRepository{db: db}
+}
+
+const flashSaleColumns = `
+	id, name, product_id, sale_price::TEXT, currency, allocation, sold, per_customer_limit,
+	starts_at, ends_at, closed_at, created_at
+`
+
+// Create moves the allocation out of the product stock, the sale price
+// must be below the regular price
+func (r *flashSaleRepository) Create(ctx context.Context, s *entities.FlashSale) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var price string
+	err = tx.QueryRowContext(ctx, `
+		UPDATE products SET stock = stock - $2
+		WHERE product_id = $1 AND stock >= $2
+		RETURNING price::TEXT
+	`, s.ProductID, s.Allocation).Scan(&price)
+	if errors.Is(err, sql.ErrNoRows) {
+		var stock int
+		if err := tx.QueryRowContext(ctx, `SELECT stock FROM products WHERE product_id = $1`, s.ProductID).Scan(&stock); err != nil {
+			return dbError(err, errProductNotFound)
+		}
+		return errs.InsufficientStock("insufficient_stock", "not enough stock")
+	}
+	if err != nil {
+		return err
+	}
+
+	regular, err := money.Parse(price, money.DefaultCurrency)
+	if err != nil {
+		return err
+	}
+
+	if s.SalePrice.Cmp(regular) >= 0 {
+		return errs.Validation("flash_sale_price_too_high", "sale price must be below the regular price of %s", regular.String())
+	}
+
+	query := `
+		INSERT INTO flash_sales (name, product_id, sale_price, currency, allocation, per_customer_limit, starts_at, ends_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, created_at
+	`
+	err = tx.QueryRowContext(
+		ctx,
+		query,
+		s.Name,
+		s.ProductID,
+		s.SalePrice,
+		s.SalePrice.Currency,
+		s.Allocation,
+		s.PerCustomerLimit,
+		s.StartsAt,
+		s.EndsAt,
+	).Scan(&s.ID, &s.CreatedAt)
+	if err != nil {
+		return dbError(err, nil)
+	}
+
+	s.Remaining = s.Allocation
+	s.Status = flashSaleStatus(s, time.Now())
+
+	return tx.Commit()
+}
+
+func (r *flashSaleRepository) GetByID(ctx context.Context, id int) (*entities.FlashSale, error) {
+	query := `SELECT ` + flashSaleColumns + ` FROM flash_sales WHERE id = $1`
+
+	s, err := scanFlashSale(r.db.QueryRowContext(ctx, query, id).Scan)
+	if err != nil {
+		return nil, dbError(err, errFlashSaleNotFound)
+	}
+
+	return s, nil
+}
+
+func (r *flashSaleRepository) List(ctx context.Context, f *entities.FlashSaleFilter) ([]*entities.FlashSale, error) {
+	var where []string
+	var args []any
+
+	if f.ProductID != "" {
+		args = append(args, f.ProductID)
+		where = append(where, fmt.Sprintf("product_id = $%d", len(args)))
+	}
+
+	switch f.Status {
+	case entities.FlashSaleUpcoming:
+		where = append(where, "closed_at IS NULL AND starts_at > now()")
+	case entities.FlashSaleLive:
+		where = append(where, "closed_at IS NULL AND starts_at <= now() AND ends_at > now()")
+	case entities.FlashSaleEnded:
+		where = append(where, "(closed_at IS NOT NULL OR ends_at <= now())")
+	}
+
+	query := `SELECT ` + flashSaleColumns + ` FROM flash_sales`
+	if len(where) > 0 {
+		query += ` WHERE ` + strings.Join(where, " AND ")
+	}
+	query += ` ORDER BY starts_at DESC, id DESC`
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	sales := []*entities.FlashSale{}
+	for rows.Next() {
+		s, err := scanFlashSale(rows.Scan)
+		if err != nil {
+			return nil, err
+		}
+		sales = append(sales, s)
+	}
+
+	return sales, rows.Err()
+}
+
+// Close ends an open sale early. A sale that has not started is removed,
+// either way the unsold units go back to the product.
+func (r *flashSaleRepository) Close(ctx context.Context, id int, now time.Time) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `SELECT ` + flashSaleColumns + ` FROM flash_sales WHERE id = $1 AND closed_at IS NULL FOR UPDATE`
+
+	s, err := scanFlashSale(tx.QueryRowContext(ctx, query, id).Scan)
+	if err != nil {
+		return dbError(err, errFlashSaleNotFound)
+	}
+
+	if s.StartsAt.After(now) {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM flash_sales WHERE id = $1`, id); err != nil {
+			return err
+		}
+		if err := returnAllocation(ctx, tx, s); err != nil {
+			return err
+		}
+		return tx.Commit()
+	}
+
+	if s.EndsAt.After(now) {
+		if _, err := tx.ExecContext(ctx, `UPDATE flash_sales SET ends_at = $2 WHERE id = $1`, id, now); err != nil {
+			return dbError(err, nil)
+		}
+	}
+
+	if err := closeFlashSale(ctx, tx, s, now); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// CloseDue closes the sales that ended, rows another run holds are skipped
+func (r *flashSaleRepository) CloseDue(ctx context.Context, now time.Time, limit int) ([]*entities.FlashSale, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	query := `
+		SELECT ` + flashSaleColumns + `
+		FROM flash_sales
+		WHERE closed_at IS NULL AND ends_at <= $1
+		ORDER BY ends_at, id
+		LIMIT $2
+		FOR UPDATE SKIP LOCKED
+	`
+	rows, err := tx.QueryContext(ctx, query, now, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	var due []*entities.FlashSale
+	for rows.Next() {
+		s, err := scanFlashSale(rows.Scan)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+		due = append(due, s)
+	}
+	rows.Close()
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, s := range due {
+		if err := closeFlashSale(ctx, tx, s, now); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return due, nil
+}
+
+// Reserve sells the units in one transaction. The conditional update of
+// the sold counter is the hard cap, the row lock it takes serializes the
+// purchases of one sale so the per-customer count cannot be raced either.
+func (r *flashSaleRepository) Reserve(ctx context.Context, p *entities.FlashSalePurchase, now time.Time) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var price, currency string
+	var limit int
+
+	err = tx.QueryRowContext(ctx, `
+		UPDATE flash_sales SET sold = sold + $2
+		WHERE id = $1 AND closed_at IS NULL AND starts_at <= $3 AND ends_at > $3 AND sold + $2 <= allocation
+		RETURNING sale_price::TEXT, currency, per_customer_limit, allocation - sold
+	`, p.FlashSaleID, p.Quantity, now).Scan(&price, &currency, &limit, &p.Remaining)
+	if errors.Is(err, sql.ErrNoRows) {
+		return unavailableFlashSale(ctx, tx, p, now)
+	}
+	if err != nil {
+		return err
+	}
+
+	if p.Quantity > limit {
+		return errFlashSaleLimit(limit)
+	}
+
+	result, err := tx.ExecContext(ctx, `
+		INSERT INTO flash_sale_customers AS c (flash_sale_id, user_id, quantity)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (flash_sale_id, user_id) DO UPDATE SET quantity = c.quantity + EXCLUDED.quantity
+		WHERE c.quantity + EXCLUDED.quantity <= $4
+	`, p.FlashSaleID, p.UserID, p.Quantity, limit)
+	if err != nil {
+		return dbError(err, nil)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return errFlashSaleLimit(limit)
+	}
+
+	if p.UnitPrice, err = money.Parse(price, currency); err != nil {
+		return err
+	}
+	if p.Total, err = p.UnitPrice.Times(int64(p.Quantity)); err != nil {
+		return errs.Validation("amount_too_large", "the total is too large").Wrap(err)
+	}
+
+	query := `
+		INSERT INTO flash_sale_purchases (flash_sale_id, user_id, quantity, unit_price, currency)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at
+	`
+	err = tx.QueryRowContext(ctx, query, p.FlashSaleID, p.UserID, p.Quantity, p.UnitPrice, currency).Scan(&p.ID, &p.CreatedAt)
+	if err != nil {
+		return dbError(err, nil)
+	}
+
+	return tx.Commit()
+}
+
+// unavailableFlashSale tells why the sold counter was not raised
+func unavailableFlashSale(ctx context.Context, tx *sql.Tx, p *entities.FlashSalePurchase, now time.Time) error {
+	query := `SELECT ` + flashSaleColumns + ` FROM flash_sales WHERE id = $1`
+
+	s, err := scanFlashSale(tx.QueryRowContext(ctx, query, p.FlashSaleID).Scan)
+	if err != nil {
+		return dbError(err, errFlashSaleNotFound)
+	}
+
+	switch {
+	case s.ClosedAt != nil || !s.EndsAt.After(now):
+		return errFlashSaleEnded
+	case s.StartsAt.After(now):
+		return errFlashSaleNotStarted
+	case s.Remaining == 0:
+		return errFlashSaleSoldOut
+	}
+
+	return errs.InsufficientStock("flash_sale_insufficient", "only %d left in this flash sale", s.Remaining)
+}
+
+// closeFlashSale gives the unsold units back and counts the sold ones as
+// sold quantity of the product
+func closeFlashSale(ctx context.Context, tx *sql.Tx, s *entities.FlashSale, now time.Time) error {
+	err := tx.QueryRowContext(ctx, `
+		UPDATE flash_sales SET closed_at = $2 WHERE id = $1 RETURNING ends_at, closed_at
+	`, s.ID, now).Scan(&s.EndsAt, &s.ClosedAt)
+	if err != nil {
+		return err
+	}
+	s.Status = entities.FlashSaleEnded
+
+	return returnAllocation(ctx, tx, s)
+}
+
+func returnAllocation(ctx context.Context, tx *sql.Tx, s *entities.FlashSale) error {
+	_, err := tx.ExecContext(ctx, `
+		UPDATE products SET stock = stock + $2, quantity = quantity + $3 WHERE product_id = $1
+	`, s.ProductID, s.Allocation-s.Sold, s.Sold)
+
+	return err
+}
+
+func scanFlashSale(scan func(dest ...any) error) (*entities.FlashSale, error) {
+	var s entities.FlashSale
+	var price, currency string
+
+	err := scan(
+		&s.ID,
+		&s.Name,
+		&s.ProductID,
+		&price,
+		&currency,
+		&s.Allocation,
+		&s.Sold,
+		&s.PerCustomerLimit,
+		&s.StartsAt,
+		&s.EndsAt,
+		&s.ClosedAt,
+		&s.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.SalePrice, err = money.Parse(price, currency); err != nil {
+		return nil, err
+	}
+	s.Remaining = s.Allocation - s.Sold
+	s.Status = flashSaleStatus(&s, time.Now())
+
+	return &s, nil
+}
+
+func flashSaleStatus(s *entities.FlashSale, now time.Time) string {
+	switch {
+	case s.ClosedAt != nil || !s.EndsAt.After(now):
+		return entities.FlashSaleEnded
+	case s.StartsAt.After(now):
+		return entities.FlashSaleUpcoming
+	}
+	return entities.FlashSaleLive
+}
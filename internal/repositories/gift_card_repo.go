@@ -0,0 +1,265 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/codepnw/react_go_ecom/internal/entities"
+	"github.com/codepnw/react_go_ecom/internal/errs"
+	"github.com/codepnw/react_go_ecom/pkg/money"
+)
+
+type GiftCardRepository interface {
+	Create(ctx context.Context, card *entities.GiftCard) error
+	GetByID(ctx context.Context, id int64) (*entities.GiftCard, error)
+	List(ctx context.Context, f *entities.GiftCardFilter) ([]*entities.GiftCard, error)
+	Adjust(ctx context.Context, id int64, amount money.Money, note string) error
+}
+
+type giftCardRepository struct {
+	db *sql.DB
+}
+
+func NewGiftCardRepository(db *sql.DB) GiftCardRepository {
+	return &giftCardRepository{db: db}
+}
+
+const giftCardColumns = `
+	id, code, currency, initial_amount::TEXT, balance::TEXT, expires_at, note, created_at, updated_at
+`
+
+// Create stores the card with its issue transaction
+func (r *giftCardRepository) Create(ctx context.Context, card *entities.GiftCard) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO gift_cards (code, currency, initial_amount, balance, expires_at, note)
+		VALUES ($1, $2, $3, $3, $4, $5)
+		RETURNING id, created_at
+	`
+	err = tx.QueryRowContext(ctx, query, card.Code, card.Currency, card.InitialAmount, card.ExpiresAt, card.Note).
+		Scan(&card.ID, &card.CreatedAt)
+	if err != nil {
+		return dbError(err, nil)
+	}
+	card.Balance = card.InitialAmount
+
+	issue := &entities.GiftCardTransaction{
+		Kind:         entities.GiftCardIssue,
+		Amount:       card.InitialAmount,
+		BalanceAfter: card.Balance,
+		Note:         card.Note,
+	}
+	if err := addGiftCardTransaction(ctx, tx, card.ID, issue); err != nil {
+		return err
+	}
+	card.Transactions = []*entities.GiftCardTransaction{issue}
+
+	return tx.Commit()
+}
+
+func (r *giftCardRepository) GetByID(ctx context.Context, id int64) (*entities.GiftCard, error) {
+	query := `SELECT ` + giftCardColumns + ` FROM gift_cards WHERE id = $1`
+
+	card, err := scanGiftCard(r.db.QueryRowContext(ctx, query, id).Scan)
+	if err != nil {
+		return nil, dbError(err, errGiftCardNotFound)
+	}
+
+	if card.Transactions, err = r.listTransactions(ctx, card); err != nil {
+		return nil, err
+	}
+
+	return card, nil
+}
+
+// List reads the cards newest first, without their transactions
+func (r *giftCardRepository) List(ctx context.Context, f *entities.GiftCardFilter) ([]*entities.GiftCard, error) {
+	query := `SELECT ` + giftCardColumns + ` FROM gift_cards WHERE ($1 = '' OR code = $1) ORDER BY id DESC`
+
+	rows, err := r.db.QueryContext(ctx, query, f.Code)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	cards := []*entities.GiftCard{}
+	for rows.Next() {
+		card, err := scanGiftCard(rows.Scan)
+		if err != nil {
+			return nil, err
+		}
+		cards = append(cards, card)
+	}
+
+	return cards, rows.Err()
+}
+
+// Adjust moves the balance by a signed amount, never below zero
+func (r *giftCardRepository) Adjust(ctx context.Context, id int64, amount money.Money, note string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	card, err := lockGiftCard(ctx, tx, `id = $1`, id)
+	if err != nil {
+		return err
+	}
+
+	if amount.Currency != card.Currency {
+		return errs.Validation("gift_card_currency", "gift card is in %s", card.Currency)
+	}
+
+	if card.Balance.Add(amount).IsNegative() {
+		return errs.Conflict("gift_card_insufficient", "only %s is left on the gift card", card.Balance)
+	}
+
+	if err := moveGiftCard(ctx, tx, card, entities.GiftCardAdjust, amount, nil, note); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// redeemGiftCard spends up to due from the card with the code on the
+// order and returns what it took. The card row stays locked until the
+// checkout commits.
+func redeemGiftCard(ctx context.Context, tx *sql.Tx, order *entities.Order, code string, due money.Money) (money.Money, error) {
+	card, err := lockGiftCard(ctx, tx, `code = $1`, code)
+	if err != nil {
+		return money.Money{}, err
+	}
+
+	switch {
+	case card.ExpiresAt != nil && !card.ExpiresAt.After(time.Now()):
+		return money.Money{}, errs.Conflict("gift_card_expired", "gift card %s has expired", maskCode(code))
+	case card.Currency != order.Currency:
+		return money.Money{}, errs.Validation("gift_card_currency", "gift card %s is in %s", maskCode(code), card.Currency)
+	case card.Balance.IsZero():
+		return money.Money{}, errs.Conflict("gift_card_empty", "gift card %s has no balance left", maskCode(code))
+	}
+
+	taken := due
+	if card.Balance.Cmp(due) < 0 {
+		taken = card.Balance
+	}
+
+	if err := moveGiftCard(ctx, tx, card, entities.GiftCardRedeem, taken.Neg(), &order.ID, ""); err != nil {
+		return money.Money{}, err
+	}
+
+	payment := &entities.Payment{Method: entities.PaymentGiftCard, GiftCard: maskCode(code), Amount: taken}
+	if err := addPayment(ctx, tx, order.ID, &card.ID, payment); err != nil {
+		return money.Money{}, err
+	}
+	order.Payments = append(order.Payments, payment)
+
+	return taken, nil
+}
+
+func lockGiftCard(ctx context.Context, tx *sql.Tx, where string, arg any) (*entities.GiftCard, error) {
+	query := `SELECT ` + giftCardColumns + ` FROM gift_cards WHERE ` + where + ` FOR UPDATE`
+
+	card, err := scanGiftCard(tx.QueryRowContext(ctx, query, arg).Scan)
+	if err != nil {
+		return nil, dbError(err, errGiftCardNotFound)
+	}
+
+	return card, nil
+}
+
+// moveGiftCard changes the balance of a locked card and records why
+func moveGiftCard(ctx context.Context, tx *sql.Tx, card *entities.GiftCard, kind string, amount money.Money, orderID *int64, note string) error {
+	card.Balance = card.Balance.Add(amount)
+
+	query := `UPDATE gift_cards SET balance = $2, updated_at = now() WHERE id = $1`
+	if _, err := tx.ExecContext(ctx, query, card.ID, card.Balance); err != nil {
+		return dbError(err, nil)
+	}
+
+	return addGiftCardTransaction(ctx, tx, card.ID, &entities.GiftCardTransaction{
+		Kind:         kind,
+		Amount:       amount,
+		BalanceAfter: card.Balance,
+		OrderID:      orderID,
+		Note:         note,
+	})
+}
+
+func addGiftCardTransaction(ctx context.Context, tx *sql.Tx, cardID int64, t *entities.GiftCardTransaction) error {
+	query := `
+		INSERT INTO gift_card_transactions (gift_card_id, kind, amount, balance_after, order_id, note)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at
+	`
+	err := tx.QueryRowContext(ctx, query, cardID, t.Kind, t.Amount, t.BalanceAfter, t.OrderID, t.Note).Scan(&t.ID, &t.CreatedAt)
+	return dbError(err, nil)
+}
+
+func (r *giftCardRepository) listTransactions(ctx context.Context, card *entities.GiftCard) ([]*entities.GiftCardTransaction, error) {
+	query := `
+		SELECT id, kind, amount::TEXT, balance_after::TEXT, order_id, note, created_at
+		FROM gift_card_transactions WHERE gift_card_id = $1
+		ORDER BY id DESC
+	`
+	rows, err := r.db.QueryContext(ctx, query, card.ID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	transactions := []*entities.GiftCardTransaction{}
+	for rows.Next() {
+		var t entities.GiftCardTransaction
+		var amount, after string
+
+		if err := rows.Scan(&t.ID, &t.Kind, &amount, &after, &t.OrderID, &t.Note, &t.CreatedAt); err != nil {
+			return nil, err
+		}
+
+		if t.Amount, err = money.Parse(amount, card.Currency); err != nil {
+			return nil, err
+		}
+		if t.BalanceAfter, err = money.Parse(after, card.Currency); err != nil {
+			return nil, err
+		}
+		transactions = append(transactions, &t)
+	}
+
+	return transactions, rows.Err()
+}
+
+func scanGiftCard(scan func(dest ...any) error) (*entities.GiftCard, error) {
+	var card entities.GiftCard
+	var initial, balance string
+
+	err := scan(&card.ID, &card.Code, &card.Currency, &initial, &balance, &card.ExpiresAt, &card.Note, &card.CreatedAt, &card.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	if card.InitialAmount, err = money.Parse(initial, card.Currency); err != nil {
+		return nil, err
+	}
+	if card.Balance, err = money.Parse(balance, card.Currency); err != nil {
+		return nil, err
+	}
+
+	return &card, nil
+}
+
+// maskCode keeps the last four characters of a gift card code, enough for
+// the customer to tell cards apart
+func maskCode(code string) string {
+	if len(code) <= 4 {
+		return code
+	}
+	return "****" + code[len(code)-4:]
+}
@@ -0,0 +1,16 @@
+package repositories
+
+import "testing"
+
+func TestMaskCode(t *testing.T) {
+	for in, want := range map[string]string{
+		"ABCDEFGHJKMNPQRS": "****PQRS",
+		"ABCDE":            "****BCDE",
+		"ABCD":             "ABCD",
+		"":                 "",
+	} {
+		if got := maskCode(in); got != want {
+			t.Errorf("maskCode(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,331 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+
+	"github.com/codepnw/react_go_ecom/internal/entities"
+	"github.com/codepnw/react_go_ecom/internal/errs"
+	"github.com/codepnw/react_go_ecom/pkg/money"
+)
+
+type InvoiceRepository interface {
+	Issue(ctx context.Context, inv *entities.Invoice) error
+	SetPDF(ctx context.Context, id int64, key string) error
+	GetByID(ctx context.Context, id int64) (*entities.Invoice, error)
+	GetByOrder(ctx context.Context, orderID int64) (*entities.Invoice, error)
+	List(ctx context.Context, f *entities.InvoiceFilter) ([]*entities.Invoice, error)
+	GetRefund(ctx context.Context, id int64) (*entities.Refund, error)
+}
+
+type invoiceRepository struct {
+	db *sql.DB
+}
+
+func NewInvoiceRepository(db *sql.DB) InvoiceRepository {
+	return &invoiceRepository{db: db}
+}
+
+const invoiceColumns = `
+	id, kind, number, fiscal_year, sequence, order_id, invoice_id, refund_id, currency,
+	seller_name, seller_tax_id, seller_branch, seller_address,
+	buyer_name, buyer_tax_id, buyer_branch, buyer_address, reason,
+	subtotal::TEXT, tax_total::TEXT, total::TEXT, pdf_key, issued_at
+`
+
+// Issue numbers and stores a document. The sequence row of its kind and
+// fiscal year stays locked until the document is stored, a failed issue
+// rolls the number back so the numbering has no gaps. Credit notes never
+// add up to more than their invoice.
+func (r *invoiceRepository) Issue(ctx context.Context, inv *entities.Invoice) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if inv.Kind == entities.InvoiceKindCreditNote {
+		if err := creditable(ctx, tx, inv); err != nil {
+			return err
+		}
+	}
+
+	query := `
+		INSERT INTO document_sequences (kind, fiscal_year, last_number) VALUES ($1, $2, 1)
+		ON CONFLICT (kind, fiscal_year) DO UPDATE SET last_number = document_sequences.last_number + 1
+		RETURNING last_number
+	`
+	if err := tx.QueryRowContext(ctx, query, inv.Kind, inv.FiscalYear).Scan(&inv.Sequence); err != nil {
+		return err
+	}
+	inv.Number = entities.InvoiceNumber(inv.Kind, inv.FiscalYear, inv.Sequence)
+
+	query = `
+		INSERT INTO invoices (kind, number, fiscal_year, sequence, order_id, invoice_id, refund_id, currency,
+			seller_name, seller_tax_id, seller_branch, seller_address,
+			buyer_name, buyer_tax_id, buyer_branch, buyer_address, reason,
+			subtotal, tax_total, total, issued_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21)
+		RETURNING id
+	`
+	err = tx.QueryRowContext(ctx, query,
+		inv.Kind,
+		inv.Number,
+		inv.FiscalYear,
+		inv.Sequence,
+		inv.OrderID,
+		inv.InvoiceID,
+		inv.RefundID,
+		inv.Currency,
+		inv.Seller.Name,
+		inv.Seller.TaxID,
+		inv.Seller.Branch,
+		inv.Seller.Address,
+		inv.Buyer.Name,
+		inv.Buyer.TaxID,
+		inv.Buyer.Branch,
+		inv.Buyer.Address,
+		inv.Reason,
+		inv.Subtotal,
+		inv.TaxTotal,
+		inv.Total,
+		inv.IssuedAt,
+	).Scan(&inv.ID)
+	if err != nil {
+		return dbError(err, nil)
+	}
+
+	lineQuery := `
+		INSERT INTO invoice_lines (invoice_id, position, description, quantity, unit_price, amount, tax_name, tax_rate, tax_amount)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`
+	for i, line := range inv.Lines {
+		_, err := tx.ExecContext(ctx, lineQuery, inv.ID, i+1, line.Description, line.Quantity, line.UnitPrice,
+			line.Amount, line.TaxName, line.TaxRate, line.TaxAmount)
+		if err != nil {
+			return dbError(err, nil)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// creditable locks the invoice a credit note is against and checks what is
+// left to credit, a refund must be a completed refund of the same order
+func creditable(ctx context.Context, tx *sql.Tx, inv *entities.Invoice) error {
+	var total string
+	query := `SELECT total::TEXT FROM invoices WHERE id = $1 AND kind = $2 FOR UPDATE`
+	if err := tx.QueryRowContext(ctx, query, inv.InvoiceID, entities.InvoiceKindInvoice).Scan(&total); err != nil {
+		return dbError(err, errInvoiceNotFound)
+	}
+
+	var credited string
+	query = `SELECT COALESCE(SUM(total), 0)::TEXT FROM invoices WHERE invoice_id = $1`
+	if err := tx.QueryRowContext(ctx, query, inv.InvoiceID).Scan(&credited); err != nil {
+		return err
+	}
+
+	invoiced, err := money.Parse(total, inv.Currency)
+	if err != nil {
+		return err
+	}
+	spent, err := money.Parse(credited, inv.Currency)
+	if err != nil {
+		return err
+	}
+
+	if left := invoiced.Sub(spent); inv.Total.Cmp(left) > 0 {
+		return errs.Conflict("credit_exceeds_invoice", "at most %s can still be credited", left)
+	}
+
+	if inv.RefundID != nil {
+		var orderID int64
+		var status string
+		query := `SELECT order_id, status FROM refunds WHERE id = $1`
+		if err := tx.QueryRowContext(ctx, query, *inv.RefundID).Scan(&orderID, &status); err != nil {
+			return dbError(err, errRefundNotFound)
+		}
+		if orderID != inv.OrderID || status != entities.RefundSucceeded {
+			return errs.Conflict("refund_not_creditable", "refund %d is not a completed refund of this order", *inv.RefundID)
+		}
+	}
+
+	return nil
+}
+
+func (r *invoiceRepository) SetPDF(ctx context.Context, id int64, key string) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE invoices SET pdf_key = $2 WHERE id = $1`, id, key)
+	return err
+}
+
+func (r *invoiceRepository) GetByID(ctx context.Context, id int64) (*entities.Invoice, error) {
+	query := `SELECT ` + invoiceColumns + ` FROM invoices WHERE id = $1`
+	return r.get(ctx, query, id)
+}
+
+// GetByOrder is the tax invoice of an order
+func (r *invoiceRepository) GetByOrder(ctx context.Context, orderID int64) (*entities.Invoice, error) {
+	query := `SELECT ` + invoiceColumns + ` FROM invoices WHERE order_id = $1 AND kind = $2`
+	return r.get(ctx, query, orderID, entities.InvoiceKindInvoice)
+}
+
+func (r *invoiceRepository) get(ctx context.Context, query string, args ...any) (*entities.Invoice, error) {
+	inv, err := scanInvoice(r.db.QueryRowContext(ctx, query, args...).Scan)
+	if err != nil {
+		return nil, dbError(err, errInvoiceNotFound)
+	}
+
+	if inv.Lines, err = r.listLines(ctx, inv); err != nil {
+		return nil, err
+	}
+
+	return inv, nil
+}
+
+// List reads documents newest first, without their lines
+func (r *invoiceRepository) List(ctx context.Context, f *entities.InvoiceFilter) ([]*entities.Invoice, error) {
+	var where []string
+	var args []any
+
+	if f.Kind != "" {
+		args = append(args, f.Kind)
+		where = append(where, fmt.Sprintf("kind = $%d", len(args)))
+	}
+	if f.FiscalYear != 0 {
+		args = append(args, f.FiscalYear)
+		where = append(where, fmt.Sprintf("fiscal_year = $%d", len(args)))
+	}
+	if f.OrderID != 0 {
+		args = append(args, f.OrderID)
+		where = append(where, fmt.Sprintf("order_id = $%d", len(args)))
+	}
+
+	query := `SELECT ` + invoiceColumns + ` FROM invoices`
+	if len(where) > 0 {
+		query += ` WHERE ` + strings.Join(where, " AND ")
+	}
+	query += ` ORDER BY issued_at DESC, id DESC`
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	invoices := []*entities.Invoice{}
+	for rows.Next() {
+		inv, err := scanInvoice(rows.Scan)
+		if err != nil {
+			return nil, err
+		}
+		invoices = append(invoices, inv)
+	}
+
+	return invoices, rows.Err()
+}
+
+func (r *invoiceRepository) GetRefund(ctx context.Context, id int64) (*entities.Refund, error) {
+	query := `
+		SELECT id, order_id, return_id, amount::TEXT, currency, provider, provider_ref, status, note, created_at, updated_at
+		FROM refunds WHERE id = $1
+	`
+	var rf entities.Refund
+	var amount, currency string
+
+	err := r.db.QueryRowContext(ctx, query, id).Scan(&rf.ID, &rf.OrderID, &rf.ReturnID, &amount, &currency,
+		&rf.Provider, &rf.ProviderRef, &rf.Status, &rf.Note, &rf.CreatedAt, &rf.UpdatedAt)
+	if err != nil {
+		return nil, dbError(err, errRefundNotFound)
+	}
+
+	if rf.Amount, err = money.Parse(amount, currency); err != nil {
+		return nil, err
+	}
+
+	return &rf, nil
+}
+
+func (r *invoiceRepository) listLines(ctx context.Context, inv *entities.Invoice) ([]*entities.InvoiceLine, error) {
+	query := `
+		SELECT description, quantity, unit_price::TEXT, amount::TEXT, tax_name, tax_rate::TEXT, tax_amount::TEXT
+		FROM invoice_lines WHERE invoice_id = $1
+		ORDER BY position
+	`
+	rows, err := r.db.QueryContext(ctx, query, inv.ID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	lines := []*entities.InvoiceLine{}
+	for rows.Next() {
+		var line entities.InvoiceLine
+		var unit, amount, tax string
+
+		err := rows.Scan(&line.Description, &line.Quantity, &unit, &amount, &line.TaxName, &line.TaxRate, &tax)
+		if err != nil {
+			return nil, err
+		}
+
+		if line.UnitPrice, err = money.Parse(unit, inv.Currency); err != nil {
+			return nil, err
+		}
+		if line.Amount, err = money.Parse(amount, inv.Currency); err != nil {
+			return nil, err
+		}
+		if line.TaxAmount, err = money.Parse(tax, inv.Currency); err != nil {
+			return nil, err
+		}
+		lines = append(lines, &line)
+	}
+
+	return lines, rows.Err()
+}
+
+func scanInvoice(scan func(dest ...any) error) (*entities.Invoice, error) {
+	var inv entities.Invoice
+	var subtotal, taxTotal, total string
+
+	err := scan(
+		&inv.ID,
+		&inv.Kind,
+		&inv.Number,
+		&inv.FiscalYear,
+		&inv.Sequence,
+		&inv.OrderID,
+		&inv.InvoiceID,
+		&inv.RefundID,
+		&inv.Currency,
+		&inv.Seller.Name,
+		&inv.Seller.TaxID,
+		&inv.Seller.Branch,
+		&inv.Seller.Address,
+		&inv.Buyer.Name,
+		&inv.Buyer.TaxID,
+		&inv.Buyer.Branch,
+		&inv.Buyer.Address,
+		&inv.Reason,
+		&subtotal,
+		&taxTotal,
+		&total,
+		&inv.PDFKey,
+		&inv.IssuedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if inv.Subtotal, err = money.Parse(subtotal, inv.Currency); err != nil {
+		return nil, err
+	}
+	if inv.TaxTotal, err = money.Parse(taxTotal, inv.Currency); err != nil {
+		return nil, err
+	}
+	if inv.Total, err = money.Parse(total, inv.Currency); err != nil {
+		return nil, err
+	}
+
+	return &inv, nil
+}
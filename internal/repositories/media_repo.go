@@ -0,0 +1,300 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/codepnw/react_go_ecom/internal/entities"
+	"github.com/codepnw/react_go_ecom/internal/errs"
+	"github.com/lib/pq"
+)
+
+type MediaRepository interface {
+	CreateProductImages(ctx context.Context, images []*entities.ProductImage) error
+	GetProductImage(ctx context.Context, productID string, id int) (*entities.ProductImage, error)
+	ListProductImages(ctx context.Context, productID string) ([]*entities.ProductImage, error)
+	ListImagesByProducts(ctx context.Context, productIDs []string) (map[string][]*entities.ProductImage, error)
+	DeleteProductImage(ctx context.Context, productID string, id int) error
+	ReorderProductImages(ctx context.Context, productID string, imageIDs []int) error
+	SaveRenditions(ctx context.Context, imageID int, renditions []*entities.ImageRendition) error
+	UpdateUserAvatar(ctx context.Context, userID, key string) (string, error)
+}
+
+type mediaRepository struct {
+	db *sql.DB
+}
+
+func NewMediaRepository(db *sql.DB) MediaRepository {
+	return &mediaRepository{db: db}
+}
+
+// CreateProductImages stores a batch of uploads together, either every image
+// is added or none is
+func (r *mediaRepository) CreateProductImages(ctx context.Context, images []*entities.ProductImage) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	// New images are appended to the end of the gallery
+	query := `
+		INSERT INTO product_images (product_id, storage_key, content_type, size_bytes, position, created_at)
+		VALUES ($1, $2, $3, $4, (
+			SELECT COALESCE(MAX(position) + 1, 0) FROM product_images WHERE product_id = $1
+		), $5)
+		RETURNING id, position
+	`
+	for _, img := range images {
+		err := tx.QueryRowContext(
+			ctx,
+			query,
+			img.ProductID,
+			img.Key,
+			img.ContentType,
+			img.Size,
+			img.CreatedAt,
+		).Scan(&img.ID, &img.Position)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r *mediaRepository) GetProductImage(ctx context.Context, productID string, id int) (*entities.ProductImage, error) {
+	query := `
+		SELECT id, product_id, storage_key, content_type, size_bytes, position, created_at
+		FROM product_images WHERE product_id = $1 AND id = $2
+	`
+	var img entities.ProductImage
+
+	err := r.db.QueryRowContext(ctx, query, productID, id).Scan(
+		&img.ID,
+		&img.ProductID,
+		&img.Key,
+		&img.ContentType,
+		&img.Size,
+		&img.Position,
+		&img.CreatedAt,
+	)
+	if err != nil {
+		return nil, dbError(err, errImageNotFound)
+	}
+
+	renditions, err := r.listRenditions(ctx, []int{img.ID})
+	if err != nil {
+		return nil, err
+	}
+	img.Renditions = renditions[img.ID]
+
+	return &img, nil
+}
+
+func (r *mediaRepository) ListProductImages(ctx context.Context, productID string) ([]*entities.ProductImage, error) {
+	images, err := r.ListImagesByProducts(ctx, []string{productID})
+	if err != nil {
+		return nil, err
+	}
+
+	return images[productID], nil
+}
+
+func (r *mediaRepository) ListImagesByProducts(ctx context.Context, productIDs []string) (map[string][]*entities.ProductImage, error) {
+	query := `
+		SELECT id, product_id, storage_key, content_type, size_bytes, position, created_at
+		FROM product_images WHERE product_id = ANY($1)
+		ORDER BY product_id, position, id
+	`
+	rows, err := r.db.QueryContext(ctx, query, pq.Array(productIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	images := make(map[string][]*entities.ProductImage)
+	var all []*entities.ProductImage
+	for rows.Next() {
+		var img entities.ProductImage
+		if err := rows.Scan(
+			&img.ID,
+			&img.ProductID,
+			&img.Key,
+			&img.ContentType,
+			&img.Size,
+			&img.Position,
+			&img.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		images[img.ProductID] = append(images[img.ProductID], &img)
+		all = append(all, &img)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(all) == 0 {
+		return images, nil
+	}
+
+	ids := make([]int, len(all))
+	for i, img := range all {
+		ids[i] = img.ID
+	}
+
+	renditions, err := r.listRenditions(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, img := range all {
+		img.Renditions = renditions[img.ID]
+	}
+
+	return images, nil
+}
+
+func (r *mediaRepository) listRenditions(ctx context.Context, imageIDs []int) (map[int][]*entities.ImageRendition, error) {
+	query := `
+		SELECT image_id, name, storage_key, content_type, width, height
+		FROM product_image_renditions WHERE image_id = ANY($1)
+		ORDER BY image_id, width
+	`
+	rows, err := r.db.QueryContext(ctx, query, pq.Array(imageIDs))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	renditions := make(map[int][]*entities.ImageRendition)
+	for rows.Next() {
+		var rd entities.ImageRendition
+		if err := rows.Scan(
+			&rd.ImageID,
+			&rd.Name,
+			&rd.Key,
+			&rd.ContentType,
+			&rd.Width,
+			&rd.Height,
+		); err != nil {
+			return nil, err
+		}
+		renditions[rd.ImageID] = append(renditions[rd.ImageID], &rd)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return renditions, nil
+}
+
+func (r *mediaRepository) DeleteProductImage(ctx context.Context, productID string, id int) error {
+	query := `DELETE FROM product_images WHERE product_id = $1 AND id = $2`
+
+	result, err := r.db.ExecContext(ctx, query, productID, id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return errImageNotFound
+	}
+
+	return nil
+}
+
+func (r *mediaRepository) ReorderProductImages(ctx context.Context, productID string, imageIDs []int) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var count int
+	query := `SELECT COUNT(*) FROM product_images WHERE product_id = $1`
+	if err := tx.QueryRowContext(ctx, query, productID).Scan(&count); err != nil {
+		return err
+	}
+
+	if count != len(imageIDs) {
+		return errs.Validation("image_order_incomplete", "image_ids must contain every image of the product")
+	}
+
+	query = `UPDATE product_images SET position = $1 WHERE product_id = $2 AND id = $3`
+	for position, id := range imageIDs {
+		result, err := tx.ExecContext(ctx, query, position, productID, id)
+		if err != nil {
+			return err
+		}
+
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+
+		if rowsAffected == 0 {
+			return errImageNotFound
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r *mediaRepository) SaveRenditions(ctx context.Context, imageID int, renditions []*entities.ImageRendition) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO product_image_renditions (image_id, name, storage_key, content_type, width, height)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (image_id, name) DO UPDATE
+		SET storage_key = EXCLUDED.storage_key, content_type = EXCLUDED.content_type,
+			width = EXCLUDED.width, height = EXCLUDED.height, created_at = now()
+	`
+	for _, rd := range renditions {
+		if _, err := tx.ExecContext(
+			ctx,
+			query,
+			imageID,
+			rd.Name,
+			rd.Key,
+			rd.ContentType,
+			rd.Width,
+			rd.Height,
+		); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r *mediaRepository) UpdateUserAvatar(ctx context.Context, userID, key string) (string, error) {
+	// Return the previous key so the caller can remove the old blob
+	query := `
+		UPDATE users u SET images = $1, updated_at = now()
+		FROM (SELECT user_id, images FROM users WHERE user_id = $2 FOR UPDATE) old
+		WHERE u.user_id = old.user_id
+		RETURNING COALESCE(old.images, '')
+	`
+	var oldKey string
+
+	err := r.db.QueryRowContext(ctx, query, key, userID).Scan(&oldKey)
+	if err != nil {
+		return "", dbError(err, errUserNotFound)
+	}
+
+	return oldKey, nil
+}
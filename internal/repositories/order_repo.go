@@ -0,0 +1,626 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/codepnw/react_go_ecom/internal/entities"
+	"github.com/codepnw/react_go_ecom/internal/errs"
+	"github.com/codepnw/react_go_ecom/pkg/money"
+	"github.com/codepnw/react_go_ecom/pkg/pagination"
+	"github.com/lib/pq"
+)
+
+type OrderRepository interface {
+	Create(ctx context.Context, order *entities.Order) error
+	GetByID(ctx context.Context, id int64) (*entities.Order, error)
+	List(ctx context.Context, f *entities.OrderFilter) ([]*entities.Order, error)
+	Count(ctx context.Context, f *entities.OrderFilter) (int, error)
+}
+
+type orderRepository struct {
+	db *sql.DB
+}
+
+func NewOrderRepository(db *sql.DB) OrderRepository {
+	return &orderRepository{db: db}
+}
+
+// Create takes the stock of every item, stores the order with its
+// discounts, counts the promotion uses, redeems gift cards and store credit
+// and empties the user's cart in one transaction. Stock is taken with a
+// conditional update so concurrent checkouts can never oversell.
+func (r *orderRepository) Create(ctx context.Context, order *entities.Order) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stockQuery := `
+		UPDATE products SET stock = stock - $2, quantity = quantity + $2, updated_at = now()
+		WHERE product_id = $1 AND stock >= $2
+	`
+	for _, item := range order.Items {
+		result, err := tx.ExecContext(ctx, stockQuery, item.ProductID, item.Quantity)
+		if err != nil {
+			return err
+		}
+
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+
+		if rowsAffected == 0 {
+			return errs.InsufficientStock("product_out_of_stock", "not enough stock of %s", item.Title)
+		}
+	}
+
+	orderQuery := `
+		INSERT INTO orders (user_id, status, currency, exchange_rate, exchange_rate_id, subtotal,
+			discount_total, free_shipping, tax_total, prices_include_tax, tax_country, tax_region, shipping_method_id,
+			shipping_method, shipping_kind, shipping_total, weight_grams, ship_name, ship_phone, ship_line1, ship_line2,
+			ship_city, ship_postal_code, total, gateway_total)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22,
+			$23, $24, $24)
+		RETURNING id, created_at
+	`
+	var methodID *int
+	var method, kind *string
+	if order.Shipping != nil {
+		methodID, method, kind = &order.Shipping.MethodID, &order.Shipping.Name, &order.Shipping.Kind
+	}
+
+	var addr entities.Address
+	if order.ShippingAddress != nil {
+		addr = *order.ShippingAddress
+	}
+
+	err = tx.QueryRowContext(
+		ctx,
+		orderQuery,
+		order.UserID,
+		order.Status,
+		order.Currency,
+		order.ExchangeRate,
+		order.ExchangeRateID,
+		order.Subtotal,
+		order.DiscountTotal,
+		order.FreeShipping,
+		order.TaxTotal,
+		order.PricesIncludeTax,
+		order.TaxCountry,
+		order.TaxRegion,
+		methodID,
+		method,
+		kind,
+		order.ShippingTotal,
+		order.WeightGrams,
+		addr.Name,
+		addr.Phone,
+		addr.Line1,
+		addr.Line2,
+		addr.City,
+		addr.PostalCode,
+		order.Total,
+	).Scan(&order.ID, &order.CreatedAt)
+	if err != nil {
+		return dbError(err, nil)
+	}
+
+	if err := redeemPromotions(ctx, tx, order); err != nil {
+		return err
+	}
+
+	itemQuery := `
+		INSERT INTO order_items (order_id, product_id, title, quantity, unit_price, base_unit_price, line_total,
+			tax_name, tax_rate, taxable_amount, tax_amount)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING id
+	`
+	for _, item := range order.Items {
+		var taxName, taxRate, taxable any
+		taxAmount := money.New(0, order.Currency)
+		if item.Tax != nil {
+			taxName, taxRate, taxable, taxAmount = item.Tax.Name, item.Tax.Rate, item.Tax.Taxable, item.Tax.Amount
+		}
+
+		err := tx.QueryRowContext(
+			ctx,
+			itemQuery,
+			order.ID,
+			item.ProductID,
+			item.Title,
+			item.Quantity,
+			item.UnitPrice,
+			item.BaseUnitPrice,
+			item.LineTotal,
+			taxName,
+			taxRate,
+			taxable,
+			taxAmount,
+		).Scan(&item.ID)
+		if err != nil {
+			return dbError(err, nil)
+		}
+	}
+
+	discountQuery := `
+		INSERT INTO order_discounts (order_id, promotion_id, code, name, kind, product_id, amount)
+		VALUES ($1, $2, $3, $4, $5, NULLIF($6, ''), $7)
+	`
+	for _, d := range order.Discounts {
+		_, err := tx.ExecContext(ctx, discountQuery, order.ID, d.PromotionID, d.Code, d.Name, d.Kind, d.ProductID, d.Amount)
+		if err != nil {
+			return dbError(err, nil)
+		}
+	}
+
+	if err := payWithCredit(ctx, tx, order); err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM cart_items WHERE user_id = $1`, order.UserID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *orderRepository) GetByID(ctx context.Context, id int64) (*entities.Order, error) {
+	query := `
+		SELECT o.id, COALESCE(o.user_id, ''), o.status, o.currency, o.exchange_rate::TEXT, o.exchange_rate_id,
+			o.subtotal::TEXT, o.discount_total::TEXT, o.free_shipping, o.tax_total::TEXT, o.prices_include_tax, o.tax_country,
+			o.tax_region, o.shipping_method_id, o.shipping_method, o.shipping_kind, o.shipping_total::TEXT,
+			o.weight_grams, o.ship_name, o.ship_phone, o.ship_line1, o.ship_line2, o.ship_city, o.ship_postal_code, o.total::TEXT, o.refunded_total::TEXT,
+			o.gateway_total::TEXT, o.created_at, o.updated_at, ARRAY[o.id::TEXT]
+		FROM orders o WHERE o.id = $1
+	`
+	order, err := scanOrder(r.db.QueryRowContext(ctx, query, id).Scan)
+	if err != nil {
+		return nil, dbError(err, errOrderNotFound)
+	}
+
+	if order.Items, err = r.listItems(ctx, order); err != nil {
+		return nil, err
+	}
+
+	if order.Discounts, err = r.listDiscounts(ctx, order); err != nil {
+		return nil, err
+	}
+
+	order.Taxes = taxSummaries(order)
+
+	if order.Payments, err = r.listPayments(ctx, order); err != nil {
+		return nil, err
+	}
+
+	if order.History, err = r.listHistory(ctx, order.ID); err != nil {
+		return nil, err
+	}
+
+	return order, nil
+}
+
+// List reads the user's orders newest first, one row past the limit, the
+// items are only loaded for a single order
+func (r *orderRepository) List(ctx context.Context, f *entities.OrderFilter) ([]*entities.Order, error) {
+	columns := []pagination.Column{{Expr: "o.id", Type: "BIGINT", Desc: true}}
+
+	var args []any
+	arg := func(v any) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	where := "WHERE o.user_id = " + arg(f.UserID)
+	if f.Status != "" {
+		where += " AND o.status = " + arg(f.Status)
+	}
+
+	backward := false
+	if f.After != nil {
+		where += " AND " + pagination.Condition(columns, f.After, arg)
+		backward = f.After.Backward
+	}
+
+	query := fmt.Sprintf(`
+		SELECT o.id, COALESCE(o.user_id, ''), o.status, o.currency, o.exchange_rate::TEXT, o.exchange_rate_id,
+			o.subtotal::TEXT, o.discount_total::TEXT, o.free_shipping, o.tax_total::TEXT, o.prices_include_tax, o.tax_country,
+			o.tax_region, o.shipping_method_id, o.shipping_method, o.shipping_kind, o.shipping_total::TEXT,
+			o.weight_grams, o.ship_name, o.ship_phone, o.ship_line1, o.ship_line2, o.ship_city, o.ship_postal_code, o.total::TEXT, o.refunded_total::TEXT,
+			o.gateway_total::TEXT, o.created_at, o.updated_at, ARRAY[%s]
+		FROM orders o
+		%s
+		%s
+		LIMIT %s
+	`, pagination.Keys(columns), where, pagination.OrderBy(columns, backward), arg(f.Limit+1))
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	orders := []*entities.Order{}
+	for rows.Next() {
+		order, err := scanOrder(rows.Scan)
+		if err != nil {
+			return nil, err
+		}
+		orders = append(orders, order)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return orders, nil
+}
+
+func (r *orderRepository) Count(ctx context.Context, f *entities.OrderFilter) (int, error) {
+	query := `SELECT COUNT(*) FROM orders WHERE user_id = $1 AND ($2 = '' OR status::TEXT = $2)`
+
+	var total int
+	if err := r.db.QueryRowContext(ctx, query, f.UserID, f.Status).Scan(&total); err != nil {
+		return 0, err
+	}
+
+	return total, nil
+}
+
+func (r *orderRepository) listItems(ctx context.Context, order *entities.Order) ([]*entities.OrderItem, error) {
+	query := `
+		SELECT id, product_id, title, quantity, unit_price::TEXT, base_unit_price::TEXT, line_total::TEXT,
+			tax_name, tax_rate::TEXT, taxable_amount::TEXT, tax_amount::TEXT
+		FROM order_items WHERE order_id = $1
+		ORDER BY id
+	`
+	rows, err := r.db.QueryContext(ctx, query, order.ID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	items := []*entities.OrderItem{}
+	for rows.Next() {
+		var item entities.OrderItem
+		var unit, base, line, taxAmount string
+		var taxName, taxRate, taxable sql.NullString
+
+		err := rows.Scan(&item.ID, &item.ProductID, &item.Title, &item.Quantity, &unit, &base, &line,
+			&taxName, &taxRate, &taxable, &taxAmount)
+		if err != nil {
+			return nil, err
+		}
+
+		if item.UnitPrice, err = money.Parse(unit, order.Currency); err != nil {
+			return nil, err
+		}
+		if item.BaseUnitPrice, err = money.Parse(base, money.DefaultCurrency); err != nil {
+			return nil, err
+		}
+		if item.LineTotal, err = money.Parse(line, order.Currency); err != nil {
+			return nil, err
+		}
+
+		if taxName.Valid {
+			item.Tax = &entities.LineTax{Name: taxName.String, Rate: taxRate.String}
+			if item.Tax.Taxable, err = money.Parse(taxable.String, order.Currency); err != nil {
+				return nil, err
+			}
+			if item.Tax.Amount, err = money.Parse(taxAmount, order.Currency); err != nil {
+				return nil, err
+			}
+		}
+		items = append(items, &item)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+func (r *orderRepository) listDiscounts(ctx context.Context, order *entities.Order) ([]*entities.Discount, error) {
+	query := `
+		SELECT COALESCE(promotion_id, 0), code, name, kind, COALESCE(product_id, ''), amount::TEXT
+		FROM order_discounts WHERE order_id = $1
+		ORDER BY id
+	`
+	rows, err := r.db.QueryContext(ctx, query, order.ID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	discounts := []*entities.Discount{}
+	for rows.Next() {
+		var d entities.Discount
+		var amount string
+
+		if err := rows.Scan(&d.PromotionID, &d.Code, &d.Name, &d.Kind, &d.ProductID, &amount); err != nil {
+			return nil, err
+		}
+
+		if d.Amount, err = money.Parse(amount, order.Currency); err != nil {
+			return nil, err
+		}
+		discounts = append(discounts, &d)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return discounts, nil
+}
+
+func (r *orderRepository) listPayments(ctx context.Context, order *entities.Order) ([]*entities.Payment, error) {
+	query := `
+		SELECT p.method, COALESCE(g.code, ''), p.amount::TEXT, p.created_at
+		FROM order_payments p
+		LEFT JOIN gift_cards g ON g.id = p.gift_card_id
+		WHERE p.order_id = $1
+		ORDER BY p.id
+	`
+	rows, err := r.db.QueryContext(ctx, query, order.ID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	payments := []*entities.Payment{}
+	for rows.Next() {
+		var p entities.Payment
+		var code, amount string
+
+		if err := rows.Scan(&p.Method, &code, &amount, &p.CreatedAt); err != nil {
+			return nil, err
+		}
+
+		if code != "" {
+			p.GiftCard = maskCode(code)
+		}
+		if p.Amount, err = money.Parse(amount, order.Currency); err != nil {
+			return nil, err
+		}
+		payments = append(payments, &p)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return payments, nil
+}
+
+func (r *orderRepository) listHistory(ctx context.Context, orderID int64) ([]*entities.OrderEvent, error) {
+	query := `
+		SELECT event, status, note, created_at
+		FROM order_history WHERE order_id = $1
+		ORDER BY id
+	`
+	rows, err := r.db.QueryContext(ctx, query, orderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	history := []*entities.OrderEvent{}
+	for rows.Next() {
+		var e entities.OrderEvent
+		if err := rows.Scan(&e.Event, &e.Status, &e.Note, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		history = append(history, &e)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return history, nil
+}
+
+// scanOrder reads the amounts as text since their currency is only known
+// once the row is read
+func scanOrder(scan func(dest ...any) error) (*entities.Order, error) {
+	var o entities.Order
+	var subtotal, discountTotal, taxTotal, shippingTotal, total, refunded, gateway string
+	var methodID sql.NullInt64
+	var method, kind sql.NullString
+	var addr entities.Address
+
+	err := scan(
+		&o.ID,
+		&o.UserID,
+		&o.Status,
+		&o.Currency,
+		&o.ExchangeRate,
+		&o.ExchangeRateID,
+		&subtotal,
+		&discountTotal,
+		&o.FreeShipping,
+		&taxTotal,
+		&o.PricesIncludeTax,
+		&o.TaxCountry,
+		&o.TaxRegion,
+		&methodID,
+		&method,
+		&kind,
+		&shippingTotal,
+		&o.WeightGrams,
+		&addr.Name,
+		&addr.Phone,
+		&addr.Line1,
+		&addr.Line2,
+		&addr.City,
+		&addr.PostalCode,
+		&total,
+		&refunded,
+		&gateway,
+		&o.CreatedAt,
+		&o.UpdatedAt,
+		pq.Array(&o.SortValues),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if o.Subtotal, err = money.Parse(subtotal, o.Currency); err != nil {
+		return nil, err
+	}
+	if o.DiscountTotal, err = money.Parse(discountTotal, o.Currency); err != nil {
+		return nil, err
+	}
+	if o.TaxTotal, err = money.Parse(taxTotal, o.Currency); err != nil {
+		return nil, err
+	}
+	if o.ShippingTotal, err = money.Parse(shippingTotal, o.Currency); err != nil {
+		return nil, err
+	}
+	if o.Total, err = money.Parse(total, o.Currency); err != nil {
+		return nil, err
+	}
+	if o.RefundedTotal, err = money.Parse(refunded, o.Currency); err != nil {
+		return nil, err
+	}
+	if o.GatewayTotal, err = money.Parse(gateway, o.Currency); err != nil {
+		return nil, err
+	}
+
+	// Orders placed before shipping existed have neither method nor address
+	if method.Valid {
+		o.Shipping = &entities.ShippingQuote{
+			MethodID: int(methodID.Int64),
+			Name:     method.String,
+			Kind:     kind.String,
+			Cost:     o.ShippingTotal,
+		}
+
+		if o.TaxCountry != nil {
+			addr.Country = *o.TaxCountry
+		}
+		addr.Region = o.TaxRegion
+		o.ShippingAddress = &addr
+	}
+
+	return &o, nil
+}
+
+// taxSummaries adds up the taxed items of the order by tax and rate in the
+// order they first appear
+func taxSummaries(order *entities.Order) []*entities.TaxSummary {
+	type taxKey struct{ name, rate string }
+
+	summaries := []*entities.TaxSummary{}
+	byKey := make(map[taxKey]*entities.TaxSummary)
+	for _, item := range order.Items {
+		if item.Tax == nil {
+			continue
+		}
+
+		key := taxKey{item.Tax.Name, item.Tax.Rate}
+		summary, ok := byKey[key]
+		if !ok {
+			summary = &entities.TaxSummary{
+				Name:    item.Tax.Name,
+				Rate:    item.Tax.Rate,
+				Taxable: money.New(0, order.Currency),
+				Amount:  money.New(0, order.Currency),
+			}
+			byKey[key] = summary
+			summaries = append(summaries, summary)
+		}
+		summary.Taxable = summary.Taxable.Add(item.Tax.Taxable)
+		summary.Amount = summary.Amount.Add(item.Tax.Amount)
+	}
+
+	return summaries
+}
+
+// payWithCredit pays what it can of a new order from the gift cards, in
+// the order given, then from the store credit it asked for. The payment
+// gateway is left the rest, an order paid in full this way is paid.
+func payWithCredit(ctx context.Context, tx *sql.Tx, order *entities.Order) error {
+	due := order.Total
+
+	for _, code := range order.GiftCards {
+		if due.IsZero() {
+			break
+		}
+
+		taken, err := redeemGiftCard(ctx, tx, order, code, due)
+		if err != nil {
+			return err
+		}
+		due = due.Sub(taken)
+	}
+
+	if order.UseStoreCredit && !due.IsZero() {
+		var balance string
+		query := `SELECT balance::TEXT FROM store_credit_balances WHERE user_id = $1 AND currency = $2 FOR UPDATE`
+		err := tx.QueryRowContext(ctx, query, order.UserID, order.Currency).Scan(&balance)
+		if err != nil && err != sql.ErrNoRows {
+			return err
+		}
+
+		available := money.New(0, order.Currency)
+		if err == nil {
+			if available, err = money.Parse(balance, order.Currency); err != nil {
+				return err
+			}
+		}
+
+		if !available.IsZero() {
+			taken := due
+			if available.Cmp(due) < 0 {
+				taken = available
+			}
+
+			entry := &entities.StoreCreditEntry{
+				UserID:  order.UserID,
+				Kind:    entities.CreditRedemption,
+				Amount:  taken.Neg(),
+				OrderID: &order.ID,
+			}
+			if err := addCreditEntry(ctx, tx, entry); err != nil {
+				return err
+			}
+
+			payment := &entities.Payment{Method: entities.PaymentStoreCredit, Amount: taken}
+			if err := addPayment(ctx, tx, order.ID, nil, payment); err != nil {
+				return err
+			}
+			order.Payments = append(order.Payments, payment)
+			due = due.Sub(taken)
+		}
+	}
+
+	order.GatewayTotal = due
+	if len(order.Payments) == 0 {
+		return nil
+	}
+
+	if due.IsZero() {
+		order.Status = entities.OrderPaid
+	}
+
+	query := `UPDATE orders SET gateway_total = $2, status = $3 WHERE id = $1`
+	_, err := tx.ExecContext(ctx, query, order.ID, order.GatewayTotal, order.Status)
+	return err
+}
+
+func addPayment(ctx context.Context, tx *sql.Tx, orderID int64, giftCardID *int64, payment *entities.Payment) error {
+	query := `
+		INSERT INTO order_payments (order_id, method, gift_card_id, amount)
+		VALUES ($1, $2, $3, $4)
+		RETURNING created_at
+	`
+	err := tx.QueryRowContext(ctx, query, orderID, payment.Method, giftCardID, payment.Amount).Scan(&payment.CreatedAt)
+	return dbError(err, nil)
+}
@@ -0,0 +1,380 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/codepnw/react_go_ecom/internal/entities"
+	"github.com/codepnw/react_go_ecom/internal/errs"
+	"github.com/codepnw/react_go_ecom/pkg/money"
+	"github.com/codepnw/react_go_ecom/pkg/pagination"
+	"github.com/lib/pq"
+)
+
+type PriceHistoryRepository interface {
+	ListHistory(ctx context.Context, f *entities.PriceHistoryFilter) ([]*entities.PriceChange, error)
+	CountHistory(ctx context.Context, f *entities.PriceHistoryFilter) (int, error)
+	CreateSchedule(ctx context.Context, s *entities.ScheduledPrice) error
+	ListSchedules(ctx context.Context, productID string) ([]*entities.ScheduledPrice, error)
+	CancelSchedule(ctx context.Context, productID string, id int64) error
+	ApplyDue(ctx context.Context, now time.Time, limit int) ([]*entities.ScheduledPrice, error)
+}
+
+type priceHistoryRepository struct {
+	db *sql.DB
+}
+
+func NewPriceHistoryRepository(db *sql.DB) PriceHistoryRepository {
+	return &priceHistoryRepository{db: db}
+}
+
+// ListHistory reads the product's price changes newest first, one row past
+// the limit
+func (r *priceHistoryRepository) ListHistory(ctx context.Context, f *entities.PriceHistoryFilter) ([]*entities.PriceChange, error) {
+	columns := []pagination.Column{{Expr: "h.id", Type: "BIGINT", Desc: true}}
+
+	var args []any
+	arg := func(v any) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	where := "WHERE h.product_id = " + arg(f.ProductID)
+	if f.Currency != "" {
+		where += " AND h.currency = " + arg(f.Currency)
+	}
+
+	backward := false
+	if f.After != nil {
+		where += " AND " + pagination.Condition(columns, f.After, arg)
+		backward = f.After.Backward
+	}
+
+	query := fmt.Sprintf(`
+		SELECT h.id, h.product_id, h.currency, h.old_amount::TEXT, h.new_amount::TEXT, h.actor,
+			h.reason, h.scheduled_price_id, h.changed_at, ARRAY[%s]
+		FROM price_history h
+		%s
+		%s
+		LIMIT %s
+	`, pagination.Keys(columns), where, pagination.OrderBy(columns, backward), arg(f.Limit+1))
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	changes := []*entities.PriceChange{}
+	for rows.Next() {
+		var c entities.PriceChange
+		var oldAmount, newAmount sql.NullString
+
+		if err := rows.Scan(
+			&c.ID,
+			&c.ProductID,
+			&c.Currency,
+			&oldAmount,
+			&newAmount,
+			&c.Actor,
+			&c.Reason,
+			&c.ScheduledPriceID,
+			&c.ChangedAt,
+			pq.Array(&c.SortValues),
+		); err != nil {
+			return nil, err
+		}
+
+		if c.OldPrice, err = nullMoney(oldAmount, c.Currency); err != nil {
+			return nil, err
+		}
+		if c.NewPrice, err = nullMoney(newAmount, c.Currency); err != nil {
+			return nil, err
+		}
+		changes = append(changes, &c)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return changes, nil
+}
+
+func (r *priceHistoryRepository) CountHistory(ctx context.Context, f *entities.PriceHistoryFilter) (int, error) {
+	query := `SELECT COUNT(*) FROM price_history WHERE product_id = $1 AND ($2 = '' OR currency = $2)`
+
+	var total int
+	if err := r.db.QueryRowContext(ctx, query, f.ProductID, f.Currency).Scan(&total); err != nil {
+		return 0, err
+	}
+
+	return total, nil
+}
+
+func (r *priceHistoryRepository) CreateSchedule(ctx context.Context, s *entities.ScheduledPrice) error {
+	query := `
+		INSERT INTO scheduled_prices (product_id, currency, amount, effective_at, reason, created_by)
+		SELECT product_id, $2, $3, $4, $5, $6 FROM products WHERE product_id = $1
+		RETURNING id, status, created_at
+	`
+	err := r.db.QueryRowContext(
+		ctx,
+		query,
+		s.ProductID,
+		s.Price.Currency,
+		s.Price,
+		s.EffectiveAt,
+		s.Reason,
+		s.CreatedBy,
+	).Scan(&s.ID, &s.Status, &s.CreatedAt)
+
+	return dbError(err, errProductNotFound)
+}
+
+// ListSchedules lists pending changes first in the order they apply, then
+// applied and canceled ones newest first
+func (r *priceHistoryRepository) ListSchedules(ctx context.Context, productID string) ([]*entities.ScheduledPrice, error) {
+	query := `
+		SELECT id, product_id, currency, amount::TEXT, effective_at, reason, created_by, status, applied_at, created_at
+		FROM scheduled_prices WHERE product_id = $1
+		ORDER BY status <> 'pending',
+			CASE WHEN status = 'pending' THEN effective_at END,
+			effective_at DESC, id DESC
+	`
+	rows, err := r.db.QueryContext(ctx, query, productID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	schedules := []*entities.ScheduledPrice{}
+	for rows.Next() {
+		s, err := scanSchedule(rows.Scan)
+		if err != nil {
+			return nil, err
+		}
+		schedules = append(schedules, s)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return schedules, nil
+}
+
+// CancelSchedule only cancels pending changes, an applied one is history
+func (r *priceHistoryRepository) CancelSchedule(ctx context.Context, productID string, id int64) error {
+	query := `
+		UPDATE scheduled_prices SET status = 'canceled'
+		WHERE id = $1 AND product_id = $2 AND status = 'pending'
+	`
+	result, err := r.db.ExecContext(ctx, query, id, productID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return errs.NotFound("scheduled_price_not_found", "no pending scheduled price with this id")
+	}
+
+	return nil
+}
+
+// ApplyDue applies up to limit changes that became effective by now in one
+// transaction. SKIP LOCKED lets several instances run the scheduler without
+// applying a change twice.
+func (r *priceHistoryRepository) ApplyDue(ctx context.Context, now time.Time, limit int) ([]*entities.ScheduledPrice, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	query := `
+		SELECT id, product_id, currency, amount::TEXT, effective_at, reason, created_by, status, applied_at, created_at
+		FROM scheduled_prices
+		WHERE status = 'pending' AND effective_at <= $1
+		ORDER BY effective_at, id
+		LIMIT $2
+		FOR UPDATE SKIP LOCKED
+	`
+	rows, err := tx.QueryContext(ctx, query, now, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	var due []*entities.ScheduledPrice
+	for rows.Next() {
+		s, err := scanSchedule(rows.Scan)
+		if err != nil {
+			rows.Close()
+			return nil, err
+		}
+		due = append(due, s)
+	}
+	rows.Close()
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, s := range due {
+		old, _, err := setPrice(ctx, tx, s.ProductID, s.Price)
+		if err != nil {
+			return nil, err
+		}
+
+		change := &entities.PriceChange{
+			ProductID:        s.ProductID,
+			Currency:         s.Price.Currency,
+			OldPrice:         old,
+			NewPrice:         &s.Price,
+			Actor:            s.CreatedBy,
+			Reason:           s.Reason,
+			ScheduledPriceID: &s.ID,
+		}
+		if err := insertPriceChange(ctx, tx, change); err != nil {
+			return nil, err
+		}
+
+		err = tx.QueryRowContext(ctx, `
+			UPDATE scheduled_prices SET status = 'applied', applied_at = now()
+			WHERE id = $1 RETURNING status, applied_at
+		`, s.ID).Scan(&s.Status, &s.AppliedAt)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return due, nil
+}
+
+// setPrice changes the product price for the base currency, the explicit
+// price otherwise, and returns the price it replaced
+func setPrice(ctx context.Context, tx *sql.Tx, productID string, price money.Money) (*money.Money, *time.Time, error) {
+	var old sql.NullString
+	var updatedAt *time.Time
+
+	if price.Currency == money.DefaultCurrency {
+		err := tx.QueryRowContext(ctx, `SELECT price::TEXT FROM products WHERE product_id = $1 FOR UPDATE`, productID).Scan(&old)
+		if err != nil {
+			return nil, nil, dbError(err, errProductNotFound)
+		}
+
+		err = tx.QueryRowContext(ctx, `
+			UPDATE products SET price = $2, updated_at = now()
+			WHERE product_id = $1 RETURNING updated_at
+		`, productID, price).Scan(&updatedAt)
+		if err != nil {
+			return nil, nil, dbError(err, errProductNotFound)
+		}
+	} else {
+		err := tx.QueryRowContext(ctx, `
+			SELECT amount::TEXT FROM product_prices WHERE product_id = $1 AND currency = $2 FOR UPDATE
+		`, productID, price.Currency).Scan(&old)
+		if err != nil && err != sql.ErrNoRows {
+			return nil, nil, err
+		}
+
+		err = tx.QueryRowContext(ctx, `
+			INSERT INTO product_prices (product_id, currency, amount)
+			SELECT product_id, $2, $3 FROM products WHERE product_id = $1
+			ON CONFLICT (product_id, currency) DO UPDATE
+			SET amount = EXCLUDED.amount, updated_at = now()
+			RETURNING updated_at
+		`, productID, price.Currency, price).Scan(&updatedAt)
+		if err != nil {
+			return nil, nil, dbError(err, errProductNotFound)
+		}
+	}
+
+	oldPrice, err := nullMoney(old, price.Currency)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return oldPrice, updatedAt, nil
+}
+
+// insertPriceChange records a change inside the transaction that made it,
+// unchanged prices are not recorded
+func insertPriceChange(ctx context.Context, tx *sql.Tx, c *entities.PriceChange) error {
+	if c.OldPrice != nil && c.NewPrice != nil && *c.OldPrice == *c.NewPrice {
+		return nil
+	}
+
+	query := `
+		INSERT INTO price_history (product_id, currency, old_amount, new_amount, actor, reason, scheduled_price_id)
+		VALUES ($1, $2, $3, $4, NULLIF($5, ''), $6, $7)
+		RETURNING id, changed_at
+	`
+	var actor string
+	if c.Actor != nil {
+		actor = *c.Actor
+	}
+
+	return tx.QueryRowContext(
+		ctx,
+		query,
+		c.ProductID,
+		c.Currency,
+		c.OldPrice,
+		c.NewPrice,
+		actor,
+		c.Reason,
+		c.ScheduledPriceID,
+	).Scan(&c.ID, &c.ChangedAt)
+}
+
+func scanSchedule(scan func(dest ...any) error) (*entities.ScheduledPrice, error) {
+	var s entities.ScheduledPrice
+	var currency, amount string
+
+	err := scan(
+		&s.ID,
+		&s.ProductID,
+		&currency,
+		&amount,
+		&s.EffectiveAt,
+		&s.Reason,
+		&s.CreatedBy,
+		&s.Status,
+		&s.AppliedAt,
+		&s.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.Price, err = money.Parse(amount, currency); err != nil {
+		return nil, err
+	}
+
+	return &s, nil
+}
+
+func nullMoney(amount sql.NullString, currency string) (*money.Money, error) {
+	if !amount.Valid {
+		return nil, nil
+	}
+
+	m, err := money.Parse(amount.String, currency)
+	if err != nil {
+		return nil, err
+	}
+
+	return &m, nil
+}
@@ -0,0 +1,275 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/codepnw/react_go_ecom/internal/entities"
+	"github.com/codepnw/react_go_ecom/internal/errs"
+	"github.com/codepnw/react_go_ecom/pkg/money"
+	"github.com/lib/pq"
+)
+
+type PricingRepository interface {
+	CreateRate(ctx context.Context, rate *entities.ExchangeRate) error
+	ListRates(ctx context.Context, f *entities.ExchangeRateFilter) ([]*entities.ExchangeRate, error)
+	DeleteRate(ctx context.Context, id int) error
+	CurrentRate(ctx context.Context, base, quote string, at time.Time) (*entities.ExchangeRate, error)
+	ListProductPrices(ctx context.Context, productID string) ([]*entities.ProductPrice, error)
+	PricesByProducts(ctx context.Context, ids []string, currency string) (map[string]money.Money, error)
+	UpsertProductPrice(ctx context.Context, productID string, price *entities.ProductPrice, audit entities.PriceAudit) error
+	DeleteProductPrice(ctx context.Context, productID, currency string, audit entities.PriceAudit) error
+}
+
+type pricingRepository struct {
+	db *sql.DB
+}
+
+func NewPricingRepository(db *sql.DB) PricingRepository {
+	return &pricingRepository{db: db}
+}
+
+func (r *pricingRepository) CreateRate(ctx context.Context, rate *entities.ExchangeRate) error {
+	query := `
+		INSERT INTO exchange_rates (base, quote, rate, effective_from)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, rate::TEXT, created_at
+	`
+	err := r.db.QueryRowContext(ctx, query, rate.Base, rate.Quote, rate.Rate, rate.EffectiveFrom).
+		Scan(&rate.ID, &rate.Rate, &rate.CreatedAt)
+
+	return dbError(err, nil)
+}
+
+// ListRates lists rates newest first per pair, base and quote narrow it
+// down when set
+func (r *pricingRepository) ListRates(ctx context.Context, f *entities.ExchangeRateFilter) ([]*entities.ExchangeRate, error) {
+	query := `
+		SELECT id, base, quote, rate::TEXT, effective_from, created_at
+		FROM exchange_rates
+		WHERE ($1 = '' OR base = $1) AND ($2 = '' OR quote = $2)
+		ORDER BY base, quote, effective_from DESC, id DESC
+	`
+	rows, err := r.db.QueryContext(ctx, query, f.Base, f.Quote)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rates := []*entities.ExchangeRate{}
+	for rows.Next() {
+		var rate entities.ExchangeRate
+		if err := rows.Scan(&rate.ID, &rate.Base, &rate.Quote, &rate.Rate, &rate.EffectiveFrom, &rate.CreatedAt); err != nil {
+			return nil, err
+		}
+		rates = append(rates, &rate)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return rates, nil
+}
+
+// DeleteRate refuses to remove the last rate in effect for a currency that
+// still has product prices, shipping and other base currency amounts of
+// carts in it are converted at that rate
+func (r *pricingRepository) DeleteRate(ctx context.Context, id int) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var base, quote string
+	err = tx.QueryRowContext(ctx, `DELETE FROM exchange_rates WHERE id = $1 RETURNING base, quote`, id).Scan(&base, &quote)
+	if err != nil {
+		return dbError(err, errRateNotFound)
+	}
+
+	currency := quote
+	if quote == money.DefaultCurrency {
+		currency = base
+	}
+
+	if base == money.DefaultCurrency || quote == money.DefaultCurrency {
+		query := `
+			SELECT EXISTS (SELECT 1 FROM product_prices WHERE currency = $2)
+				AND NOT EXISTS (
+					SELECT 1 FROM exchange_rates
+					WHERE ((base = $1 AND quote = $2) OR (base = $2 AND quote = $1)) AND effective_from <= now()
+				)
+		`
+		var stranded bool
+		if err := tx.QueryRowContext(ctx, query, money.DefaultCurrency, currency).Scan(&stranded); err != nil {
+			return err
+		}
+		if stranded {
+			return errs.Conflict("exchange_rate_in_use", "products are priced in %s, it needs a rate in effect", currency)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// CurrentRate finds the latest rate in effect at the given time for the
+// pair in either direction. The caller inverts a rate stored the other way
+// round.
+func (r *pricingRepository) CurrentRate(ctx context.Context, base, quote string, at time.Time) (*entities.ExchangeRate, error) {
+	query := `
+		SELECT id, base, quote, rate::TEXT, effective_from, created_at
+		FROM exchange_rates
+		WHERE ((base = $1 AND quote = $2) OR (base = $2 AND quote = $1))
+			AND effective_from <= $3
+		ORDER BY effective_from DESC, id DESC
+		LIMIT 1
+	`
+	var rate entities.ExchangeRate
+
+	err := r.db.QueryRowContext(ctx, query, base, quote, at).Scan(
+		&rate.ID,
+		&rate.Base,
+		&rate.Quote,
+		&rate.Rate,
+		&rate.EffectiveFrom,
+		&rate.CreatedAt,
+	)
+	if err != nil {
+		return nil, dbError(err, errRateNotFound)
+	}
+
+	return &rate, nil
+}
+
+func (r *pricingRepository) ListProductPrices(ctx context.Context, productID string) ([]*entities.ProductPrice, error) {
+	query := `
+		SELECT currency, amount::TEXT, updated_at
+		FROM product_prices WHERE product_id = $1
+		ORDER BY currency
+	`
+	rows, err := r.db.QueryContext(ctx, query, productID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	prices := []*entities.ProductPrice{}
+	for rows.Next() {
+		var p entities.ProductPrice
+		var currency, amount string
+
+		if err := rows.Scan(&currency, &amount, &p.UpdatedAt); err != nil {
+			return nil, err
+		}
+		if p.Price, err = money.Parse(amount, currency); err != nil {
+			return nil, fmt.Errorf("product %s price: %w", productID, err)
+		}
+		prices = append(prices, &p)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return prices, nil
+}
+
+// PricesByProducts maps product ids to their explicit price in currency,
+// products without one are absent
+func (r *pricingRepository) PricesByProducts(ctx context.Context, ids []string, currency string) (map[string]money.Money, error) {
+	query := `
+		SELECT product_id, amount::TEXT
+		FROM product_prices WHERE product_id = ANY($1) AND currency = $2
+	`
+	rows, err := r.db.QueryContext(ctx, query, pq.Array(ids), currency)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	prices := make(map[string]money.Money)
+	for rows.Next() {
+		var id, amount string
+		if err := rows.Scan(&id, &amount); err != nil {
+			return nil, err
+		}
+
+		price, err := money.Parse(amount, currency)
+		if err != nil {
+			return nil, fmt.Errorf("product %s price: %w", id, err)
+		}
+		prices[id] = price
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return prices, nil
+}
+
+// UpsertProductPrice sets the explicit price and records the change in the
+// price history in one transaction
+func (r *pricingRepository) UpsertProductPrice(ctx context.Context, productID string, price *entities.ProductPrice, audit entities.PriceAudit) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	old, updatedAt, err := setPrice(ctx, tx, productID, price.Price)
+	if err != nil {
+		return err
+	}
+	price.UpdatedAt = updatedAt
+
+	change := &entities.PriceChange{
+		ProductID: productID,
+		Currency:  price.Price.Currency,
+		OldPrice:  old,
+		NewPrice:  &price.Price,
+		Actor:     &audit.Actor,
+		Reason:    audit.Reason,
+	}
+	if err := insertPriceChange(ctx, tx, change); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *pricingRepository) DeleteProductPrice(ctx context.Context, productID, currency string, audit entities.PriceAudit) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `DELETE FROM product_prices WHERE product_id = $1 AND currency = $2 RETURNING amount::TEXT`
+
+	var amount string
+	if err := tx.QueryRowContext(ctx, query, productID, currency).Scan(&amount); err != nil {
+		return dbError(err, errPriceNotFound)
+	}
+
+	old, err := money.Parse(amount, currency)
+	if err != nil {
+		return err
+	}
+
+	change := &entities.PriceChange{
+		ProductID: productID,
+		Currency:  currency,
+		OldPrice:  &old,
+		Actor:     &audit.Actor,
+		Reason:    audit.Reason,
+	}
+	if err := insertPriceChange(ctx, tx, change); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
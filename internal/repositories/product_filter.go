@@ -0,0 +1,484 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/codepnw/react_go_ecom/internal/entities"
+	"github.com/codepnw/react_go_ecom/pkg/pagination"
+	"github.com/lib/pq"
+)
+
+// Filter dimensions that a facet can leave out of its own counts
+const (
+	facetNone      = ""
+	facetCategory  = "category"
+	facetPrice     = "price"
+	facetInStock   = "in_stock"
+	facetAttribute = "attr:"
+)
+
+// Sort expressions are never NULL so keyset comparisons hold
+var productSortColumns = map[string]pagination.Column{
+	"price":      {Expr: "p.price", Type: "NUMERIC"},
+	"created_at": {Expr: "COALESCE(p.created_at, '-infinity')", Type: "TIMESTAMPTZ"},
+	"quantity":   {Expr: "COALESCE(p.quantity, 0)", Type: "INT"},
+	"title":      {Expr: "COALESCE(pt.title, p.title)", Type: "TEXT"},
+}
+
+const headlineOptions = `'StartSel=<mark>, StopSel=</mark>, MaxWords=30, MinWords=10, MaxFragments=2'`
+
+// fuzzyThreshold is the trigram word similarity a fuzzy match needs. The
+// default of 0.6 misses common typos such as "iphnoe".
+const fuzzyThreshold = 0.3
+
+// querier runs statements on the database or inside a transaction
+type querier interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// searchQuerier returns where the queries of a listing run. A fuzzy search
+// runs in a read only transaction that lowers the trigram threshold for
+// itself only, done ends it once the rows are read.
+func (r *productRepository) searchQuerier(ctx context.Context, f *entities.ProductFilter) (q querier, done func(), err error) {
+	if f.Search == "" || f.SearchMode != entities.SearchFuzzy {
+		return r.db, func() {}, nil
+	}
+
+	tx, err := r.db.BeginTx(ctx, &sql.TxOptions{ReadOnly: true})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// SET takes no placeholders
+	query := fmt.Sprintf("SET LOCAL pg_trgm.word_similarity_threshold = %g", fuzzyThreshold)
+	if _, err := tx.ExecContext(ctx, query); err != nil {
+		tx.Rollback()
+		return nil, nil, err
+	}
+
+	return tx, func() { tx.Rollback() }, nil
+}
+
+// whereBuilder collects conditions and their positional arguments. For a
+// search it also carries the relevance and tsquery expressions.
+type whereBuilder struct {
+	conds   []string
+	args    []any
+	rank    string
+	tsquery string
+}
+
+func (w *whereBuilder) arg(v any) string {
+	w.args = append(w.args, v)
+	return fmt.Sprintf("$%d", len(w.args))
+}
+
+func (w *whereBuilder) add(cond string) {
+	w.conds = append(w.conds, cond)
+}
+
+func (w *whereBuilder) sql() string {
+	if len(w.conds) == 0 {
+		return ""
+	}
+	return "WHERE " + strings.Join(w.conds, " AND ")
+}
+
+// productWhere builds the filter on products aliased as p. The exclude
+// dimension is skipped so a facet can count values of its own filter.
+func productWhere(f *entities.ProductFilter, exclude string) *whereBuilder {
+	w := &whereBuilder{}
+
+	if f.Search != "" {
+		switch {
+		case f.SearchMode == entities.SearchFuzzy:
+			// Trigram match on the title in any locale, served by the title
+			// trigram indexes. The query runs with fuzzyThreshold, see
+			// searchQuerier.
+			q := w.arg(f.Search)
+			w.add(fmt.Sprintf(`(%[1]s <%% p.title OR EXISTS (
+				SELECT 1 FROM product_translations t WHERE t.product_id = p.product_id AND %[1]s <%% t.title
+			))`, q))
+			w.rank = fmt.Sprintf(`GREATEST(word_similarity(%[1]s, p.title), (
+				SELECT COALESCE(MAX(word_similarity(%[1]s, t.title)), 0) FROM product_translations t WHERE t.product_id = p.product_id
+			))`, q)
+		case len(f.SearchTerms) > 0:
+			w.tsquery = expandedTsquery(w, f.SearchTerms)
+		default:
+			w.tsquery = fmt.Sprintf("websearch_to_tsquery('simple', %s)", w.arg(f.Search))
+		}
+
+		if w.tsquery != "" {
+			w.add("p.search_vector @@ " + w.tsquery)
+			w.rank = fmt.Sprintf("ts_rank(p.search_vector, %s)", w.tsquery)
+		}
+	}
+
+	if f.CategoryID != nil && exclude != facetCategory {
+		w.add("p.category_id = " + w.arg(*f.CategoryID))
+	}
+
+	if exclude != facetPrice {
+		if f.MinPrice != nil {
+			w.add("p.price >= " + w.arg(*f.MinPrice))
+		}
+		if f.MaxPrice != nil {
+			w.add("p.price <= " + w.arg(*f.MaxPrice))
+		}
+	}
+
+	if f.InStock != nil && exclude != facetInStock {
+		if *f.InStock {
+			w.add("p.stock > 0")
+		} else {
+			w.add("p.stock = 0")
+		}
+	}
+
+	if f.CreatedFrom != nil {
+		w.add("p.created_at >= " + w.arg(*f.CreatedFrom))
+	}
+
+	if f.CreatedTo != nil {
+		// The date is inclusive
+		w.add("p.created_at < " + w.arg(f.CreatedTo.AddDate(0, 0, 1)))
+	}
+
+	for _, af := range f.Attributes {
+		if exclude == facetAttribute+af.Code {
+			continue
+		}
+		w.add(attributeCondition(w, af))
+	}
+
+	return w
+}
+
+// expandedTsquery ANDs the terms of a query expanded with synonyms, the
+// alternatives of a term are ORed and each is matched as a phrase
+func expandedTsquery(w *whereBuilder, terms []*entities.SearchTerm) string {
+	parts := make([]string, len(terms))
+
+	for i, t := range terms {
+		alternatives := make([]string, len(t.Alternatives))
+		for j, a := range t.Alternatives {
+			alternatives[j] = fmt.Sprintf("phraseto_tsquery('simple', %s)", w.arg(a))
+		}
+
+		parts[i] = "(" + strings.Join(alternatives, " || ") + ")"
+		if t.Negate {
+			parts[i] = "!!" + parts[i]
+		}
+	}
+
+	return "(" + strings.Join(parts, " && ") + ")"
+}
+
+func attributeCondition(w *whereBuilder, af *entities.AttributeFilter) string {
+	var cond string
+
+	switch af.Type {
+	case entities.AttributeNumber:
+		var parts []string
+		if af.Min != nil {
+			parts = append(parts, "v.value_number >= "+w.arg(*af.Min))
+		}
+		if af.Max != nil {
+			parts = append(parts, "v.value_number <= "+w.arg(*af.Max))
+		}
+		cond = strings.Join(parts, " AND ")
+		if cond == "" {
+			cond = "v.value_number IS NOT NULL"
+		}
+	case entities.AttributeBoolean:
+		cond = "v.value_bool::text = ANY(" + w.arg(pq.Array(af.Values)) + ")"
+	default:
+		cond = "v.value_text = ANY(" + w.arg(pq.Array(af.Values)) + ")"
+	}
+
+	return fmt.Sprintf(`EXISTS (
+		SELECT 1 FROM product_attribute_values v
+		JOIN attributes a ON a.id = v.attribute_id
+		WHERE v.product_id = p.product_id AND a.code = %s AND %s
+	)`, w.arg(af.Code), cond)
+}
+
+// productKeyset turns the sort keys into keyset columns, the usecase has
+// already applied the default order. The product id breaks ties.
+func productKeyset(keys []*entities.SortKey, rank string) []pagination.Column {
+	var columns []pagination.Column
+
+	for _, k := range keys {
+		column, ok := productSortColumns[k.Field]
+		if k.Field == "relevance" && rank != "" {
+			column, ok = pagination.Column{Expr: rank, Type: "REAL"}, true
+		}
+		if !ok {
+			continue
+		}
+
+		column.Desc = k.Desc
+		columns = append(columns, column)
+	}
+
+	return append(columns, pagination.Column{Expr: "p.product_id", Type: "TEXT"})
+}
+
+func (r *productRepository) List(ctx context.Context, f *entities.ProductFilter) ([]*entities.Product, error) {
+	w := productWhere(f, facetNone)
+	columns := productKeyset(f.SortKeys, w.rank)
+
+	backward := false
+	if f.After != nil {
+		w.add(pagination.Condition(columns, f.After, w.arg))
+		backward = f.After.Backward
+	}
+
+	highlights := "NULL, NULL"
+	if w.tsquery != "" {
+		highlights = fmt.Sprintf(
+			"ts_headline('simple', COALESCE(pt.title, p.title), %[1]s, %[2]s), ts_headline('simple', COALESCE(pt.description, p.description, ''), %[1]s, %[2]s)",
+			w.tsquery, headlineOptions,
+		)
+	}
+
+	// Content in the requested locale, the columns are the fallback
+	query := fmt.Sprintf(`
+		SELECT p.product_id, COALESCE(pt.title, p.title), COALESCE(pt.description, p.description),
+			p.price, p.stock, p.quantity, p.category_id, p.tax_class_id, p.weight_grams,
+			p.length_mm, p.width_mm, p.height_mm, p.created_at, p.updated_at, %s, ARRAY[%s]
+		FROM products p
+		LEFT JOIN product_translations pt ON pt.product_id = p.product_id AND pt.locale = %s
+		%s
+		%s
+		LIMIT %s
+	`, highlights, pagination.Keys(columns), w.arg(f.Locale), w.sql(), pagination.OrderBy(columns, backward), w.arg(f.Limit+1))
+
+	q, done, err := r.searchQuerier(ctx, f)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+
+	rows, err := q.QueryContext(ctx, query, w.args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	products := []*entities.Product{}
+	for rows.Next() {
+		var p entities.Product
+		var title, description sql.NullString
+
+		if err := rows.Scan(
+			&p.ID,
+			&p.Title,
+			&p.Description,
+			&p.Price,
+			&p.Stock,
+			&p.Quantity,
+			&p.CategoryID,
+			&p.TaxClassID,
+			&p.WeightGrams,
+			&p.LengthMM,
+			&p.WidthMM,
+			&p.HeightMM,
+			&p.CreatedAt,
+			&p.UpdatedAt,
+			&title,
+			&description,
+			pq.Array(&p.SortValues),
+		); err != nil {
+			return nil, err
+		}
+
+		if title.Valid {
+			p.Highlights = &entities.ProductHighlights{
+				Title:       title.String,
+				Description: description.String,
+			}
+		}
+		products = append(products, &p)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return products, nil
+}
+
+func (r *productRepository) Count(ctx context.Context, f *entities.ProductFilter) (int, error) {
+	w := productWhere(f, facetNone)
+
+	q, done, err := r.searchQuerier(ctx, f)
+	if err != nil {
+		return 0, err
+	}
+	defer done()
+
+	var total int
+	query := "SELECT COUNT(*) FROM products p " + w.sql()
+	if err := q.QueryRowContext(ctx, query, w.args...).Scan(&total); err != nil {
+		return 0, err
+	}
+
+	return total, nil
+}
+
+// Facets counts are disjunctive: each facet applies every filter except its
+// own, so the sidebar still offers the other values of a selected filter.
+func (r *productRepository) Facets(ctx context.Context, f *entities.ProductFilter) (*entities.ProductFacets, error) {
+	facets := &entities.ProductFacets{
+		Categories: []*entities.FacetCount{},
+		Price:      &entities.PriceRange{},
+		Attributes: []*entities.AttributeFacet{},
+	}
+
+	q, done, err := r.searchQuerier(ctx, f)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+
+	// Categories
+	w := productWhere(f, facetCategory)
+	w.add("p.category_id IS NOT NULL")
+	query := fmt.Sprintf(`
+		SELECT p.category_id, COALESCE(ct.title, c.title), COUNT(*)
+		FROM products p
+		JOIN categories c ON c.id = p.category_id
+		LEFT JOIN category_translations ct ON ct.category_id = c.id AND ct.locale = %s
+		%s
+		GROUP BY p.category_id, COALESCE(ct.title, c.title)
+		ORDER BY COUNT(*) DESC, COALESCE(ct.title, c.title)
+	`, w.arg(f.Locale), w.sql())
+
+	rows, err := q.QueryContext(ctx, query, w.args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var fc entities.FacetCount
+		if err := rows.Scan(&fc.Value, &fc.Label, &fc.Count); err != nil {
+			return nil, err
+		}
+		facets.Categories = append(facets.Categories, &fc)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// Price range
+	w = productWhere(f, facetPrice)
+	query = "SELECT COALESCE(MIN(p.price), 0), COALESCE(MAX(p.price), 0) FROM products p " + w.sql()
+	if err := q.QueryRowContext(ctx, query, w.args...).Scan(&facets.Price.Min, &facets.Price.Max); err != nil {
+		return nil, err
+	}
+
+	// In stock
+	w = productWhere(f, facetInStock)
+	query = "SELECT COUNT(*) FILTER (WHERE p.stock > 0) FROM products p " + w.sql()
+	if err := q.QueryRowContext(ctx, query, w.args...).Scan(&facets.InStock); err != nil {
+		return nil, err
+	}
+
+	// Attributes without an active filter share one query, each filtered
+	// attribute is counted without its own condition
+	byCode := make(map[string]*entities.AttributeFacet)
+
+	filtered := make([]string, len(f.Attributes))
+	for i, af := range f.Attributes {
+		filtered[i] = af.Code
+	}
+
+	w = productWhere(f, facetNone)
+	w.add("a.code <> ALL(" + w.arg(pq.Array(filtered)) + ")")
+	if err := r.attributeFacets(ctx, q, w, f.Locale, byCode); err != nil {
+		return nil, err
+	}
+
+	for _, af := range f.Attributes {
+		w = productWhere(f, facetAttribute+af.Code)
+		w.add("a.code = " + w.arg(af.Code))
+		if err := r.attributeFacets(ctx, q, w, f.Locale, byCode); err != nil {
+			return nil, err
+		}
+	}
+
+	codes := make([]string, 0, len(byCode))
+	for code := range byCode {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+
+	for _, code := range codes {
+		facets.Attributes = append(facets.Attributes, byCode[code])
+	}
+
+	return facets, nil
+}
+
+func (r *productRepository) attributeFacets(ctx context.Context, q querier, w *whereBuilder, locale string, byCode map[string]*entities.AttributeFacet) error {
+	w.add("a.type IN ('enum', 'boolean', 'number')")
+
+	query := fmt.Sprintf(`
+		SELECT a.code, COALESCE(atr.label, a.label), a.type, COALESCE(a.unit, ''),
+			COALESCE(v.value_text, v.value_bool::text), COUNT(*),
+			MIN(v.value_number), MAX(v.value_number)
+		FROM product_attribute_values v
+		JOIN attributes a ON a.id = v.attribute_id
+		LEFT JOIN attribute_translations atr ON atr.attribute_id = a.id AND atr.locale = %s
+		JOIN products p ON p.product_id = v.product_id
+		%s
+		GROUP BY a.code, COALESCE(atr.label, a.label), a.type, a.unit, COALESCE(v.value_text, v.value_bool::text)
+		ORDER BY a.code, COUNT(*) DESC
+	`, w.arg(locale), w.sql())
+
+	rows, err := q.QueryContext(ctx, query, w.args...)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var code, label, typ, unit string
+		var value sql.NullString
+		var count int
+		var lo, hi sql.NullFloat64
+
+		if err := rows.Scan(&code, &label, &typ, &unit, &value, &count, &lo, &hi); err != nil {
+			return err
+		}
+
+		facet, ok := byCode[code]
+		if !ok {
+			facet = &entities.AttributeFacet{Code: code, Label: label, Type: typ, Unit: unit}
+			byCode[code] = facet
+		}
+
+		if typ == entities.AttributeNumber {
+			if lo.Valid && hi.Valid {
+				facet.Min, facet.Max = &lo.Float64, &hi.Float64
+			}
+			continue
+		}
+
+		facet.Values = append(facet.Values, &entities.FacetCount{
+			Value: value.String,
+			Count: count,
+		})
+	}
+
+	return rows.Err()
+}
@@ -0,0 +1,71 @@
+package repositories
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/codepnw/react_go_ecom/internal/entities"
+	"github.com/codepnw/react_go_ecom/pkg/money"
+)
+
+func TestProductWhere(t *testing.T) {
+	category, inStock := 3, true
+	min := money.New(10000, "THB")
+	to := time.Date(2024, 5, 31, 0, 0, 0, 0, time.UTC)
+	f := &entities.ProductFilter{
+		CategoryID: &category,
+		MinPrice:   &min,
+		InStock:    &inStock,
+		CreatedTo:  &to,
+		Attributes: []*entities.AttributeFilter{{Code: "color", Type: entities.AttributeText, Values: []string{"red"}}},
+	}
+
+	w := productWhere(f, facetNone)
+	where := w.sql()
+	for _, cond := range []string{"p.category_id = $1", "p.price >= $2", "p.stock > 0", "p.created_at < $3", "a.code = $5"} {
+		if !strings.Contains(where, cond) {
+			t.Errorf("%q missing from %s", cond, where)
+		}
+	}
+	if len(w.args) != 5 {
+		t.Fatalf("got %d args, want 5", len(w.args))
+	}
+	// The end date is inclusive
+	if got := w.args[2].(time.Time); !got.Equal(to.AddDate(0, 0, 1)) {
+		t.Errorf("created_to bound = %v", got)
+	}
+}
+
+func TestProductWhereExcludesOwnFacet(t *testing.T) {
+	category := 3
+	f := &entities.ProductFilter{
+		CategoryID: &category,
+		Attributes: []*entities.AttributeFilter{{Code: "size", Type: entities.AttributeNumber}},
+	}
+
+	if where := productWhere(f, facetCategory).sql(); strings.Contains(where, "category_id") {
+		t.Errorf("category facet filtered by category: %s", where)
+	}
+	if where := productWhere(f, facetAttribute+"size").sql(); strings.Contains(where, "product_attribute_values") {
+		t.Errorf("size facet filtered by size: %s", where)
+	}
+	if where := productWhere(&entities.ProductFilter{}, facetNone).sql(); where != "" {
+		t.Errorf("empty filter = %s", where)
+	}
+}
+
+func TestProductKeyset(t *testing.T) {
+	keys := []*entities.SortKey{{Field: "relevance", Desc: true}, {Field: "price"}}
+
+	columns := productKeyset(keys, "ts_rank(x)")
+	if len(columns) != 3 || columns[0].Expr != "ts_rank(x)" || !columns[0].Desc || columns[1].Expr != "p.price" || columns[2].Expr != "p.product_id" {
+		t.Errorf("keyset = %+v", columns)
+	}
+
+	// Relevance without a search has nothing to rank by
+	columns = productKeyset(keys, "")
+	if len(columns) != 2 || columns[0].Expr != "p.price" {
+		t.Errorf("keyset without rank = %+v", columns)
+	}
+}
@@ -3,24 +3,24 @@ package repositories
 import (
 	"context"
 	"database/sql"
-	"errors"
 	"fmt"
 	"strings"
 
 	"github.com/codepnw/react_go_ecom/internal/entities"
+	"github.com/codepnw/react_go_ecom/internal/errs"
 	"github.com/codepnw/react_go_ecom/internal/utils"
+	"github.com/codepnw/react_go_ecom/pkg/money"
 )
 
 type ProductRepository interface {
 	Create(ctx context.Context, req *entities.Product) (string, error)
-	GetByID(ctx context.Context, id string) (*entities.Product, error)
-	List(ctx context.Context, limit, offset string) ([]*entities.Product, error)
-	Update(ctx context.Context, id string, req entities.Product) error
+	GetByID(ctx context.Context, id, locale string) (*entities.Product, error)
+	List(ctx context.Context, f *entities.ProductFilter) ([]*entities.Product, error)
+	Count(ctx context.Context, f *entities.ProductFilter) (int, error)
+	Facets(ctx context.Context, f *entities.ProductFilter) (*entities.ProductFacets, error)
+	Update(ctx context.Context, id string, req entities.Product, audit entities.PriceAudit) error
 	Delete(ctx context.Context, id string) error
-	Search(ctx context.Context, text string) ([]*entities.Product, error)
-	ReduceStock(productID string, stock int) error
-	CheckStock(productID string) (int, error)
-	AddSoldQuantity(productID string, quantity int) error
+	Purchase(ctx context.Context, productID string, quantity int) error
 	CheckOutOfStock() ([]*entities.Product, error)
 	RestockProduct(req *entities.ProductStock) error
 }
@@ -35,8 +35,9 @@ func NewProductRepository(db *sql.DB) ProductRepository {
 
 func (r *productRepository) Create(ctx context.Context, req *entities.Product) (string, error) {
 	query := `
-		INSERT INTO products (title, description, price, stock, created_at)
-		VALUES ($1, $2, $3, $4, $5)
+		INSERT INTO products (title, description, price, stock, category_id, tax_class_id, weight_grams,
+			length_mm, width_mm, height_mm, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
 		RETURNING product_id
 	`
 	var id string
@@ -48,81 +49,60 @@ func (r *productRepository) Create(ctx context.Context, req *entities.Product) (
 		&req.Description,
 		&req.Price,
 		&req.Stock,
+		&req.CategoryID,
+		&req.TaxClassID,
+		&req.WeightGrams,
+		&req.LengthMM,
+		&req.WidthMM,
+		&req.HeightMM,
 		&req.CreatedAt,
 	).Scan(&id)
 	if err != nil {
-		return "", err
+		return "", dbError(err, nil)
 	}
 
 	return id, nil
 }
 
-func (r *productRepository) GetByID(ctx context.Context, id string) (*entities.Product, error) {
+// GetByID reads the product with its content in locale, an empty or
+// untranslated locale gives the default content
+func (r *productRepository) GetByID(ctx context.Context, id, locale string) (*entities.Product, error) {
 	query := `
-		SELECT product_id, title, description, price, stock, created_at, updated_at
-		FROM products WHERE product_id = $1
+		SELECT p.product_id, COALESCE(pt.title, p.title), COALESCE(pt.description, p.description),
+			p.price, p.stock, p.quantity, p.category_id, p.tax_class_id, p.weight_grams,
+			p.length_mm, p.width_mm, p.height_mm, p.created_at, p.updated_at
+		FROM products p
+		LEFT JOIN product_translations pt ON pt.product_id = p.product_id AND pt.locale = $2
+		WHERE p.product_id = $1
 	`
 	var p entities.Product
 
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
+	err := r.db.QueryRowContext(ctx, query, id, locale).Scan(
 		&p.ID,
 		&p.Title,
 		&p.Description,
 		&p.Price,
 		&p.Stock,
+		&p.Quantity,
+		&p.CategoryID,
+		&p.TaxClassID,
+		&p.WeightGrams,
+		&p.LengthMM,
+		&p.WidthMM,
+		&p.HeightMM,
 		&p.CreatedAt,
 		&p.UpdatedAt,
 	)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return nil, errors.New("product not found")
-		}
-		return nil, err
+		return nil, dbError(err, errProductNotFound)
 	}
 
 	return &p, nil
 }
 
-func (r *productRepository) List(ctx context.Context, limit, offset string) ([]*entities.Product, error) {
-	query := `
-		SELECT product_id, title, description, price, stock, quantity, created_at, updated_at
-		FROM products
-	`
-	query += fmt.Sprintf("LIMIT %s OFFSET %s", limit, offset)
-
-	var products []*entities.Product
-
-	rows, err := r.db.QueryContext(ctx, query)
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-
-	for rows.Next() {
-		var p entities.Product
-		if err := rows.Scan(
-			&p.ID,
-			&p.Title,
-			&p.Description,
-			&p.Price,
-			&p.Stock,
-			&p.Quantity,
-			&p.CreatedAt,
-			&p.UpdatedAt,
-		); err != nil {
-			return nil, err
-		}
-		products = append(products, &p)
-	}
-
-	if err := rows.Err(); err != nil {
-		return nil, err
-	}
-
-	return products, nil
-}
-
-func (r *productRepository) Update(ctx context.Context, id string, req entities.Product) error {
+// Update changes the set fields. A price change is recorded in the price
+// history in the same transaction.
+func (r *productRepository) Update(ctx context.Context, id string, req entities.Product, audit entities.PriceAudit) error {
 	var fields []string
 	var values []any
 	var lastIndex int
@@ -141,7 +121,7 @@ func (r *productRepository) Update(ctx context.Context, id string, req entities.
 		fields = append(fields, fmt.Sprintf("description = $%d", lastIndex))
 	}
 
-	if req.Price != 0 {
+	if !req.Price.IsZero() {
 		values = append(values, req.Price)
 		lastIndex = len(values)
 
@@ -155,6 +135,38 @@ func (r *productRepository) Update(ctx context.Context, id string, req entities.
 		fields = append(fields, fmt.Sprintf("stock = $%d", lastIndex))
 	}
 
+	if req.CategoryID != nil {
+		values = append(values, *req.CategoryID)
+		lastIndex = len(values)
+
+		fields = append(fields, fmt.Sprintf("category_id = $%d", lastIndex))
+	}
+
+	if req.TaxClassID != nil {
+		values = append(values, *req.TaxClassID)
+		lastIndex = len(values)
+
+		fields = append(fields, fmt.Sprintf("tax_class_id = $%d", lastIndex))
+	}
+
+	dimensions := []struct {
+		column string
+		value  int
+	}{
+		{"weight_grams", req.WeightGrams},
+		{"length_mm", req.LengthMM},
+		{"width_mm", req.WidthMM},
+		{"height_mm", req.HeightMM},
+	}
+	for _, d := range dimensions {
+		if d.value != 0 {
+			values = append(values, d.value)
+			lastIndex = len(values)
+
+			fields = append(fields, fmt.Sprintf("%s = $%d", d.column, lastIndex))
+		}
+	}
+
 	// Add Field updated_at
 	values = append(values, utils.ThaiTime)
 	lastIndex = len(values)
@@ -167,10 +179,24 @@ func (r *productRepository) Update(ctx context.Context, id string, req entities.
 
 	// log.Println(query)
 
-	result, err := r.db.ExecContext(ctx, query, values...)
+	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
 		return err
 	}
+	defer tx.Rollback()
+
+	var old money.Money
+	if !req.Price.IsZero() {
+		err := tx.QueryRowContext(ctx, `SELECT price FROM products WHERE product_id = $1 FOR UPDATE`, id).Scan(&old)
+		if err != nil {
+			return dbError(err, errProductNotFound)
+		}
+	}
+
+	result, err := tx.ExecContext(ctx, query, values...)
+	if err != nil {
+		return dbError(err, nil)
+	}
 
 	rowsAffected, err := result.RowsAffected()
 	if err != nil {
@@ -178,10 +204,24 @@ func (r *productRepository) Update(ctx context.Context, id string, req entities.
 	}
 
 	if rowsAffected == 0 {
-		return errors.New("product not found")
+		return errProductNotFound
 	}
 
-	return nil
+	if !req.Price.IsZero() {
+		change := &entities.PriceChange{
+			ProductID: id,
+			Currency:  req.Price.Currency,
+			OldPrice:  &old,
+			NewPrice:  &req.Price,
+			Actor:     &audit.Actor,
+			Reason:    audit.Reason,
+		}
+		if err := insertPriceChange(ctx, tx, change); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
 }
 
 func (r *productRepository) Delete(ctx context.Context, id string) error {
@@ -198,83 +238,36 @@ func (r *productRepository) Delete(ctx context.Context, id string) error {
 	}
 
 	if rowsAffected == 0 {
-		return errors.New("product not found")
+		return errProductNotFound
 	}
 
 	return nil
 }
 
-func (r *productRepository) Search(ctx context.Context, text string) ([]*entities.Product, error) {
-	query := `
-		SELECT product_id, title, description, price, stock, quantity, created_at, updated_at
-		FROM products WHERE title ILIKE $1 OR description ILIKE $2
-		ORDER BY created_at DESC
-	`
-	rows, err := r.db.QueryContext(ctx, query, "%"+text+"%", "%"+text+"%")
-	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-
-	var products []*entities.Product
-	for rows.Next() {
-		var p entities.Product
-		if err := rows.Scan(
-			&p.ID,
-			&p.Title,
-			&p.Description,
-			&p.Price,
-			&p.Stock,
-			&p.Quantity,
-			&p.CreatedAt,
-			&p.UpdatedAt,
-		); err != nil {
-			return nil, err
-		}
-		products = append(products, &p)
-	}
-
-	return products, nil
-}
-
-func (r *productRepository) ReduceStock(productID string, stock int) error {
+// Purchase takes the units off the stock and counts them as sold in one
+// conditional update, concurrent purchases can never take the stock below
+// zero
+func (r *productRepository) Purchase(ctx context.Context, productID string, quantity int) error {
 	query := `
-		UPDATE products SET stock = stock - $1
-		WHERE product_id = $2 AND stock >= $1
+		UPDATE products SET stock = stock - $2, quantity = quantity + $2
+		WHERE product_id = $1 AND stock >= $2
 	`
-	result, err := r.db.Exec(query, stock, productID)
-	if err != nil {
-		return err
-	}
-
-	rowsAffected, _ := result.RowsAffected()
-	if rowsAffected == 0 {
-		return errors.New("not enough stock")
-	}
-
-	return nil
-}
-
-func (r *productRepository) CheckStock(productID string) (int, error) {
-	var stock int
-
-	err := r.db.QueryRow("SELECT stock FROM products WHERE product_id = $1", productID).Scan(&stock)
+	result, err := r.db.ExecContext(ctx, query, productID, quantity)
 	if err != nil {
-		return 0, err
+		return dbError(err, nil)
 	}
 
-	return stock, nil
-}
-
-func (r *productRepository) AddSoldQuantity(productID string, quantity int) error {
-	result, err := r.db.Exec("UPDATE products SET quantity = $1 WHERE product_id = $2", quantity, productID)
+	rowsAffected, err := result.RowsAffected()
 	if err != nil {
 		return err
 	}
 
-	rowsAffected, _ := result.RowsAffected()
 	if rowsAffected == 0 {
-		return errors.New("update sold quantity fail")
+		var stock int
+		if err := r.db.QueryRowContext(ctx, `SELECT stock FROM products WHERE product_id = $1`, productID).Scan(&stock); err != nil {
+			return dbError(err, errProductNotFound)
+		}
+		return errs.InsufficientStock("insufficient_stock", "not enough stock")
 	}
 
 	return nil
@@ -282,7 +275,7 @@ func (r *productRepository) AddSoldQuantity(productID string, quantity int) erro
 
 func (r *productRepository) CheckOutOfStock() ([]*entities.Product, error) {
 	query := `
-		SELECT product_id, title, description, price, stock, quantity, created_at, updated_at
+		SELECT product_id, title, description, price, stock, quantity, category_id, created_at, updated_at
 		FROM products WHERE stock = 0	
 	`
 	rows, err := r.db.Query(query)
@@ -303,6 +296,7 @@ func (r *productRepository) CheckOutOfStock() ([]*entities.Product, error) {
 			&p.Price,
 			&p.Stock,
 			&p.Quantity,
+			&p.CategoryID,
 			&p.CreatedAt,
 			&p.UpdatedAt,
 		); err != nil {
@@ -315,8 +309,26 @@ func (r *productRepository) CheckOutOfStock() ([]*entities.Product, error) {
 }
 
 func (r *productRepository) RestockProduct(req *entities.ProductStock) error {
+	return restockProduct(context.Background(), r.db, req.ProductID, req.Quantity)
+}
+
+// restockProduct puts quantity units back into stock, on its own or inside
+// the transaction that took them back
+func restockProduct(ctx context.Context, q querier, productID string, quantity int) error {
 	query := `UPDATE products SET stock = stock + $1 WHERE product_id = $2`
-	_, err := r.db.Exec(query, req.Quantity, req.ProductID)
+	result, err := q.ExecContext(ctx, query, quantity, productID)
+	if err != nil {
+		return dbError(err, nil)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
 
-	return err
-}
\ No newline at end of file
+	if rowsAffected == 0 {
+		return errProductNotFound
+	}
+
+	return nil
+}
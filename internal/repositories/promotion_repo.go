@@ -0,0 +1,283 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/codepnw/react_go_ecom/internal/entities"
+	"github.com/lib/pq"
+)
+
+type PromotionRepository interface {
+	Create(ctx context.Context, p *entities.Promotion) error
+	GetByID(ctx context.Context, id int) (*entities.Promotion, error)
+	List(ctx context.Context) ([]*entities.Promotion, error)
+	Update(ctx context.Context, p *entities.Promotion) error
+	Delete(ctx context.Context, id int) error
+	ListApplicable(ctx context.Context, codes []string, at time.Time) ([]*entities.Promotion, error)
+	UserUsage(ctx context.Context, userID string, ids []int) (map[int]int, error)
+}
+
+type promotionRepository struct {
+	db *sql.DB
+}
+
+func NewPromotionRepository(db *sql.DB) PromotionRepository {
+	return &promotionRepository{db: db}
+}
+
+const promotionColumns = `
+	id, name, code, kind, percent::TEXT, amount::TEXT, buy_quantity, get_quantity, currency,
+	product_ids, category_ids, min_spend::TEXT, starts_at, ends_at, usage_limit, per_user_limit,
+	used_count, stackable, priority, active, created_at, updated_at
+`
+
+func (r *promotionRepository) Create(ctx context.Context, p *entities.Promotion) error {
+	query := `
+		INSERT INTO promotions (name, code, kind, percent, amount, buy_quantity, get_quantity, currency,
+			product_ids, category_ids, min_spend, starts_at, ends_at, usage_limit, per_user_limit,
+			stackable, priority, active)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18)
+		RETURNING id, created_at
+	`
+	err := r.db.QueryRowContext(ctx, query, promotionArgs(p)...).Scan(&p.ID, &p.CreatedAt)
+
+	return dbError(err, nil)
+}
+
+func (r *promotionRepository) GetByID(ctx context.Context, id int) (*entities.Promotion, error) {
+	query := `SELECT ` + promotionColumns + ` FROM promotions WHERE id = $1`
+
+	p, err := scanPromotion(r.db.QueryRowContext(ctx, query, id).Scan)
+	if err != nil {
+		return nil, dbError(err, errPromoNotFound)
+	}
+
+	return p, nil
+}
+
+func (r *promotionRepository) List(ctx context.Context) ([]*entities.Promotion, error) {
+	query := `SELECT ` + promotionColumns + ` FROM promotions ORDER BY active DESC, priority DESC, id DESC`
+
+	return r.query(ctx, query)
+}
+
+// Update replaces the rule, the usage count is kept
+func (r *promotionRepository) Update(ctx context.Context, p *entities.Promotion) error {
+	query := `
+		UPDATE promotions SET name = $1, code = $2, kind = $3, percent = $4, amount = $5,
+			buy_quantity = $6, get_quantity = $7, currency = $8, product_ids = $9, category_ids = $10,
+			min_spend = $11, starts_at = $12, ends_at = $13, usage_limit = $14, per_user_limit = $15,
+			stackable = $16, priority = $17, active = $18, updated_at = now()
+		WHERE id = $19
+		RETURNING used_count, created_at, updated_at
+	`
+	args := append(promotionArgs(p), p.ID)
+
+	err := r.db.QueryRowContext(ctx, query, args...).Scan(&p.UsedCount, &p.CreatedAt, &p.UpdatedAt)
+
+	return dbError(err, errPromoNotFound)
+}
+
+func (r *promotionRepository) Delete(ctx context.Context, id int) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM promotions WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return errPromoNotFound
+	}
+
+	return nil
+}
+
+// ListApplicable reads the automatic promotions running at the given time
+// and the coupons of the codes whatever their state, so the caller can say
+// why a coupon does not apply
+func (r *promotionRepository) ListApplicable(ctx context.Context, codes []string, at time.Time) ([]*entities.Promotion, error) {
+	query := `
+		SELECT ` + promotionColumns + ` FROM promotions
+		WHERE (code IS NULL AND active AND starts_at <= $2 AND (ends_at IS NULL OR ends_at > $2))
+			OR code = ANY($1)
+	`
+	return r.query(ctx, query, pq.Array(codes), at)
+}
+
+// UserUsage counts the orders of the user per promotion
+func (r *promotionRepository) UserUsage(ctx context.Context, userID string, ids []int) (map[int]int, error) {
+	query := `
+		SELECT promotion_id, COUNT(*) FROM promotion_redemptions
+		WHERE user_id = $1 AND promotion_id = ANY($2)
+		GROUP BY promotion_id
+	`
+	rows, err := r.db.QueryContext(ctx, query, userID, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	usage := make(map[int]int)
+	for rows.Next() {
+		var id, count int
+		if err := rows.Scan(&id, &count); err != nil {
+			return nil, err
+		}
+		usage[id] = count
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return usage, nil
+}
+
+func (r *promotionRepository) query(ctx context.Context, query string, args ...any) ([]*entities.Promotion, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	promotions := []*entities.Promotion{}
+	for rows.Next() {
+		p, err := scanPromotion(rows.Scan)
+		if err != nil {
+			return nil, err
+		}
+		promotions = append(promotions, p)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return promotions, nil
+}
+
+func promotionArgs(p *entities.Promotion) []any {
+	return []any{
+		p.Name,
+		p.Code,
+		p.Kind,
+		p.Percent,
+		p.Amount,
+		p.BuyQuantity,
+		p.GetQuantity,
+		p.Currency,
+		pq.Array(p.ProductIDs),
+		pq.Array(p.CategoryIDs),
+		p.MinSpend,
+		p.StartsAt,
+		p.EndsAt,
+		p.UsageLimit,
+		p.PerUserLimit,
+		p.Stackable,
+		p.Priority,
+		p.Active,
+	}
+}
+
+func scanPromotion(scan func(dest ...any) error) (*entities.Promotion, error) {
+	var p entities.Promotion
+	var amount, minSpend sql.NullString
+
+	err := scan(
+		&p.ID,
+		&p.Name,
+		&p.Code,
+		&p.Kind,
+		&p.Percent,
+		&amount,
+		&p.BuyQuantity,
+		&p.GetQuantity,
+		&p.Currency,
+		pq.Array(&p.ProductIDs),
+		pq.Array(&p.CategoryIDs),
+		&minSpend,
+		&p.StartsAt,
+		&p.EndsAt,
+		&p.UsageLimit,
+		&p.PerUserLimit,
+		&p.UsedCount,
+		&p.Stackable,
+		&p.Priority,
+		&p.Active,
+		&p.CreatedAt,
+		&p.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if p.Amount, err = nullMoney(amount, p.Currency); err != nil {
+		return nil, err
+	}
+	if p.MinSpend, err = nullMoney(minSpend, p.Currency); err != nil {
+		return nil, err
+	}
+
+	return &p, nil
+}
+
+// redeemPromotions counts the order against the usage limits of its
+// promotions. The promotion rows are locked so concurrent checkouts cannot
+// both take the last use.
+func redeemPromotions(ctx context.Context, tx *sql.Tx, order *entities.Order) error {
+	seen := make(map[int]bool)
+
+	for _, d := range order.Discounts {
+		if seen[d.PromotionID] {
+			continue
+		}
+		seen[d.PromotionID] = true
+
+		var usageLimit, perUserLimit sql.NullInt64
+		var used int
+
+		err := tx.QueryRowContext(ctx, `
+			SELECT usage_limit, per_user_limit, used_count FROM promotions WHERE id = $1 FOR UPDATE
+		`, d.PromotionID).Scan(&usageLimit, &perUserLimit, &used)
+		if err != nil {
+			return dbError(err, errPromotionUnavailable(d.Name))
+		}
+
+		if usageLimit.Valid && int64(used) >= usageLimit.Int64 {
+			return errPromotionUnavailable(d.Name)
+		}
+
+		if perUserLimit.Valid {
+			var count int64
+			err := tx.QueryRowContext(ctx, `
+				SELECT COUNT(*) FROM promotion_redemptions WHERE promotion_id = $1 AND user_id = $2
+			`, d.PromotionID, order.UserID).Scan(&count)
+			if err != nil {
+				return err
+			}
+
+			if count >= perUserLimit.Int64 {
+				return errPromotionUnavailable(d.Name)
+			}
+		}
+
+		if _, err := tx.ExecContext(ctx, `UPDATE promotions SET used_count = used_count + 1 WHERE id = $1`, d.PromotionID); err != nil {
+			return err
+		}
+
+		_, err = tx.ExecContext(ctx, `
+			INSERT INTO promotion_redemptions (promotion_id, order_id, user_id) VALUES ($1, $2, $3)
+		`, d.PromotionID, order.ID, order.UserID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,584 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/codepnw/react_go_ecom/internal/entities"
+	"github.com/codepnw/react_go_ecom/internal/errs"
+	"github.com/codepnw/react_go_ecom/pkg/money"
+	"github.com/lib/pq"
+)
+
+type ReturnRepository interface {
+	Create(ctx context.Context, ret *entities.Return) error
+	GetByID(ctx context.Context, id int64) (*entities.Return, error)
+	List(ctx context.Context, f *entities.ReturnFilter) ([]*entities.Return, error)
+	ListByOrder(ctx context.Context, orderID int64) ([]*entities.Return, error)
+	AddPhoto(ctx context.Context, returnID int64, photo *entities.ReturnPhoto) error
+	Decide(ctx context.Context, ret *entities.Return, status, note string) error
+	Receive(ctx context.Context, ret *entities.Return, restock map[int64]bool, note string) error
+	CreateRefund(ctx context.Context, refund *entities.Refund) error
+	CompleteRefund(ctx context.Context, refund *entities.Refund) error
+	FailRefund(ctx context.Context, refund *entities.Refund) error
+}
+
+type returnRepository struct {
+	db *sql.DB
+}
+
+func NewReturnRepository(db *sql.DB) ReturnRepository {
+	return &returnRepository{db: db}
+}
+
+const returnColumns = `
+	r.id, r.order_id, COALESCE(o.user_id, ''), r.status, r.reason, r.comment, r.admin_note, r.created_at, r.updated_at
+`
+
+// Create stores a return of units that were not returned before. The
+// order row is locked so two requests cannot return the same units.
+func (r *returnRepository) Create(ctx context.Context, ret *entities.Return) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var status string
+	err = tx.QueryRowContext(ctx, `SELECT status FROM orders WHERE id = $1 FOR UPDATE`, ret.OrderID).Scan(&status)
+	if err != nil {
+		return dbError(err, errOrderNotFound)
+	}
+
+	switch status {
+	case entities.OrderShipped, entities.OrderDelivered, entities.OrderCompleted, entities.OrderPartiallyRefunded:
+	default:
+		return errs.Conflict("order_not_returnable", "order is %s and cannot be returned", status)
+	}
+
+	lineQuery := `
+		SELECT oi.product_id, oi.title, oi.quantity,
+			(SELECT COALESCE(SUM(ri.quantity), 0) FROM return_items ri
+				JOIN returns rt ON rt.id = ri.return_id
+				WHERE ri.order_item_id = oi.id AND rt.status <> $3)
+		FROM order_items oi
+		WHERE oi.id = $1 AND oi.order_id = $2
+	`
+	for _, item := range ret.Items {
+		var ordered, returned int
+
+		err := tx.QueryRowContext(ctx, lineQuery, item.OrderItemID, ret.OrderID, entities.ReturnRejected).
+			Scan(&item.ProductID, &item.Title, &ordered, &returned)
+		if err != nil {
+			return dbError(err, errs.NotFound("order_item_not_found", "order item %d is not in the order", item.OrderItemID))
+		}
+
+		if left := ordered - returned; item.Quantity > left {
+			return errs.Conflict("return_over_quantity", "only %d of %s can be returned", left, item.Title)
+		}
+	}
+
+	query := `
+		INSERT INTO returns (order_id, reason, comment)
+		VALUES ($1, $2, $3)
+		RETURNING id, status, created_at
+	`
+	err = tx.QueryRowContext(ctx, query, ret.OrderID, ret.Reason, ret.Comment).Scan(&ret.ID, &ret.Status, &ret.CreatedAt)
+	if err != nil {
+		return dbError(err, nil)
+	}
+
+	itemQuery := `INSERT INTO return_items (return_id, order_item_id, quantity) VALUES ($1, $2, $3)`
+	for _, item := range ret.Items {
+		if _, err := tx.ExecContext(ctx, itemQuery, ret.ID, item.OrderItemID, item.Quantity); err != nil {
+			return dbError(err, nil)
+		}
+	}
+
+	if err := addOrderEvent(ctx, tx, ret.OrderID, entities.OrderEventReturnRequested, fmt.Sprintf("return #%d: %s", ret.ID, ret.Reason)); err != nil {
+		return err
+	}
+
+	ret.Photos = []*entities.ReturnPhoto{}
+	ret.Refunds = []*entities.Refund{}
+
+	return tx.Commit()
+}
+
+func (r *returnRepository) GetByID(ctx context.Context, id int64) (*entities.Return, error) {
+	query := `SELECT ` + returnColumns + ` FROM returns r JOIN orders o ON o.id = r.order_id WHERE r.id = $1`
+
+	ret, err := scanReturn(r.db.QueryRowContext(ctx, query, id).Scan)
+	if err != nil {
+		return nil, dbError(err, errReturnNotFound)
+	}
+
+	if err := r.loadReturn(ctx, ret); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+// List reads the returns newest first, without their lines
+func (r *returnRepository) List(ctx context.Context, f *entities.ReturnFilter) ([]*entities.Return, error) {
+	var where []string
+	var args []any
+
+	if f.Status != "" {
+		args = append(args, f.Status)
+		where = append(where, fmt.Sprintf("r.status = $%d", len(args)))
+	}
+	if f.UserID != "" {
+		args = append(args, f.UserID)
+		where = append(where, fmt.Sprintf("o.user_id = $%d", len(args)))
+	}
+
+	query := `SELECT ` + returnColumns + ` FROM returns r JOIN orders o ON o.id = r.order_id`
+	if len(where) > 0 {
+		query += ` WHERE ` + strings.Join(where, " AND ")
+	}
+	query += ` ORDER BY r.id DESC`
+
+	return r.queryReturns(ctx, query, args...)
+}
+
+func (r *returnRepository) ListByOrder(ctx context.Context, orderID int64) ([]*entities.Return, error) {
+	query := `SELECT ` + returnColumns + ` FROM returns r JOIN orders o ON o.id = r.order_id WHERE r.order_id = $1 ORDER BY r.id`
+
+	returns, err := r.queryReturns(ctx, query, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, ret := range returns {
+		if err := r.loadReturn(ctx, ret); err != nil {
+			return nil, err
+		}
+	}
+
+	return returns, nil
+}
+
+func (r *returnRepository) AddPhoto(ctx context.Context, returnID int64, photo *entities.ReturnPhoto) error {
+	query := `
+		INSERT INTO return_photos (return_id, key, content_type)
+		VALUES ($1, $2, $3)
+		RETURNING id, created_at
+	`
+	err := r.db.QueryRowContext(ctx, query, returnID, photo.Key, photo.ContentType).Scan(&photo.ID, &photo.CreatedAt)
+	if err != nil {
+		return dbError(err, nil)
+	}
+
+	return nil
+}
+
+// Decide approves or rejects a return. A requested return can be either,
+// a received return that failed inspection can still be rejected.
+func (r *returnRepository) Decide(ctx context.Context, ret *entities.Return, status, note string) error {
+	from := []string{entities.ReturnRequested}
+	event := entities.OrderEventReturnApproved
+	if status == entities.ReturnRejected {
+		from = append(from, entities.ReturnReceived)
+		event = entities.OrderEventReturnRejected
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := moveReturn(ctx, tx, ret, from, status, note); err != nil {
+		return err
+	}
+
+	if err := addOrderEvent(ctx, tx, ret.OrderID, event, returnNote(ret.ID, note)); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// Receive stores the inspection of an approved return, the accepted
+// quantities are read from ret.Items. The accepted units of the lines
+// marked in restock go back into stock in the same transaction, a product
+// deleted since the order is not restocked.
+func (r *returnRepository) Receive(ctx context.Context, ret *entities.Return, restock map[int64]bool, note string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := moveReturn(ctx, tx, ret, []string{entities.ReturnApproved}, entities.ReturnReceived, note); err != nil {
+		return err
+	}
+
+	query := `UPDATE return_items SET accepted_quantity = $3 WHERE return_id = $1 AND order_item_id = $2`
+	for _, item := range ret.Items {
+		if _, err := tx.ExecContext(ctx, query, ret.ID, item.OrderItemID, item.AcceptedQuantity); err != nil {
+			return dbError(err, nil)
+		}
+	}
+
+	for _, item := range ret.Items {
+		if !restock[item.OrderItemID] || *item.AcceptedQuantity == 0 || item.ProductID == nil {
+			continue
+		}
+
+		// A product deleted since the order is not restocked
+		err := restockProduct(ctx, tx, *item.ProductID, *item.AcceptedQuantity)
+		if errors.Is(err, errProductNotFound) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		query := `UPDATE return_items SET restocked_quantity = restocked_quantity + $3 WHERE return_id = $1 AND order_item_id = $2`
+		if _, err := tx.ExecContext(ctx, query, ret.ID, item.OrderItemID, *item.AcceptedQuantity); err != nil {
+			return dbError(err, nil)
+		}
+		item.RestockedQuantity += *item.AcceptedQuantity
+	}
+
+	if err := addOrderEvent(ctx, tx, ret.OrderID, entities.OrderEventReturnReceived, returnNote(ret.ID, note)); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// CreateRefund stores a pending refund. Pending and succeeded refunds
+// together never exceed the order total, nor those through the payment
+// provider what the gateway was paid. The order row is locked while they
+// are added up.
+func (r *returnRepository) CreateRefund(ctx context.Context, refund *entities.Refund) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var total, gatewayTotal string
+	query := `SELECT total::TEXT, gateway_total::TEXT FROM orders WHERE id = $1 FOR UPDATE`
+	err = tx.QueryRowContext(ctx, query, refund.OrderID).Scan(&total, &gatewayTotal)
+	if err != nil {
+		return dbError(err, errOrderNotFound)
+	}
+
+	if refund.ReturnID != nil {
+		var status string
+		err := tx.QueryRowContext(ctx, `SELECT status FROM returns WHERE id = $1 FOR UPDATE`, *refund.ReturnID).Scan(&status)
+		if err != nil {
+			return dbError(err, errReturnNotFound)
+		}
+		if status != entities.ReturnReceived {
+			return errs.Conflict("return_not_refundable", "return is %s and cannot be refunded", status)
+		}
+	}
+
+	var refunded, refundedGateway string
+	query = `
+		SELECT COALESCE(SUM(amount), 0)::TEXT, COALESCE(SUM(amount) FILTER (WHERE provider <> $3), 0)::TEXT
+		FROM refunds WHERE order_id = $1 AND status <> $2
+	`
+	err = tx.QueryRowContext(ctx, query, refund.OrderID, entities.RefundFailed, entities.PaymentStoreCredit).
+		Scan(&refunded, &refundedGateway)
+	if err != nil {
+		return err
+	}
+
+	paid, err := money.Parse(total, refund.Amount.Currency)
+	if err != nil {
+		return err
+	}
+	spent, err := money.Parse(refunded, refund.Amount.Currency)
+	if err != nil {
+		return err
+	}
+
+	if left := paid.Sub(spent); refund.Amount.Cmp(left) > 0 {
+		return errs.Conflict("refund_exceeds_paid", "at most %s can still be refunded", left)
+	}
+
+	if refund.Provider != entities.PaymentStoreCredit {
+		charged, err := money.Parse(gatewayTotal, refund.Amount.Currency)
+		if err != nil {
+			return err
+		}
+		returned, err := money.Parse(refundedGateway, refund.Amount.Currency)
+		if err != nil {
+			return err
+		}
+
+		if left := charged.Sub(returned); refund.Amount.Cmp(left) > 0 {
+			return errs.Conflict("refund_exceeds_gateway", "at most %s can be refunded through the payment provider, the rest as store credit", left)
+		}
+	}
+
+	query = `
+		INSERT INTO refunds (order_id, return_id, amount, currency, provider, note)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, status, created_at
+	`
+	err = tx.QueryRowContext(ctx, query, refund.OrderID, refund.ReturnID, refund.Amount, refund.Amount.Currency, refund.Provider, refund.Note).
+		Scan(&refund.ID, &refund.Status, &refund.CreatedAt)
+	if err != nil {
+		return dbError(err, nil)
+	}
+
+	return tx.Commit()
+}
+
+// CompleteRefund books a refund the provider accepted on the order, which
+// is refunded once the refunds reach its total, and closes the return. A
+// refund as store credit is credited to the customer here.
+func (r *returnRepository) CompleteRefund(ctx context.Context, refund *entities.Refund) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if refund.Provider == entities.PaymentStoreCredit {
+		if err := creditRefund(ctx, tx, refund); err != nil {
+			return err
+		}
+	}
+
+	query := `
+		UPDATE refunds SET status = $2, provider_ref = $3, updated_at = now()
+		WHERE id = $1 AND status = $4
+		RETURNING status, updated_at
+	`
+	err = tx.QueryRowContext(ctx, query, refund.ID, entities.RefundSucceeded, refund.ProviderRef, entities.RefundPending).
+		Scan(&refund.Status, &refund.UpdatedAt)
+	if err != nil {
+		return dbError(err, errRefundNotFound)
+	}
+
+	query = `
+		UPDATE orders SET refunded_total = refunded_total + $2, updated_at = now(),
+			status = CASE WHEN refunded_total + $2 >= total THEN $3 ELSE $4 END::order_status
+		WHERE id = $1
+	`
+	_, err = tx.ExecContext(ctx, query, refund.OrderID, refund.Amount, entities.OrderRefunded, entities.OrderPartiallyRefunded)
+	if err != nil {
+		return err
+	}
+
+	if refund.ReturnID != nil {
+		query := `UPDATE returns SET status = $2, updated_at = now() WHERE id = $1`
+		if _, err := tx.ExecContext(ctx, query, *refund.ReturnID, entities.ReturnRefunded); err != nil {
+			return err
+		}
+	}
+
+	note := fmt.Sprintf("refund #%d of %s", refund.ID, refund.Amount)
+	if refund.ReturnID != nil {
+		note += fmt.Sprintf(" for return #%d", *refund.ReturnID)
+	}
+	if err := addOrderEvent(ctx, tx, refund.OrderID, entities.OrderEventRefunded, note); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// creditRefund books a refund on the store credit of the order's customer,
+// the ledger entry is the provider reference
+func creditRefund(ctx context.Context, tx *sql.Tx, refund *entities.Refund) error {
+	var userID sql.NullString
+	if err := tx.QueryRowContext(ctx, `SELECT user_id FROM orders WHERE id = $1`, refund.OrderID).Scan(&userID); err != nil {
+		return dbError(err, errOrderNotFound)
+	}
+
+	if !userID.Valid {
+		return errs.Conflict("store_credit_no_customer", "the customer of the order no longer exists")
+	}
+
+	entry := &entities.StoreCreditEntry{
+		UserID:   userID.String,
+		Kind:     entities.CreditRefund,
+		Amount:   refund.Amount,
+		OrderID:  &refund.OrderID,
+		RefundID: &refund.ID,
+		Note:     refund.Note,
+	}
+	if err := addCreditEntry(ctx, tx, entry); err != nil {
+		return err
+	}
+
+	ref := fmt.Sprintf("credit_%d", entry.ID)
+	refund.ProviderRef = &ref
+
+	return nil
+}
+
+// FailRefund releases a refund the provider did not take
+func (r *returnRepository) FailRefund(ctx context.Context, refund *entities.Refund) error {
+	query := `UPDATE refunds SET status = $2, updated_at = now() WHERE id = $1 AND status = $3`
+	_, err := r.db.ExecContext(ctx, query, refund.ID, entities.RefundFailed, entities.RefundPending)
+	if err == nil {
+		refund.Status = entities.RefundFailed
+	}
+	return err
+}
+
+func (r *returnRepository) queryReturns(ctx context.Context, query string, args ...any) ([]*entities.Return, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	returns := []*entities.Return{}
+	for rows.Next() {
+		ret, err := scanReturn(rows.Scan)
+		if err != nil {
+			return nil, err
+		}
+		returns = append(returns, ret)
+	}
+
+	return returns, rows.Err()
+}
+
+func (r *returnRepository) loadReturn(ctx context.Context, ret *entities.Return) error {
+	itemQuery := `
+		SELECT ri.order_item_id, oi.product_id, oi.title, ri.quantity, ri.accepted_quantity, ri.restocked_quantity
+		FROM return_items ri
+		JOIN order_items oi ON oi.id = ri.order_item_id
+		WHERE ri.return_id = $1
+		ORDER BY ri.order_item_id
+	`
+	rows, err := r.db.QueryContext(ctx, itemQuery, ret.ID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var item entities.ReturnItem
+		err := rows.Scan(&item.OrderItemID, &item.ProductID, &item.Title, &item.Quantity, &item.AcceptedQuantity, &item.RestockedQuantity)
+		if err != nil {
+			return err
+		}
+		ret.Items = append(ret.Items, &item)
+	}
+
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	photoQuery := `SELECT id, key, content_type, created_at FROM return_photos WHERE return_id = $1 ORDER BY id`
+	photos, err := r.db.QueryContext(ctx, photoQuery, ret.ID)
+	if err != nil {
+		return err
+	}
+	defer photos.Close()
+
+	for photos.Next() {
+		var p entities.ReturnPhoto
+		if err := photos.Scan(&p.ID, &p.Key, &p.ContentType, &p.CreatedAt); err != nil {
+			return err
+		}
+		ret.Photos = append(ret.Photos, &p)
+	}
+
+	if err := photos.Err(); err != nil {
+		return err
+	}
+
+	refundQuery := `
+		SELECT id, order_id, return_id, amount::TEXT, currency, provider, provider_ref, status, note, created_at, updated_at
+		FROM refunds WHERE return_id = $1
+		ORDER BY id
+	`
+	refunds, err := r.db.QueryContext(ctx, refundQuery, ret.ID)
+	if err != nil {
+		return err
+	}
+	defer refunds.Close()
+
+	for refunds.Next() {
+		var rf entities.Refund
+		var amount, currency string
+
+		err := refunds.Scan(&rf.ID, &rf.OrderID, &rf.ReturnID, &amount, &currency, &rf.Provider, &rf.ProviderRef,
+			&rf.Status, &rf.Note, &rf.CreatedAt, &rf.UpdatedAt)
+		if err != nil {
+			return err
+		}
+
+		if rf.Amount, err = money.Parse(amount, currency); err != nil {
+			return err
+		}
+		ret.Refunds = append(ret.Refunds, &rf)
+	}
+
+	return refunds.Err()
+}
+
+// moveReturn changes the status of a return that is in one of from, the
+// note replaces the admin note when given
+func moveReturn(ctx context.Context, tx *sql.Tx, ret *entities.Return, from []string, to, note string) error {
+	query := `
+		UPDATE returns SET status = $2, admin_note = CASE WHEN $3 = '' THEN admin_note ELSE $3 END, updated_at = now()
+		WHERE id = $1 AND status = ANY($4)
+		RETURNING status, admin_note, updated_at
+	`
+	err := tx.QueryRowContext(ctx, query, ret.ID, to, note, pq.Array(from)).Scan(&ret.Status, &ret.AdminNote, &ret.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return errs.Conflict("return_status", "return is %s and cannot be %s", ret.Status, to)
+	}
+
+	return dbError(err, nil)
+}
+
+func addOrderEvent(ctx context.Context, tx *sql.Tx, orderID int64, event, note string) error {
+	query := `
+		INSERT INTO order_history (order_id, event, status, note)
+		SELECT id, $2, status::TEXT, $3 FROM orders WHERE id = $1
+	`
+	_, err := tx.ExecContext(ctx, query, orderID, event, note)
+	return err
+}
+
+func returnNote(id int64, note string) string {
+	if note == "" {
+		return fmt.Sprintf("return #%d", id)
+	}
+	return fmt.Sprintf("return #%d: %s", id, note)
+}
+
+func scanReturn(scan func(dest ...any) error) (*entities.Return, error) {
+	ret := entities.Return{
+		Items:   []*entities.ReturnItem{},
+		Photos:  []*entities.ReturnPhoto{},
+		Refunds: []*entities.Refund{},
+	}
+
+	err := scan(
+		&ret.ID,
+		&ret.OrderID,
+		&ret.UserID,
+		&ret.Status,
+		&ret.Reason,
+		&ret.Comment,
+		&ret.AdminNote,
+		&ret.CreatedAt,
+		&ret.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ret, nil
+}
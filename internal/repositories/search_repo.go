@@ -0,0 +1,248 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"math"
+	"time"
+
+	"github.com/codepnw/react_go_ecom/internal/entities"
+	"github.com/codepnw/react_go_ecom/internal/errs"
+)
+
+type SearchRepository interface {
+	ListProductTitles(ctx context.Context) ([]*entities.SuggestItem, error)
+	ListCategoryTitles(ctx context.Context) ([]*entities.SuggestItem, error)
+	ListPopularQueries(ctx context.Context, since time.Time, limit int) ([]*entities.SuggestItem, error)
+	CreateQuery(ctx context.Context, q *entities.SearchQuery) error
+	RecordClick(ctx context.Context, searchID int64, productID string) error
+	TopQueries(ctx context.Context, from, to time.Time, limit int) ([]*entities.SearchQueryStat, error)
+	ZeroResultQueries(ctx context.Context, from, to time.Time, limit int) ([]*entities.ZeroResultQuery, error)
+	ClickThrough(ctx context.Context, from, to time.Time) (*entities.ClickThroughReport, error)
+}
+
+type searchRepository struct {
+	db *sql.DB
+}
+
+func NewSearchRepository(db *sql.DB) SearchRepository {
+	return &searchRepository{db: db}
+}
+
+// ListProductTitles is weighted by sold quantity so best sellers come first
+func (r *searchRepository) ListProductTitles(ctx context.Context) ([]*entities.SuggestItem, error) {
+	return r.listItems(ctx, `SELECT product_id, title, quantity FROM products`)
+}
+
+// ListCategoryTitles is weighted by the number of products in the category
+func (r *searchRepository) ListCategoryTitles(ctx context.Context) ([]*entities.SuggestItem, error) {
+	query := `
+		SELECT c.id::TEXT, c.title, COUNT(p.product_id)
+		FROM categories c
+		LEFT JOIN products p ON p.category_id = c.id
+		GROUP BY c.id, c.title
+	`
+	return r.listItems(ctx, query)
+}
+
+// ListPopularQueries seeds the suggest index with searches that found
+// something, the query itself is the ID
+func (r *searchRepository) ListPopularQueries(ctx context.Context, since time.Time, limit int) ([]*entities.SuggestItem, error) {
+	query := `
+		SELECT query, query, COUNT(*)
+		FROM search_queries
+		WHERE created_at >= $1 AND result_count > 0
+		GROUP BY query
+		ORDER BY COUNT(*) DESC
+		LIMIT $2
+	`
+	return r.listItems(ctx, query, since, limit)
+}
+
+func (r *searchRepository) CreateQuery(ctx context.Context, q *entities.SearchQuery) error {
+	query := `
+		INSERT INTO search_queries (query, raw_query, result_count, match_mode, user_id, session_id)
+		VALUES ($1, $2, $3, $4, NULLIF($5, ''), NULLIF($6, ''))
+		RETURNING id, created_at
+	`
+
+	return r.db.QueryRowContext(
+		ctx,
+		query,
+		q.Query,
+		q.RawQuery,
+		q.ResultCount,
+		q.MatchMode,
+		q.UserID,
+		q.SessionID,
+	).Scan(&q.ID, &q.CreatedAt)
+}
+
+// RecordClick keeps the first click of a search, later clicks on the same
+// result page do not count again
+func (r *searchRepository) RecordClick(ctx context.Context, searchID int64, productID string) error {
+	var exists bool
+	if err := r.db.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM search_queries WHERE id = $1)`, searchID).Scan(&exists); err != nil {
+		return err
+	}
+
+	if !exists {
+		return errs.NotFound("search_not_found", "search not found")
+	}
+
+	query := `
+		UPDATE search_queries SET clicked_product_id = $2, clicked_at = now()
+		WHERE id = $1 AND clicked_product_id IS NULL
+	`
+
+	_, err := r.db.ExecContext(ctx, query, searchID, productID)
+	return dbError(err, nil)
+}
+
+func (r *searchRepository) TopQueries(ctx context.Context, from, to time.Time, limit int) ([]*entities.SearchQueryStat, error) {
+	query := `
+		SELECT query,
+			COUNT(*),
+			ROUND(AVG(result_count), 2),
+			COUNT(*) FILTER (WHERE result_count = 0),
+			COUNT(clicked_at),
+			COALESCE(ROUND(COUNT(clicked_at)::NUMERIC / NULLIF(COUNT(*) FILTER (WHERE result_count > 0), 0), 4), 0)
+		FROM search_queries
+		WHERE created_at >= $1 AND created_at < $2
+		GROUP BY query
+		ORDER BY COUNT(*) DESC, query
+		LIMIT $3
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, from, to, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	stats := []*entities.SearchQueryStat{}
+	for rows.Next() {
+		var st entities.SearchQueryStat
+		if err := rows.Scan(&st.Query, &st.Searches, &st.AvgResults, &st.ZeroResults, &st.Clicks, &st.CTR); err != nil {
+			return nil, err
+		}
+		stats = append(stats, &st)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return stats, nil
+}
+
+func (r *searchRepository) ZeroResultQueries(ctx context.Context, from, to time.Time, limit int) ([]*entities.ZeroResultQuery, error) {
+	query := `
+		SELECT query, COUNT(*), MAX(created_at)
+		FROM search_queries
+		WHERE created_at >= $1 AND created_at < $2 AND result_count = 0
+		GROUP BY query
+		ORDER BY COUNT(*) DESC, query
+		LIMIT $3
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, from, to, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	queries := []*entities.ZeroResultQuery{}
+	for rows.Next() {
+		var zq entities.ZeroResultQuery
+		if err := rows.Scan(&zq.Query, &zq.Searches, &zq.LastSearchedAt); err != nil {
+			return nil, err
+		}
+		queries = append(queries, &zq)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return queries, nil
+}
+
+// ClickThrough reports the share of searches with a clicked result, per day
+// and for the whole range. Zero result searches are left out of the rate
+// since there was nothing to click.
+func (r *searchRepository) ClickThrough(ctx context.Context, from, to time.Time) (*entities.ClickThroughReport, error) {
+	query := `
+		SELECT created_at::DATE,
+			COUNT(*) FILTER (WHERE result_count > 0),
+			COUNT(clicked_at),
+			COUNT(*) FILTER (WHERE result_count = 0)
+		FROM search_queries
+		WHERE created_at >= $1 AND created_at < $2
+		GROUP BY created_at::DATE
+		ORDER BY created_at::DATE
+	`
+
+	rows, err := r.db.QueryContext(ctx, query, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	report := &entities.ClickThroughReport{Days: []*entities.ClickThroughDay{}}
+	for rows.Next() {
+		var day entities.ClickThroughDay
+		var date time.Time
+		var zero int
+
+		if err := rows.Scan(&date, &day.Searches, &day.Clicks, &zero); err != nil {
+			return nil, err
+		}
+
+		day.Date = date.Format("2006-01-02")
+		day.CTR = clickRate(day.Clicks, day.Searches)
+		report.Days = append(report.Days, &day)
+
+		report.Searches += day.Searches
+		report.Clicks += day.Clicks
+		report.ZeroResults += zero
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	report.CTR = clickRate(report.Clicks, report.Searches)
+
+	return report, nil
+}
+
+func clickRate(clicks, searches int) float64 {
+	if searches == 0 {
+		return 0
+	}
+	return math.Round(float64(clicks)/float64(searches)*10000) / 10000
+}
+
+func (r *searchRepository) listItems(ctx context.Context, query string, args ...any) ([]*entities.SuggestItem, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var items []*entities.SuggestItem
+	for rows.Next() {
+		var item entities.SuggestItem
+		if err := rows.Scan(&item.ID, &item.Text, &item.Weight); err != nil {
+			return nil, err
+		}
+		items = append(items, &item)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
@@ -0,0 +1,383 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/codepnw/react_go_ecom/internal/entities"
+	"github.com/codepnw/react_go_ecom/internal/errs"
+	"github.com/lib/pq"
+)
+
+type ShipmentRepository interface {
+	Create(ctx context.Context, s *entities.Shipment) error
+	Delete(ctx context.Context, id int64) error
+	SetLabel(ctx context.Context, s *entities.Shipment) error
+	GetByID(ctx context.Context, id int64) (*entities.Shipment, error)
+	ListByOrder(ctx context.Context, orderID int64) ([]*entities.Shipment, error)
+	ClaimDue(ctx context.Context, polledBefore time.Time, limit int) ([]*entities.Shipment, error)
+	RecordEvents(ctx context.Context, s *entities.Shipment, events []*entities.TrackingEvent) error
+}
+
+type shipmentRepository struct {
+	db *sql.DB
+}
+
+func NewShipmentRepository(db *sql.DB) ShipmentRepository {
+	return &shipmentRepository{db: db}
+}
+
+const shipmentColumns = `
+	id, order_id, carrier, tracking_number, label_key, status, weight_grams, delivered_at, created_at, updated_at
+`
+
+// Statuses a shipment is still polled in
+var trackedStatuses = []string{
+	entities.ShipmentLabelCreated,
+	entities.ShipmentInTransit,
+	entities.ShipmentOutForDelivery,
+	entities.ShipmentException,
+}
+
+// Create allocates the items to a new pending shipment. The order row is
+// locked so concurrent shipments of the same order cannot allocate a line
+// twice. The weight comes from the current product weights.
+func (r *shipmentRepository) Create(ctx context.Context, s *entities.Shipment) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var status string
+	err = tx.QueryRowContext(ctx, `SELECT status FROM orders WHERE id = $1 FOR UPDATE`, s.OrderID).Scan(&status)
+	if err != nil {
+		return dbError(err, errOrderNotFound)
+	}
+
+	switch status {
+	case entities.OrderPaid, entities.OrderShipped:
+	default:
+		return errs.Conflict("order_not_shippable", "order is %s and cannot be shipped", status)
+	}
+
+	lineQuery := `
+		SELECT oi.product_id, oi.title, oi.quantity, COALESCE(p.weight_grams, 0),
+			(SELECT COALESCE(SUM(si.quantity), 0) FROM shipment_items si WHERE si.order_item_id = oi.id)
+		FROM order_items oi
+		LEFT JOIN products p ON p.product_id = oi.product_id
+		WHERE oi.id = $1 AND oi.order_id = $2
+	`
+	s.WeightGrams = 0
+	for _, item := range s.Items {
+		var ordered, weight, allocated int
+
+		err := tx.QueryRowContext(ctx, lineQuery, item.OrderItemID, s.OrderID).
+			Scan(&item.ProductID, &item.Title, &ordered, &weight, &allocated)
+		if err != nil {
+			return dbError(err, errs.NotFound("order_item_not_found", "order item %d is not in the order", item.OrderItemID))
+		}
+
+		if left := ordered - allocated; item.Quantity > left {
+			return errs.Conflict("shipment_over_allocated", "only %d of %s left to ship", left, item.Title)
+		}
+
+		s.WeightGrams += weight * item.Quantity
+	}
+
+	query := `
+		INSERT INTO shipments (order_id, carrier, weight_grams)
+		VALUES ($1, $2, $3)
+		RETURNING id, status, created_at
+	`
+	err = tx.QueryRowContext(ctx, query, s.OrderID, s.Carrier, s.WeightGrams).Scan(&s.ID, &s.Status, &s.CreatedAt)
+	if err != nil {
+		return dbError(err, nil)
+	}
+
+	itemQuery := `INSERT INTO shipment_items (shipment_id, order_item_id, quantity) VALUES ($1, $2, $3)`
+	for _, item := range s.Items {
+		if _, err := tx.ExecContext(ctx, itemQuery, s.ID, item.OrderItemID, item.Quantity); err != nil {
+			return dbError(err, nil)
+		}
+	}
+
+	s.Events = []*entities.TrackingEvent{}
+
+	return tx.Commit()
+}
+
+// Delete drops a shipment the carrier never booked, its items are free to
+// ship again
+func (r *shipmentRepository) Delete(ctx context.Context, id int64) error {
+	_, err := r.db.ExecContext(ctx, `DELETE FROM shipments WHERE id = $1 AND status = $2`, id, entities.ShipmentPending)
+	return err
+}
+
+// SetLabel stores the booking of a pending shipment, the order is shipped
+// once every unit is booked
+func (r *shipmentRepository) SetLabel(ctx context.Context, s *entities.Shipment) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE shipments SET tracking_number = $1, label_key = $2, status = $3, updated_at = now()
+		WHERE id = $4 AND status = $5
+		RETURNING status, updated_at
+	`
+	err = tx.QueryRowContext(ctx, query, s.TrackingNumber, s.LabelKey, entities.ShipmentLabelCreated, s.ID, entities.ShipmentPending).
+		Scan(&s.Status, &s.UpdatedAt)
+	if err != nil {
+		return dbError(err, errShipmentNotFound)
+	}
+
+	if err := syncOrderStatus(ctx, tx, s.OrderID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *shipmentRepository) GetByID(ctx context.Context, id int64) (*entities.Shipment, error) {
+	query := `SELECT ` + shipmentColumns + ` FROM shipments WHERE id = $1`
+
+	s, err := scanShipment(r.db.QueryRowContext(ctx, query, id).Scan)
+	if err != nil {
+		return nil, dbError(err, errShipmentNotFound)
+	}
+
+	if err := r.loadShipment(ctx, s); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+func (r *shipmentRepository) ListByOrder(ctx context.Context, orderID int64) ([]*entities.Shipment, error) {
+	query := `SELECT ` + shipmentColumns + ` FROM shipments WHERE order_id = $1 ORDER BY id`
+
+	rows, err := r.db.QueryContext(ctx, query, orderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	shipments := []*entities.Shipment{}
+	for rows.Next() {
+		s, err := scanShipment(rows.Scan)
+		if err != nil {
+			return nil, err
+		}
+		shipments = append(shipments, s)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, s := range shipments {
+		if err := r.loadShipment(ctx, s); err != nil {
+			return nil, err
+		}
+	}
+
+	return shipments, nil
+}
+
+// ClaimDue marks up to limit shipments still on their way and last polled
+// before polledBefore as polled and returns them. Rows another run holds
+// are skipped, a claimed shipment is polled again on a later run.
+func (r *shipmentRepository) ClaimDue(ctx context.Context, polledBefore time.Time, limit int) ([]*entities.Shipment, error) {
+	query := `
+		UPDATE shipments SET polled_at = now()
+		WHERE id IN (
+			SELECT id FROM shipments
+			WHERE status = ANY($1) AND (polled_at IS NULL OR polled_at < $2)
+			ORDER BY polled_at NULLS FIRST, id
+			LIMIT $3
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + shipmentColumns
+
+	rows, err := r.db.QueryContext(ctx, query, pq.Array(trackedStatuses), polledBefore, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	shipments := []*entities.Shipment{}
+	for rows.Next() {
+		s, err := scanShipment(rows.Scan)
+		if err != nil {
+			return nil, err
+		}
+		shipments = append(shipments, s)
+	}
+
+	return shipments, rows.Err()
+}
+
+// RecordEvents adds the events not stored yet and moves the shipment to the
+// status of the latest one, a delivery may complete the order
+func (r *shipmentRepository) RecordEvents(ctx context.Context, s *entities.Shipment, events []*entities.TrackingEvent) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	eventQuery := `
+		INSERT INTO tracking_events (shipment_id, status, description, location, occurred_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (shipment_id, occurred_at, status, description) DO NOTHING
+	`
+	for _, e := range events {
+		if _, err := tx.ExecContext(ctx, eventQuery, s.ID, e.Status, e.Description, e.Location, e.OccurredAt); err != nil {
+			return dbError(err, nil)
+		}
+	}
+
+	query := `
+		UPDATE shipments s SET status = latest.status, updated_at = now(),
+			delivered_at = CASE WHEN latest.status = $2 THEN COALESCE(s.delivered_at, latest.occurred_at) END
+		FROM (
+			SELECT status, occurred_at FROM tracking_events
+			WHERE shipment_id = $1
+			ORDER BY occurred_at DESC, id DESC
+			LIMIT 1
+		) latest
+		WHERE s.id = $1 AND s.status <> latest.status
+		RETURNING s.status, s.delivered_at, s.updated_at
+	`
+	err = tx.QueryRowContext(ctx, query, s.ID, entities.ShipmentDelivered).Scan(&s.Status, &s.DeliveredAt, &s.UpdatedAt)
+	if err == sql.ErrNoRows {
+		return tx.Commit()
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := syncOrderStatus(ctx, tx, s.OrderID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *shipmentRepository) loadShipment(ctx context.Context, s *entities.Shipment) error {
+	itemQuery := `
+		SELECT si.order_item_id, oi.product_id, oi.title, si.quantity
+		FROM shipment_items si
+		JOIN order_items oi ON oi.id = si.order_item_id
+		WHERE si.shipment_id = $1
+		ORDER BY si.order_item_id
+	`
+	rows, err := r.db.QueryContext(ctx, itemQuery, s.ID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var item entities.ShipmentItem
+		if err := rows.Scan(&item.OrderItemID, &item.ProductID, &item.Title, &item.Quantity); err != nil {
+			return err
+		}
+		s.Items = append(s.Items, &item)
+	}
+
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	eventQuery := `
+		SELECT status, description, location, occurred_at
+		FROM tracking_events
+		WHERE shipment_id = $1
+		ORDER BY occurred_at, id
+	`
+	events, err := r.db.QueryContext(ctx, eventQuery, s.ID)
+	if err != nil {
+		return err
+	}
+	defer events.Close()
+
+	for events.Next() {
+		var e entities.TrackingEvent
+		if err := events.Scan(&e.Status, &e.Description, &e.Location, &e.OccurredAt); err != nil {
+			return err
+		}
+		s.Events = append(s.Events, &e)
+	}
+
+	return events.Err()
+}
+
+// syncOrderStatus moves an order to shipped once every unit is booked with
+// a carrier and to delivered once every unit was delivered. Orders past
+// delivery or canceled are left alone.
+func syncOrderStatus(ctx context.Context, tx *sql.Tx, orderID int64) error {
+	query := `
+		SELECT COALESCE(bool_and(booked >= quantity), FALSE), COALESCE(bool_and(delivered >= quantity), FALSE)
+		FROM (
+			SELECT oi.quantity,
+				COALESCE(SUM(si.quantity) FILTER (WHERE s.status <> $2), 0) AS booked,
+				COALESCE(SUM(si.quantity) FILTER (WHERE s.status = $3), 0) AS delivered
+			FROM order_items oi
+			LEFT JOIN shipment_items si ON si.order_item_id = oi.id
+			LEFT JOIN shipments s ON s.id = si.shipment_id
+			WHERE oi.order_id = $1
+			GROUP BY oi.id, oi.quantity
+		) lines
+	`
+	var booked, delivered bool
+	err := tx.QueryRowContext(ctx, query, orderID, entities.ShipmentPending, entities.ShipmentDelivered).Scan(&booked, &delivered)
+	if err != nil {
+		return err
+	}
+
+	var next string
+	var from []string
+	switch {
+	case delivered:
+		next, from = entities.OrderDelivered, []string{entities.OrderPending, entities.OrderPaid, entities.OrderShipped}
+	case booked:
+		next, from = entities.OrderShipped, []string{entities.OrderPending, entities.OrderPaid}
+	default:
+		return nil
+	}
+
+	_, err = tx.ExecContext(ctx, `UPDATE orders SET status = $2, updated_at = now() WHERE id = $1 AND status::TEXT = ANY($3)`,
+		orderID, next, pq.Array(from))
+
+	return err
+}
+
+func scanShipment(scan func(dest ...any) error) (*entities.Shipment, error) {
+	s := entities.Shipment{
+		Items:  []*entities.ShipmentItem{},
+		Events: []*entities.TrackingEvent{},
+	}
+
+	err := scan(
+		&s.ID,
+		&s.OrderID,
+		&s.Carrier,
+		&s.TrackingNumber,
+		&s.LabelKey,
+		&s.Status,
+		&s.WeightGrams,
+		&s.DeliveredAt,
+		&s.CreatedAt,
+		&s.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &s, nil
+}
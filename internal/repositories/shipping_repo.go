@@ -0,0 +1,454 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/codepnw/react_go_ecom/internal/entities"
+	"github.com/codepnw/react_go_ecom/pkg/money"
+	"github.com/lib/pq"
+)
+
+type ShippingRepository interface {
+	CreateZone(ctx context.Context, zone *entities.ShippingZone) error
+	ListZones(ctx context.Context) ([]*entities.ShippingZone, error)
+	GetZone(ctx context.Context, id int) (*entities.ShippingZone, error)
+	UpdateZone(ctx context.Context, zone *entities.ShippingZone) error
+	DeleteZone(ctx context.Context, id int) error
+	CreateMethod(ctx context.Context, method *entities.ShippingMethod) error
+	GetMethod(ctx context.Context, id int) (*entities.ShippingMethod, error)
+	UpdateMethod(ctx context.Context, method *entities.ShippingMethod) error
+	DeleteMethod(ctx context.Context, id int) error
+	FindZone(ctx context.Context, dest entities.Destination) (*entities.ShippingZone, error)
+}
+
+type shippingRepository struct {
+	db *sql.DB
+}
+
+func NewShippingRepository(db *sql.DB) ShippingRepository {
+	return &shippingRepository{db: db}
+}
+
+const shippingMethodColumns = `
+	id, zone_id, name, kind, rate::TEXT, threshold::TEXT, active, position, created_at, updated_at
+`
+
+func (r *shippingRepository) CreateZone(ctx context.Context, zone *entities.ShippingZone) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRowContext(ctx, `INSERT INTO shipping_zones (name) VALUES ($1) RETURNING id, created_at`, zone.Name).
+		Scan(&zone.ID, &zone.CreatedAt)
+	if err != nil {
+		return dbError(err, nil)
+	}
+
+	if err := insertLocations(ctx, tx, zone); err != nil {
+		return err
+	}
+
+	zone.Methods = []*entities.ShippingMethod{}
+
+	return tx.Commit()
+}
+
+// ListZones reads every zone with its locations and methods, there are few
+// of them
+func (r *shippingRepository) ListZones(ctx context.Context) ([]*entities.ShippingZone, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT id, name, created_at, updated_at FROM shipping_zones ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	zones := []*entities.ShippingZone{}
+	for rows.Next() {
+		z, err := scanShippingZone(rows.Scan)
+		if err != nil {
+			return nil, err
+		}
+		zones = append(zones, z)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, z := range zones {
+		if err := r.loadZone(ctx, z); err != nil {
+			return nil, err
+		}
+	}
+
+	return zones, nil
+}
+
+func (r *shippingRepository) GetZone(ctx context.Context, id int) (*entities.ShippingZone, error) {
+	query := `SELECT id, name, created_at, updated_at FROM shipping_zones WHERE id = $1`
+
+	zone, err := scanShippingZone(r.db.QueryRowContext(ctx, query, id).Scan)
+	if err != nil {
+		return nil, dbError(err, errShippingZoneNotFound)
+	}
+
+	if err := r.loadZone(ctx, zone); err != nil {
+		return nil, err
+	}
+
+	return zone, nil
+}
+
+// UpdateZone renames the zone and replaces its locations
+func (r *shippingRepository) UpdateZone(ctx context.Context, zone *entities.ShippingZone) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `UPDATE shipping_zones SET name = $1, updated_at = now() WHERE id = $2 RETURNING created_at, updated_at`
+
+	err = tx.QueryRowContext(ctx, query, zone.Name, zone.ID).Scan(&zone.CreatedAt, &zone.UpdatedAt)
+	if err != nil {
+		return dbError(err, errShippingZoneNotFound)
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM shipping_zone_locations WHERE zone_id = $1`, zone.ID); err != nil {
+		return err
+	}
+
+	if err := insertLocations(ctx, tx, zone); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	zone.Methods, err = r.listMethods(ctx, zone.ID)
+
+	return err
+}
+
+func (r *shippingRepository) DeleteZone(ctx context.Context, id int) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM shipping_zones WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return errShippingZoneNotFound
+	}
+
+	return nil
+}
+
+func (r *shippingRepository) CreateMethod(ctx context.Context, method *entities.ShippingMethod) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		INSERT INTO shipping_methods (zone_id, name, kind, rate, threshold, active, position)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id, created_at
+	`
+	err = tx.QueryRowContext(
+		ctx,
+		query,
+		method.ZoneID,
+		method.Name,
+		method.Kind,
+		method.Rate,
+		method.Threshold,
+		method.Active,
+		method.Position,
+	).Scan(&method.ID, &method.CreatedAt)
+	if err != nil {
+		return shippingMethodError(err)
+	}
+
+	if err := insertWeightRates(ctx, tx, method); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *shippingRepository) GetMethod(ctx context.Context, id int) (*entities.ShippingMethod, error) {
+	query := `SELECT ` + shippingMethodColumns + ` FROM shipping_methods WHERE id = $1`
+
+	method, err := scanShippingMethod(r.db.QueryRowContext(ctx, query, id).Scan)
+	if err != nil {
+		return nil, dbError(err, errShippingMethodNotFound)
+	}
+
+	if method.WeightRates, err = r.listWeightRates(ctx, method.ID); err != nil {
+		return nil, err
+	}
+
+	return method, nil
+}
+
+// UpdateMethod rewrites the method and replaces its weight brackets, the
+// zone it belongs to stays
+func (r *shippingRepository) UpdateMethod(ctx context.Context, method *entities.ShippingMethod) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	query := `
+		UPDATE shipping_methods SET name = $1, kind = $2, rate = $3, threshold = $4, active = $5, position = $6,
+			updated_at = now()
+		WHERE id = $7
+		RETURNING zone_id, created_at, updated_at
+	`
+	err = tx.QueryRowContext(
+		ctx,
+		query,
+		method.Name,
+		method.Kind,
+		method.Rate,
+		method.Threshold,
+		method.Active,
+		method.Position,
+		method.ID,
+	).Scan(&method.ZoneID, &method.CreatedAt, &method.UpdatedAt)
+	if err != nil {
+		return dbError(err, errShippingMethodNotFound)
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM shipping_weight_rates WHERE method_id = $1`, method.ID); err != nil {
+		return err
+	}
+
+	if err := insertWeightRates(ctx, tx, method); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r *shippingRepository) DeleteMethod(ctx context.Context, id int) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM shipping_methods WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return errShippingMethodNotFound
+	}
+
+	return nil
+}
+
+// FindZone picks the zone of the region, else the one of the whole
+// country. No zone is nil, nothing ships there then.
+func (r *shippingRepository) FindZone(ctx context.Context, dest entities.Destination) (*entities.ShippingZone, error) {
+	query := `
+		SELECT z.id, z.name, z.created_at, z.updated_at
+		FROM shipping_zone_locations l
+		JOIN shipping_zones z ON z.id = l.zone_id
+		WHERE l.country = $1 AND l.region IN ($2, '')
+		ORDER BY l.region DESC
+		LIMIT 1
+	`
+	zone, err := scanShippingZone(r.db.QueryRowContext(ctx, query, dest.Country, dest.Region).Scan)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.loadZone(ctx, zone); err != nil {
+		return nil, err
+	}
+
+	return zone, nil
+}
+
+func (r *shippingRepository) loadZone(ctx context.Context, zone *entities.ShippingZone) error {
+	query := `SELECT country, region FROM shipping_zone_locations WHERE zone_id = $1 ORDER BY country, region`
+
+	rows, err := r.db.QueryContext(ctx, query, zone.ID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var l entities.ShippingLocation
+		if err := rows.Scan(&l.Country, &l.Region); err != nil {
+			return err
+		}
+		zone.Locations = append(zone.Locations, &l)
+	}
+
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	zone.Methods, err = r.listMethods(ctx, zone.ID)
+
+	return err
+}
+
+func (r *shippingRepository) listMethods(ctx context.Context, zoneID int) ([]*entities.ShippingMethod, error) {
+	query := `SELECT ` + shippingMethodColumns + ` FROM shipping_methods WHERE zone_id = $1 ORDER BY position, id`
+
+	rows, err := r.db.QueryContext(ctx, query, zoneID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	methods := []*entities.ShippingMethod{}
+	for rows.Next() {
+		m, err := scanShippingMethod(rows.Scan)
+		if err != nil {
+			return nil, err
+		}
+		methods = append(methods, m)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	for _, m := range methods {
+		if m.WeightRates, err = r.listWeightRates(ctx, m.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	return methods, nil
+}
+
+func (r *shippingRepository) listWeightRates(ctx context.Context, methodID int) ([]*entities.WeightRate, error) {
+	query := `SELECT up_to_grams, rate::TEXT FROM shipping_weight_rates WHERE method_id = $1 ORDER BY up_to_grams`
+
+	rows, err := r.db.QueryContext(ctx, query, methodID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rates := []*entities.WeightRate{}
+	for rows.Next() {
+		var w entities.WeightRate
+		var rate string
+
+		if err := rows.Scan(&w.UpToGrams, &rate); err != nil {
+			return nil, err
+		}
+		if w.Rate, err = money.Parse(rate, money.DefaultCurrency); err != nil {
+			return nil, err
+		}
+		rates = append(rates, &w)
+	}
+
+	return rates, rows.Err()
+}
+
+func insertLocations(ctx context.Context, tx *sql.Tx, zone *entities.ShippingZone) error {
+	query := `INSERT INTO shipping_zone_locations (zone_id, country, region) VALUES ($1, $2, $3)`
+
+	for _, l := range zone.Locations {
+		if _, err := tx.ExecContext(ctx, query, zone.ID, l.Country, l.Region); err != nil {
+			return dbError(err, nil)
+		}
+	}
+
+	return nil
+}
+
+func insertWeightRates(ctx context.Context, tx *sql.Tx, method *entities.ShippingMethod) error {
+	query := `INSERT INTO shipping_weight_rates (method_id, up_to_grams, rate) VALUES ($1, $2, $3)`
+
+	for _, w := range method.WeightRates {
+		if _, err := tx.ExecContext(ctx, query, method.ID, w.UpToGrams, w.Rate); err != nil {
+			return dbError(err, nil)
+		}
+	}
+
+	return nil
+}
+
+// shippingMethodError reports a method added to a missing zone as the zone
+// not being found
+func shippingMethodError(err error) error {
+	var pqErr *pq.Error
+	if errors.As(err, &pqErr) && pqErr.Code.Name() == "foreign_key_violation" {
+		return errShippingZoneNotFound.Wrap(err)
+	}
+	return dbError(err, nil)
+}
+
+func scanShippingZone(scan func(dest ...any) error) (*entities.ShippingZone, error) {
+	z := entities.ShippingZone{
+		Locations: []*entities.ShippingLocation{},
+		Methods:   []*entities.ShippingMethod{},
+	}
+
+	if err := scan(&z.ID, &z.Name, &z.CreatedAt, &z.UpdatedAt); err != nil {
+		return nil, err
+	}
+
+	return &z, nil
+}
+
+func scanShippingMethod(scan func(dest ...any) error) (*entities.ShippingMethod, error) {
+	m := entities.ShippingMethod{WeightRates: []*entities.WeightRate{}}
+	var rate string
+	var threshold sql.NullString
+
+	err := scan(
+		&m.ID,
+		&m.ZoneID,
+		&m.Name,
+		&m.Kind,
+		&rate,
+		&threshold,
+		&m.Active,
+		&m.Position,
+		&m.CreatedAt,
+		&m.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if m.Rate, err = money.Parse(rate, money.DefaultCurrency); err != nil {
+		return nil, err
+	}
+
+	if threshold.Valid {
+		t, err := money.Parse(threshold.String, money.DefaultCurrency)
+		if err != nil {
+			return nil, err
+		}
+		m.Threshold = &t
+	}
+
+	return &m, nil
+}
@@ -0,0 +1,150 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/codepnw/react_go_ecom/internal/entities"
+	"github.com/codepnw/react_go_ecom/internal/errs"
+	"github.com/codepnw/react_go_ecom/pkg/money"
+)
+
+type StoreCreditRepository interface {
+	Balances(ctx context.Context, userID string) ([]money.Money, error)
+	ListEntries(ctx context.Context, userID string) ([]*entities.StoreCreditEntry, error)
+	AddEntry(ctx context.Context, entry *entities.StoreCreditEntry) error
+}
+
+type storeCreditRepository struct {
+	db *sql.DB
+}
+
+func NewStoreCreditRepository(db *sql.DB) StoreCreditRepository {
+	return &storeCreditRepository{db: db}
+}
+
+func (r *storeCreditRepository) Balances(ctx context.Context, userID string) ([]money.Money, error) {
+	query := `SELECT currency, balance::TEXT FROM store_credit_balances WHERE user_id = $1 ORDER BY currency`
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	balances := []money.Money{}
+	for rows.Next() {
+		var currency, balance string
+		if err := rows.Scan(&currency, &balance); err != nil {
+			return nil, err
+		}
+
+		m, err := money.Parse(balance, currency)
+		if err != nil {
+			return nil, err
+		}
+		balances = append(balances, m)
+	}
+
+	return balances, rows.Err()
+}
+
+// ListEntries reads the ledger of a user newest first
+func (r *storeCreditRepository) ListEntries(ctx context.Context, userID string) ([]*entities.StoreCreditEntry, error) {
+	query := `
+		SELECT id, user_id, kind, currency, amount::TEXT, balance_after::TEXT, order_id, refund_id, note, created_at
+		FROM store_credit_entries WHERE user_id = $1
+		ORDER BY id DESC
+	`
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []*entities.StoreCreditEntry{}
+	for rows.Next() {
+		var e entities.StoreCreditEntry
+		var currency, amount, after string
+
+		err := rows.Scan(&e.ID, &e.UserID, &e.Kind, &currency, &amount, &after, &e.OrderID, &e.RefundID, &e.Note, &e.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+
+		if e.Amount, err = money.Parse(amount, currency); err != nil {
+			return nil, err
+		}
+		if e.BalanceAfter, err = money.Parse(after, currency); err != nil {
+			return nil, err
+		}
+		entries = append(entries, &e)
+	}
+
+	return entries, rows.Err()
+}
+
+func (r *storeCreditRepository) AddEntry(ctx context.Context, entry *entities.StoreCreditEntry) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := addCreditEntry(ctx, tx, entry); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// addCreditEntry books an entry on the ledger and moves the balance with
+// it. The balance row stays locked until the transaction ends, an entry
+// that would take it below zero fails.
+func addCreditEntry(ctx context.Context, tx *sql.Tx, entry *entities.StoreCreditEntry) error {
+	currency := entry.Amount.Currency
+
+	// The first entry of a currency opens its balance
+	query := `
+		INSERT INTO store_credit_balances (user_id, currency)
+		SELECT user_id, $2 FROM users WHERE user_id = $1
+		ON CONFLICT (user_id, currency) DO NOTHING
+	`
+	if _, err := tx.ExecContext(ctx, query, entry.UserID, currency); err != nil {
+		return dbError(err, nil)
+	}
+
+	var balance string
+	query = `SELECT balance::TEXT FROM store_credit_balances WHERE user_id = $1 AND currency = $2 FOR UPDATE`
+	if err := tx.QueryRowContext(ctx, query, entry.UserID, currency).Scan(&balance); err != nil {
+		return dbError(err, errUserNotFound)
+	}
+
+	current, err := money.Parse(balance, currency)
+	if err != nil {
+		return err
+	}
+
+	entry.BalanceAfter = current.Add(entry.Amount)
+	if entry.BalanceAfter.IsNegative() {
+		return errs.Conflict("store_credit_insufficient", "only %s of store credit is left", current)
+	}
+
+	query = `UPDATE store_credit_balances SET balance = $3, updated_at = now() WHERE user_id = $1 AND currency = $2`
+	if _, err := tx.ExecContext(ctx, query, entry.UserID, currency, entry.BalanceAfter); err != nil {
+		return dbError(err, nil)
+	}
+
+	query = `
+		INSERT INTO store_credit_entries (user_id, currency, kind, amount, balance_after, order_id, refund_id, note)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, created_at
+	`
+	err = tx.QueryRowContext(ctx, query, entry.UserID, currency, entry.Kind, entry.Amount, entry.BalanceAfter,
+		entry.OrderID, entry.RefundID, entry.Note).Scan(&entry.ID, &entry.CreatedAt)
+	if err != nil {
+		return dbError(err, nil)
+	}
+
+	return nil
+}
@@ -0,0 +1,125 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/codepnw/react_go_ecom/internal/entities"
+	"github.com/codepnw/react_go_ecom/internal/errs"
+	"github.com/lib/pq"
+)
+
+type SynonymRepository interface {
+	ListSynonyms(ctx context.Context) ([]*entities.SynonymGroup, error)
+	CreateSynonym(ctx context.Context, group *entities.SynonymGroup) error
+	UpdateSynonym(ctx context.Context, group *entities.SynonymGroup) error
+	DeleteSynonym(ctx context.Context, id int) error
+	ListStopWords(ctx context.Context) ([]*entities.StopWord, error)
+	AddStopWord(ctx context.Context, word *entities.StopWord) error
+	DeleteStopWord(ctx context.Context, word string) error
+}
+
+type synonymRepository struct {
+	db *sql.DB
+}
+
+func NewSynonymRepository(db *sql.DB) SynonymRepository {
+	return &synonymRepository{db: db}
+}
+
+func (r *synonymRepository) ListSynonyms(ctx context.Context) ([]*entities.SynonymGroup, error) {
+	query := `SELECT id, terms, created_at FROM search_synonyms ORDER BY id`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	groups := []*entities.SynonymGroup{}
+	for rows.Next() {
+		var g entities.SynonymGroup
+		if err := rows.Scan(&g.ID, pq.Array(&g.Terms), &g.CreatedAt); err != nil {
+			return nil, err
+		}
+		groups = append(groups, &g)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return groups, nil
+}
+
+func (r *synonymRepository) CreateSynonym(ctx context.Context, group *entities.SynonymGroup) error {
+	query := `INSERT INTO search_synonyms (terms) VALUES ($1) RETURNING id, created_at`
+
+	return r.db.QueryRowContext(ctx, query, pq.Array(group.Terms)).Scan(&group.ID, &group.CreatedAt)
+}
+
+func (r *synonymRepository) UpdateSynonym(ctx context.Context, group *entities.SynonymGroup) error {
+	query := `UPDATE search_synonyms SET terms = $2 WHERE id = $1 RETURNING created_at`
+
+	err := r.db.QueryRowContext(ctx, query, group.ID, pq.Array(group.Terms)).Scan(&group.CreatedAt)
+	if err != nil {
+		return dbError(err, errs.NotFound("synonym_not_found", "synonym group not found"))
+	}
+
+	return nil
+}
+
+func (r *synonymRepository) DeleteSynonym(ctx context.Context, id int) error {
+	query := `DELETE FROM search_synonyms WHERE id = $1`
+
+	if _, err := r.db.ExecContext(ctx, query, id); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *synonymRepository) ListStopWords(ctx context.Context) ([]*entities.StopWord, error) {
+	query := `SELECT word, created_at FROM search_stop_words ORDER BY word`
+
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	words := []*entities.StopWord{}
+	for rows.Next() {
+		var w entities.StopWord
+		if err := rows.Scan(&w.Word, &w.CreatedAt); err != nil {
+			return nil, err
+		}
+		words = append(words, &w)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return words, nil
+}
+
+func (r *synonymRepository) AddStopWord(ctx context.Context, word *entities.StopWord) error {
+	query := `
+		INSERT INTO search_stop_words (word) VALUES ($1)
+		ON CONFLICT (word) DO UPDATE SET word = EXCLUDED.word
+		RETURNING created_at
+	`
+
+	return r.db.QueryRowContext(ctx, query, word.Word).Scan(&word.CreatedAt)
+}
+
+func (r *synonymRepository) DeleteStopWord(ctx context.Context, word string) error {
+	query := `DELETE FROM search_stop_words WHERE word = $1`
+
+	if _, err := r.db.ExecContext(ctx, query, word); err != nil {
+		return err
+	}
+
+	return nil
+}
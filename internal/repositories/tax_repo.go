@@ -0,0 +1,290 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/codepnw/react_go_ecom/internal/entities"
+	"github.com/codepnw/react_go_ecom/internal/errs"
+)
+
+type TaxRepository interface {
+	CreateClass(ctx context.Context, class *entities.TaxClass) error
+	ListClasses(ctx context.Context) ([]*entities.TaxClass, error)
+	DeleteClass(ctx context.Context, id int) error
+	CreateZone(ctx context.Context, zone *entities.TaxZone) error
+	ListZones(ctx context.Context) ([]*entities.TaxZone, error)
+	GetZone(ctx context.Context, id int) (*entities.TaxZone, error)
+	UpdateZone(ctx context.Context, zone *entities.TaxZone) error
+	DeleteZone(ctx context.Context, id int) error
+	SetRate(ctx context.Context, zoneID, classID int, req *entities.TaxRateReq) error
+	DeleteRate(ctx context.Context, zoneID, classID int) error
+	FindZone(ctx context.Context, dest entities.Destination) (*entities.TaxZone, error)
+}
+
+type taxRepository struct {
+	db *sql.DB
+}
+
+func NewTaxRepository(db *sql.DB) TaxRepository {
+	return &taxRepository{db: db}
+}
+
+func (r *taxRepository) CreateClass(ctx context.Context, class *entities.TaxClass) error {
+	query := `INSERT INTO tax_classes (code, name) VALUES ($1, $2) RETURNING id, created_at`
+
+	err := r.db.QueryRowContext(ctx, query, class.Code, class.Name).Scan(&class.ID, &class.CreatedAt)
+
+	return dbError(err, nil)
+}
+
+func (r *taxRepository) ListClasses(ctx context.Context) ([]*entities.TaxClass, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT id, code, name, created_at FROM tax_classes ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	classes := []*entities.TaxClass{}
+	for rows.Next() {
+		var c entities.TaxClass
+		if err := rows.Scan(&c.ID, &c.Code, &c.Name, &c.CreatedAt); err != nil {
+			return nil, err
+		}
+		classes = append(classes, &c)
+	}
+
+	return classes, rows.Err()
+}
+
+// DeleteClass keeps the standard class, products of a removed class fall
+// back to it
+func (r *taxRepository) DeleteClass(ctx context.Context, id int) error {
+	var code string
+
+	err := r.db.QueryRowContext(ctx, `SELECT code FROM tax_classes WHERE id = $1`, id).Scan(&code)
+	if err != nil {
+		return dbError(err, errTaxClassNotFound)
+	}
+
+	if code == entities.TaxClassStandard {
+		return errs.Conflict("standard_tax_class", "the standard tax class cannot be removed")
+	}
+
+	_, err = r.db.ExecContext(ctx, `DELETE FROM tax_classes WHERE id = $1`, id)
+
+	return err
+}
+
+func (r *taxRepository) CreateZone(ctx context.Context, zone *entities.TaxZone) error {
+	query := `
+		INSERT INTO tax_zones (name, country, region, prices_include_tax)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at
+	`
+	err := r.db.QueryRowContext(ctx, query, zone.Name, zone.Country, zone.Region, zone.PricesIncludeTax).Scan(&zone.ID, &zone.CreatedAt)
+	if err != nil {
+		return dbError(err, nil)
+	}
+
+	zone.Rates = []*entities.TaxRate{}
+
+	return nil
+}
+
+// ListZones reads every zone with its rates, there are few of them
+func (r *taxRepository) ListZones(ctx context.Context) ([]*entities.TaxZone, error) {
+	query := `
+		SELECT id, name, country, region, prices_include_tax, created_at, updated_at
+		FROM tax_zones ORDER BY country, region
+	`
+	rows, err := r.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	zones := []*entities.TaxZone{}
+	byID := make(map[int]*entities.TaxZone)
+	for rows.Next() {
+		z, err := scanTaxZone(rows.Scan)
+		if err != nil {
+			return nil, err
+		}
+		zones = append(zones, z)
+		byID[z.ID] = z
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	rates, err := r.db.QueryContext(ctx, `
+		SELECT tr.zone_id, tr.tax_class_id, tc.code, tr.name, tr.rate::TEXT
+		FROM tax_rates tr
+		JOIN tax_classes tc ON tc.id = tr.tax_class_id
+		ORDER BY tr.zone_id, tr.tax_class_id
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rates.Close()
+
+	for rates.Next() {
+		var zoneID int
+		var rate entities.TaxRate
+
+		if err := rates.Scan(&zoneID, &rate.TaxClassID, &rate.TaxClassCode, &rate.Name, &rate.Rate); err != nil {
+			return nil, err
+		}
+		if z, ok := byID[zoneID]; ok {
+			z.Rates = append(z.Rates, &rate)
+		}
+	}
+
+	return zones, rates.Err()
+}
+
+func (r *taxRepository) GetZone(ctx context.Context, id int) (*entities.TaxZone, error) {
+	query := `
+		SELECT id, name, country, region, prices_include_tax, created_at, updated_at
+		FROM tax_zones WHERE id = $1
+	`
+	zone, err := scanTaxZone(r.db.QueryRowContext(ctx, query, id).Scan)
+	if err != nil {
+		return nil, dbError(err, errTaxZoneNotFound)
+	}
+
+	if zone.Rates, err = r.listRates(ctx, zone.ID); err != nil {
+		return nil, err
+	}
+
+	return zone, nil
+}
+
+func (r *taxRepository) UpdateZone(ctx context.Context, zone *entities.TaxZone) error {
+	query := `
+		UPDATE tax_zones SET name = $1, country = $2, region = $3, prices_include_tax = $4, updated_at = now()
+		WHERE id = $5
+		RETURNING created_at, updated_at
+	`
+	err := r.db.QueryRowContext(ctx, query, zone.Name, zone.Country, zone.Region, zone.PricesIncludeTax, zone.ID).
+		Scan(&zone.CreatedAt, &zone.UpdatedAt)
+	if err != nil {
+		return dbError(err, errTaxZoneNotFound)
+	}
+
+	zone.Rates, err = r.listRates(ctx, zone.ID)
+
+	return err
+}
+
+func (r *taxRepository) DeleteZone(ctx context.Context, id int) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM tax_zones WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return errTaxZoneNotFound
+	}
+
+	return nil
+}
+
+func (r *taxRepository) SetRate(ctx context.Context, zoneID, classID int, req *entities.TaxRateReq) error {
+	query := `
+		INSERT INTO tax_rates (zone_id, tax_class_id, name, rate)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (zone_id, tax_class_id) DO UPDATE SET name = EXCLUDED.name, rate = EXCLUDED.rate
+	`
+	_, err := r.db.ExecContext(ctx, query, zoneID, classID, req.Name, req.Rate)
+
+	return dbError(err, nil)
+}
+
+func (r *taxRepository) DeleteRate(ctx context.Context, zoneID, classID int) error {
+	result, err := r.db.ExecContext(ctx, `DELETE FROM tax_rates WHERE zone_id = $1 AND tax_class_id = $2`, zoneID, classID)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return errTaxRateNotFound
+	}
+
+	return nil
+}
+
+// FindZone picks the zone of the region, else the one of the whole
+// country. No zone is nil, nothing is taxed then.
+func (r *taxRepository) FindZone(ctx context.Context, dest entities.Destination) (*entities.TaxZone, error) {
+	query := `
+		SELECT id, name, country, region, prices_include_tax, created_at, updated_at
+		FROM tax_zones
+		WHERE country = $1 AND region IN ($2, '')
+		ORDER BY region DESC
+		LIMIT 1
+	`
+	zone, err := scanTaxZone(r.db.QueryRowContext(ctx, query, dest.Country, dest.Region).Scan)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if zone.Rates, err = r.listRates(ctx, zone.ID); err != nil {
+		return nil, err
+	}
+
+	return zone, nil
+}
+
+func (r *taxRepository) listRates(ctx context.Context, zoneID int) ([]*entities.TaxRate, error) {
+	query := `
+		SELECT tr.tax_class_id, tc.code, tr.name, tr.rate::TEXT
+		FROM tax_rates tr
+		JOIN tax_classes tc ON tc.id = tr.tax_class_id
+		WHERE tr.zone_id = $1
+		ORDER BY tr.tax_class_id
+	`
+	rows, err := r.db.QueryContext(ctx, query, zoneID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rates := []*entities.TaxRate{}
+	for rows.Next() {
+		var rate entities.TaxRate
+		if err := rows.Scan(&rate.TaxClassID, &rate.TaxClassCode, &rate.Name, &rate.Rate); err != nil {
+			return nil, err
+		}
+		rates = append(rates, &rate)
+	}
+
+	return rates, rows.Err()
+}
+
+func scanTaxZone(scan func(dest ...any) error) (*entities.TaxZone, error) {
+	z := entities.TaxZone{Rates: []*entities.TaxRate{}}
+
+	err := scan(&z.ID, &z.Name, &z.Country, &z.Region, &z.PricesIncludeTax, &z.CreatedAt, &z.UpdatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return &z, nil
+}
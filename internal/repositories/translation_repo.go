@@ -0,0 +1,189 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/codepnw/react_go_ecom/internal/entities"
+)
+
+type TranslationRepository interface {
+	ListProductTranslations(ctx context.Context, productID string) ([]*entities.ProductTranslation, error)
+	UpsertProductTranslation(ctx context.Context, productID string, t *entities.ProductTranslation) error
+	DeleteProductTranslation(ctx context.Context, productID, locale string) error
+	ListCategoryTranslations(ctx context.Context, categoryID int) ([]*entities.CategoryTranslation, error)
+	UpsertCategoryTranslation(ctx context.Context, categoryID int, t *entities.CategoryTranslation) error
+	DeleteCategoryTranslation(ctx context.Context, categoryID int, locale string) error
+	ListAttributeTranslations(ctx context.Context, attributeID int) ([]*entities.AttributeTranslation, error)
+	UpsertAttributeTranslation(ctx context.Context, attributeID int, t *entities.AttributeTranslation) error
+	DeleteAttributeTranslation(ctx context.Context, attributeID int, locale string) error
+}
+
+type translationRepository struct {
+	db *sql.DB
+}
+
+func NewTranslationRepository(db *sql.DB) TranslationRepository {
+	return &translationRepository{db: db}
+}
+
+func (r *translationRepository) ListProductTranslations(ctx context.Context, productID string) ([]*entities.ProductTranslation, error) {
+	query := `
+		SELECT locale, title, COALESCE(description, ''), updated_at
+		FROM product_translations WHERE product_id = $1
+		ORDER BY locale
+	`
+	rows, err := r.db.QueryContext(ctx, query, productID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	translations := []*entities.ProductTranslation{}
+	for rows.Next() {
+		var t entities.ProductTranslation
+		if err := rows.Scan(&t.Locale, &t.Title, &t.Description, &t.UpdatedAt); err != nil {
+			return nil, err
+		}
+		translations = append(translations, &t)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return translations, nil
+}
+
+// UpsertProductTranslation selects from products so a missing product is
+// reported as not found rather than as a foreign key violation
+func (r *translationRepository) UpsertProductTranslation(ctx context.Context, productID string, t *entities.ProductTranslation) error {
+	query := `
+		INSERT INTO product_translations (product_id, locale, title, description)
+		SELECT product_id, $2, $3, NULLIF($4, '') FROM products WHERE product_id = $1
+		ON CONFLICT (product_id, locale) DO UPDATE
+		SET title = EXCLUDED.title, description = EXCLUDED.description, updated_at = now()
+		RETURNING updated_at
+	`
+	err := r.db.QueryRowContext(ctx, query, productID, t.Locale, t.Title, t.Description).Scan(&t.UpdatedAt)
+
+	return dbError(err, errProductNotFound)
+}
+
+func (r *translationRepository) DeleteProductTranslation(ctx context.Context, productID, locale string) error {
+	query := `DELETE FROM product_translations WHERE product_id = $1 AND locale = $2`
+
+	return r.delete(ctx, query, productID, locale)
+}
+
+func (r *translationRepository) ListCategoryTranslations(ctx context.Context, categoryID int) ([]*entities.CategoryTranslation, error) {
+	query := `
+		SELECT locale, title, updated_at
+		FROM category_translations WHERE category_id = $1
+		ORDER BY locale
+	`
+	rows, err := r.db.QueryContext(ctx, query, categoryID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	translations := []*entities.CategoryTranslation{}
+	for rows.Next() {
+		var t entities.CategoryTranslation
+		if err := rows.Scan(&t.Locale, &t.Title, &t.UpdatedAt); err != nil {
+			return nil, err
+		}
+		translations = append(translations, &t)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return translations, nil
+}
+
+func (r *translationRepository) UpsertCategoryTranslation(ctx context.Context, categoryID int, t *entities.CategoryTranslation) error {
+	query := `
+		INSERT INTO category_translations (category_id, locale, title)
+		SELECT id, $2, $3 FROM categories WHERE id = $1
+		ON CONFLICT (category_id, locale) DO UPDATE
+		SET title = EXCLUDED.title, updated_at = now()
+		RETURNING updated_at
+	`
+	err := r.db.QueryRowContext(ctx, query, categoryID, t.Locale, t.Title).Scan(&t.UpdatedAt)
+
+	return dbError(err, errCategoryNotFound)
+}
+
+func (r *translationRepository) DeleteCategoryTranslation(ctx context.Context, categoryID int, locale string) error {
+	query := `DELETE FROM category_translations WHERE category_id = $1 AND locale = $2`
+
+	return r.delete(ctx, query, categoryID, locale)
+}
+
+func (r *translationRepository) ListAttributeTranslations(ctx context.Context, attributeID int) ([]*entities.AttributeTranslation, error) {
+	query := `
+		SELECT locale, label, updated_at
+		FROM attribute_translations WHERE attribute_id = $1
+		ORDER BY locale
+	`
+	rows, err := r.db.QueryContext(ctx, query, attributeID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	translations := []*entities.AttributeTranslation{}
+	for rows.Next() {
+		var t entities.AttributeTranslation
+		if err := rows.Scan(&t.Locale, &t.Label, &t.UpdatedAt); err != nil {
+			return nil, err
+		}
+		translations = append(translations, &t)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return translations, nil
+}
+
+func (r *translationRepository) UpsertAttributeTranslation(ctx context.Context, attributeID int, t *entities.AttributeTranslation) error {
+	query := `
+		INSERT INTO attribute_translations (attribute_id, locale, label)
+		SELECT id, $2, $3 FROM attributes WHERE id = $1
+		ON CONFLICT (attribute_id, locale) DO UPDATE
+		SET label = EXCLUDED.label, updated_at = now()
+		RETURNING updated_at
+	`
+	err := r.db.QueryRowContext(ctx, query, attributeID, t.Locale, t.Label).Scan(&t.UpdatedAt)
+
+	return dbError(err, errAttrNotFound)
+}
+
+func (r *translationRepository) DeleteAttributeTranslation(ctx context.Context, attributeID int, locale string) error {
+	query := `DELETE FROM attribute_translations WHERE attribute_id = $1 AND locale = $2`
+
+	return r.delete(ctx, query, attributeID, locale)
+}
+
+func (r *translationRepository) delete(ctx context.Context, query string, args ...any) error {
+	result, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected == 0 {
+		return errTransNotFound
+	}
+
+	return nil
+}
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/codepnw/react_go_ecom/internal/entities"
+	"github.com/codepnw/react_go_ecom/internal/errs"
 )
 
 type UserRepository interface {
@@ -27,8 +28,8 @@ func NewUserRepository(db *sql.DB) UserRepository {
 
 func (r *userRepository) Create(ctx context.Context, user *entities.User) (string, error) {
 	query := `
-		INSERT INTO users (email, password, first_name, last_name, role_id, enabled, images, address, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		INSERT INTO users (email, password, first_name, last_name, role_id, enabled, address, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
 		RETURNING user_id
 	`
 	var id string
@@ -42,13 +43,12 @@ func (r *userRepository) Create(ctx context.Context, user *entities.User) (strin
 		&user.LastName,
 		&user.RoleID,
 		&user.Enabled,
-		"later",
 		&user.Address,
 		&user.CreatedAt,
 		&user.UpdatedAt,
 	).Scan(&id)
 	if err != nil {
-		return "", err
+		return "", dbError(err, nil)
 	}
 
 	return id, nil
@@ -56,7 +56,7 @@ func (r *userRepository) Create(ctx context.Context, user *entities.User) (strin
 
 func (r *userRepository) GetByID(ctx context.Context, id string) (*entities.User, error) {
 	query := `
-		SELECT user_id, email, first_name, last_name, role_id, address, enabled, created_at, updated_at
+		SELECT user_id, email, first_name, last_name, role_id, address, enabled, COALESCE(images, ''), created_at, updated_at
 		FROM users WHERE user_id = $1
 	`
 	var user entities.User
@@ -68,11 +68,12 @@ func (r *userRepository) GetByID(ctx context.Context, id string) (*entities.User
 		&user.RoleID,
 		&user.Address,
 		&user.Enabled,
+		&user.Avatar,
 		&user.CreatedAt,
 		&user.UpdatedAt,
 	)
 	if err != nil {
-		return nil, err
+		return nil, dbError(err, errUserNotFound)
 	}
 
 	return &user, nil
@@ -91,7 +92,7 @@ func (r *userRepository) GetByEmail(ctx context.Context, email string) (*entitie
 		&user.RoleID,
 	)
 	if err != nil {
-		return nil, err
+		return nil, dbError(err, errUserNotFound)
 	}
 
 	return &user, nil
@@ -112,7 +113,7 @@ func (r *userRepository) ValidateRefreshToken(token string) (int, error) {
 	`
 	err := r.db.QueryRow(query, token).Scan(&userID)
 	if err != nil {
-		return -1, err
+		return -1, dbError(err, errs.Unauthorized("invalid_refresh_token", "invalid or expires refresh token"))
 	}
 
 	return userID, nil
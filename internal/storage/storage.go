@@ -7,30 +7,152 @@ import (
 	"github.com/codepnw/react_go_ecom/internal/handlers"
 	"github.com/codepnw/react_go_ecom/internal/repositories"
 	"github.com/codepnw/react_go_ecom/internal/usecases"
+	"github.com/codepnw/react_go_ecom/pkg/media"
+	"github.com/codepnw/react_go_ecom/pkg/payment"
+	"github.com/codepnw/react_go_ecom/pkg/pdf"
+	"github.com/codepnw/react_go_ecom/pkg/scheduler"
+	"github.com/codepnw/react_go_ecom/pkg/shipping"
+	"github.com/codepnw/react_go_ecom/pkg/stockgate"
 )
 
 type Storage struct {
-	User     handlers.UserHandler
-	Category handlers.CategoryHandler
-	Product  handlers.ProductHandler
+	User         handlers.UserHandler
+	Category     handlers.CategoryHandler
+	Product      handlers.ProductHandler
+	Media        handlers.MediaHandler
+	Attribute    handlers.AttributeHandler
+	Search       handlers.SearchHandler
+	Synonym      handlers.SynonymHandler
+	Translation  handlers.TranslationHandler
+	Pricing      handlers.PricingHandler
+	Cart         handlers.CartHandler
+	Order        handlers.OrderHandler
+	PriceHistory handlers.PriceHistoryHandler
+	Promotion    handlers.PromotionHandler
+	FlashSale    handlers.FlashSaleHandler
+	Tax          handlers.TaxHandler
+	Shipping     handlers.ShippingHandler
+	Shipment     handlers.ShipmentHandler
+	Return       handlers.ReturnHandler
+	Invoice      handlers.InvoiceHandler
+	GiftCard     handlers.GiftCardHandler
+	StoreCredit  handlers.StoreCreditHandler
 }
 
-func NewStorage(db *sql.DB, cfg config.Config) Storage {
+func NewStorage(db *sql.DB, blobs, documents media.BlobStore, processor *media.Processor, jobs *scheduler.Scheduler, font *pdf.Font, cfg config.Config) Storage {
+	mediaRepo := repositories.NewMediaRepository(db)
+	attrRepo := repositories.NewAttributeRepository(db)
+
+	searchRepo := repositories.NewSearchRepository(db)
+	searchUsecase := usecases.NewSearchUsecase(searchRepo)
+	searchHandler := handlers.NewSearchHandler(searchUsecase)
+
+	synonymRepo := repositories.NewSynonymRepository(db)
+	synonymUsecase := usecases.NewSynonymUsecase(synonymRepo)
+	synonymHandler := handlers.NewSynonymHandler(synonymUsecase)
+
 	userRepo := repositories.NewUserRepository(db)
-	userUsecase := usecases.NewUserUsecase(userRepo, *cfg.JWTConfig)
+	userUsecase := usecases.NewUserUsecase(userRepo, *cfg.JWTConfig, blobs)
 	userHandler := handlers.NewUserHandler(userUsecase)
 
 	catRepo := repositories.NewCategoryRepo(db)
-	catUc := usecases.NewCategoryUsecase(catRepo)
+	catUc := usecases.NewCategoryUsecase(catRepo, searchUsecase)
 	catHandler := handlers.NewCategoryHandler(catUc)
 
+	pricingRepo := repositories.NewPricingRepository(db)
+	pricingUsecase := usecases.NewPricingUsecase(pricingRepo)
+	pricingHandler := handlers.NewPricingHandler(pricingUsecase)
+
 	proRepo := repositories.NewProductRepository(db)
-	proUsecase := usecases.NewProductUsecase(proRepo)
+	proUsecase := usecases.NewProductUsecase(proRepo, mediaRepo, attrRepo, blobs, searchUsecase, synonymUsecase, pricingUsecase)
 	proHandler := handlers.NewProductHandler(proUsecase)
 
+	mediaUsecase := usecases.NewMediaUsecase(mediaRepo, proRepo, blobs, processor)
+	mediaHandler := handlers.NewMediaHandler(mediaUsecase, cfg.MaxUploadMB)
+
+	attrUsecase := usecases.NewAttributeUsecase(attrRepo, proRepo)
+	attrHandler := handlers.NewAttributeHandler(attrUsecase)
+
+	transRepo := repositories.NewTranslationRepository(db)
+	transUsecase := usecases.NewTranslationUsecase(transRepo)
+	transHandler := handlers.NewTranslationHandler(transUsecase)
+
+	promoRepo := repositories.NewPromotionRepository(db)
+	promoUsecase := usecases.NewPromotionUsecase(promoRepo)
+	promoHandler := handlers.NewPromotionHandler(promoUsecase)
+
+	taxRepo := repositories.NewTaxRepository(db)
+	taxUsecase := usecases.NewTaxUsecase(taxRepo, cfg.DefaultCountry)
+	taxHandler := handlers.NewTaxHandler(taxUsecase)
+
+	shippingRepo := repositories.NewShippingRepository(db)
+	shippingUsecase := usecases.NewShippingUsecase(shippingRepo, cfg.DefaultCountry)
+	shippingHandler := handlers.NewShippingHandler(shippingUsecase)
+
+	cartRepo := repositories.NewCartRepository(db)
+	cartUsecase := usecases.NewCartUsecase(cartRepo, proRepo, pricingUsecase, promoUsecase, taxUsecase, shippingUsecase)
+	cartHandler := handlers.NewCartHandler(cartUsecase)
+
+	orderRepo := repositories.NewOrderRepository(db)
+	orderUsecase := usecases.NewOrderUsecase(orderRepo, cartRepo, pricingUsecase, promoUsecase, taxUsecase, shippingUsecase)
+	orderHandler := handlers.NewOrderHandler(orderUsecase)
+
+	shipmentRepo := repositories.NewShipmentRepository(db)
+	carriers := shipping.NewCarriers(shipping.NewFakeCarrier(cfg.FakeCarrierStep))
+	shipmentUsecase := usecases.NewShipmentUsecase(shipmentRepo, orderRepo, carriers, documents, cfg.TrackingInterval)
+	shipmentHandler := handlers.NewShipmentHandler(shipmentUsecase)
+	jobs.Every("poll shipment tracking", cfg.TrackingInterval, shipmentUsecase.PollTracking)
+
+	payments := payment.NewFakeProvider()
+
+	returnRepo := repositories.NewReturnRepository(db)
+	returnUsecase := usecases.NewReturnUsecase(returnRepo, orderRepo, payments, documents)
+	returnHandler := handlers.NewReturnHandler(returnUsecase, cfg.MaxUploadMB)
+
+	invoiceRepo := repositories.NewInvoiceRepository(db)
+	invoiceUsecase := usecases.NewInvoiceUsecase(invoiceRepo, orderRepo, documents, font, *cfg.InvoiceConfig)
+	invoiceHandler := handlers.NewInvoiceHandler(invoiceUsecase)
+
+	giftCardRepo := repositories.NewGiftCardRepository(db)
+	giftCardUsecase := usecases.NewGiftCardUsecase(giftCardRepo)
+	giftCardHandler := handlers.NewGiftCardHandler(giftCardUsecase)
+
+	creditRepo := repositories.NewStoreCreditRepository(db)
+	creditUsecase := usecases.NewStoreCreditUsecase(creditRepo)
+	creditHandler := handlers.NewStoreCreditHandler(creditUsecase)
+
+	historyRepo := repositories.NewPriceHistoryRepository(db)
+	historyUsecase := usecases.NewPriceHistoryUsecase(historyRepo)
+	historyHandler := handlers.NewPriceHistoryHandler(historyUsecase)
+	jobs.Every("apply scheduled prices", cfg.PriceInterval, historyUsecase.ApplyDue)
+
+	flashSaleRepo := repositories.NewFlashSaleRepository(db)
+	flashSaleGate := stockgate.New(cfg.FlashSaleSlots, cfg.FlashSaleQueueWait)
+	flashSaleUsecase := usecases.NewFlashSaleUsecase(flashSaleRepo, flashSaleGate)
+	flashSaleHandler := handlers.NewFlashSaleHandler(flashSaleUsecase)
+	jobs.Every("close ended flash sales", cfg.FlashSaleInterval, flashSaleUsecase.CloseDue)
+
 	return Storage{
-		User:     userHandler,
-		Category: catHandler,
-		Product:  proHandler,
+		User:         userHandler,
+		Category:     catHandler,
+		Product:      proHandler,
+		Media:        mediaHandler,
+		Attribute:    attrHandler,
+		Search:       searchHandler,
+		Synonym:      synonymHandler,
+		Translation:  transHandler,
+		Pricing:      pricingHandler,
+		Cart:         cartHandler,
+		Order:        orderHandler,
+		PriceHistory: historyHandler,
+		Promotion:    promoHandler,
+		FlashSale:    flashSaleHandler,
+		Tax:          taxHandler,
+		Shipping:     shippingHandler,
+		Shipment:     shipmentHandler,
+		Return:       returnHandler,
+		Invoice:      invoiceHandler,
+		GiftCard:     giftCardHandler,
+		StoreCredit:  creditHandler,
 	}
 }
@@ -0,0 +1,210 @@
+package usecases
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"slices"
+
+	"github.com/codepnw/react_go_ecom/internal/entities"
+	"github.com/codepnw/react_go_ecom/internal/errs"
+	"github.com/codepnw/react_go_ecom/internal/repositories"
+)
+
+var attributeCodeRegex = regexp.MustCompile(`^[a-z][a-z0-9_]{0,49}$`)
+
+type AttributeUsecase interface {
+	CreateAttribute(ctx context.Context, req *entities.AttributeReq) (*entities.Attribute, error)
+	ListAttributes(ctx context.Context) ([]*entities.Attribute, error)
+	DeleteAttribute(ctx context.Context, id int) error
+	AttachToCategory(ctx context.Context, categoryID int, req *entities.CategoryAttributeReq) ([]*entities.CategoryAttribute, error)
+	DetachFromCategory(ctx context.Context, categoryID, attributeID int) error
+	ListCategoryAttributes(ctx context.Context, categoryID int, locale string) ([]*entities.CategoryAttribute, error)
+	SetProductAttributes(ctx context.Context, productID string, values map[string]any) ([]*entities.ProductAttribute, error)
+	ListProductAttributes(ctx context.Context, productID, locale string) ([]*entities.ProductAttribute, error)
+}
+
+type attributeUsecase struct {
+	repo        repositories.AttributeRepository
+	productRepo repositories.ProductRepository
+}
+
+func NewAttributeUsecase(repo repositories.AttributeRepository, productRepo repositories.ProductRepository) AttributeUsecase {
+	return &attributeUsecase{
+		repo:        repo,
+		productRepo: productRepo,
+	}
+}
+
+func (uc *attributeUsecase) CreateAttribute(ctx context.Context, req *entities.AttributeReq) (*entities.Attribute, error) {
+	if !attributeCodeRegex.MatchString(req.Code) {
+		return nil, errs.Validation("invalid_attribute_code", "code must be lowercase letters, digits or underscore")
+	}
+
+	attr := &entities.Attribute{
+		Code:  req.Code,
+		Label: req.Label,
+		Type:  req.Type,
+	}
+
+	switch req.Type {
+	case entities.AttributeNumber:
+		attr.Unit = req.Unit
+	case entities.AttributeEnum:
+		if len(req.Options) == 0 {
+			return nil, errs.Validation("enum_options_required", "enum attribute needs at least one option")
+		}
+		attr.Options = req.Options
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, contextTimeoutQuery)
+	defer cancel()
+
+	if err := uc.repo.Create(ctx, attr); err != nil {
+		return nil, err
+	}
+
+	return attr, nil
+}
+
+func (uc *attributeUsecase) ListAttributes(ctx context.Context) ([]*entities.Attribute, error) {
+	ctx, cancel := context.WithTimeout(ctx, contextTimeoutQuery)
+	defer cancel()
+
+	return uc.repo.List(ctx)
+}
+
+func (uc *attributeUsecase) DeleteAttribute(ctx context.Context, id int) error {
+	ctx, cancel := context.WithTimeout(ctx, contextTimeoutQuery)
+	defer cancel()
+
+	return uc.repo.Delete(ctx, id)
+}
+
+func (uc *attributeUsecase) AttachToCategory(ctx context.Context, categoryID int, req *entities.CategoryAttributeReq) ([]*entities.CategoryAttribute, error) {
+	ctx, cancel := context.WithTimeout(ctx, contextTimeoutQuery)
+	defer cancel()
+
+	if err := uc.repo.AttachToCategory(ctx, categoryID, req); err != nil {
+		return nil, err
+	}
+
+	return uc.repo.ListByCategory(ctx, categoryID, "")
+}
+
+func (uc *attributeUsecase) DetachFromCategory(ctx context.Context, categoryID, attributeID int) error {
+	ctx, cancel := context.WithTimeout(ctx, contextTimeoutQuery)
+	defer cancel()
+
+	return uc.repo.DetachFromCategory(ctx, categoryID, attributeID)
+}
+
+func (uc *attributeUsecase) ListCategoryAttributes(ctx context.Context, categoryID int, locale string) ([]*entities.CategoryAttribute, error) {
+	ctx, cancel := context.WithTimeout(ctx, contextTimeoutQuery)
+	defer cancel()
+
+	return uc.repo.ListByCategory(ctx, categoryID, locale)
+}
+
+// SetProductAttributes validates values against the attributes attached to
+// the product's category and replaces the stored values.
+func (uc *attributeUsecase) SetProductAttributes(ctx context.Context, productID string, values map[string]any) ([]*entities.ProductAttribute, error) {
+	ctx, cancel := context.WithTimeout(ctx, contextTimeoutQuery)
+	defer cancel()
+
+	product, err := uc.productRepo.GetByID(ctx, productID, "")
+	if err != nil {
+		return nil, err
+	}
+
+	if product.CategoryID == nil {
+		return nil, errs.Validation("product_without_category", "product has no category")
+	}
+
+	definitions, err := uc.repo.ListByCategory(ctx, *product.CategoryID, "")
+	if err != nil {
+		return nil, err
+	}
+
+	byCode := make(map[string]*entities.CategoryAttribute, len(definitions))
+	for _, def := range definitions {
+		byCode[def.Code] = def
+	}
+
+	var attrs []*entities.ProductAttribute
+	for code, value := range values {
+		def, ok := byCode[code]
+		if !ok {
+			return nil, errs.Validation("attribute_not_in_category", "attribute %s is not available for this category", code)
+		}
+
+		if value == nil {
+			continue
+		}
+
+		if err := validateAttributeValue(def, value); err != nil {
+			return nil, err
+		}
+
+		attrs = append(attrs, &entities.ProductAttribute{
+			ProductID:   productID,
+			AttributeID: def.ID,
+			Value:       value,
+		})
+	}
+
+	for _, def := range definitions {
+		if def.Required && values[def.Code] == nil {
+			return nil, errs.Validation("attribute_required", "attribute %s is required", def.Code)
+		}
+	}
+
+	if err := uc.repo.ReplaceProductValues(ctx, productID, attrs); err != nil {
+		return nil, err
+	}
+
+	return uc.ListProductAttributes(ctx, productID, "")
+}
+
+func (uc *attributeUsecase) ListProductAttributes(ctx context.Context, productID, locale string) ([]*entities.ProductAttribute, error) {
+	ctx, cancel := context.WithTimeout(ctx, contextTimeoutQuery)
+	defer cancel()
+
+	values, err := uc.repo.ListValuesByProducts(ctx, []string{productID}, locale)
+	if err != nil {
+		return nil, err
+	}
+
+	if values[productID] == nil {
+		return []*entities.ProductAttribute{}, nil
+	}
+
+	return values[productID], nil
+}
+
+// validateAttributeValue checks a decoded JSON value against its definition
+func validateAttributeValue(def *entities.CategoryAttribute, value any) error {
+	switch def.Type {
+	case entities.AttributeText:
+		if s, ok := value.(string); !ok || s == "" {
+			return errs.Validation("invalid_attribute_text", "attribute %s must be a non-empty string", def.Code)
+		}
+	case entities.AttributeNumber:
+		if _, ok := value.(float64); !ok {
+			return errs.Validation("invalid_attribute_number", "attribute %s must be a number", def.Code)
+		}
+	case entities.AttributeBoolean:
+		if _, ok := value.(bool); !ok {
+			return errs.Validation("invalid_attribute_boolean", "attribute %s must be true or false", def.Code)
+		}
+	case entities.AttributeEnum:
+		s, ok := value.(string)
+		if !ok || !slices.Contains(def.Options, s) {
+			return errs.Validation("invalid_attribute_option", "attribute %s must be one of %v", def.Code, def.Options)
+		}
+	default:
+		return fmt.Errorf("attribute %s has unknown type %s", def.Code, def.Type)
+	}
+
+	return nil
+}
@@ -0,0 +1,174 @@
+package usecases
+
+import (
+	"context"
+
+	"github.com/codepnw/react_go_ecom/internal/entities"
+	"github.com/codepnw/react_go_ecom/internal/errs"
+	"github.com/codepnw/react_go_ecom/internal/repositories"
+	"github.com/codepnw/react_go_ecom/pkg/money"
+)
+
+type CartUsecase interface {
+	Get(ctx context.Context, userID string, q *entities.CartQuery) (*entities.Cart, error)
+	QuoteShipping(ctx context.Context, userID string, q *entities.CartQuery) (*entities.ShippingQuotes, error)
+	AddItem(ctx context.Context, userID string, req *entities.CartItemReq) error
+	UpdateItem(ctx context.Context, userID, productID string, req *entities.CartItemQuantityReq) error
+	RemoveItem(ctx context.Context, userID, productID string) error
+}
+
+type cartUsecase struct {
+	repo        repositories.CartRepository
+	productRepo repositories.ProductRepository
+	pricer      Pricer
+	discounter  Discounter
+	taxer       Taxer
+	shipper     Shipper
+}
+
+func NewCartUsecase(repo repositories.CartRepository, productRepo repositories.ProductRepository, pricer Pricer, discounter Discounter, taxer Taxer, shipper Shipper) CartUsecase {
+	return &cartUsecase{
+		repo:        repo,
+		productRepo: productRepo,
+		pricer:      pricer,
+		discounter:  discounter,
+		taxer:       taxer,
+		shipper:     shipper,
+	}
+}
+
+// Get prices the cart in the query currency with the automatic promotions
+// and the entered coupons applied, taxed for the query destination and
+// with the chosen shipping method charged
+func (uc *cartUsecase) Get(ctx context.Context, userID string, q *entities.CartQuery) (*entities.Cart, error) {
+	ctx, cancel := context.WithTimeout(ctx, contextTimeoutQuery)
+	defer cancel()
+
+	cart, rate, err := uc.discountedCart(ctx, userID, q)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := uc.taxer.ApplyTaxes(ctx, cart, q.Country, q.Region); err != nil {
+		return nil, err
+	}
+
+	if q.ShippingMethodID != nil {
+		err := uc.shipper.ApplyShipping(ctx, cart, rate, q.Country, q.Region, *q.ShippingMethodID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return cart, nil
+}
+
+// QuoteShipping lists the shipping methods able to ship the cart to the
+// query destination with their cost
+func (uc *cartUsecase) QuoteShipping(ctx context.Context, userID string, q *entities.CartQuery) (*entities.ShippingQuotes, error) {
+	ctx, cancel := context.WithTimeout(ctx, contextTimeoutQuery)
+	defer cancel()
+
+	cart, rate, err := uc.discountedCart(ctx, userID, q)
+	if err != nil {
+		return nil, err
+	}
+
+	return uc.shipper.QuoteShipping(ctx, cart, rate, q.Country, q.Region)
+}
+
+func (uc *cartUsecase) discountedCart(ctx context.Context, userID string, q *entities.CartQuery) (*entities.Cart, *entities.ExchangeRate, error) {
+	currency, err := parseCurrency(q.Currency)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	items, err := uc.repo.ListItems(ctx, userID, q.Locale)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cart, rate, err := priceCart(ctx, uc.pricer, currency, items)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := uc.discounter.ApplyPromotions(ctx, userID, cart, q.Coupons); err != nil {
+		return nil, nil, err
+	}
+
+	return cart, rate, nil
+}
+
+func (uc *cartUsecase) AddItem(ctx context.Context, userID string, req *entities.CartItemReq) error {
+	ctx, cancel := context.WithTimeout(ctx, contextTimeoutQuery)
+	defer cancel()
+
+	// Tells a missing product apart from one without enough stock
+	if _, err := uc.productRepo.GetByID(ctx, req.ProductID, ""); err != nil {
+		return err
+	}
+
+	return uc.repo.AddItem(ctx, userID, req.ProductID, req.Quantity)
+}
+
+// UpdateItem sets the quantity of an item already in the cart. The stock
+// may still run out before checkout, which takes it for good.
+func (uc *cartUsecase) UpdateItem(ctx context.Context, userID, productID string, req *entities.CartItemQuantityReq) error {
+	ctx, cancel := context.WithTimeout(ctx, contextTimeoutQuery)
+	defer cancel()
+
+	product, err := uc.productRepo.GetByID(ctx, productID, "")
+	if err != nil {
+		return err
+	}
+
+	if product.Stock < req.Quantity {
+		return errs.InsufficientStock("insufficient_stock", "not enough stock")
+	}
+
+	return uc.repo.SetQuantity(ctx, userID, productID, req.Quantity)
+}
+
+func (uc *cartUsecase) RemoveItem(ctx context.Context, userID, productID string) error {
+	ctx, cancel := context.WithTimeout(ctx, contextTimeoutQuery)
+	defer cancel()
+
+	return uc.repo.RemoveItem(ctx, userID, productID)
+}
+
+// priceCart replaces the base unit prices of the items with prices in
+// currency and totals them. The rate is the current one of currency even
+// when explicit prices covered every item. It is nil for the base currency,
+// an empty cart, or when no rate exists and only explicit prices were used.
+func priceCart(ctx context.Context, pricer Pricer, currency string, items []*entities.CartItem) (*entities.Cart, *entities.ExchangeRate, error) {
+	base := make(map[string]money.Money, len(items))
+	for _, item := range items {
+		base[item.ProductID] = item.UnitPrice
+	}
+
+	prices, rate, err := pricer.Prices(ctx, currency, base)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cart := &entities.Cart{
+		Currency:      currency,
+		Items:         items,
+		Subtotal:      money.New(0, currency),
+		ShippingTotal: money.New(0, currency),
+	}
+	cart.Total = cart.Subtotal
+	for _, item := range items {
+		item.UnitPrice = prices[item.ProductID]
+		if item.LineTotal, err = item.UnitPrice.Times(int64(item.Quantity)); err != nil {
+			return nil, nil, errs.Validation("amount_too_large", "the total is too large").Wrap(err)
+		}
+
+		cart.Subtotal = cart.Subtotal.Add(item.LineTotal)
+		cart.ItemCount += item.Quantity
+		cart.WeightGrams += item.WeightGrams * item.Quantity
+	}
+
+	return cart, rate, nil
+}
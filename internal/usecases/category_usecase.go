@@ -2,42 +2,119 @@ package usecases
 
 import (
 	"context"
+	"strings"
 
 	"github.com/codepnw/react_go_ecom/internal/entities"
+	"github.com/codepnw/react_go_ecom/internal/errs"
 	"github.com/codepnw/react_go_ecom/internal/repositories"
+	"github.com/codepnw/react_go_ecom/pkg/pagination"
 )
 
 type CategoryUsecase interface {
 	CreateCategory(ctx context.Context, title string) error
-	ListCategory(ctx context.Context) ([]*entities.Category, error)
+	ListCategory(ctx context.Context, f *entities.CategoryFilter) (*entities.CategoryListResult, error)
 	DeleteCategory(ctx context.Context, id int) error
 }
 
 type categoryUsecase struct {
-	repo repositories.CategoryRepo
+	repo    repositories.CategoryRepo
+	indexer SuggestIndexer
 }
 
-func NewCategoryUsecase(repo repositories.CategoryRepo) CategoryUsecase {
-	return &categoryUsecase{repo: repo}
+func NewCategoryUsecase(repo repositories.CategoryRepo, indexer SuggestIndexer) CategoryUsecase {
+	return &categoryUsecase{
+		repo:    repo,
+		indexer: indexer,
+	}
 }
 
 func (uc *categoryUsecase) CreateCategory(ctx context.Context, title string) error {
 	ctx, cancel := context.WithTimeout(ctx, contextTimeoutQuery)
 	defer cancel()
 
-	return uc.repo.Create(ctx, title)
+	id, err := uc.repo.Create(ctx, title)
+	if err != nil {
+		return err
+	}
+	uc.indexer.CategoryChanged(id, title)
+
+	return nil
 }
 
-func (uc *categoryUsecase) ListCategory(ctx context.Context) ([]*entities.Category, error) {
+func (uc *categoryUsecase) ListCategory(ctx context.Context, f *entities.CategoryFilter) (*entities.CategoryListResult, error) {
 	ctx, cancel := context.WithTimeout(ctx, contextTimeoutQuery)
 	defer cancel()
 
-	return uc.repo.List(ctx)
+	f.Limit = pageLimit(f.Limit)
+
+	sortKeys, err := parseCategorySort(f.Sort)
+	if err != nil {
+		return nil, err
+	}
+	f.SortKeys = sortKeys
+
+	if f.After, err = decodeCursor(&f.PageReq, sortKeys); err != nil {
+		return nil, err
+	}
+
+	categories, err := uc.repo.List(ctx, f)
+	if err != nil {
+		return nil, err
+	}
+
+	categories, more := pagination.Trim(categories, f.Limit, f.After != nil && f.After.Backward)
+
+	var first, last []string
+	if len(categories) > 0 {
+		first, last = categories[0].SortValues, categories[len(categories)-1].SortValues
+	}
+
+	result := &entities.CategoryListResult{
+		Items:    categories,
+		PageInfo: pageInfo(f.Limit, f.After, more, sortKeys, "", first, last),
+	}
+
+	if f.WithTotal {
+		total, err := uc.repo.Count(ctx)
+		if err != nil {
+			return nil, err
+		}
+		result.PageInfo.Total = &total
+	}
+
+	return result, nil
+}
+
+// parseCategorySort accepts title and created_at with an optional leading
+// "-", categories are listed by id otherwise
+func parseCategorySort(spec string) ([]*entities.SortKey, error) {
+	var keys []*entities.SortKey
+
+	for _, field := range strings.Split(spec, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+
+		key := &entities.SortKey{Field: strings.TrimPrefix(field, "-"), Desc: strings.HasPrefix(field, "-")}
+		if key.Field != "title" && key.Field != "created_at" {
+			return nil, errs.Validation("invalid_sort", "cannot sort by %s", key.Field)
+		}
+
+		keys = append(keys, key)
+	}
+
+	return keys, nil
 }
 
 func (uc *categoryUsecase) DeleteCategory(ctx context.Context, id int) error {
 	ctx, cancel := context.WithTimeout(ctx, contextTimeoutQuery)
 	defer cancel()
 
-	return uc.repo.Delete(ctx, id)
+	if err := uc.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+	uc.indexer.CategoryRemoved(id)
+
+	return nil
 }
@@ -3,3 +3,8 @@ package usecases
 import "time"
 
 const contextTimeoutQuery = 5 * time.Second
+
+const (
+	defaultListLimit = 10
+	maxListLimit     = 100
+)
@@ -0,0 +1,168 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/codepnw/react_go_ecom/internal/entities"
+	"github.com/codepnw/react_go_ecom/internal/errs"
+	"github.com/codepnw/react_go_ecom/internal/repositories"
+	"github.com/codepnw/react_go_ecom/pkg/money"
+	"github.com/codepnw/react_go_ecom/pkg/stockgate"
+)
+
+// Ended sales closed per transaction
+const flashSaleBatchSize = 100
+
+var errFlashSaleSoldOut = errs.InsufficientStock("flash_sale_sold_out", "flash sale is sold out")
+
+type FlashSaleUsecase interface {
+	CreateFlashSale(ctx context.Context, req *entities.FlashSaleReq) (*entities.FlashSale, error)
+	ListFlashSales(ctx context.Context, f *entities.FlashSaleFilter) ([]*entities.FlashSale, error)
+	GetFlashSale(ctx context.Context, id int) (*entities.FlashSale, error)
+	CloseFlashSale(ctx context.Context, id int) error
+	Purchase(ctx context.Context, userID string, id int, req *entities.FlashSalePurchaseReq) (*entities.FlashSalePurchase, error)
+	CloseDue(ctx context.Context) error
+}
+
+type flashSaleUsecase struct {
+	repo repositories.FlashSaleRepository
+	gate *stockgate.Gate
+}
+
+func NewFlashSaleUsecase(repo repositories.FlashSaleRepository, gate *stockgate.Gate) FlashSaleUsecase {
+	return &flashSaleUsecase{
+		repo: repo,
+		gate: gate,
+	}
+}
+
+func (uc *flashSaleUsecase) CreateFlashSale(ctx context.Context, req *entities.FlashSaleReq) (*entities.FlashSale, error) {
+	if req.SalePrice.Amount <= 0 {
+		return nil, errs.Validation("invalid_price", "price must be greater than zero")
+	}
+
+	if req.SalePrice.Currency != money.DefaultCurrency {
+		return nil, errs.Validation("flash_sale_currency", "sale price must be in %s", money.DefaultCurrency)
+	}
+
+	if !req.EndsAt.After(req.StartsAt) || !req.EndsAt.After(time.Now()) {
+		return nil, errs.Validation("invalid_flash_sale_window", "ends_at must be after starts_at and in the future")
+	}
+
+	sale := &entities.FlashSale{
+		Name:             req.Name,
+		ProductID:        req.ProductID,
+		SalePrice:        req.SalePrice,
+		Allocation:       req.Allocation,
+		PerCustomerLimit: req.PerCustomerLimit,
+		StartsAt:         req.StartsAt,
+		EndsAt:           req.EndsAt,
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, contextTimeoutQuery)
+	defer cancel()
+
+	if err := uc.repo.Create(ctx, sale); err != nil {
+		return nil, err
+	}
+
+	return sale, nil
+}
+
+func (uc *flashSaleUsecase) ListFlashSales(ctx context.Context, f *entities.FlashSaleFilter) ([]*entities.FlashSale, error) {
+	ctx, cancel := context.WithTimeout(ctx, contextTimeoutQuery)
+	defer cancel()
+
+	return uc.repo.List(ctx, f)
+}
+
+func (uc *flashSaleUsecase) GetFlashSale(ctx context.Context, id int) (*entities.FlashSale, error) {
+	ctx, cancel := context.WithTimeout(ctx, contextTimeoutQuery)
+	defer cancel()
+
+	return uc.repo.GetByID(ctx, id)
+}
+
+func (uc *flashSaleUsecase) CloseFlashSale(ctx context.Context, id int) error {
+	ctx, cancel := context.WithTimeout(ctx, contextTimeoutQuery)
+	defer cancel()
+
+	if err := uc.repo.Close(ctx, id, time.Now()); err != nil {
+		return err
+	}
+	uc.gate.Forget(id)
+
+	return nil
+}
+
+// Purchase goes through the gate before the database: buyers of a sold
+// out sale are turned away without a query and at most a fixed number of
+// purchase transactions wait on the sale row, the rest are shed with a
+// retryable error instead of piling up on the connection pool.
+func (uc *flashSaleUsecase) Purchase(ctx context.Context, userID string, id int, req *entities.FlashSalePurchaseReq) (*entities.FlashSalePurchase, error) {
+	ctx, cancel := context.WithTimeout(ctx, contextTimeoutQuery)
+	defer cancel()
+
+	if !uc.gate.Known(id) {
+		sale, err := uc.repo.GetByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		uc.gate.Seed(id, int64(sale.Remaining))
+	}
+
+	ticket, err := uc.gate.Enter(ctx, id, int64(req.Quantity))
+	switch {
+	case errors.Is(err, stockgate.ErrSoldOut):
+		if left := uc.gate.Remaining(id); left > 0 {
+			return nil, errs.InsufficientStock("flash_sale_insufficient", "only %d left in this flash sale", left)
+		}
+		return nil, errFlashSaleSoldOut
+	case errors.Is(err, stockgate.ErrBusy):
+		return nil, errs.TooManyRequests("flash_sale_busy", "too many buyers right now, try again shortly")
+	case err != nil:
+		return nil, err
+	}
+
+	purchase := &entities.FlashSalePurchase{
+		FlashSaleID: id,
+		UserID:      userID,
+		Quantity:    req.Quantity,
+	}
+
+	if err := uc.repo.Reserve(ctx, purchase, time.Now()); err != nil {
+		ticket.Cancel()
+		if errors.Is(err, errFlashSaleSoldOut) {
+			uc.gate.SoldOut(id)
+		}
+		return nil, err
+	}
+	ticket.Commit()
+
+	return purchase, nil
+}
+
+// CloseDue is the scheduler job, it returns the unsold units of ended
+// sales to their products
+func (uc *flashSaleUsecase) CloseDue(ctx context.Context) error {
+	now := time.Now()
+
+	for {
+		closed, err := uc.repo.CloseDue(ctx, now, flashSaleBatchSize)
+		if err != nil {
+			return err
+		}
+
+		for _, s := range closed {
+			uc.gate.Forget(s.ID)
+			log.Printf("closed flash sale %d: sold %d of %d", s.ID, s.Sold, s.Allocation)
+		}
+
+		if len(closed) < flashSaleBatchSize {
+			return nil
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package usecases
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/codepnw/react_go_ecom/internal/entities"
+	"github.com/codepnw/react_go_ecom/internal/errs"
+	"github.com/codepnw/react_go_ecom/internal/repositories"
+	"github.com/codepnw/react_go_ecom/pkg/money"
+)
+
+// Generated gift card codes leave out characters that read alike
+const (
+	giftCodeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+	giftCodeLength   = 16
+)
+
+var errGiftCardCodeTaken = errs.Conflict("gift_card_code_taken", "gift card code already exists")
+
+type GiftCardUsecase interface {
+	IssueGiftCard(ctx context.Context, req *entities.GiftCardReq) (*entities.GiftCard, error)
+	ListGiftCards(ctx context.Context, f *entities.GiftCardFilter) ([]*entities.GiftCard, error)
+	GetGiftCard(ctx context.Context, id int64) (*entities.GiftCard, error)
+	AdjustGiftCard(ctx context.Context, id int64, req *entities.GiftCardAdjustReq) (*entities.GiftCard, error)
+}
+
+type giftCardUsecase struct {
+	repo repositories.GiftCardRepository
+}
+
+func NewGiftCardUsecase(repo repositories.GiftCardRepository) GiftCardUsecase {
+	return &giftCardUsecase{repo: repo}
+}
+
+// IssueGiftCard creates a card holding the amount, a generated code is
+// tried again in the unlikely case it is taken
+func (uc *giftCardUsecase) IssueGiftCard(ctx context.Context, req *entities.GiftCardReq) (*entities.GiftCard, error) {
+	if !money.Known(req.Amount.Currency) {
+		return nil, errs.Validation("unknown_currency", "currency is not supported")
+	}
+	if req.Amount.Amount <= 0 {
+		return nil, errs.Validation("invalid_gift_card_amount", "gift card amount must be greater than zero")
+	}
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		return nil, errs.Validation("invalid_gift_card_expiry", "expires_at must be in the future")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, contextTimeoutQuery)
+	defer cancel()
+
+	card := &entities.GiftCard{
+		Currency:      req.Amount.Currency,
+		InitialAmount: req.Amount,
+		ExpiresAt:     req.ExpiresAt,
+		Note:          req.Note,
+	}
+
+	for attempt := 0; ; attempt++ {
+		card.Code = normalizeGiftCode(req.Code)
+		if req.Code == "" {
+			code, err := newGiftCode()
+			if err != nil {
+				return nil, err
+			}
+			card.Code = code
+		}
+
+		err := uc.repo.Create(ctx, card)
+		if err == nil {
+			return card, nil
+		}
+		if req.Code != "" || attempt == 2 || !errors.Is(err, errGiftCardCodeTaken) {
+			return nil, err
+		}
+	}
+}
+
+func (uc *giftCardUsecase) ListGiftCards(ctx context.Context, f *entities.GiftCardFilter) ([]*entities.GiftCard, error) {
+	ctx, cancel := context.WithTimeout(ctx, contextTimeoutQuery)
+	defer cancel()
+
+	f.Code = normalizeGiftCode(f.Code)
+	return uc.repo.List(ctx, f)
+}
+
+func (uc *giftCardUsecase) GetGiftCard(ctx context.Context, id int64) (*entities.GiftCard, error) {
+	ctx, cancel := context.WithTimeout(ctx, contextTimeoutQuery)
+	defer cancel()
+
+	return uc.repo.GetByID(ctx, id)
+}
+
+func (uc *giftCardUsecase) AdjustGiftCard(ctx context.Context, id int64, req *entities.GiftCardAdjustReq) (*entities.GiftCard, error) {
+	if req.Amount.IsZero() {
+		return nil, errs.Validation("invalid_adjustment", "adjustment amount must not be zero")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, contextTimeoutQuery)
+	defer cancel()
+
+	if err := uc.repo.Adjust(ctx, id, req.Amount, req.Note); err != nil {
+		return nil, err
+	}
+
+	return uc.repo.GetByID(ctx, id)
+}
+
+// normalizeGiftCode accepts a code as printed, in any case and with the
+// dashes or spaces that group it
+func normalizeGiftCode(code string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '-' || r == ' ' {
+			return -1
+		}
+		return r
+	}, strings.ToUpper(strings.TrimSpace(code)))
+}
+
+func newGiftCode() (string, error) {
+	b := make([]byte, giftCodeLength)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	// The alphabet has 32 characters, so every byte maps without bias
+	for i := range b {
+		b[i] = giftCodeAlphabet[int(b[i])%len(giftCodeAlphabet)]
+	}
+	return string(b), nil
+}
@@ -0,0 +1,45 @@
+package usecases
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNormalizeGiftCode(t *testing.T) {
+	for in, want := range map[string]string{
+		"abcd-efgh-jkmn-pqrs":   "ABCDEFGHJKMNPQRS",
+		" ABCD EFGH JKMN PQRS ": "ABCDEFGHJKMNPQRS",
+		"abcdefghjkmnpqrs":      "ABCDEFGHJKMNPQRS",
+	} {
+		if got := normalizeGiftCode(in); got != want {
+			t.Errorf("normalizeGiftCode(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestNewGiftCode(t *testing.T) {
+	seen := make(map[string]bool)
+	for i := 0; i < 100; i++ {
+		code, err := newGiftCode()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(code) != giftCodeLength {
+			t.Fatalf("code %q has %d characters, want %d", code, len(code), giftCodeLength)
+		}
+		for _, r := range code {
+			if !strings.ContainsRune(giftCodeAlphabet, r) {
+				t.Fatalf("code %q has %q outside the alphabet", code, r)
+			}
+		}
+		if seen[code] {
+			t.Fatalf("code %q issued twice", code)
+		}
+		seen[code] = true
+
+		// A code as printed reads back as itself
+		if normalizeGiftCode(strings.ToLower(code[:8])+"-"+code[8:]) != code {
+			t.Fatalf("code %q does not survive normalizing", code)
+		}
+	}
+}
@@ -0,0 +1,224 @@
+package usecases
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/codepnw/react_go_ecom/internal/entities"
+	"github.com/codepnw/react_go_ecom/pkg/pdf"
+)
+
+// Page layout in points from the top left corner
+const (
+	invoiceMargin   = 50.0
+	invoiceBottom   = pdf.PageHeight - 60
+	invoiceRowSize  = 9.0
+	invoiceRowSpace = 14.0
+)
+
+// Right edges of the line table columns, the description fills the rest
+const (
+	columnQuantity = 330.0
+	columnUnit     = 405.0
+	columnTax      = 465.0
+	columnAmount   = pdf.PageWidth - invoiceMargin
+)
+
+var invoiceTitles = map[string][2]string{
+	entities.InvoiceKindInvoice:    {"Tax Invoice", "ใบกำกับภาษี"},
+	entities.InvoiceKindCreditNote: {"Credit Note", "ใบลดหนี้"},
+}
+
+// invoiceLayout writes one document, starting new pages as the lines need
+type invoiceLayout struct {
+	doc  *pdf.Document
+	page *pdf.Page
+	y    float64
+}
+
+// renderInvoice lays out a tax invoice or a credit note, reference is the
+// number of the invoice a credit note is against. The Thai titles are
+// printed only with an embedded font, Helvetica has no Thai glyphs.
+func renderInvoice(inv *entities.Invoice, reference string, font *pdf.Font) ([]byte, error) {
+	l := &invoiceLayout{doc: pdf.New(font)}
+	l.page = l.doc.AddPage()
+
+	title := invoiceTitles[inv.Kind][0]
+	if font != nil {
+		title += " / " + invoiceTitles[inv.Kind][1]
+	}
+
+	// Seller on the left, the document on the right
+	l.y = 60
+	l.page.Text(invoiceMargin, l.y, 14, true, inv.Seller.Name)
+	top := l.y
+	l.y += 16
+	l.block(inv.Seller, 9, 280)
+
+	right := columnAmount
+	l.page.TextRight(right, top, 14, true, title)
+	details := [][2]string{
+		{"No.", inv.Number},
+		{"Date", inv.IssuedAt.In(invoiceZone).Format("02 Jan 2006")},
+		{"Order", fmt.Sprintf("#%d", inv.OrderID)},
+	}
+	if reference != "" {
+		details = append(details, [2]string{"Invoice", reference})
+	}
+	dy := top + 16
+	for _, d := range details {
+		l.page.TextRight(right-90, dy, 9, false, d[0])
+		l.page.TextRight(right, dy, 9, false, d[1])
+		dy += 12
+	}
+	l.y = max(l.y, dy) + 14
+
+	l.page.Text(invoiceMargin, l.y, 10, true, "Bill to")
+	l.y += 14
+	l.page.Text(invoiceMargin, l.y, 9, true, inv.Buyer.Name)
+	l.y += 12
+	l.block(inv.Buyer, 9, 300)
+
+	if inv.Reason != "" {
+		l.y += 6
+		for _, line := range l.wrap("Reason: "+inv.Reason, 9, columnAmount-invoiceMargin) {
+			l.page.Text(invoiceMargin, l.y, 9, false, line)
+			l.y += 12
+		}
+	}
+
+	l.y += 10
+	l.header(inv.Currency)
+	for _, line := range inv.Lines {
+		l.row(line)
+	}
+
+	l.totals(inv)
+
+	return l.doc.Bytes()
+}
+
+// block prints the address and tax registration of a party
+func (l *invoiceLayout) block(party entities.Party, size, width float64) {
+	for _, line := range l.wrap(party.Address, size, width) {
+		l.page.Text(invoiceMargin, l.y, size, false, line)
+		l.y += size + 3
+	}
+
+	if party.TaxID != "" {
+		registration := "Tax ID " + party.TaxID
+		switch party.Branch {
+		case "":
+		case headOffice:
+			registration += ", Head office"
+		default:
+			registration += ", Branch " + party.Branch
+		}
+		l.page.Text(invoiceMargin, l.y, size, false, registration)
+		l.y += size + 3
+	}
+}
+
+func (l *invoiceLayout) header(currency string) {
+	l.page.Box(invoiceMargin, l.y-11, columnAmount-invoiceMargin, 16, 0.9)
+	l.page.Text(invoiceMargin+4, l.y, invoiceRowSize, true, "Description")
+	l.page.TextRight(columnQuantity, l.y, invoiceRowSize, true, "Qty")
+	l.page.TextRight(columnUnit, l.y, invoiceRowSize, true, "Unit price")
+	l.page.TextRight(columnTax, l.y, invoiceRowSize, true, "Tax")
+	l.page.TextRight(columnAmount-4, l.y, invoiceRowSize, true, "Amount "+currency)
+	l.y += invoiceRowSpace + 4
+}
+
+// row prints one line, a long description wraps and a line that does not
+// fit starts a new page with the table header
+func (l *invoiceLayout) row(line *entities.InvoiceLine) {
+	description := l.wrap(line.Description, invoiceRowSize, columnQuantity-invoiceMargin-40)
+	if l.y+float64(len(description)-1)*12 > invoiceBottom {
+		l.page = l.doc.AddPage()
+		l.y = 60
+		l.header(line.Amount.Currency)
+	}
+
+	tax := "-"
+	if line.TaxRate != nil {
+		tax = formatRate(*line.TaxRate) + "%"
+	}
+
+	l.page.TextRight(columnQuantity, l.y, invoiceRowSize, false, fmt.Sprint(line.Quantity))
+	l.page.TextRight(columnUnit, l.y, invoiceRowSize, false, line.UnitPrice.Decimal())
+	l.page.TextRight(columnTax, l.y, invoiceRowSize, false, tax)
+	l.page.TextRight(columnAmount-4, l.y, invoiceRowSize, false, line.Amount.Decimal())
+	for i, text := range description {
+		if i > 0 {
+			l.y += 12
+		}
+		l.page.Text(invoiceMargin+4, l.y, invoiceRowSize, false, text)
+	}
+	l.y += invoiceRowSpace
+}
+
+// totals prints the tax breakdown and the totals under the lines
+func (l *invoiceLayout) totals(inv *entities.Invoice) {
+	rows := 3 + len(inv.Taxes)
+	if l.y+float64(rows)*14+10 > invoiceBottom {
+		l.page = l.doc.AddPage()
+		l.y = 60
+	}
+
+	l.page.Line(invoiceMargin, l.y-8, columnAmount, l.y-8, 0.5)
+	l.y += 6
+
+	label := columnAmount - 110
+	l.page.TextRight(label, l.y, 9, false, "Subtotal")
+	l.page.TextRight(columnAmount-4, l.y, 9, false, inv.Subtotal.Decimal())
+	l.y += 14
+
+	for _, t := range inv.Taxes {
+		text := fmt.Sprintf("%s %s%% on %s", t.Name, formatRate(t.Rate), t.Taxable.Decimal())
+		l.page.TextRight(label, l.y, 9, false, text)
+		l.page.TextRight(columnAmount-4, l.y, 9, false, t.Amount.Decimal())
+		l.y += 14
+	}
+
+	l.page.Line(label-120, l.y-8, columnAmount, l.y-8, 0.5)
+	l.y += 4
+	l.page.TextRight(label, l.y, 10, true, "Total "+inv.Currency)
+	l.page.TextRight(columnAmount-4, l.y, 10, true, inv.Total.Decimal())
+}
+
+// wrap breaks s into lines no wider than width, at spaces where it can.
+// Words longer than a line, and Thai which has no spaces, break anywhere.
+func (l *invoiceLayout) wrap(s string, size, width float64) []string {
+	var lines []string
+
+	for _, paragraph := range strings.Split(s, "\n") {
+		var line string
+		for _, word := range strings.Fields(paragraph) {
+			candidate := word
+			if line != "" {
+				candidate = line + " " + word
+			}
+			if l.doc.TextWidth(candidate, size) <= width {
+				line = candidate
+				continue
+			}
+
+			if line != "" {
+				lines = append(lines, line)
+			}
+			line = ""
+			for _, r := range word {
+				if line != "" && l.doc.TextWidth(line+string(r), size) > width {
+					lines = append(lines, line)
+					line = ""
+				}
+				line += string(r)
+			}
+		}
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+
+	return lines
+}
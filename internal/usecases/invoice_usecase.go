@@ -0,0 +1,537 @@
+package usecases
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/codepnw/react_go_ecom/config"
+	"github.com/codepnw/react_go_ecom/internal/entities"
+	"github.com/codepnw/react_go_ecom/internal/errs"
+	"github.com/codepnw/react_go_ecom/internal/repositories"
+	"github.com/codepnw/react_go_ecom/pkg/media"
+	"github.com/codepnw/react_go_ecom/pkg/money"
+	"github.com/codepnw/react_go_ecom/pkg/pdf"
+)
+
+// Documents are dated and numbered in Thai time
+var invoiceZone = time.FixedZone("ICT", 7*60*60)
+
+// Branch printed for a buyer with a tax ID and no branch, the head office
+const headOffice = "00000"
+
+var errInvoiceNotFound = errs.NotFound("invoice_not_found", "invoice not found")
+
+type InvoiceUsecase interface {
+	IssueInvoice(ctx context.Context, userID string, orderID int64, req *entities.InvoiceReq) (*entities.Invoice, error)
+	ListOrderInvoices(ctx context.Context, userID string, orderID int64) ([]*entities.Invoice, error)
+	OrderInvoicePDF(ctx context.Context, userID string, orderID, id int64) (*entities.Invoice, []byte, error)
+	ListInvoices(ctx context.Context, f *entities.InvoiceFilter) ([]*entities.Invoice, error)
+	GetInvoice(ctx context.Context, userID string, id int64) (*entities.Invoice, error)
+	InvoicePDF(ctx context.Context, userID string, id int64) (*entities.Invoice, []byte, error)
+	IssueCreditNote(ctx context.Context, invoiceID int64, req *entities.CreditNoteReq) (*entities.Invoice, error)
+}
+
+type invoiceUsecase struct {
+	repo      repositories.InvoiceRepository
+	orderRepo repositories.OrderRepository
+	documents media.BlobStore
+	font      *pdf.Font
+	cfg       config.InvoiceConfig
+}
+
+// NewInvoiceUsecase keeps the PDFs in documents, a store that is not
+// served publicly, as they carry the buyer's tax ID and address
+func NewInvoiceUsecase(repo repositories.InvoiceRepository, orderRepo repositories.OrderRepository, documents media.BlobStore, font *pdf.Font, cfg config.InvoiceConfig) InvoiceUsecase {
+	return &invoiceUsecase{
+		repo:      repo,
+		orderRepo: orderRepo,
+		documents: documents,
+		font:      font,
+		cfg:       cfg,
+	}
+}
+
+// IssueInvoice issues the tax invoice of a paid order, an order has one
+// and asking again returns it
+func (uc *invoiceUsecase) IssueInvoice(ctx context.Context, userID string, orderID int64, req *entities.InvoiceReq) (*entities.Invoice, error) {
+	ctx, cancel := context.WithTimeout(ctx, contextTimeoutQuery)
+	defer cancel()
+
+	order, err := uc.ownOrder(ctx, userID, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := uc.repo.GetByOrder(ctx, orderID)
+	if err == nil {
+		summarizeTaxes(existing)
+		return existing, nil
+	}
+	if !errors.Is(err, errs.ErrNotFound) {
+		return nil, err
+	}
+
+	switch order.Status {
+	case entities.OrderPending, entities.OrderCanceled:
+		return nil, errs.Conflict("order_not_invoiceable", "order is %s and cannot be invoiced", order.Status)
+	}
+
+	inv := uc.newDocument(entities.InvoiceKindInvoice, order.ID, order.Currency)
+	inv.Buyer = buyer(order, req)
+	inv.Lines = orderLines(order)
+	totalLines(inv)
+
+	if err := uc.repo.Issue(ctx, inv); err != nil {
+		return nil, err
+	}
+	summarizeTaxes(inv)
+
+	return inv, nil
+}
+
+func (uc *invoiceUsecase) ListOrderInvoices(ctx context.Context, userID string, orderID int64) ([]*entities.Invoice, error) {
+	ctx, cancel := context.WithTimeout(ctx, contextTimeoutQuery)
+	defer cancel()
+
+	if _, err := uc.ownOrder(ctx, userID, orderID); err != nil {
+		return nil, err
+	}
+
+	return uc.repo.List(ctx, &entities.InvoiceFilter{OrderID: orderID})
+}
+
+func (uc *invoiceUsecase) OrderInvoicePDF(ctx context.Context, userID string, orderID, id int64) (*entities.Invoice, []byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, contextTimeoutQuery)
+	defer cancel()
+
+	if _, err := uc.ownOrder(ctx, userID, orderID); err != nil {
+		return nil, nil, err
+	}
+
+	inv, err := uc.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if inv.OrderID != orderID {
+		return nil, nil, errInvoiceNotFound
+	}
+
+	data, err := uc.document(ctx, inv)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return inv, data, nil
+}
+
+func (uc *invoiceUsecase) ListInvoices(ctx context.Context, f *entities.InvoiceFilter) ([]*entities.Invoice, error) {
+	ctx, cancel := context.WithTimeout(ctx, contextTimeoutQuery)
+	defer cancel()
+
+	return uc.repo.List(ctx, f)
+}
+
+// GetInvoice hides documents of other users' orders as not found, an empty
+// userID reads any document, for admins
+func (uc *invoiceUsecase) GetInvoice(ctx context.Context, userID string, id int64) (*entities.Invoice, error) {
+	ctx, cancel := context.WithTimeout(ctx, contextTimeoutQuery)
+	defer cancel()
+
+	inv, err := uc.ownInvoice(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	summarizeTaxes(inv)
+
+	return inv, nil
+}
+
+func (uc *invoiceUsecase) InvoicePDF(ctx context.Context, userID string, id int64) (*entities.Invoice, []byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, contextTimeoutQuery)
+	defer cancel()
+
+	inv, err := uc.ownInvoice(ctx, userID, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	data, err := uc.document(ctx, inv)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return inv, data, nil
+}
+
+// IssueCreditNote credits part of an invoice, usually for a refund. The
+// credit is split over the tax rates of the invoice in proportion to what
+// each rate charged.
+func (uc *invoiceUsecase) IssueCreditNote(ctx context.Context, invoiceID int64, req *entities.CreditNoteReq) (*entities.Invoice, error) {
+	ctx, cancel := context.WithTimeout(ctx, contextTimeoutQuery)
+	defer cancel()
+
+	invoice, err := uc.repo.GetByID(ctx, invoiceID)
+	if err != nil {
+		return nil, err
+	}
+
+	if invoice.Kind != entities.InvoiceKindInvoice {
+		return nil, errs.Conflict("not_an_invoice", "credit notes are issued against tax invoices")
+	}
+
+	var amount money.Money
+	switch {
+	case req.Amount != nil:
+		amount = *req.Amount
+	case req.RefundID != nil:
+		refund, err := uc.repo.GetRefund(ctx, *req.RefundID)
+		if err != nil {
+			return nil, err
+		}
+		amount = refund.Amount
+	default:
+		return nil, errs.Validation("credit_amount_required", "an amount or a refund is required")
+	}
+
+	if amount.Currency != invoice.Currency {
+		return nil, errs.Validation("credit_currency", "amount must be in %s", invoice.Currency)
+	}
+	if amount.Amount <= 0 {
+		return nil, errs.Validation("invalid_credit_amount", "amount must be positive")
+	}
+
+	note := uc.newDocument(entities.InvoiceKindCreditNote, invoice.OrderID, invoice.Currency)
+	note.InvoiceID = &invoice.ID
+	note.RefundID = req.RefundID
+	note.Reason = req.Reason
+	note.Buyer = invoice.Buyer
+	note.Lines = creditLines(invoice, amount)
+	totalLines(note)
+
+	if err := uc.repo.Issue(ctx, note); err != nil {
+		return nil, err
+	}
+	summarizeTaxes(note)
+
+	return note, nil
+}
+
+// document is the PDF of a document, rendered and stored the first time
+// it is asked for
+func (uc *invoiceUsecase) document(ctx context.Context, inv *entities.Invoice) ([]byte, error) {
+	if inv.PDFKey != nil {
+		blob, err := uc.documents.Get(ctx, *inv.PDFKey)
+		if err == nil {
+			defer blob.Close()
+			return io.ReadAll(blob)
+		}
+		if !errors.Is(err, media.ErrNotFound) {
+			return nil, err
+		}
+	}
+
+	var reference string
+	if inv.InvoiceID != nil {
+		original, err := uc.repo.GetByID(ctx, *inv.InvoiceID)
+		if err != nil {
+			return nil, err
+		}
+		reference = original.Number
+	}
+
+	summarizeTaxes(inv)
+	data, err := renderInvoice(inv, reference, uc.font)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := media.NewKey(fmt.Sprintf("invoices/%d", inv.OrderID), ".pdf")
+	if err != nil {
+		return nil, err
+	}
+
+	if err := uc.documents.Put(ctx, key, bytes.NewReader(data)); err != nil {
+		return nil, err
+	}
+
+	if err := uc.repo.SetPDF(ctx, inv.ID, key); err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+func (uc *invoiceUsecase) newDocument(kind string, orderID int64, currency string) *entities.Invoice {
+	now := time.Now().In(invoiceZone)
+
+	return &entities.Invoice{
+		Kind:       kind,
+		FiscalYear: fiscalYear(now, uc.cfg.FiscalYearStart),
+		OrderID:    orderID,
+		Currency:   currency,
+		Seller: entities.Party{
+			Name:    uc.cfg.CompanyName,
+			TaxID:   uc.cfg.CompanyTaxID,
+			Branch:  uc.cfg.CompanyBranch,
+			Address: uc.cfg.CompanyAddress,
+		},
+		IssuedAt: now,
+	}
+}
+
+// ownInvoice reads a document of an order of userID, any document when
+// userID is empty
+func (uc *invoiceUsecase) ownInvoice(ctx context.Context, userID string, id int64) (*entities.Invoice, error) {
+	inv, err := uc.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if userID == "" {
+		return inv, nil
+	}
+
+	if _, err := uc.ownOrder(ctx, userID, inv.OrderID); err != nil {
+		if errors.Is(err, errs.ErrNotFound) {
+			return nil, errInvoiceNotFound
+		}
+		return nil, err
+	}
+
+	return inv, nil
+}
+
+func (uc *invoiceUsecase) ownOrder(ctx context.Context, userID string, orderID int64) (*entities.Order, error) {
+	order, err := uc.orderRepo.GetByID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	if order.UserID != userID {
+		return nil, errs.NotFound("order_not_found", "order not found")
+	}
+
+	return order, nil
+}
+
+// fiscalYear is named after the calendar year it starts in, a year that
+// starts in January is the calendar year
+func fiscalYear(t time.Time, startMonth int) int {
+	if startMonth > 1 && int(t.Month()) < startMonth {
+		return t.Year() - 1
+	}
+	return t.Year()
+}
+
+// buyer is the buyer of the request, the shipping address fills what it
+// leaves out
+func buyer(order *entities.Order, req *entities.InvoiceReq) entities.Party {
+	party := entities.Party{
+		Name:    req.Name,
+		TaxID:   req.TaxID,
+		Branch:  req.Branch,
+		Address: req.Address,
+	}
+
+	if addr := order.ShippingAddress; addr != nil {
+		if party.Name == "" {
+			party.Name = addr.Name
+		}
+		if party.Address == "" {
+			party.Address = formatAddress(addr)
+		}
+	}
+
+	if party.TaxID != "" && party.Branch == "" {
+		party.Branch = headOffice
+	}
+
+	return party
+}
+
+func formatAddress(addr *entities.Address) string {
+	var parts []string
+	for _, p := range []string{addr.Line1, addr.Line2, addr.City, strings.TrimSpace(addr.Region + " " + addr.PostalCode), addr.Country} {
+		if p != "" {
+			parts = append(parts, p)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// orderLines lists the order net of tax. Taxed items are their taxable
+// amounts, the rest and order discounts end up in one balancing line so
+// the document adds up to the order total.
+func orderLines(order *entities.Order) []*entities.InvoiceLine {
+	var lines []*entities.InvoiceLine
+	charged := money.New(0, order.Currency)
+
+	for _, item := range order.Items {
+		line := &entities.InvoiceLine{
+			Description: item.Title,
+			Quantity:    item.Quantity,
+			Amount:      item.LineTotal,
+			TaxAmount:   money.New(0, order.Currency),
+		}
+		if item.Tax != nil {
+			rate := item.Tax.Rate
+			line.Amount = item.Tax.Taxable
+			line.TaxName = item.Tax.Name
+			line.TaxRate = &rate
+			line.TaxAmount = item.Tax.Amount
+		}
+		line.UnitPrice = line.Amount.Mul(big.NewRat(1, int64(max(line.Quantity, 1))))
+
+		charged = charged.Add(line.Amount).Add(line.TaxAmount)
+		lines = append(lines, line)
+	}
+
+	if !order.ShippingTotal.IsZero() {
+		description := "Shipping"
+		if order.Shipping != nil {
+			description = "Shipping: " + order.Shipping.Name
+		}
+		lines = append(lines, untaxedLine(description, order.ShippingTotal))
+		charged = charged.Add(order.ShippingTotal)
+	}
+
+	if rest := order.Total.Sub(charged); !rest.IsZero() {
+		description := "Adjustment"
+		if rest.IsNegative() {
+			description = "Discount"
+		}
+		lines = append(lines, untaxedLine(description, rest))
+	}
+
+	return lines
+}
+
+// creditLines splits amount, tax included, over the tax rates of the
+// invoice. The last group takes the rounding so the lines add up.
+func creditLines(invoice *entities.Invoice, amount money.Money) []*entities.InvoiceLine {
+	type group struct {
+		name  string
+		rate  *string
+		gross money.Money
+	}
+
+	var groups []*group
+	index := map[string]*group{}
+	total := money.New(0, invoice.Currency)
+
+	for _, line := range invoice.Lines {
+		key := ""
+		if line.TaxRate != nil {
+			key = line.TaxName + "|" + *line.TaxRate
+		}
+		g, ok := index[key]
+		if !ok {
+			g = &group{name: line.TaxName, rate: line.TaxRate, gross: money.New(0, invoice.Currency)}
+			index[key] = g
+			groups = append(groups, g)
+		}
+		g.gross = g.gross.Add(line.Amount).Add(line.TaxAmount)
+	}
+
+	// Groups that charged nothing, such as discounts alone, get no credit
+	var charged []*group
+	for _, g := range groups {
+		if g.gross.Amount > 0 {
+			charged = append(charged, g)
+			total = total.Add(g.gross)
+		}
+	}
+
+	if len(charged) == 0 {
+		return []*entities.InvoiceLine{untaxedLine("Credit", amount)}
+	}
+
+	var lines []*entities.InvoiceLine
+	left := amount
+	for i, g := range charged {
+		share := left
+		if i < len(charged)-1 {
+			share = amount.Mul(new(big.Rat).SetFrac64(g.gross.Amount, total.Amount))
+		}
+		left = left.Sub(share)
+
+		if g.rate == nil {
+			lines = append(lines, untaxedLine("Credit", share))
+			continue
+		}
+
+		line := untaxedLine(fmt.Sprintf("Credit: %s %s%%", g.name, formatRate(*g.rate)), share)
+		if percent, ok := new(big.Rat).SetString(*g.rate); ok {
+			tax, net := lineTax(share, percent, true)
+			line.Amount, line.UnitPrice, line.TaxAmount = net, net, tax
+		}
+		line.TaxName, line.TaxRate = g.name, g.rate
+		lines = append(lines, line)
+	}
+
+	return lines
+}
+
+func untaxedLine(description string, amount money.Money) *entities.InvoiceLine {
+	return &entities.InvoiceLine{
+		Description: description,
+		Quantity:    1,
+		UnitPrice:   amount,
+		Amount:      amount,
+		TaxAmount:   money.New(0, amount.Currency),
+	}
+}
+
+func totalLines(inv *entities.Invoice) {
+	inv.Subtotal = money.New(0, inv.Currency)
+	inv.TaxTotal = money.New(0, inv.Currency)
+
+	for _, line := range inv.Lines {
+		inv.Subtotal = inv.Subtotal.Add(line.Amount)
+		inv.TaxTotal = inv.TaxTotal.Add(line.TaxAmount)
+	}
+
+	inv.Total = inv.Subtotal.Add(inv.TaxTotal)
+}
+
+// summarizeTaxes adds up the taxed lines per rate for the tax breakdown
+func summarizeTaxes(inv *entities.Invoice) {
+	inv.Taxes = []*entities.TaxSummary{}
+	index := map[string]*entities.TaxSummary{}
+
+	for _, line := range inv.Lines {
+		if line.TaxRate == nil {
+			continue
+		}
+
+		key := line.TaxName + "|" + *line.TaxRate
+		summary, ok := index[key]
+		if !ok {
+			summary = &entities.TaxSummary{
+				Name:    line.TaxName,
+				Rate:    *line.TaxRate,
+				Taxable: money.New(0, inv.Currency),
+				Amount:  money.New(0, inv.Currency),
+			}
+			index[key] = summary
+			inv.Taxes = append(inv.Taxes, summary)
+		}
+		summary.Taxable = summary.Taxable.Add(line.Amount)
+		summary.Amount = summary.Amount.Add(line.TaxAmount)
+	}
+}
+
+// formatRate prints a stored rate such as 7.0000 as 7
+func formatRate(rate string) string {
+	if strings.Contains(rate, ".") {
+		rate = strings.TrimRight(strings.TrimRight(rate, "0"), ".")
+	}
+	return rate
+}
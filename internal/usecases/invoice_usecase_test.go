@@ -0,0 +1,77 @@
+package usecases
+
+import (
+	"testing"
+
+	"github.com/codepnw/react_go_ecom/internal/entities"
+)
+
+func TestCreditLinesSplitsOverRates(t *testing.T) {
+	vat := "7.0000"
+	invoice := &entities.Invoice{
+		Currency: "THB",
+		Lines: []*entities.InvoiceLine{
+			{Description: "Shirt", Amount: thb(10000), TaxName: "VAT", TaxRate: &vat, TaxAmount: thb(700)},
+			{Description: "Shipping", Amount: thb(5000), TaxAmount: thb(0)},
+			{Description: "Discount", Amount: thb(-1000), TaxAmount: thb(0)},
+		},
+	}
+
+	lines := creditLines(invoice, thb(1470))
+	if len(lines) != 2 {
+		t.Fatalf("got %d lines, want 2", len(lines))
+	}
+
+	// 107.00 of the 147.00 charged was taxed at 7%
+	taxed := lines[0]
+	if taxed.Amount != thb(1000) || taxed.TaxAmount != thb(70) || taxed.TaxRate == nil || *taxed.TaxRate != vat {
+		t.Errorf("taxed line = %+v, want 10.00 plus 0.70 VAT", taxed)
+	}
+
+	untaxed := lines[1]
+	if untaxed.Amount != thb(400) || !untaxed.TaxAmount.IsZero() || untaxed.TaxRate != nil {
+		t.Errorf("untaxed line = %+v, want 4.00", untaxed)
+	}
+}
+
+func TestCreditLinesAddUp(t *testing.T) {
+	vat, reduced := "7.0000", "3.0000"
+	invoice := &entities.Invoice{
+		Currency: "THB",
+		Lines: []*entities.InvoiceLine{
+			{Amount: thb(3333), TaxName: "VAT", TaxRate: &vat, TaxAmount: thb(233)},
+			{Amount: thb(3333), TaxName: "VAT", TaxRate: &reduced, TaxAmount: thb(100)},
+			{Amount: thb(3333), TaxAmount: thb(0)},
+		},
+	}
+
+	for _, amount := range []int64{1, 100, 999, 10332} {
+		total := thb(0)
+		for _, line := range creditLines(invoice, thb(amount)) {
+			total = total.Add(line.Amount).Add(line.TaxAmount)
+		}
+		if total != thb(amount) {
+			t.Errorf("credit of %d adds up to %v", amount, total)
+		}
+	}
+}
+
+func TestCreditLinesWithoutCharges(t *testing.T) {
+	invoice := &entities.Invoice{
+		Currency: "THB",
+		Lines:    []*entities.InvoiceLine{{Amount: thb(-500), TaxAmount: thb(0)}},
+	}
+
+	lines := creditLines(invoice, thb(500))
+	if len(lines) != 1 || lines[0].Amount != thb(500) || lines[0].Description != "Credit" {
+		t.Errorf("lines = %+v, want one untaxed credit of 5.00", lines)
+	}
+}
+
+func TestFormatRate(t *testing.T) {
+	for in, want := range map[string]string{"7.0000": "7", "7.5000": "7.5", "10": "10", "0.0000": "0"} {
+		if got := formatRate(in); got != want {
+			t.Errorf("formatRate(%s) = %s, want %s", in, got, want)
+		}
+	}
+}
@@ -0,0 +1,269 @@
+package usecases
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"strings"
+
+	"github.com/codepnw/react_go_ecom/internal/entities"
+	"github.com/codepnw/react_go_ecom/internal/errs"
+	"github.com/codepnw/react_go_ecom/internal/repositories"
+	"github.com/codepnw/react_go_ecom/internal/utils"
+	"github.com/codepnw/react_go_ecom/pkg/media"
+)
+
+type MediaUsecase interface {
+	UploadProductImages(ctx context.Context, productID string, files []io.Reader) ([]*entities.ProductImage, error)
+	ListProductImages(ctx context.Context, productID string) ([]*entities.ProductImage, error)
+	DeleteProductImage(ctx context.Context, productID string, id int) error
+	ReorderProductImages(ctx context.Context, productID string, imageIDs []int) ([]*entities.ProductImage, error)
+	UploadAvatar(ctx context.Context, userID string, file io.Reader) (string, error)
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+}
+
+type mediaUsecase struct {
+	repo        repositories.MediaRepository
+	productRepo repositories.ProductRepository
+	blobs       media.BlobStore
+	processor   *media.Processor
+}
+
+func NewMediaUsecase(repo repositories.MediaRepository, productRepo repositories.ProductRepository, blobs media.BlobStore, processor *media.Processor) MediaUsecase {
+	return &mediaUsecase{
+		repo:        repo,
+		productRepo: productRepo,
+		blobs:       blobs,
+		processor:   processor,
+	}
+}
+
+// UploadProductImages adds all files to the gallery or none of them. Every
+// file is checked before the first one is stored, and blobs already stored
+// are removed again if a later step fails.
+func (uc *mediaUsecase) UploadProductImages(ctx context.Context, productID string, files []io.Reader) ([]*entities.ProductImage, error) {
+	ctx, cancel := context.WithTimeout(ctx, contextTimeoutQuery)
+	defer cancel()
+
+	if _, err := uc.productRepo.GetByID(ctx, productID, ""); err != nil {
+		return nil, err
+	}
+
+	images := make([]*entities.ProductImage, len(files))
+	contents := make([][]byte, len(files))
+	for i, file := range files {
+		contentType, ext, data, err := readImage(file)
+		if err != nil {
+			return nil, err
+		}
+
+		key, err := media.NewKey("products/"+productID, ext)
+		if err != nil {
+			return nil, err
+		}
+
+		images[i] = &entities.ProductImage{
+			ProductID:   productID,
+			Key:         key,
+			ContentType: contentType,
+			Size:        int64(len(data)),
+			CreatedAt:   utils.ThaiTime,
+		}
+		contents[i] = data
+	}
+
+	for i, img := range images {
+		if err := uc.blobs.Put(ctx, img.Key, bytes.NewReader(contents[i])); err != nil {
+			uc.removeImages(images[:i])
+			return nil, err
+		}
+	}
+
+	if err := uc.repo.CreateProductImages(ctx, images); err != nil {
+		uc.removeImages(images)
+		return nil, err
+	}
+
+	for _, img := range images {
+		imageURLs(uc.blobs, img)
+		uc.enqueueRenditions(img)
+	}
+
+	return images, nil
+}
+
+// enqueueRenditions has the worker render an image. Renditions show up on
+// the product once it is done, the original is served until then.
+func (uc *mediaUsecase) enqueueRenditions(img *entities.ProductImage) {
+	imageID := img.ID
+	err := uc.processor.Enqueue(img.Key, func(ctx context.Context, outputs []media.Output) error {
+		renditions := make([]*entities.ImageRendition, len(outputs))
+		for i, out := range outputs {
+			renditions[i] = &entities.ImageRendition{
+				ImageID:     imageID,
+				Name:        out.Name,
+				Key:         out.Key,
+				ContentType: out.ContentType,
+				Width:       out.Width,
+				Height:      out.Height,
+			}
+		}
+		return uc.repo.SaveRenditions(ctx, imageID, renditions)
+	})
+	if err != nil {
+		log.Println("enqueue renditions:", img.Key, err)
+	}
+}
+
+func (uc *mediaUsecase) ListProductImages(ctx context.Context, productID string) ([]*entities.ProductImage, error) {
+	ctx, cancel := context.WithTimeout(ctx, contextTimeoutQuery)
+	defer cancel()
+
+	images, err := uc.repo.ListProductImages(ctx, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, img := range images {
+		imageURLs(uc.blobs, img)
+	}
+
+	return images, nil
+}
+
+func (uc *mediaUsecase) DeleteProductImage(ctx context.Context, productID string, id int) error {
+	ctx, cancel := context.WithTimeout(ctx, contextTimeoutQuery)
+	defer cancel()
+
+	img, err := uc.repo.GetProductImage(ctx, productID, id)
+	if err != nil {
+		return err
+	}
+
+	if err := uc.repo.DeleteProductImage(ctx, productID, id); err != nil {
+		return err
+	}
+
+	uc.removeBlob(img.Key)
+	for _, rd := range img.Renditions {
+		uc.removeBlob(rd.Key)
+	}
+
+	return nil
+}
+
+func (uc *mediaUsecase) ReorderProductImages(ctx context.Context, productID string, imageIDs []int) ([]*entities.ProductImage, error) {
+	seen := make(map[int]bool, len(imageIDs))
+	for _, id := range imageIDs {
+		if seen[id] {
+			return nil, errs.Validation("duplicate_image_id", "duplicate image id in image_ids")
+		}
+		seen[id] = true
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, contextTimeoutQuery)
+	defer cancel()
+
+	if err := uc.repo.ReorderProductImages(ctx, productID, imageIDs); err != nil {
+		return nil, err
+	}
+
+	return uc.ListProductImages(ctx, productID)
+}
+
+func (uc *mediaUsecase) UploadAvatar(ctx context.Context, userID string, file io.Reader) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, contextTimeoutQuery)
+	defer cancel()
+
+	_, ext, data, err := readImage(file)
+	if err != nil {
+		return "", err
+	}
+
+	key, err := media.NewKey("avatars/"+userID, ext)
+	if err != nil {
+		return "", err
+	}
+
+	if err := uc.blobs.Put(ctx, key, bytes.NewReader(data)); err != nil {
+		return "", err
+	}
+
+	oldKey, err := uc.repo.UpdateUserAvatar(ctx, userID, key)
+	if err != nil {
+		uc.removeBlob(key)
+		return "", err
+	}
+
+	if oldKey != "" {
+		uc.removeBlob(oldKey)
+	}
+
+	return uc.blobs.URL(key), nil
+}
+
+func (uc *mediaUsecase) Open(ctx context.Context, key string) (io.ReadCloser, error) {
+	return uc.blobs.Get(ctx, key)
+}
+
+// removeBlob is best effort, a leftover file is harmless
+func (uc *mediaUsecase) removeBlob(key string) {
+	if err := uc.blobs.Delete(context.Background(), key); err != nil {
+		log.Println("remove blob:", key, err)
+	}
+}
+
+func (uc *mediaUsecase) removeImages(images []*entities.ProductImage) {
+	for _, img := range images {
+		uc.removeBlob(img.Key)
+	}
+}
+
+// readImage sniffs the upload, rejects images too large to process and
+// strips EXIF and other metadata before it is stored, so location data from
+// phone cameras is never published. JPEGs are rotated upright here, blobs
+// are served as immutable and are never rewritten once stored.
+func readImage(file io.Reader) (contentType, ext string, data []byte, err error) {
+	contentType, ext, body, err := media.SniffImage(file)
+	if err != nil {
+		return "", "", nil, err
+	}
+
+	data, err = io.ReadAll(body)
+	if err != nil {
+		return "", "", nil, err
+	}
+
+	if err := media.CheckBounds(contentType, data); err != nil {
+		return "", "", nil, err
+	}
+
+	data, err = media.StripMetadata(contentType, data)
+	if err != nil {
+		return "", "", nil, fmt.Errorf("%w: %v", media.ErrUnsupportedType, err)
+	}
+
+	data, err = media.Upright(contentType, data)
+	if err != nil {
+		return "", "", nil, fmt.Errorf("%w: %v", media.ErrUnsupportedType, err)
+	}
+
+	return contentType, ext, data, nil
+}
+
+// imageURLs fills the public URLs of an image and its renditions
+func imageURLs(blobs media.BlobStore, img *entities.ProductImage) {
+	img.URL = blobs.URL(img.Key)
+	if img.Renditions == nil {
+		img.Renditions = []*entities.ImageRendition{}
+	}
+
+	srcset := make([]string, len(img.Renditions))
+	for i, rd := range img.Renditions {
+		rd.URL = blobs.URL(rd.Key)
+		srcset[i] = fmt.Sprintf("%s %dw", rd.URL, rd.Width)
+	}
+	img.SrcSet = strings.Join(srcset, ", ")
+}
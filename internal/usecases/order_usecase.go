@@ -0,0 +1,223 @@
+package usecases
+
+import (
+	"context"
+	"strings"
+
+	"github.com/codepnw/react_go_ecom/internal/entities"
+	"github.com/codepnw/react_go_ecom/internal/errs"
+	"github.com/codepnw/react_go_ecom/internal/repositories"
+	"github.com/codepnw/react_go_ecom/pkg/money"
+	"github.com/codepnw/react_go_ecom/pkg/pagination"
+)
+
+// baseRate is recorded on orders placed in the base currency
+const baseRate = "1.00000000"
+
+type OrderUsecase interface {
+	Checkout(ctx context.Context, userID string, req *entities.CheckoutReq) (*entities.Order, error)
+	GetOrder(ctx context.Context, userID string, id int64) (*entities.Order, error)
+	ListOrders(ctx context.Context, f *entities.OrderFilter) (*entities.OrderListResult, error)
+}
+
+type orderUsecase struct {
+	repo       repositories.OrderRepository
+	cartRepo   repositories.CartRepository
+	pricer     Pricer
+	discounter Discounter
+	taxer      Taxer
+	shipper    Shipper
+}
+
+func NewOrderUsecase(repo repositories.OrderRepository, cartRepo repositories.CartRepository, pricer Pricer, discounter Discounter, taxer Taxer, shipper Shipper) OrderUsecase {
+	return &orderUsecase{
+		repo:       repo,
+		cartRepo:   cartRepo,
+		pricer:     pricer,
+		discounter: discounter,
+		taxer:      taxer,
+		shipper:    shipper,
+	}
+}
+
+// Checkout turns the cart into an order. The currency and the rate the
+// cart was priced at are stored with it, later rate or price changes never
+// alter a placed order. A coupon that no longer applies fails the checkout
+// rather than silently charging more, so does a shipping method that can
+// no longer ship the cart. Gift cards and store credit pay what they can,
+// the payment gateway is charged the rest.
+func (uc *orderUsecase) Checkout(ctx context.Context, userID string, req *entities.CheckoutReq) (*entities.Order, error) {
+	currency, err := parseCurrency(req.Currency)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, contextTimeoutQuery)
+	defer cancel()
+
+	items, err := uc.cartRepo.ListItems(ctx, userID, "")
+	if err != nil {
+		return nil, err
+	}
+
+	if len(items) == 0 {
+		return nil, errs.Validation("cart_empty", "cart is empty")
+	}
+
+	basePrices := make(map[string]money.Money, len(items))
+	for _, item := range items {
+		basePrices[item.ProductID] = item.UnitPrice
+	}
+
+	cart, rate, err := priceCart(ctx, uc.pricer, currency, items)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := uc.discounter.ApplyPromotions(ctx, userID, cart, req.Coupons); err != nil {
+		return nil, err
+	}
+
+	if len(cart.RejectedCoupons) > 0 {
+		r := cart.RejectedCoupons[0]
+		return nil, errs.Validation(r.Reason, couponReasons[r.Reason], r.Code)
+	}
+
+	addr := req.ShippingAddress
+
+	if err := uc.taxer.ApplyTaxes(ctx, cart, addr.Country, addr.Region); err != nil {
+		return nil, err
+	}
+
+	if err := uc.shipper.ApplyShipping(ctx, cart, rate, addr.Country, addr.Region, req.ShippingMethodID); err != nil {
+		return nil, err
+	}
+
+	if cart.Shipping.Kind != entities.ShippingLocalPickup && !deliverable(&addr) {
+		return nil, errs.Validation("shipping_address_required", "name, line1, city and postal_code are required for delivery")
+	}
+	addr.Country, addr.Region = cart.TaxCountry, cart.TaxRegion
+
+	order := &entities.Order{
+		UserID:           userID,
+		Status:           entities.OrderPending,
+		Currency:         currency,
+		Subtotal:         cart.Subtotal,
+		DiscountTotal:    cart.DiscountTotal,
+		FreeShipping:     cart.FreeShipping,
+		TaxCountry:       &cart.TaxCountry,
+		TaxRegion:        cart.TaxRegion,
+		PricesIncludeTax: cart.PricesIncludeTax,
+		Taxes:            cart.Taxes,
+		TaxTotal:         cart.TaxTotal,
+		Shipping:         cart.Shipping,
+		ShippingTotal:    cart.ShippingTotal,
+		WeightGrams:      cart.WeightGrams,
+		Total:            cart.Total,
+		ShippingAddress:  &addr,
+		Discounts:        cart.Discounts,
+		UseStoreCredit:   req.UseStoreCredit,
+	}
+
+	// A card listed twice is redeemed once
+	seen := make(map[string]bool, len(req.GiftCards))
+	for _, code := range req.GiftCards {
+		if code = normalizeGiftCode(code); code != "" && !seen[code] {
+			seen[code] = true
+			order.GiftCards = append(order.GiftCards, code)
+		}
+	}
+
+	switch {
+	case currency == money.DefaultCurrency:
+		r := baseRate
+		order.ExchangeRate = &r
+	case rate != nil:
+		order.ExchangeRate, order.ExchangeRateID = &rate.Rate, &rate.ID
+	}
+
+	for _, item := range cart.Items {
+		productID := item.ProductID
+		order.Items = append(order.Items, &entities.OrderItem{
+			ProductID:     &productID,
+			Title:         item.Title,
+			Quantity:      item.Quantity,
+			UnitPrice:     item.UnitPrice,
+			BaseUnitPrice: basePrices[item.ProductID],
+			LineTotal:     item.LineTotal,
+			Tax:           item.Tax,
+		})
+	}
+
+	if err := uc.repo.Create(ctx, order); err != nil {
+		return nil, err
+	}
+
+	return order, nil
+}
+
+// deliverable reports whether a courier could find the address
+func deliverable(addr *entities.Address) bool {
+	for _, field := range []string{addr.Name, addr.Line1, addr.City, addr.PostalCode} {
+		if strings.TrimSpace(field) == "" {
+			return false
+		}
+	}
+	return true
+}
+
+// GetOrder hides orders of other users as not found
+func (uc *orderUsecase) GetOrder(ctx context.Context, userID string, id int64) (*entities.Order, error) {
+	ctx, cancel := context.WithTimeout(ctx, contextTimeoutQuery)
+	defer cancel()
+
+	order, err := uc.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if order.UserID != userID {
+		return nil, errs.NotFound("order_not_found", "order not found")
+	}
+
+	return order, nil
+}
+
+func (uc *orderUsecase) ListOrders(ctx context.Context, f *entities.OrderFilter) (*entities.OrderListResult, error) {
+	ctx, cancel := context.WithTimeout(ctx, contextTimeoutQuery)
+	defer cancel()
+
+	f.Limit = pageLimit(f.Limit)
+
+	var err error
+	if f.After, err = decodeCursor(&f.PageReq, nil); err != nil {
+		return nil, err
+	}
+
+	orders, err := uc.repo.List(ctx, f)
+	if err != nil {
+		return nil, err
+	}
+
+	orders, more := pagination.Trim(orders, f.Limit, f.After != nil && f.After.Backward)
+
+	var first, last []string
+	if len(orders) > 0 {
+		first, last = orders[0].SortValues, orders[len(orders)-1].SortValues
+	}
+
+	result := &entities.OrderListResult{
+		Items:    orders,
+		PageInfo: pageInfo(f.Limit, f.After, more, nil, "", first, last),
+	}
+
+	if f.WithTotal {
+		total, err := uc.repo.Count(ctx, f)
+		if err != nil {
+			return nil, err
+		}
+		result.PageInfo.Total = &total
+	}
+
+	return result, nil
+}
@@ -0,0 +1,83 @@
+package usecases
+
+import (
+	"strings"
+
+	"github.com/codepnw/react_go_ecom/internal/entities"
+	"github.com/codepnw/react_go_ecom/internal/errs"
+	"github.com/codepnw/react_go_ecom/pkg/pagination"
+)
+
+func pageLimit(limit int) int {
+	if limit <= 0 {
+		return defaultListLimit
+	}
+	if limit > maxListLimit {
+		return maxListLimit
+	}
+	return limit
+}
+
+// sortSignature names an ordering in its cursors, the unique tie breaker
+// is implied so the cursor carries one value more than there are keys
+func sortSignature(keys []*entities.SortKey) string {
+	parts := make([]string, len(keys))
+	for i, k := range keys {
+		parts[i] = k.Field
+		if k.Desc {
+			parts[i] = "-" + k.Field
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+func decodeCursor(req *entities.PageReq, keys []*entities.SortKey) (*pagination.Cursor, error) {
+	if req.Cursor == "" {
+		return nil, nil
+	}
+	c, err := pagination.Decode(req.Cursor, sortSignature(keys), len(keys)+1)
+	if err != nil {
+		return nil, errs.Validation("invalid_cursor", "invalid cursor").Wrap(err)
+	}
+
+	return c, nil
+}
+
+// pageInfo builds the cursors around a page. first and last are the sort
+// values of its edge rows, more comes from pagination.Trim.
+func pageInfo(limit int, after *pagination.Cursor, more bool, keys []*entities.SortKey, mode string, first, last []string) *entities.PageInfo {
+	info := &entities.PageInfo{Limit: limit}
+	backward := after != nil && after.Backward
+
+	if backward {
+		info.HasPrev, info.HasNext = more, true
+	} else {
+		info.HasNext, info.HasPrev = more, after != nil
+	}
+
+	cursor := func(values []string, backward bool) string {
+		return pagination.Encode(&pagination.Cursor{
+			Sort:     sortSignature(keys),
+			Values:   values,
+			Backward: backward,
+			Mode:     mode,
+		})
+	}
+
+	// An empty page past either end can still turn around at the cursor
+	if first == nil {
+		if after == nil {
+			return info
+		}
+		first, last = after.Values, after.Values
+	}
+
+	if info.HasNext {
+		info.NextCursor = cursor(last, false)
+	}
+	if info.HasPrev {
+		info.PrevCursor = cursor(first, true)
+	}
+
+	return info
+}
@@ -0,0 +1,146 @@
+package usecases
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"github.com/codepnw/react_go_ecom/internal/entities"
+	"github.com/codepnw/react_go_ecom/internal/errs"
+	"github.com/codepnw/react_go_ecom/internal/repositories"
+	"github.com/codepnw/react_go_ecom/pkg/money"
+	"github.com/codepnw/react_go_ecom/pkg/pagination"
+)
+
+// Scheduled changes applied per transaction, a backlog is worked off in
+// batches within one run
+const scheduleBatchSize = 100
+
+type PriceHistoryUsecase interface {
+	ListHistory(ctx context.Context, f *entities.PriceHistoryFilter) (*entities.PriceHistoryResult, error)
+	SchedulePrice(ctx context.Context, actor, productID string, req *entities.ScheduledPriceReq) (*entities.ScheduledPrice, error)
+	ListSchedules(ctx context.Context, productID string) ([]*entities.ScheduledPrice, error)
+	CancelSchedule(ctx context.Context, productID string, id int64) error
+	ApplyDue(ctx context.Context) error
+}
+
+type priceHistoryUsecase struct {
+	repo repositories.PriceHistoryRepository
+}
+
+func NewPriceHistoryUsecase(repo repositories.PriceHistoryRepository) PriceHistoryUsecase {
+	return &priceHistoryUsecase{repo: repo}
+}
+
+func (uc *priceHistoryUsecase) ListHistory(ctx context.Context, f *entities.PriceHistoryFilter) (*entities.PriceHistoryResult, error) {
+	ctx, cancel := context.WithTimeout(ctx, contextTimeoutQuery)
+	defer cancel()
+
+	f.Limit = pageLimit(f.Limit)
+
+	var err error
+	if f.Currency != "" {
+		if f.Currency, err = parseCurrency(f.Currency); err != nil {
+			return nil, err
+		}
+	}
+
+	if f.After, err = decodeCursor(&f.PageReq, nil); err != nil {
+		return nil, err
+	}
+
+	changes, err := uc.repo.ListHistory(ctx, f)
+	if err != nil {
+		return nil, err
+	}
+
+	changes, more := pagination.Trim(changes, f.Limit, f.After != nil && f.After.Backward)
+
+	var first, last []string
+	if len(changes) > 0 {
+		first, last = changes[0].SortValues, changes[len(changes)-1].SortValues
+	}
+
+	result := &entities.PriceHistoryResult{
+		Items:    changes,
+		PageInfo: pageInfo(f.Limit, f.After, more, nil, "", first, last),
+	}
+
+	if f.WithTotal {
+		total, err := uc.repo.CountHistory(ctx, f)
+		if err != nil {
+			return nil, err
+		}
+		result.PageInfo.Total = &total
+	}
+
+	return result, nil
+}
+
+func (uc *priceHistoryUsecase) SchedulePrice(ctx context.Context, actor, productID string, req *entities.ScheduledPriceReq) (*entities.ScheduledPrice, error) {
+	if req.Price.Amount <= 0 {
+		return nil, errs.Validation("invalid_price", "price must be greater than zero")
+	}
+
+	if !money.Known(req.Price.Currency) {
+		return nil, errs.Validation("unknown_currency", "currency is not supported")
+	}
+
+	if !req.EffectiveAt.After(time.Now()) {
+		return nil, errs.Validation("schedule_in_past", "effective_at must be in the future")
+	}
+
+	schedule := &entities.ScheduledPrice{
+		ProductID:   productID,
+		Price:       req.Price,
+		EffectiveAt: req.EffectiveAt,
+		Reason:      req.Reason,
+	}
+	if actor != "" {
+		schedule.CreatedBy = &actor
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, contextTimeoutQuery)
+	defer cancel()
+
+	if err := uc.repo.CreateSchedule(ctx, schedule); err != nil {
+		return nil, err
+	}
+
+	return schedule, nil
+}
+
+func (uc *priceHistoryUsecase) ListSchedules(ctx context.Context, productID string) ([]*entities.ScheduledPrice, error) {
+	ctx, cancel := context.WithTimeout(ctx, contextTimeoutQuery)
+	defer cancel()
+
+	return uc.repo.ListSchedules(ctx, productID)
+}
+
+func (uc *priceHistoryUsecase) CancelSchedule(ctx context.Context, productID string, id int64) error {
+	ctx, cancel := context.WithTimeout(ctx, contextTimeoutQuery)
+	defer cancel()
+
+	return uc.repo.CancelSchedule(ctx, productID, id)
+}
+
+// ApplyDue is the scheduler job, it applies every change that became
+// effective since the last run
+func (uc *priceHistoryUsecase) ApplyDue(ctx context.Context) error {
+	now := time.Now()
+
+	for {
+		applied, err := uc.repo.ApplyDue(ctx, now, scheduleBatchSize)
+		if err != nil {
+			return err
+		}
+
+		for _, s := range applied {
+			log.Printf("applied scheduled price %d: product %s now %s", s.ID, s.ProductID, s.Price)
+		}
+
+		if len(applied) < scheduleBatchSize {
+			return nil
+		}
+	}
+}
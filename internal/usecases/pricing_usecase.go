@@ -0,0 +1,250 @@
+package usecases
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/codepnw/react_go_ecom/internal/entities"
+	"github.com/codepnw/react_go_ecom/internal/errs"
+	"github.com/codepnw/react_go_ecom/internal/repositories"
+	"github.com/codepnw/react_go_ecom/pkg/money"
+)
+
+// Pricer converts base prices keyed by product id into currency. Explicit
+// prices of the currency win, the others are converted at the current rate,
+// which is returned as applied to the base currency.
+type Pricer interface {
+	Prices(ctx context.Context, currency string, base map[string]money.Money) (map[string]money.Money, *entities.ExchangeRate, error)
+}
+
+type PricingUsecase interface {
+	Pricer
+	CreateRate(ctx context.Context, req *entities.ExchangeRateReq) (*entities.ExchangeRate, error)
+	ListRates(ctx context.Context, f *entities.ExchangeRateFilter) ([]*entities.ExchangeRate, error)
+	DeleteRate(ctx context.Context, id int) error
+	ListProductPrices(ctx context.Context, productID string) ([]*entities.ProductPrice, error)
+	SetProductPrice(ctx context.Context, actor, productID, currency string, req *entities.ProductPriceReq) (*entities.ProductPrice, error)
+	DeleteProductPrice(ctx context.Context, actor, productID, currency string) error
+}
+
+type pricingUsecase struct {
+	repo repositories.PricingRepository
+}
+
+func NewPricingUsecase(repo repositories.PricingRepository) PricingUsecase {
+	return &pricingUsecase{repo: repo}
+}
+
+func (uc *pricingUsecase) CreateRate(ctx context.Context, req *entities.ExchangeRateReq) (*entities.ExchangeRate, error) {
+	base, err := parseCurrency(req.Base)
+	if err != nil {
+		return nil, err
+	}
+
+	quote, err := parseCurrency(req.Quote)
+	if err != nil {
+		return nil, err
+	}
+
+	if base == quote {
+		return nil, errs.Validation("same_currency_rate", "base and quote currency must differ")
+	}
+
+	r, err := money.ParseRate(req.Rate)
+	if err != nil {
+		return nil, errs.Validation("invalid_rate", "rate must be a positive decimal with at most %d places", money.RateDecimals).Wrap(err)
+	}
+
+	rate := &entities.ExchangeRate{
+		Base:          base,
+		Quote:         quote,
+		Rate:          r.FloatString(money.RateDecimals),
+		EffectiveFrom: time.Now(),
+	}
+	if req.EffectiveFrom != nil {
+		rate.EffectiveFrom = *req.EffectiveFrom
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, contextTimeoutQuery)
+	defer cancel()
+
+	if err := uc.repo.CreateRate(ctx, rate); err != nil {
+		return nil, err
+	}
+
+	return rate, nil
+}
+
+func (uc *pricingUsecase) ListRates(ctx context.Context, f *entities.ExchangeRateFilter) ([]*entities.ExchangeRate, error) {
+	f.Base, f.Quote = strings.ToUpper(f.Base), strings.ToUpper(f.Quote)
+
+	ctx, cancel := context.WithTimeout(ctx, contextTimeoutQuery)
+	defer cancel()
+
+	return uc.repo.ListRates(ctx, f)
+}
+
+func (uc *pricingUsecase) DeleteRate(ctx context.Context, id int) error {
+	ctx, cancel := context.WithTimeout(ctx, contextTimeoutQuery)
+	defer cancel()
+
+	return uc.repo.DeleteRate(ctx, id)
+}
+
+func (uc *pricingUsecase) ListProductPrices(ctx context.Context, productID string) ([]*entities.ProductPrice, error) {
+	ctx, cancel := context.WithTimeout(ctx, contextTimeoutQuery)
+	defer cancel()
+
+	return uc.repo.ListProductPrices(ctx, productID)
+}
+
+func (uc *pricingUsecase) SetProductPrice(ctx context.Context, actor, productID, currency string, req *entities.ProductPriceReq) (*entities.ProductPrice, error) {
+	currency, err := parseCurrency(currency)
+	if err != nil {
+		return nil, err
+	}
+
+	if currency == money.DefaultCurrency {
+		return nil, errs.Validation("base_currency_price", "the %s price is the product's own price", money.DefaultCurrency)
+	}
+
+	audit, err := priceAudit(actor, req.Reason)
+	if err != nil {
+		return nil, err
+	}
+
+	amount, err := money.Parse(req.Amount, currency)
+	if err != nil || amount.Amount <= 0 {
+		return nil, errs.Validation("invalid_price", "price must be greater than zero")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, contextTimeoutQuery)
+	defer cancel()
+
+	// Shipping and other base currency amounts of a cart are converted at
+	// the rate, a currency without one could price goods but not checkout
+	rate, err := uc.currentRate(ctx, currency)
+	if err != nil {
+		return nil, err
+	}
+	if rate == nil {
+		return nil, errs.Conflict("exchange_rate_required", "set an exchange rate for %s before pricing in it", currency)
+	}
+
+	price := &entities.ProductPrice{Price: amount}
+	if err := uc.repo.UpsertProductPrice(ctx, productID, price, audit); err != nil {
+		return nil, err
+	}
+
+	return price, nil
+}
+
+func (uc *pricingUsecase) DeleteProductPrice(ctx context.Context, actor, productID, currency string) error {
+	audit, err := priceAudit(actor, "")
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, contextTimeoutQuery)
+	defer cancel()
+
+	return uc.repo.DeleteProductPrice(ctx, productID, strings.ToUpper(currency), audit)
+}
+
+// priceAudit attributes a price change to the signed in user who made it,
+// a change nobody answers for is refused
+func priceAudit(actor, reason string) (entities.PriceAudit, error) {
+	if actor == "" {
+		return entities.PriceAudit{}, errs.Unauthorized("unauthorized", "user_id not found")
+	}
+	return entities.PriceAudit{Actor: actor, Reason: reason}, nil
+}
+
+func (uc *pricingUsecase) Prices(ctx context.Context, currency string, base map[string]money.Money) (map[string]money.Money, *entities.ExchangeRate, error) {
+	currency, err := parseCurrency(currency)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if currency == money.DefaultCurrency || len(base) == 0 {
+		return base, nil, nil
+	}
+
+	ids := make([]string, 0, len(base))
+	for id := range base {
+		ids = append(ids, id)
+	}
+
+	explicit, err := uc.repo.PricesByProducts(ctx, ids, currency)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rate, err := uc.currentRate(ctx, currency)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	prices := make(map[string]money.Money, len(base))
+	for id, price := range base {
+		if p, ok := explicit[id]; ok {
+			prices[id] = p
+			continue
+		}
+
+		if rate == nil {
+			return nil, nil, errs.Validation("currency_unavailable", "prices in %s are not available", currency)
+		}
+
+		r, err := money.ParseRate(rate.Rate)
+		if err != nil {
+			return nil, nil, err
+		}
+		prices[id] = price.Convert(currency, r)
+	}
+
+	return prices, rate, nil
+}
+
+// currentRate is the rate from the base currency into currency in effect
+// now, inverted when only the opposite pair is stored. No rate is not an
+// error, explicit prices may still cover everything.
+func (uc *pricingUsecase) currentRate(ctx context.Context, currency string) (*entities.ExchangeRate, error) {
+	rate, err := uc.repo.CurrentRate(ctx, money.DefaultCurrency, currency, time.Now())
+	if errors.Is(err, errs.ErrNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if rate.Base == money.DefaultCurrency {
+		return rate, nil
+	}
+
+	r, err := money.ParseRate(rate.Rate)
+	if err != nil {
+		return nil, err
+	}
+
+	rate.Base, rate.Quote = rate.Quote, rate.Base
+	rate.Rate = money.InvertRate(r).FloatString(money.RateDecimals)
+
+	return rate, nil
+}
+
+// parseCurrency reads an ISO currency code, empty is the base currency
+func parseCurrency(currency string) (string, error) {
+	currency = strings.ToUpper(strings.TrimSpace(currency))
+	if currency == "" {
+		return money.DefaultCurrency, nil
+	}
+
+	if !money.Known(currency) {
+		return "", errs.Validation("unknown_currency", "currency is not supported")
+	}
+
+	return currency, nil
+}
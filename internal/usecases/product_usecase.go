@@ -2,34 +2,58 @@ package usecases
 
 import (
 	"context"
-	"errors"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
 
 	"github.com/codepnw/react_go_ecom/internal/entities"
+	"github.com/codepnw/react_go_ecom/internal/errs"
 	"github.com/codepnw/react_go_ecom/internal/repositories"
 	"github.com/codepnw/react_go_ecom/internal/utils"
+	"github.com/codepnw/react_go_ecom/pkg/media"
+	"github.com/codepnw/react_go_ecom/pkg/money"
+	"github.com/codepnw/react_go_ecom/pkg/pagination"
 )
 
 type ProductUsecase interface {
 	Create(ctx context.Context, req *entities.ProductPayloadReq) (string, error)
-	GetByID(ctx context.Context, id string) (*entities.Product, error)
-	List(ctx context.Context, limit, offset string) ([]*entities.Product, error)
-	Update(ctx context.Context, id string, req entities.Product) error
+	GetByID(ctx context.Context, id, locale, currency string) (*entities.Product, error)
+	List(ctx context.Context, f *entities.ProductFilter, attrs map[string]string) (*entities.ProductListResult, error)
+	Update(ctx context.Context, actor, id string, req entities.Product) error
 	Delete(ctx context.Context, id string) error
-	Search(ctx context.Context, text string) ([]*entities.Product, error)
-	PurchaseProduct(req *entities.ProductStock) error
+	PurchaseProduct(ctx context.Context, req *entities.ProductStock) error
 	CheckOutOfStock() ([]*entities.Product, error)
 	RestockProduct(req *entities.ProductStock) error
 }
 
 type productUsecase struct {
-	repo repositories.ProductRepository
+	repo      repositories.ProductRepository
+	mediaRepo repositories.MediaRepository
+	attrRepo  repositories.AttributeRepository
+	blobs     media.BlobStore
+	indexer   SuggestIndexer
+	expander  QueryExpander
+	pricer    Pricer
 }
 
-func NewProductUsecase(repo repositories.ProductRepository) ProductUsecase {
-	return &productUsecase{repo: repo}
+func NewProductUsecase(repo repositories.ProductRepository, mediaRepo repositories.MediaRepository, attrRepo repositories.AttributeRepository, blobs media.BlobStore, indexer SuggestIndexer, expander QueryExpander, pricer Pricer) ProductUsecase {
+	return &productUsecase{
+		repo:      repo,
+		mediaRepo: mediaRepo,
+		attrRepo:  attrRepo,
+		blobs:     blobs,
+		indexer:   indexer,
+		expander:  expander,
+		pricer:    pricer,
+	}
 }
 
 func (uc *productUsecase) Create(ctx context.Context, req *entities.ProductPayloadReq) (string, error) {
+	if err := validatePrice(req.Price); err != nil {
+		return "", err
+	}
+
 	ctx, cancel := context.WithTimeout(ctx, contextTimeoutQuery)
 	defer cancel()
 
@@ -38,47 +62,241 @@ func (uc *productUsecase) Create(ctx context.Context, req *entities.ProductPaylo
 		Description: req.Description,
 		Price:       req.Price,
 		Stock:       req.Stock,
+		CategoryID:  req.CategoryID,
+		TaxClassID:  req.TaxClassID,
+		WeightGrams: req.WeightGrams,
+		LengthMM:    req.LengthMM,
+		WidthMM:     req.WidthMM,
+		HeightMM:    req.HeightMM,
 		CreatedAt:   utils.ThaiTime,
 	}
 
-	return uc.repo.Create(ctx, product)
+	id, err := uc.repo.Create(ctx, product)
+	if err != nil {
+		return "", err
+	}
+
+	product.ID = id
+	uc.indexer.ProductChanged(product)
+
+	return id, nil
 }
 
-func (uc *productUsecase) GetByID(ctx context.Context, id string) (*entities.Product, error) {
+func (uc *productUsecase) GetByID(ctx context.Context, id, locale, currency string) (*entities.Product, error) {
 	ctx, cancel := context.WithTimeout(ctx, contextTimeoutQuery)
 	defer cancel()
 
-	return uc.repo.GetByID(ctx, id)
+	product, err := uc.repo.GetByID(ctx, id, locale)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := uc.attachDetails(ctx, locale, product); err != nil {
+		return nil, err
+	}
+
+	if err := uc.priceIn(ctx, currency, product); err != nil {
+		return nil, err
+	}
+
+	return product, nil
 }
 
-func (uc *productUsecase) List(ctx context.Context, limit, offset string) ([]*entities.Product, error) {
+func (uc *productUsecase) List(ctx context.Context, f *entities.ProductFilter, attrs map[string]string) (*entities.ProductListResult, error) {
 	ctx, cancel := context.WithTimeout(ctx, contextTimeoutQuery)
 	defer cancel()
 
-	if limit == "" {
-		limit = "10"
+	f.Limit = pageLimit(f.Limit)
+
+	sortKeys, err := parseProductSort(f.Sort, f.Search != "")
+	if err != nil {
+		return nil, err
+	}
+	f.SortKeys = sortKeys
+
+	// Price filters, sorting and facets stay in the base currency, only the
+	// listed prices are converted
+	if f.Currency, err = parseCurrency(f.Currency); err != nil {
+		return nil, err
+	}
+
+	if f.After, err = decodeCursor(&f.PageReq, sortKeys); err != nil {
+		return nil, err
+	}
+
+	if len(attrs) > 0 {
+		if f.Attributes, err = uc.attributeFilters(ctx, attrs); err != nil {
+			return nil, err
+		}
+	}
+
+	if f.Search != "" {
+		f.SearchMode = entities.SearchFullText
+		f.SearchTerms = uc.expander.ExpandQuery(ctx, f.Search)
 	}
 
-	if offset == "" {
-		offset = "0"
+	// Later pages keep the match mode the search started with
+	if f.After != nil && f.After.Mode != "" {
+		f.SearchMode = f.After.Mode
 	}
 
-	products, err := uc.repo.List(ctx, limit, offset)
+	// The first page of a search is always counted, for the fuzzy fallback
+	// and the search log
+	firstSearch := f.Search != "" && f.After == nil
+
+	var total *int
+	if f.WithTotal || firstSearch {
+		n, err := uc.repo.Count(ctx, f)
+		if err != nil {
+			return nil, err
+		}
+
+		// Nothing matched the words as typed, retry as a typo tolerant match
+		if n == 0 && firstSearch {
+			f.SearchMode = entities.SearchFuzzy
+
+			if n, err = uc.repo.Count(ctx, f); err != nil {
+				return nil, err
+			}
+		}
+		total = &n
+	}
+
+	products, err := uc.repo.List(ctx, f)
 	if err != nil {
 		return nil, err
 	}
 
-	return products, nil
+	products, more := pagination.Trim(products, f.Limit, f.After != nil && f.After.Backward)
+
+	if err := uc.attachDetails(ctx, f.Locale, products...); err != nil {
+		return nil, err
+	}
+
+	if err := uc.priceIn(ctx, f.Currency, products...); err != nil {
+		return nil, err
+	}
+
+	var first, last []string
+	if len(products) > 0 {
+		first, last = products[0].SortValues, products[len(products)-1].SortValues
+	}
+
+	result := &entities.ProductListResult{
+		Items:     products,
+		PageInfo:  pageInfo(f.Limit, f.After, more, sortKeys, f.SearchMode, first, last),
+		MatchMode: f.SearchMode,
+	}
+
+	if f.WithTotal {
+		result.PageInfo.Total = total
+	}
+
+	// Later pages of the same search are not counted again
+	if firstSearch {
+		search := &entities.SearchQuery{
+			RawQuery:    f.Search,
+			ResultCount: *total,
+			MatchMode:   f.SearchMode,
+			UserID:      f.UserID,
+			SessionID:   f.SessionID,
+		}
+		uc.indexer.QuerySearched(ctx, search)
+		result.SearchID = search.ID
+	}
+
+	if f.Facets == nil || *f.Facets {
+		if result.Facets, err = uc.repo.Facets(ctx, f); err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
 }
 
-func (uc *productUsecase) Update(ctx context.Context, id string, req entities.Product) error {
+// attributeFilters resolves attr[code]=value query params against the
+// attribute definitions. Numbers take a "min..max" range where either side
+// may be empty, other types a comma separated list of values.
+func (uc *productUsecase) attributeFilters(ctx context.Context, attrs map[string]string) ([]*entities.AttributeFilter, error) {
+	definitions, err := uc.attrRepo.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	types := make(map[string]string, len(definitions))
+	for _, def := range definitions {
+		types[def.Code] = def.Type
+	}
+
+	codes := make([]string, 0, len(attrs))
+	for code := range attrs {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+
+	var filters []*entities.AttributeFilter
+	for _, code := range codes {
+		typ, ok := types[code]
+		if !ok {
+			return nil, errs.Validation("unknown_attribute", "unknown attribute %s", code)
+		}
+
+		af := &entities.AttributeFilter{Code: code, Type: typ}
+		value := attrs[code]
+
+		if typ == entities.AttributeNumber {
+			lo, hi, found := strings.Cut(value, "..")
+			if !found {
+				lo, hi = value, value
+			}
+
+			if af.Min, err = parseBound(lo); err != nil {
+				return nil, errs.Validation("invalid_attribute_range", "invalid range for attribute %s", code)
+			}
+			if af.Max, err = parseBound(hi); err != nil {
+				return nil, errs.Validation("invalid_attribute_range", "invalid range for attribute %s", code)
+			}
+		} else {
+			for _, v := range strings.Split(value, ",") {
+				if v = strings.TrimSpace(v); v != "" {
+					af.Values = append(af.Values, v)
+				}
+			}
+			if len(af.Values) == 0 {
+				return nil, errs.Validation("attribute_value_required", "attribute %s needs a value", code)
+			}
+		}
+
+		filters = append(filters, af)
+	}
+
+	return filters, nil
+}
+
+func (uc *productUsecase) Update(ctx context.Context, actor, id string, req entities.Product) error {
+	audit, err := priceAudit(actor, req.PriceReason)
+	if err != nil {
+		return err
+	}
+
+	// A zero price is left unchanged like the other fields
+	if !req.Price.IsZero() {
+		if err := validatePrice(req.Price); err != nil {
+			return err
+		}
+	}
+
 	ctx, cancel := context.WithTimeout(ctx, contextTimeoutQuery)
 	defer cancel()
 
-	if err := uc.repo.Update(ctx, id, req); err != nil {
+	if err := uc.repo.Update(ctx, id, req, audit); err != nil {
 		return err
 	}
 
+	if product, err := uc.repo.GetByID(ctx, id, ""); err == nil {
+		uc.indexer.ProductChanged(product)
+	}
+
 	return nil
 }
 
@@ -86,38 +304,175 @@ func (uc *productUsecase) Delete(ctx context.Context, id string) error {
 	ctx, cancel := context.WithTimeout(ctx, contextTimeoutQuery)
 	defer cancel()
 
-	return uc.repo.Delete(ctx, id)
+	images, err := uc.mediaRepo.ListProductImages(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := uc.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+	uc.indexer.ProductRemoved(id)
+
+	// Image rows are removed by cascade, the blobs are not
+	for _, img := range images {
+		keys := []string{img.Key}
+		for _, rd := range img.Renditions {
+			keys = append(keys, rd.Key)
+		}
+
+		for _, key := range keys {
+			if err := uc.blobs.Delete(ctx, key); err != nil {
+				log.Println("remove blob:", key, err)
+			}
+		}
+	}
+
+	return nil
 }
 
-func (uc *productUsecase) Search(ctx context.Context, text string) ([]*entities.Product, error) {
-	return uc.repo.Search(ctx, text)
+func (uc *productUsecase) PurchaseProduct(ctx context.Context, req *entities.ProductStock) error {
+	ctx, cancel := context.WithTimeout(ctx, contextTimeoutQuery)
+	defer cancel()
+
+	return uc.repo.Purchase(ctx, req.ProductID, req.Quantity)
+}
+
+func (uc *productUsecase) CheckOutOfStock() ([]*entities.Product, error) {
+	return uc.repo.CheckOutOfStock()
+}
+
+func (uc *productUsecase) RestockProduct(req *entities.ProductStock) error {
+	return uc.repo.RestockProduct(req)
 }
 
-func (uc *productUsecase) PurchaseProduct(req *entities.ProductStock) error {
-	stock, err := uc.repo.CheckStock(req.ProductID)
+// attachDetails loads the ordered gallery and attribute values of the
+// products, one query each for the whole page. Attribute labels are in
+// locale.
+func (uc *productUsecase) attachDetails(ctx context.Context, locale string, products ...*entities.Product) error {
+	if len(products) == 0 {
+		return nil
+	}
+
+	ids := make([]string, len(products))
+	for i, p := range products {
+		ids[i] = p.ID
+	}
+
+	images, err := uc.mediaRepo.ListImagesByProducts(ctx, ids)
 	if err != nil {
 		return err
 	}
 
-	if stock < req.Quantity {
-		return errors.New("not enough stock")
+	attributes, err := uc.attrRepo.ListValuesByProducts(ctx, ids, locale)
+	if err != nil {
+		return err
 	}
 
-	if err := uc.repo.ReduceStock(req.ProductID, req.Quantity); err != nil {
-		return err
+	for _, p := range products {
+		p.Images = images[p.ID]
+		if p.Images == nil {
+			p.Images = []*entities.ProductImage{}
+		}
+		for _, img := range p.Images {
+			imageURLs(uc.blobs, img)
+		}
+
+		p.Attributes = attributes[p.ID]
+		if p.Attributes == nil {
+			p.Attributes = []*entities.ProductAttribute{}
+		}
 	}
 
-	if err := uc.repo.AddSoldQuantity(req.ProductID, req.Quantity); err != nil {
+	return nil
+}
+
+// priceIn replaces the base prices of the products with their prices in
+// currency
+func (uc *productUsecase) priceIn(ctx context.Context, currency string, products ...*entities.Product) error {
+	base := make(map[string]money.Money, len(products))
+	for _, p := range products {
+		base[p.ID] = p.Price
+	}
+
+	prices, _, err := uc.pricer.Prices(ctx, currency, base)
+	if err != nil {
 		return err
 	}
 
+	for _, p := range products {
+		p.Price = prices[p.ID]
+	}
+
 	return nil
 }
 
-func (uc *productUsecase) CheckOutOfStock() ([]*entities.Product, error) {
-	return uc.repo.CheckOutOfStock()
+// parseProductSort reads a comma separated list of sort keys, a leading "-"
+// sorts descending. "newest" and "best_selling" are shortcuts, "relevance"
+// only applies to searches. Without keys a search is ordered by relevance
+// and a listing by newest first.
+func parseProductSort(spec string, searching bool) ([]*entities.SortKey, error) {
+	var keys []*entities.SortKey
+
+	for _, field := range strings.Split(spec, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+
+		key := &entities.SortKey{Field: strings.TrimPrefix(field, "-"), Desc: strings.HasPrefix(field, "-")}
+
+		switch key.Field {
+		case "newest":
+			key = &entities.SortKey{Field: "created_at", Desc: true}
+		case "best_selling":
+			key = &entities.SortKey{Field: "quantity", Desc: true}
+		case "relevance":
+			if !searching {
+				continue
+			}
+			key = &entities.SortKey{Field: "relevance", Desc: true}
+		case "price", "created_at", "quantity", "title":
+		default:
+			return nil, errs.Validation("invalid_sort", "cannot sort by %s", key.Field)
+		}
+
+		keys = append(keys, key)
+	}
+
+	if len(keys) == 0 && searching {
+		keys = append(keys, &entities.SortKey{Field: "relevance", Desc: true})
+	}
+
+	if len(keys) == 0 {
+		keys = append(keys, &entities.SortKey{Field: "created_at", Desc: true})
+	}
+
+	return keys, nil
 }
 
-func (uc *productUsecase) RestockProduct(req *entities.ProductStock) error {
-	return uc.repo.RestockProduct(req)
+// validatePrice accepts positive prices in the catalog currency
+func validatePrice(price money.Money) error {
+	if price.Currency != money.DefaultCurrency {
+		return errs.Validation("unsupported_currency", "prices are in %s", money.DefaultCurrency)
+	}
+
+	if price.Amount <= 0 {
+		return errs.Validation("invalid_price", "price must be greater than zero")
+	}
+
+	return nil
+}
+
+func parseBound(s string) (*float64, error) {
+	if s = strings.TrimSpace(s); s == "" {
+		return nil, nil
+	}
+
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return nil, err
+	}
+
+	return &v, nil
 }
@@ -0,0 +1,43 @@
+package usecases
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/codepnw/react_go_ecom/internal/entities"
+)
+
+func TestParseProductSort(t *testing.T) {
+	tests := []struct {
+		spec      string
+		searching bool
+		want      []entities.SortKey
+	}{
+		{"", false, []entities.SortKey{{Field: "created_at", Desc: true}}},
+		{"", true, []entities.SortKey{{Field: "relevance", Desc: true}}},
+		{"-price, title", false, []entities.SortKey{{Field: "price", Desc: true}, {Field: "title"}}},
+		{"best_selling,newest", false, []entities.SortKey{{Field: "quantity", Desc: true}, {Field: "created_at", Desc: true}}},
+		{"relevance", false, []entities.SortKey{{Field: "created_at", Desc: true}}},
+		{"price,relevance", true, []entities.SortKey{{Field: "price"}, {Field: "relevance", Desc: true}}},
+	}
+
+	for _, tt := range tests {
+		keys, err := parseProductSort(tt.spec, tt.searching)
+		if err != nil {
+			t.Errorf("parseProductSort(%q) = %v", tt.spec, err)
+			continue
+		}
+
+		got := make([]entities.SortKey, len(keys))
+		for i, k := range keys {
+			got[i] = *k
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("parseProductSort(%q, %v) = %v, want %v", tt.spec, tt.searching, got, tt.want)
+		}
+	}
+
+	if _, err := parseProductSort("stock", false); err == nil {
+		t.Error("sorting by stock was accepted")
+	}
+}
@@ -0,0 +1,367 @@
+package usecases
+
+import (
+	"math/big"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/codepnw/react_go_ecom/internal/entities"
+	"github.com/codepnw/react_go_ecom/pkg/money"
+)
+
+// Why an entered coupon was not applied, the code is the only argument
+var couponReasons = map[string]string{
+	"coupon_not_found":      "coupon %s does not exist",
+	"coupon_inactive":       "coupon %s is not active",
+	"coupon_not_started":    "coupon %s is not valid yet",
+	"coupon_expired":        "coupon %s has expired",
+	"coupon_exhausted":      "coupon %s has been used up",
+	"coupon_user_limit":     "you have already used coupon %s",
+	"coupon_currency":       "coupon %s is not valid in this currency",
+	"coupon_min_spend":      "the cart does not reach the minimum spend of coupon %s",
+	"coupon_not_applicable": "coupon %s does not apply to any item in the cart",
+	"coupon_not_combinable": "coupon %s cannot be combined with a better offer",
+}
+
+// promotionResult is the discount of one promotion on its own, per product
+type promotionResult struct {
+	promo        *entities.Promotion
+	lines        map[string]money.Money
+	freeShipping bool
+}
+
+func (r *promotionResult) total(currency string) money.Money {
+	total := money.New(0, currency)
+	for _, d := range r.lines {
+		total = total.Add(d)
+	}
+	return total
+}
+
+// applyPromotions fills the discount breakdown of a priced cart. promos
+// holds the automatic promotions and the promotions of the entered coupons,
+// usage how often the user already used each one.
+//
+// Stackable promotions add up in priority order, each line capped at what
+// is left of it. A promotion that is not stackable applies alone, the
+// larger discount of the stack and each exclusive promotion wins. Free
+// shipping does not reduce item prices and always combines.
+func applyPromotions(cart *entities.Cart, promos []*entities.Promotion, coupons []string, usage map[int]int, now time.Time) {
+	cart.Discounts = []*entities.Discount{}
+	cart.DiscountTotal = money.New(0, cart.Currency)
+	cart.FreeShipping = false
+	cart.RejectedCoupons = nil
+
+	byCode := make(map[string]*entities.Promotion)
+	var candidates []*entities.Promotion
+	for _, p := range promos {
+		if p.Code == nil {
+			candidates = append(candidates, p)
+		} else {
+			byCode[*p.Code] = p
+		}
+	}
+
+	reject := func(code, reason string) {
+		cart.RejectedCoupons = append(cart.RejectedCoupons, &entities.RejectedCoupon{
+			Code:    code,
+			Reason:  reason,
+			Message: strings.Replace(couponReasons[reason], "%s", code, 1),
+		})
+	}
+
+	for _, code := range normalizeCoupons(coupons) {
+		p, ok := byCode[code]
+		if !ok {
+			reject(code, "coupon_not_found")
+			continue
+		}
+		candidates = append(candidates, p)
+	}
+
+	var stack, exclusive, shipping []*promotionResult
+	for _, p := range candidates {
+		reason := promotionEligibility(p, cart, usage, now)
+
+		var result *promotionResult
+		if reason == "" {
+			result = evaluatePromotion(p, cart)
+			if result.total(cart.Currency).IsZero() && !result.freeShipping {
+				reason = "coupon_not_applicable"
+			}
+		}
+
+		if reason != "" {
+			if p.Code != nil {
+				reject(*p.Code, reason)
+			}
+			continue
+		}
+
+		switch {
+		case result.freeShipping:
+			shipping = append(shipping, result)
+		case p.Stackable:
+			stack = append(stack, result)
+		default:
+			exclusive = append(exclusive, result)
+		}
+	}
+
+	sort.SliceStable(stack, func(i, j int) bool { return promotionFirst(stack[i].promo, stack[j].promo) })
+	sort.SliceStable(exclusive, func(i, j int) bool { return promotionFirst(exclusive[i].promo, exclusive[j].promo) })
+
+	best, bestTotal := combinePromotions(cart, stack), money.New(0, cart.Currency)
+	for _, r := range best {
+		bestTotal = bestTotal.Add(r.total(cart.Currency))
+	}
+
+	for _, r := range exclusive {
+		if t := r.total(cart.Currency); t.Cmp(bestTotal) > 0 {
+			best, bestTotal = []*promotionResult{r}, t
+		}
+	}
+
+	applied := make(map[int]bool)
+	for _, r := range append(best, shipping...) {
+		applied[r.promo.ID] = true
+		if r.freeShipping {
+			cart.FreeShipping = true
+			cart.Discounts = append(cart.Discounts, newDiscount(r.promo, "", money.New(0, cart.Currency)))
+			continue
+		}
+
+		for _, item := range cart.Items {
+			if d, ok := r.lines[item.ProductID]; ok && !d.IsZero() {
+				cart.Discounts = append(cart.Discounts, newDiscount(r.promo, item.ProductID, d))
+				cart.DiscountTotal = cart.DiscountTotal.Add(d)
+			}
+		}
+	}
+
+	for _, r := range append(stack, exclusive...) {
+		if !applied[r.promo.ID] && r.promo.Code != nil {
+			reject(*r.promo.Code, "coupon_not_combinable")
+		}
+	}
+
+	cart.Total = cart.Subtotal.Sub(cart.DiscountTotal)
+}
+
+// combinePromotions caps the stackable discounts so no line goes below
+// zero, a promotion left without discount drops out
+func combinePromotions(cart *entities.Cart, stack []*promotionResult) []*promotionResult {
+	remaining := make(map[string]money.Money, len(cart.Items))
+	for _, item := range cart.Items {
+		remaining[item.ProductID] = item.LineTotal
+	}
+
+	var combined []*promotionResult
+	for _, r := range stack {
+		capped := &promotionResult{promo: r.promo, lines: make(map[string]money.Money)}
+
+		for id, d := range r.lines {
+			if d.Cmp(remaining[id]) > 0 {
+				d = remaining[id]
+			}
+			if !d.IsZero() {
+				capped.lines[id] = d
+				remaining[id] = remaining[id].Sub(d)
+			}
+		}
+
+		if len(capped.lines) > 0 {
+			combined = append(combined, capped)
+		}
+	}
+
+	return combined
+}
+
+// promotionEligibility returns the reason the promotion does not apply to
+// the cart, empty when it does
+func promotionEligibility(p *entities.Promotion, cart *entities.Cart, usage map[int]int, now time.Time) string {
+	switch {
+	case !p.Active:
+		return "coupon_inactive"
+	case now.Before(p.StartsAt):
+		return "coupon_not_started"
+	case p.EndsAt != nil && !now.Before(*p.EndsAt):
+		return "coupon_expired"
+	case p.UsageLimit != nil && p.UsedCount >= *p.UsageLimit:
+		return "coupon_exhausted"
+	case p.PerUserLimit != nil && usage[p.ID] >= *p.PerUserLimit:
+		return "coupon_user_limit"
+	case (p.Amount != nil || p.MinSpend != nil) && p.Currency != cart.Currency:
+		return "coupon_currency"
+	}
+
+	if p.MinSpend != nil {
+		spend := money.New(0, cart.Currency)
+		for _, item := range eligibleItems(p, cart) {
+			spend = spend.Add(item.LineTotal)
+		}
+		if spend.Cmp(*p.MinSpend) < 0 {
+			return "coupon_min_spend"
+		}
+	}
+
+	return ""
+}
+
+// evaluatePromotion computes the discount of the promotion on its own
+func evaluatePromotion(p *entities.Promotion, cart *entities.Cart) *promotionResult {
+	result := &promotionResult{promo: p, lines: make(map[string]money.Money)}
+	items := eligibleItems(p, cart)
+
+	switch p.Kind {
+	case entities.PromotionPercentage:
+		percent, ok := new(big.Rat).SetString(*p.Percent)
+		if !ok {
+			return result
+		}
+		rate := percent.Quo(percent, big.NewRat(100, 1))
+
+		for _, item := range items {
+			result.lines[item.ProductID] = item.LineTotal.Mul(rate)
+		}
+
+	case entities.PromotionFixedAmount:
+		weights := make([]money.Money, len(items))
+		for i, item := range items {
+			weights[i] = item.LineTotal
+		}
+
+		for i, share := range allocate(*p.Amount, weights) {
+			result.lines[items[i].ProductID] = share
+		}
+
+	case entities.PromotionBuyXGetY:
+		// Every block of buy+get units, most expensive first, gets its get
+		// cheapest units free
+		type unit struct {
+			productID string
+			price     money.Money
+		}
+
+		var units []unit
+		for _, item := range items {
+			for n := 0; n < item.Quantity; n++ {
+				units = append(units, unit{item.ProductID, item.UnitPrice})
+			}
+		}
+		sort.SliceStable(units, func(i, j int) bool { return units[i].price.Cmp(units[j].price) > 0 })
+
+		block := *p.BuyQuantity + *p.GetQuantity
+		for i, u := range units[:len(units)-len(units)%block] {
+			if i%block >= *p.BuyQuantity {
+				result.lines[u.productID] = result.lines[u.productID].Add(u.price)
+			}
+		}
+
+	case entities.PromotionFreeShipping:
+		result.freeShipping = len(items) > 0
+	}
+
+	return result
+}
+
+// eligibleItems are the cart lines in the promotion's scope
+func eligibleItems(p *entities.Promotion, cart *entities.Cart) []*entities.CartItem {
+	if len(p.ProductIDs) == 0 && len(p.CategoryIDs) == 0 {
+		return cart.Items
+	}
+
+	var items []*entities.CartItem
+	for _, item := range cart.Items {
+		inScope := false
+		for _, id := range p.ProductIDs {
+			inScope = inScope || id == item.ProductID
+		}
+		for _, id := range p.CategoryIDs {
+			inScope = inScope || (item.CategoryID != nil && int64(*item.CategoryID) == id)
+		}
+
+		if inScope {
+			items = append(items, item)
+		}
+	}
+
+	return items
+}
+
+// allocate splits amount over the weights in proportion, capped at their
+// sum. Minor units lost to rounding down go to the largest remainders so
+// the shares add up exactly.
+func allocate(amount money.Money, weights []money.Money) []money.Money {
+	shares := make([]money.Money, len(weights))
+
+	total := new(big.Int)
+	for _, w := range weights {
+		total.Add(total, big.NewInt(w.Amount))
+	}
+	if total.Sign() == 0 {
+		return shares
+	}
+
+	if big.NewInt(amount.Amount).Cmp(total) > 0 {
+		amount = money.New(total.Int64(), amount.Currency)
+	}
+
+	rems := make([]*big.Int, len(weights))
+	left := amount.Amount
+	for i, w := range weights {
+		q, r := new(big.Int).QuoRem(new(big.Int).Mul(big.NewInt(amount.Amount), big.NewInt(w.Amount)), total, new(big.Int))
+		shares[i] = money.New(q.Int64(), amount.Currency)
+		rems[i] = r
+		left -= q.Int64()
+	}
+
+	order := make([]int, len(weights))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool { return rems[order[a]].Cmp(rems[order[b]]) > 0 })
+
+	for _, i := range order[:left] {
+		shares[i].Amount++
+	}
+
+	return shares
+}
+
+func promotionFirst(a, b *entities.Promotion) bool {
+	if a.Priority != b.Priority {
+		return a.Priority > b.Priority
+	}
+	return a.ID < b.ID
+}
+
+func newDiscount(p *entities.Promotion, productID string, amount money.Money) *entities.Discount {
+	return &entities.Discount{
+		PromotionID: p.ID,
+		Code:        p.Code,
+		Name:        p.Name,
+		Kind:        p.Kind,
+		ProductID:   productID,
+		Amount:      amount,
+	}
+}
+
+// normalizeCoupons upper cases the entered codes and drops blanks and
+// repeats
+func normalizeCoupons(coupons []string) []string {
+	seen := make(map[string]bool, len(coupons))
+
+	var codes []string
+	for _, c := range coupons {
+		c = strings.ToUpper(strings.TrimSpace(c))
+		if c != "" && !seen[c] {
+			seen[c] = true
+			codes = append(codes, c)
+		}
+	}
+
+	return codes
+}
@@ -0,0 +1,160 @@
+package usecases
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/codepnw/react_go_ecom/internal/entities"
+	"github.com/codepnw/react_go_ecom/pkg/money"
+)
+
+func thb(amount int64) money.Money {
+	return money.New(amount, "THB")
+}
+
+func TestAllocate(t *testing.T) {
+	tests := []struct {
+		name    string
+		amount  money.Money
+		weights []money.Money
+		want    []money.Money
+	}{
+		{
+			name:    "remainder to the largest fraction",
+			amount:  thb(1000),
+			weights: []money.Money{thb(30000), thb(5000)},
+			want:    []money.Money{thb(857), thb(143)},
+		},
+		{
+			name:    "even split",
+			amount:  thb(100),
+			weights: []money.Money{thb(100), thb(100), thb(100)},
+			want:    []money.Money{thb(34), thb(33), thb(33)},
+		},
+		{
+			name:    "capped at the weights",
+			amount:  thb(50000),
+			weights: []money.Money{thb(30000), thb(5000)},
+			want:    []money.Money{thb(30000), thb(5000)},
+		},
+		{
+			name:    "nothing to weigh",
+			amount:  thb(1000),
+			weights: []money.Money{{}, {}},
+			want:    []money.Money{{}, {}},
+		},
+	}
+
+	for _, tt := range tests {
+		got := allocate(tt.amount, tt.weights)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: allocate = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func promoCart() *entities.Cart {
+	cart := &entities.Cart{
+		Currency: "THB",
+		Items: []*entities.CartItem{
+			{ProductID: "a", Quantity: 3, UnitPrice: thb(10000), LineTotal: thb(30000)},
+			{ProductID: "b", Quantity: 1, UnitPrice: thb(5000), LineTotal: thb(5000)},
+		},
+		Subtotal: thb(35000),
+	}
+	return cart
+}
+
+func promotion(id int, kind string) *entities.Promotion {
+	return &entities.Promotion{
+		ID:       id,
+		Kind:     kind,
+		Currency: "THB",
+		StartsAt: time.Now().Add(-time.Hour),
+		Active:   true,
+	}
+}
+
+func discountsByProduct(cart *entities.Cart) map[string]int64 {
+	lines := make(map[string]int64)
+	for _, d := range cart.Discounts {
+		lines[d.ProductID] += d.Amount.Amount
+	}
+	return lines
+}
+
+func TestApplyPromotionsExclusiveBeatsSmallerStack(t *testing.T) {
+	percent := promotion(1, entities.PromotionPercentage)
+	ten := "10"
+	percent.Percent = &ten
+	percent.Stackable = true
+
+	fixed := promotion(2, entities.PromotionFixedAmount)
+	code, amount := "SAVE50", thb(5000)
+	fixed.Code, fixed.Amount = &code, &amount
+
+	cart := promoCart()
+	applyPromotions(cart, []*entities.Promotion{percent, fixed}, []string{" save50 ", "bogus"}, nil, time.Now())
+
+	if cart.DiscountTotal != thb(5000) || cart.Total != thb(30000) {
+		t.Errorf("discount %v, total %v, want 50.00 and 300.00", cart.DiscountTotal, cart.Total)
+	}
+
+	if got := discountsByProduct(cart); !reflect.DeepEqual(got, map[string]int64{"a": 4286, "b": 714}) {
+		t.Errorf("lines = %v", got)
+	}
+
+	if len(cart.RejectedCoupons) != 1 || cart.RejectedCoupons[0].Code != "BOGUS" || cart.RejectedCoupons[0].Reason != "coupon_not_found" {
+		t.Errorf("rejected = %+v", cart.RejectedCoupons)
+	}
+}
+
+func TestApplyPromotionsStackCapsLines(t *testing.T) {
+	bxgy := promotion(1, entities.PromotionBuyXGetY)
+	buy, get := 2, 1
+	bxgy.BuyQuantity, bxgy.GetQuantity = &buy, &get
+	bxgy.Stackable, bxgy.Priority = true, 10
+	bxgy.ProductIDs = []string{"a"}
+
+	percent := promotion(2, entities.PromotionPercentage)
+	ten := "10"
+	percent.Percent = &ten
+	percent.Stackable = true
+
+	cart := promoCart()
+	applyPromotions(cart, []*entities.Promotion{percent, bxgy}, nil, nil, time.Now())
+
+	// One of three units of a is free, 10% comes off the rest of each line
+	if got := discountsByProduct(cart); !reflect.DeepEqual(got, map[string]int64{"a": 13000, "b": 500}) {
+		t.Errorf("lines = %v", got)
+	}
+	if cart.Total != thb(21500) {
+		t.Errorf("total = %v, want 215.00", cart.Total)
+	}
+}
+
+func TestApplyPromotionsRejectsIneligibleCoupons(t *testing.T) {
+	expired := promotion(1, entities.PromotionPercentage)
+	code, ten, ended := "OLD", "10", time.Now().Add(-time.Minute)
+	expired.Code, expired.Percent, expired.EndsAt = &code, &ten, &ended
+
+	minSpend := promotion(2, entities.PromotionFixedAmount)
+	code2, amount, spend := "BIG", thb(1000), thb(100000)
+	minSpend.Code, minSpend.Amount, minSpend.MinSpend = &code2, &amount, &spend
+
+	cart := promoCart()
+	applyPromotions(cart, []*entities.Promotion{expired, minSpend}, []string{"old", "big"}, nil, time.Now())
+
+	reasons := make(map[string]string)
+	for _, r := range cart.RejectedCoupons {
+		reasons[r.Code] = r.Reason
+	}
+	want := map[string]string{"OLD": "coupon_expired", "BIG": "coupon_min_spend"}
+	if !reflect.DeepEqual(reasons, want) {
+		t.Errorf("rejected = %v, want %v", reasons, want)
+	}
+	if !cart.DiscountTotal.IsZero() || cart.Total != cart.Subtotal {
+		t.Errorf("discount %v, total %v", cart.DiscountTotal, cart.Total)
+	}
+}
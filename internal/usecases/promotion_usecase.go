@@ -0,0 +1,197 @@
+package usecases
+
+import (
+	"context"
+	"math/big"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/codepnw/react_go_ecom/internal/entities"
+	"github.com/codepnw/react_go_ecom/internal/errs"
+	"github.com/codepnw/react_go_ecom/internal/repositories"
+	"github.com/codepnw/react_go_ecom/pkg/money"
+)
+
+var couponCodeRegex = regexp.MustCompile(`^[A-Z0-9_-]{3,50}$`)
+
+// Discounter applies the automatic promotions and the entered coupons to a
+// priced cart, filling its discount breakdown and total
+type Discounter interface {
+	ApplyPromotions(ctx context.Context, userID string, cart *entities.Cart, coupons []string) error
+}
+
+type PromotionUsecase interface {
+	Discounter
+	CreatePromotion(ctx context.Context, req *entities.PromotionReq) (*entities.Promotion, error)
+	ListPromotions(ctx context.Context) ([]*entities.Promotion, error)
+	GetPromotion(ctx context.Context, id int) (*entities.Promotion, error)
+	UpdatePromotion(ctx context.Context, id int, req *entities.PromotionReq) (*entities.Promotion, error)
+	DeletePromotion(ctx context.Context, id int) error
+}
+
+type promotionUsecase struct {
+	repo repositories.PromotionRepository
+}
+
+func NewPromotionUsecase(repo repositories.PromotionRepository) PromotionUsecase {
+	return &promotionUsecase{repo: repo}
+}
+
+func (uc *promotionUsecase) CreatePromotion(ctx context.Context, req *entities.PromotionReq) (*entities.Promotion, error) {
+	promo, err := buildPromotion(req)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, contextTimeoutQuery)
+	defer cancel()
+
+	if err := uc.repo.Create(ctx, promo); err != nil {
+		return nil, err
+	}
+
+	return promo, nil
+}
+
+func (uc *promotionUsecase) ListPromotions(ctx context.Context) ([]*entities.Promotion, error) {
+	ctx, cancel := context.WithTimeout(ctx, contextTimeoutQuery)
+	defer cancel()
+
+	return uc.repo.List(ctx)
+}
+
+func (uc *promotionUsecase) GetPromotion(ctx context.Context, id int) (*entities.Promotion, error) {
+	ctx, cancel := context.WithTimeout(ctx, contextTimeoutQuery)
+	defer cancel()
+
+	return uc.repo.GetByID(ctx, id)
+}
+
+func (uc *promotionUsecase) UpdatePromotion(ctx context.Context, id int, req *entities.PromotionReq) (*entities.Promotion, error) {
+	promo, err := buildPromotion(req)
+	if err != nil {
+		return nil, err
+	}
+	promo.ID = id
+
+	ctx, cancel := context.WithTimeout(ctx, contextTimeoutQuery)
+	defer cancel()
+
+	if err := uc.repo.Update(ctx, promo); err != nil {
+		return nil, err
+	}
+
+	return promo, nil
+}
+
+func (uc *promotionUsecase) DeletePromotion(ctx context.Context, id int) error {
+	ctx, cancel := context.WithTimeout(ctx, contextTimeoutQuery)
+	defer cancel()
+
+	return uc.repo.Delete(ctx, id)
+}
+
+func (uc *promotionUsecase) ApplyPromotions(ctx context.Context, userID string, cart *entities.Cart, coupons []string) error {
+	now := time.Now()
+
+	promos, err := uc.repo.ListApplicable(ctx, normalizeCoupons(coupons), now)
+	if err != nil {
+		return err
+	}
+
+	ids := make([]int, len(promos))
+	for i, p := range promos {
+		ids[i] = p.ID
+	}
+
+	usage := map[int]int{}
+	if userID != "" && len(ids) > 0 {
+		if usage, err = uc.repo.UserUsage(ctx, userID, ids); err != nil {
+			return err
+		}
+	}
+
+	applyPromotions(cart, promos, coupons, usage, now)
+
+	return nil
+}
+
+// buildPromotion validates the request and keeps only the fields of its
+// kind
+func buildPromotion(req *entities.PromotionReq) (*entities.Promotion, error) {
+	currency, err := parseCurrency(req.Currency)
+	if err != nil {
+		return nil, err
+	}
+
+	promo := &entities.Promotion{
+		Name:         req.Name,
+		Kind:         req.Kind,
+		Currency:     currency,
+		ProductIDs:   req.ProductIDs,
+		CategoryIDs:  req.CategoryIDs,
+		StartsAt:     time.Now(),
+		EndsAt:       req.EndsAt,
+		UsageLimit:   req.UsageLimit,
+		PerUserLimit: req.PerUserLimit,
+		Stackable:    req.Stackable,
+		Priority:     req.Priority,
+		Active:       req.Active == nil || *req.Active,
+	}
+
+	if promo.ProductIDs == nil {
+		promo.ProductIDs = []string{}
+	}
+	if promo.CategoryIDs == nil {
+		promo.CategoryIDs = []int64{}
+	}
+
+	if code := strings.ToUpper(strings.TrimSpace(req.Code)); code != "" {
+		if !couponCodeRegex.MatchString(code) {
+			return nil, errs.Validation("invalid_coupon_code", "coupon code must be 3 to 50 letters, digits, - or _")
+		}
+		promo.Code = &code
+	}
+
+	if req.StartsAt != nil {
+		promo.StartsAt = *req.StartsAt
+	}
+	if promo.EndsAt != nil && !promo.EndsAt.After(promo.StartsAt) {
+		return nil, errs.Validation("invalid_promotion_window", "ends_at must be after starts_at")
+	}
+
+	switch req.Kind {
+	case entities.PromotionPercentage:
+		percent, ok := new(big.Rat).SetString(strings.TrimSpace(req.Percent))
+		if !ok || strings.ContainsAny(req.Percent, "/eE") || percent.Sign() <= 0 || percent.Cmp(big.NewRat(100, 1)) > 0 ||
+			!new(big.Rat).Mul(percent, big.NewRat(100, 1)).IsInt() {
+			return nil, errs.Validation("invalid_percent", "percent must be above 0 and at most 100 with up to 2 decimals")
+		}
+		p := percent.FloatString(2)
+		promo.Percent = &p
+
+	case entities.PromotionFixedAmount:
+		amount, err := money.Parse(req.Amount, currency)
+		if err != nil || amount.Amount <= 0 {
+			return nil, errs.Validation("invalid_promotion_amount", "amount must be greater than zero")
+		}
+		promo.Amount = &amount
+
+	case entities.PromotionBuyXGetY:
+		if req.BuyQuantity < 1 || req.GetQuantity < 1 {
+			return nil, errs.Validation("invalid_buy_x_get_y", "buy_quantity and get_quantity must be at least 1")
+		}
+		promo.BuyQuantity, promo.GetQuantity = &req.BuyQuantity, &req.GetQuantity
+	}
+
+	if req.MinSpend != "" {
+		minSpend, err := money.Parse(req.MinSpend, currency)
+		if err != nil || minSpend.Amount <= 0 {
+			return nil, errs.Validation("invalid_min_spend", "min_spend must be greater than zero")
+		}
+		promo.MinSpend = &minSpend
+	}
+
+	return promo, nil
+}
@@ -0,0 +1,411 @@
+package usecases
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math/big"
+
+	"github.com/codepnw/react_go_ecom/internal/entities"
+	"github.com/codepnw/react_go_ecom/internal/errs"
+	"github.com/codepnw/react_go_ecom/internal/repositories"
+	"github.com/codepnw/react_go_ecom/pkg/media"
+	"github.com/codepnw/react_go_ecom/pkg/money"
+	"github.com/codepnw/react_go_ecom/pkg/payment"
+)
+
+// Photos a customer can attach to one return
+const maxReturnPhotos = 5
+
+var (
+	errReturnNotFound      = errs.NotFound("return_not_found", "return not found")
+	errReturnPhotoNotFound = errs.NotFound("return_photo_not_found", "return photo not found")
+)
+
+type ReturnUsecase interface {
+	RequestReturn(ctx context.Context, userID string, orderID int64, req *entities.ReturnReq) (*entities.Return, error)
+	ListOrderReturns(ctx context.Context, userID string, orderID int64) ([]*entities.Return, error)
+	AddPhoto(ctx context.Context, userID string, orderID, id int64, file io.Reader) (*entities.ReturnPhoto, error)
+	Photo(ctx context.Context, userID string, id, photoID int64) (*entities.ReturnPhoto, []byte, error)
+	ListReturns(ctx context.Context, f *entities.ReturnFilter) ([]*entities.Return, error)
+	GetReturn(ctx context.Context, userID string, id int64) (*entities.Return, error)
+	Approve(ctx context.Context, id int64, req *entities.ReturnDecisionReq) (*entities.Return, error)
+	Reject(ctx context.Context, id int64, req *entities.ReturnDecisionReq) (*entities.Return, error)
+	Receive(ctx context.Context, id int64, req *entities.ReturnReceiptReq) (*entities.Return, error)
+	Refund(ctx context.Context, id int64, req *entities.RefundReq) (*entities.Return, error)
+}
+
+type returnUsecase struct {
+	repo      repositories.ReturnRepository
+	orderRepo repositories.OrderRepository
+	payments  payment.Provider
+	documents media.BlobStore
+}
+
+// NewReturnUsecase keeps the photos in documents, a store that is not
+// served publicly, as they often show the customer's home
+func NewReturnUsecase(repo repositories.ReturnRepository, orderRepo repositories.OrderRepository, payments payment.Provider, documents media.BlobStore) ReturnUsecase {
+	return &returnUsecase{
+		repo:      repo,
+		orderRepo: orderRepo,
+		payments:  payments,
+		documents: documents,
+	}
+}
+
+func (uc *returnUsecase) RequestReturn(ctx context.Context, userID string, orderID int64, req *entities.ReturnReq) (*entities.Return, error) {
+	ctx, cancel := context.WithTimeout(ctx, contextTimeoutQuery)
+	defer cancel()
+
+	if _, err := uc.ownOrder(ctx, userID, orderID); err != nil {
+		return nil, err
+	}
+
+	ret := &entities.Return{
+		OrderID: orderID,
+		UserID:  userID,
+		Reason:  req.Reason,
+		Comment: req.Comment,
+	}
+
+	// Lines listed twice are one return line
+	index := map[int64]*entities.ReturnItem{}
+	for _, item := range req.Items {
+		if existing, ok := index[item.OrderItemID]; ok {
+			existing.Quantity += item.Quantity
+			continue
+		}
+		index[item.OrderItemID] = &entities.ReturnItem{OrderItemID: item.OrderItemID, Quantity: item.Quantity}
+		ret.Items = append(ret.Items, index[item.OrderItemID])
+	}
+
+	if err := uc.repo.Create(ctx, ret); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+func (uc *returnUsecase) ListOrderReturns(ctx context.Context, userID string, orderID int64) ([]*entities.Return, error) {
+	ctx, cancel := context.WithTimeout(ctx, contextTimeoutQuery)
+	defer cancel()
+
+	if _, err := uc.ownOrder(ctx, userID, orderID); err != nil {
+		return nil, err
+	}
+
+	returns, err := uc.repo.ListByOrder(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	return returns, nil
+}
+
+// AddPhoto attaches a photo to a return that was not decided on yet
+func (uc *returnUsecase) AddPhoto(ctx context.Context, userID string, orderID, id int64, file io.Reader) (*entities.ReturnPhoto, error) {
+	ctx, cancel := context.WithTimeout(ctx, contextTimeoutQuery)
+	defer cancel()
+
+	ret, err := uc.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if ret.UserID != userID || ret.OrderID != orderID {
+		return nil, errReturnNotFound
+	}
+
+	if ret.Status != entities.ReturnRequested {
+		return nil, errs.Conflict("return_status", "return is %s and cannot take photos", ret.Status)
+	}
+
+	if len(ret.Photos) >= maxReturnPhotos {
+		return nil, errs.Validation("too_many_photos", "at most %d photos per return", maxReturnPhotos)
+	}
+
+	contentType, ext, data, err := readImage(file)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := media.NewKey(fmt.Sprintf("returns/%d", ret.ID), ext)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := uc.documents.Put(ctx, key, bytes.NewReader(data)); err != nil {
+		return nil, err
+	}
+
+	photo := &entities.ReturnPhoto{Key: key, ContentType: contentType}
+	if err := uc.repo.AddPhoto(ctx, ret.ID, photo); err != nil {
+		_ = uc.documents.Delete(context.WithoutCancel(ctx), key)
+		return nil, err
+	}
+
+	return photo, nil
+}
+
+// Photo reads a photo of a return owned by userID, any return for an empty
+// userID
+func (uc *returnUsecase) Photo(ctx context.Context, userID string, id, photoID int64) (*entities.ReturnPhoto, []byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, contextTimeoutQuery)
+	defer cancel()
+
+	ret, err := uc.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if userID != "" && ret.UserID != userID {
+		return nil, nil, errReturnNotFound
+	}
+
+	for _, photo := range ret.Photos {
+		if photo.ID != photoID {
+			continue
+		}
+
+		blob, err := uc.documents.Get(ctx, photo.Key)
+		if errors.Is(err, media.ErrNotFound) {
+			return nil, nil, errReturnPhotoNotFound
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		defer blob.Close()
+
+		data, err := io.ReadAll(blob)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return photo, data, nil
+	}
+
+	return nil, nil, errReturnPhotoNotFound
+}
+
+// ListReturns reads the returns of f.UserID, all of them for admins who
+// leave it empty
+func (uc *returnUsecase) ListReturns(ctx context.Context, f *entities.ReturnFilter) ([]*entities.Return, error) {
+	ctx, cancel := context.WithTimeout(ctx, contextTimeoutQuery)
+	defer cancel()
+
+	return uc.repo.List(ctx, f)
+}
+
+// GetReturn hides returns of other users as not found, an empty userID
+// reads any return, for admins
+func (uc *returnUsecase) GetReturn(ctx context.Context, userID string, id int64) (*entities.Return, error) {
+	ctx, cancel := context.WithTimeout(ctx, contextTimeoutQuery)
+	defer cancel()
+
+	ret, err := uc.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if userID != "" && ret.UserID != userID {
+		return nil, errReturnNotFound
+	}
+
+	return ret, nil
+}
+
+func (uc *returnUsecase) Approve(ctx context.Context, id int64, req *entities.ReturnDecisionReq) (*entities.Return, error) {
+	return uc.decide(ctx, id, entities.ReturnApproved, req.Note)
+}
+
+func (uc *returnUsecase) Reject(ctx context.Context, id int64, req *entities.ReturnDecisionReq) (*entities.Return, error) {
+	return uc.decide(ctx, id, entities.ReturnRejected, req.Note)
+}
+
+func (uc *returnUsecase) decide(ctx context.Context, id int64, status, note string) (*entities.Return, error) {
+	ctx, cancel := context.WithTimeout(ctx, contextTimeoutQuery)
+	defer cancel()
+
+	ret, err := uc.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := uc.repo.Decide(ctx, ret, status, note); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+// Receive records the inspection and puts the accepted units marked for
+// restock back into stock
+func (uc *returnUsecase) Receive(ctx context.Context, id int64, req *entities.ReturnReceiptReq) (*entities.Return, error) {
+	ctx, cancel := context.WithTimeout(ctx, contextTimeoutQuery)
+	defer cancel()
+
+	ret, err := uc.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	inspected := make(map[int64]*entities.ReturnInspectedReq, len(req.Items))
+	for _, item := range req.Items {
+		inspected[item.OrderItemID] = item
+	}
+
+	for _, item := range ret.Items {
+		accepted := item.Quantity
+		if in, ok := inspected[item.OrderItemID]; ok {
+			if in.AcceptedQuantity > item.Quantity {
+				return nil, errs.Validation("invalid_accepted_quantity", "at most %d of %s can be accepted", item.Quantity, item.Title)
+			}
+			accepted = in.AcceptedQuantity
+			delete(inspected, item.OrderItemID)
+		}
+		item.AcceptedQuantity = &accepted
+	}
+
+	for orderItemID := range inspected {
+		return nil, errs.Validation("return_item_not_found", "order item %d is not in the return", orderItemID)
+	}
+
+	restock := make(map[int64]bool, len(req.Items))
+	for _, item := range req.Items {
+		restock[item.OrderItemID] = item.Restock
+	}
+
+	if err := uc.repo.Receive(ctx, ret, restock, req.Note); err != nil {
+		return nil, err
+	}
+
+	return ret, nil
+}
+
+// Refund pays back a received return through the payment provider, or as
+// store credit when asked. The refund is stored as pending first so
+// concurrent refunds cannot exceed what was paid, a provider failure
+// releases it again.
+func (uc *returnUsecase) Refund(ctx context.Context, id int64, req *entities.RefundReq) (*entities.Return, error) {
+	ctx, cancel := context.WithTimeout(ctx, contextTimeoutQuery)
+	defer cancel()
+
+	ret, err := uc.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if ret.Status != entities.ReturnReceived {
+		return nil, errs.Conflict("return_not_refundable", "return is %s and cannot be refunded", ret.Status)
+	}
+
+	order, err := uc.orderRepo.GetByID(ctx, ret.OrderID)
+	if err != nil {
+		return nil, err
+	}
+
+	amount := returnValue(order, ret)
+	if req.Amount != nil {
+		if req.Amount.Currency != order.Currency {
+			return nil, errs.Validation("refund_currency", "refund must be in the order currency %s", order.Currency)
+		}
+		amount = *req.Amount
+	}
+
+	if amount.Amount <= 0 {
+		return nil, errs.Validation("invalid_refund_amount", "refund amount must be greater than zero")
+	}
+
+	refund := &entities.Refund{
+		OrderID:  order.ID,
+		ReturnID: &ret.ID,
+		Amount:   amount,
+		Provider: uc.payments.Code(),
+		Note:     req.Note,
+	}
+	if req.StoreCredit {
+		refund.Provider = entities.PaymentStoreCredit
+	}
+
+	if err := uc.repo.CreateRefund(ctx, refund); err != nil {
+		return nil, err
+	}
+
+	if req.StoreCredit {
+		if err := uc.repo.CompleteRefund(ctx, refund); err != nil {
+			if failErr := uc.repo.FailRefund(context.WithoutCancel(ctx), refund); failErr != nil {
+				log.Printf("release refund %d: %v", refund.ID, failErr)
+			}
+			return nil, err
+		}
+		return uc.GetReturn(ctx, "", id)
+	}
+
+	issued, err := uc.payments.Refund(ctx, &payment.RefundRequest{
+		Reference: fmt.Sprint(order.ID),
+		Amount:    amount,
+		Reason:    ret.Reason,
+	})
+	if err != nil {
+		if failErr := uc.repo.FailRefund(context.WithoutCancel(ctx), refund); failErr != nil {
+			log.Printf("release refund %d: %v", refund.ID, failErr)
+		}
+		if errors.Is(err, payment.ErrRefundDeclined) {
+			return nil, errs.Conflict("refund_declined", "the payment provider declined the refund").Wrap(err)
+		}
+		return nil, errs.Conflict("payment_unavailable", "the payment provider could not be reached").Wrap(err)
+	}
+
+	refund.ProviderRef = &issued.ID
+	if err := uc.repo.CompleteRefund(ctx, refund); err != nil {
+		return nil, err
+	}
+
+	return uc.GetReturn(ctx, "", id)
+}
+
+// returnValue is what the customer paid for the accepted units, the line
+// totals scaled by what the order charged for its goods after discounts
+// and taxes, shipping left out
+func returnValue(order *entities.Order, ret *entities.Return) money.Money {
+	value := money.New(0, order.Currency)
+	if order.Subtotal.Amount <= 0 {
+		return value
+	}
+
+	lines := make(map[int64]*entities.OrderItem, len(order.Items))
+	for _, item := range order.Items {
+		lines[item.ID] = item
+	}
+
+	goods := order.Total.Sub(order.ShippingTotal)
+	accepted := new(big.Rat)
+	for _, item := range ret.Items {
+		line, ok := lines[item.OrderItemID]
+		if !ok || item.AcceptedQuantity == nil || line.Quantity == 0 {
+			continue
+		}
+		share := new(big.Rat).SetFrac64(int64(*item.AcceptedQuantity), int64(line.Quantity))
+		accepted.Add(accepted, share.Mul(share, line.LineTotal.Rat()))
+	}
+
+	ratio := new(big.Rat).Quo(goods.Rat(), order.Subtotal.Rat())
+	return money.FromRat(accepted.Mul(accepted, ratio), order.Currency)
+}
+
+// ownOrder hides orders of other users as not found
+func (uc *returnUsecase) ownOrder(ctx context.Context, userID string, orderID int64) (*entities.Order, error) {
+	order, err := uc.orderRepo.GetByID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	if order.UserID != userID {
+		return nil, errs.NotFound("order_not_found", "order not found")
+	}
+
+	return order, nil
+}
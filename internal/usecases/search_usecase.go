@@ -0,0 +1,316 @@
+package usecases
+
+import (
+	"context"
+	"log"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/codepnw/react_go_ecom/internal/entities"
+	"github.com/codepnw/react_go_ecom/internal/errs"
+	"github.com/codepnw/react_go_ecom/internal/repositories"
+	"github.com/codepnw/react_go_ecom/pkg/suggest"
+)
+
+const (
+	suggestRefreshInterval = 10 * time.Minute
+	suggestDefaultLimit    = 5
+	suggestMaxLimit        = 10
+	suggestMaxQueries      = 10000
+	suggestQueryWindow     = 30 * 24 * time.Hour
+
+	reportDefaultDays = 30
+	reportMaxDays     = 366
+)
+
+// Report dates are calendar days in shop time
+var reportLocation = time.FixedZone("Asia/Bangkok", 7*60*60)
+
+// SuggestIndexer is notified of catalog changes so suggestions stay fresh
+// without rebuilding the whole index. Searches are logged for analytics and
+// feed the popular queries.
+type SuggestIndexer interface {
+	ProductChanged(p *entities.Product)
+	ProductRemoved(id string)
+	CategoryChanged(id int, title string)
+	CategoryRemoved(id int)
+	QuerySearched(ctx context.Context, q *entities.SearchQuery)
+}
+
+type SearchUsecase interface {
+	SuggestIndexer
+	Suggest(ctx context.Context, prefix string, limit int) (*entities.Suggestions, error)
+	RecordClick(ctx context.Context, req *entities.SearchClickReq) error
+	TopQueries(ctx context.Context, f *entities.SearchReportFilter) ([]*entities.SearchQueryStat, error)
+	ZeroResultQueries(ctx context.Context, f *entities.SearchReportFilter) ([]*entities.ZeroResultQuery, error)
+	ClickThrough(ctx context.Context, f *entities.SearchReportFilter) (*entities.ClickThroughReport, error)
+}
+
+type searchUsecase struct {
+	repo  repositories.SearchRepository
+	index *suggest.Index
+
+	mu       sync.Mutex
+	loadedAt time.Time
+	loading  atomic.Bool
+	queries  atomic.Int64
+}
+
+func NewSearchUsecase(repo repositories.SearchRepository) SearchUsecase {
+	return &searchUsecase{
+		repo:  repo,
+		index: suggest.NewIndex(),
+	}
+}
+
+func (uc *searchUsecase) Suggest(ctx context.Context, prefix string, limit int) (*entities.Suggestions, error) {
+	if err := uc.ensureLoaded(ctx); err != nil {
+		return nil, err
+	}
+
+	if limit <= 0 {
+		limit = suggestDefaultLimit
+	}
+
+	if limit > suggestMaxLimit {
+		limit = suggestMaxLimit
+	}
+
+	found := uc.index.Search(prefix, limit)
+
+	return &entities.Suggestions{
+		Query:      suggest.Normalize(prefix),
+		Products:   suggestItems(found[suggest.KindProduct]),
+		Categories: suggestItems(found[suggest.KindCategory]),
+		Queries:    suggestItems(found[suggest.KindQuery]),
+	}, nil
+}
+
+// ensureLoaded builds the index on first use and afterwards refreshes it in
+// the background, so a lookup never waits on the database again.
+func (uc *searchUsecase) ensureLoaded(ctx context.Context) error {
+	uc.mu.Lock()
+	loadedAt := uc.loadedAt
+	uc.mu.Unlock()
+
+	if loadedAt.IsZero() {
+		uc.mu.Lock()
+		defer uc.mu.Unlock()
+
+		if !uc.loadedAt.IsZero() {
+			return nil
+		}
+
+		ctx, cancel := context.WithTimeout(ctx, contextTimeoutQuery)
+		defer cancel()
+
+		if err := uc.rebuild(ctx); err != nil {
+			return err
+		}
+		uc.loadedAt = time.Now()
+
+		return nil
+	}
+
+	if time.Since(loadedAt) > suggestRefreshInterval && uc.loading.CompareAndSwap(false, true) {
+		go func() {
+			defer uc.loading.Store(false)
+
+			ctx, cancel := context.WithTimeout(context.Background(), contextTimeoutQuery)
+			defer cancel()
+
+			if err := uc.rebuild(ctx); err != nil {
+				log.Println("rebuild suggest index:", err)
+				return
+			}
+
+			uc.mu.Lock()
+			uc.loadedAt = time.Now()
+			uc.mu.Unlock()
+		}()
+	}
+
+	return nil
+}
+
+func (uc *searchUsecase) rebuild(ctx context.Context) error {
+	products, err := uc.repo.ListProductTitles(ctx)
+	if err != nil {
+		return err
+	}
+
+	categories, err := uc.repo.ListCategoryTitles(ctx)
+	if err != nil {
+		return err
+	}
+
+	queries, err := uc.repo.ListPopularQueries(ctx, time.Now().Add(-suggestQueryWindow), suggestMaxQueries/2)
+	if err != nil {
+		return err
+	}
+
+	uc.index.Replace(suggest.KindProduct, suggestEntries(suggest.KindProduct, products))
+	uc.index.Replace(suggest.KindCategory, suggestEntries(suggest.KindCategory, categories))
+	uc.index.Replace(suggest.KindQuery, suggestEntries(suggest.KindQuery, queries))
+	uc.queries.Store(int64(len(queries)))
+
+	return nil
+}
+
+func (uc *searchUsecase) ProductChanged(p *entities.Product) {
+	uc.index.Upsert(suggest.Entry{
+		Kind:   suggest.KindProduct,
+		ID:     p.ID,
+		Text:   p.Title,
+		Weight: p.Quantity,
+	})
+}
+
+func (uc *searchUsecase) ProductRemoved(id string) {
+	uc.index.Remove(suggest.KindProduct, id)
+}
+
+func (uc *searchUsecase) CategoryChanged(id int, title string) {
+	uc.index.Upsert(suggest.Entry{
+		Kind: suggest.KindCategory,
+		ID:   strconv.Itoa(id),
+		Text: title,
+	})
+}
+
+func (uc *searchUsecase) CategoryRemoved(id int) {
+	uc.index.Remove(suggest.KindCategory, strconv.Itoa(id))
+}
+
+// QuerySearched logs the search and counts it towards the popular queries
+// when it found something. Logging is best effort, a failure never fails
+// the search itself.
+func (uc *searchUsecase) QuerySearched(ctx context.Context, q *entities.SearchQuery) {
+	q.Query = suggest.Normalize(q.RawQuery)
+	if q.Query == "" {
+		return
+	}
+
+	if err := uc.repo.CreateQuery(ctx, q); err != nil {
+		log.Println("record search query:", err)
+	}
+
+	if q.ResultCount == 0 || len([]rune(q.Query)) < 2 {
+		return
+	}
+
+	if _, ok := uc.index.Search(q.Query, 1)[suggest.KindQuery]; !ok && uc.queries.Load() >= suggestMaxQueries {
+		return
+	}
+
+	if uc.index.Bump(suggest.KindQuery, q.Query, q.Query, 1) {
+		uc.queries.Add(1)
+	}
+}
+
+func (uc *searchUsecase) RecordClick(ctx context.Context, req *entities.SearchClickReq) error {
+	ctx, cancel := context.WithTimeout(ctx, contextTimeoutQuery)
+	defer cancel()
+
+	return uc.repo.RecordClick(ctx, req.SearchID, req.ProductID)
+}
+
+func (uc *searchUsecase) TopQueries(ctx context.Context, f *entities.SearchReportFilter) ([]*entities.SearchQueryStat, error) {
+	from, to, err := reportRange(f)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, contextTimeoutQuery)
+	defer cancel()
+
+	return uc.repo.TopQueries(ctx, from, to, pageLimit(f.Limit))
+}
+
+func (uc *searchUsecase) ZeroResultQueries(ctx context.Context, f *entities.SearchReportFilter) ([]*entities.ZeroResultQuery, error) {
+	from, to, err := reportRange(f)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, contextTimeoutQuery)
+	defer cancel()
+
+	return uc.repo.ZeroResultQueries(ctx, from, to, pageLimit(f.Limit))
+}
+
+func (uc *searchUsecase) ClickThrough(ctx context.Context, f *entities.SearchReportFilter) (*entities.ClickThroughReport, error) {
+	from, to, err := reportRange(f)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, contextTimeoutQuery)
+	defer cancel()
+
+	report, err := uc.repo.ClickThrough(ctx, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	report.From = from.Format("2006-01-02")
+	report.To = to.AddDate(0, 0, -1).Format("2006-01-02")
+
+	return report, nil
+}
+
+// reportRange turns the inclusive day range into a half open time range,
+// defaulting to the last 30 days
+func reportRange(f *entities.SearchReportFilter) (time.Time, time.Time, error) {
+	now := time.Now().In(reportLocation)
+	to := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, reportLocation)
+	if f.To != nil {
+		to = time.Date(f.To.Year(), f.To.Month(), f.To.Day(), 0, 0, 0, 0, reportLocation)
+	}
+	to = to.AddDate(0, 0, 1)
+
+	from := to.AddDate(0, 0, -reportDefaultDays)
+	if f.From != nil {
+		from = time.Date(f.From.Year(), f.From.Month(), f.From.Day(), 0, 0, 0, 0, reportLocation)
+	}
+
+	if !from.Before(to) {
+		return time.Time{}, time.Time{}, errs.Validation("invalid_date_range", "from must not be after to")
+	}
+
+	if to.Sub(from) > reportMaxDays*24*time.Hour {
+		return time.Time{}, time.Time{}, errs.Validation("date_range_too_long", "date range is limited to one year")
+	}
+
+	return from, to, nil
+}
+
+func suggestEntries(kind suggest.Kind, items []*entities.SuggestItem) []suggest.Entry {
+	entries := make([]suggest.Entry, len(items))
+	for i, item := range items {
+		entries[i] = suggest.Entry{
+			Kind:   kind,
+			ID:     item.ID,
+			Text:   item.Text,
+			Weight: item.Weight,
+		}
+	}
+	return entries
+}
+
+func suggestItems(entries []suggest.Entry) []*entities.SuggestItem {
+	items := make([]*entities.SuggestItem, len(entries))
+	for i, e := range entries {
+		items[i] = &entities.SuggestItem{
+			ID:     e.ID,
+			Text:   e.Text,
+			Weight: e.Weight,
+		}
+		if e.Kind == suggest.KindQuery {
+			items[i].ID = ""
+		}
+	}
+	return items
+}
@@ -0,0 +1,316 @@
+package usecases
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"path"
+	"time"
+
+	"github.com/codepnw/react_go_ecom/internal/entities"
+	"github.com/codepnw/react_go_ecom/internal/errs"
+	"github.com/codepnw/react_go_ecom/internal/repositories"
+	"github.com/codepnw/react_go_ecom/pkg/media"
+	"github.com/codepnw/react_go_ecom/pkg/shipping"
+)
+
+// Shipments polled per batch
+const shipmentBatchSize = 100
+
+type ShipmentUsecase interface {
+	CreateShipment(ctx context.Context, orderID int64, req *entities.ShipmentReq) (*entities.Shipment, error)
+	GetShipment(ctx context.Context, id int64) (*entities.Shipment, error)
+	ListShipments(ctx context.Context, orderID int64) ([]*entities.Shipment, error)
+	RefreshTracking(ctx context.Context, id int64) (*entities.Shipment, error)
+	Label(ctx context.Context, id int64) (string, []byte, error)
+	OrderTracking(ctx context.Context, userID string, orderID int64) (*entities.OrderTracking, error)
+	PollTracking(ctx context.Context) error
+}
+
+type shipmentUsecase struct {
+	repo      repositories.ShipmentRepository
+	orderRepo repositories.OrderRepository
+	carriers  shipping.Carriers
+	documents media.BlobStore
+	interval  time.Duration
+}
+
+// NewShipmentUsecase polls a shipment at most once per interval. Labels
+// carry the recipient's address and are kept in documents, a store that
+// is not served publicly.
+func NewShipmentUsecase(repo repositories.ShipmentRepository, orderRepo repositories.OrderRepository, carriers shipping.Carriers, documents media.BlobStore, interval time.Duration) ShipmentUsecase {
+	return &shipmentUsecase{
+		repo:      repo,
+		orderRepo: orderRepo,
+		carriers:  carriers,
+		documents: documents,
+		interval:  interval,
+	}
+}
+
+// CreateShipment allocates the items and books the parcel with the carrier.
+// A booking that fails gives the items back.
+func (uc *shipmentUsecase) CreateShipment(ctx context.Context, orderID int64, req *entities.ShipmentReq) (*entities.Shipment, error) {
+	carrier, err := uc.carriers.Get(req.Carrier)
+	if err != nil {
+		return nil, errs.Validation("unknown_carrier", "unknown carrier %s", req.Carrier)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, contextTimeoutQuery)
+	defer cancel()
+
+	order, err := uc.orderRepo.GetByID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	if order.Shipping != nil && order.Shipping.Kind == entities.ShippingLocalPickup {
+		return nil, errs.Conflict("order_is_pickup", "order is picked up in store and cannot be shipped")
+	}
+
+	s := &entities.Shipment{
+		OrderID: orderID,
+		Carrier: carrier.Code(),
+	}
+
+	// Lines listed twice are one allocation
+	index := map[int64]*entities.ShipmentItem{}
+	for _, item := range req.Items {
+		if existing, ok := index[item.OrderItemID]; ok {
+			existing.Quantity += item.Quantity
+			continue
+		}
+		index[item.OrderItemID] = &entities.ShipmentItem{OrderItemID: item.OrderItemID, Quantity: item.Quantity}
+		s.Items = append(s.Items, index[item.OrderItemID])
+	}
+
+	if err := uc.repo.Create(ctx, s); err != nil {
+		return nil, err
+	}
+
+	if err := uc.book(ctx, carrier, order, s); err != nil {
+		if delErr := uc.repo.Delete(context.WithoutCancel(ctx), s.ID); delErr != nil {
+			log.Printf("release shipment %d: %v", s.ID, delErr)
+		}
+		return nil, err
+	}
+
+	return s, nil
+}
+
+func (uc *shipmentUsecase) book(ctx context.Context, carrier shipping.CarrierAdapter, order *entities.Order, s *entities.Shipment) error {
+	req := &shipping.LabelRequest{
+		Reference:   fmt.Sprintf("%d-%d", order.ID, s.ID),
+		WeightGrams: s.WeightGrams,
+	}
+	if addr := order.ShippingAddress; addr != nil {
+		req.To = shipping.Address{
+			Name:       addr.Name,
+			Phone:      addr.Phone,
+			Line1:      addr.Line1,
+			Line2:      addr.Line2,
+			City:       addr.City,
+			Region:     addr.Region,
+			PostalCode: addr.PostalCode,
+			Country:    addr.Country,
+		}
+	}
+
+	label, err := carrier.CreateLabel(ctx, req)
+	if err != nil {
+		return errs.Conflict("carrier_unavailable", "carrier %s could not book the parcel", carrier.Code()).Wrap(err)
+	}
+
+	key, err := media.NewKey(fmt.Sprintf("labels/%d", order.ID), labelExt(label.ContentType))
+	if err != nil {
+		return err
+	}
+
+	if err := uc.documents.Put(ctx, key, bytes.NewReader(label.Data)); err != nil {
+		return err
+	}
+
+	s.TrackingNumber = &label.TrackingNumber
+	s.LabelKey = &key
+
+	if err := uc.repo.SetLabel(ctx, s); err != nil {
+		_ = uc.documents.Delete(context.WithoutCancel(ctx), key)
+		return err
+	}
+
+	return nil
+}
+
+var labelTypes = map[string]string{
+	"application/pdf": ".pdf",
+	"image/png":       ".png",
+	"text/plain":      ".txt",
+}
+
+func labelExt(contentType string) string {
+	if ext, ok := labelTypes[contentType]; ok {
+		return ext
+	}
+	return ".txt"
+}
+
+func (uc *shipmentUsecase) GetShipment(ctx context.Context, id int64) (*entities.Shipment, error) {
+	ctx, cancel := context.WithTimeout(ctx, contextTimeoutQuery)
+	defer cancel()
+
+	return uc.repo.GetByID(ctx, id)
+}
+
+func (uc *shipmentUsecase) ListShipments(ctx context.Context, orderID int64) ([]*entities.Shipment, error) {
+	ctx, cancel := context.WithTimeout(ctx, contextTimeoutQuery)
+	defer cancel()
+
+	if _, err := uc.orderRepo.GetByID(ctx, orderID); err != nil {
+		return nil, err
+	}
+
+	return uc.repo.ListByOrder(ctx, orderID)
+}
+
+// RefreshTracking polls the carrier now instead of waiting for the job
+func (uc *shipmentUsecase) RefreshTracking(ctx context.Context, id int64) (*entities.Shipment, error) {
+	ctx, cancel := context.WithTimeout(ctx, contextTimeoutQuery)
+	defer cancel()
+
+	s, err := uc.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.TrackingNumber == nil {
+		return nil, errs.Conflict("shipment_not_booked", "shipment has no tracking number yet")
+	}
+
+	if err := uc.track(ctx, s); err != nil {
+		return nil, err
+	}
+
+	return uc.GetShipment(ctx, id)
+}
+
+// Label is the carrier label of a booked shipment with its content type
+func (uc *shipmentUsecase) Label(ctx context.Context, id int64) (string, []byte, error) {
+	ctx, cancel := context.WithTimeout(ctx, contextTimeoutQuery)
+	defer cancel()
+
+	s, err := uc.repo.GetByID(ctx, id)
+	if err != nil {
+		return "", nil, err
+	}
+
+	if s.LabelKey == nil {
+		return "", nil, errs.Conflict("shipment_not_booked", "shipment has no tracking number yet")
+	}
+
+	blob, err := uc.documents.Get(ctx, *s.LabelKey)
+	if errors.Is(err, media.ErrNotFound) {
+		return "", nil, errs.NotFound("label_not_found", "label not found")
+	}
+	if err != nil {
+		return "", nil, err
+	}
+	defer blob.Close()
+
+	data, err := io.ReadAll(blob)
+	if err != nil {
+		return "", nil, err
+	}
+
+	contentType := "text/plain"
+	for ct, ext := range labelTypes {
+		if ext == path.Ext(*s.LabelKey) {
+			contentType = ct
+		}
+	}
+
+	return contentType, data, nil
+}
+
+// OrderTracking hides orders of other users as not found
+func (uc *shipmentUsecase) OrderTracking(ctx context.Context, userID string, orderID int64) (*entities.OrderTracking, error) {
+	ctx, cancel := context.WithTimeout(ctx, contextTimeoutQuery)
+	defer cancel()
+
+	order, err := uc.orderRepo.GetByID(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	if order.UserID != userID {
+		return nil, errs.NotFound("order_not_found", "order not found")
+	}
+
+	shipments, err := uc.repo.ListByOrder(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &entities.OrderTracking{
+		OrderID:   order.ID,
+		Status:    order.Status,
+		Shipments: shipments,
+	}, nil
+}
+
+// PollTracking is the scheduler job, it reads the tracking of shipments on
+// their way that were not polled within the interval. A carrier failing for
+// one shipment does not stop the others.
+func (uc *shipmentUsecase) PollTracking(ctx context.Context) error {
+	polledBefore := time.Now().Add(-uc.interval)
+
+	for {
+		due, err := uc.repo.ClaimDue(ctx, polledBefore, shipmentBatchSize)
+		if err != nil {
+			return err
+		}
+
+		for _, s := range due {
+			if err := uc.track(ctx, s); err != nil {
+				log.Printf("track shipment %d: %v", s.ID, err)
+			}
+		}
+
+		if len(due) < shipmentBatchSize {
+			return nil
+		}
+	}
+}
+
+func (uc *shipmentUsecase) track(ctx context.Context, s *entities.Shipment) error {
+	carrier, err := uc.carriers.Get(s.Carrier)
+	if err != nil {
+		return err
+	}
+
+	events, err := carrier.Track(ctx, *s.TrackingNumber)
+	if errors.Is(err, shipping.ErrUnknownTracking) {
+		return errs.Conflict("unknown_tracking_number", "carrier %s does not know tracking number %s", s.Carrier, *s.TrackingNumber).Wrap(err)
+	}
+	if err != nil {
+		return errs.Conflict("carrier_unavailable", "carrier %s could not be reached", s.Carrier).Wrap(err)
+	}
+
+	if len(events) == 0 {
+		return nil
+	}
+
+	tracked := make([]*entities.TrackingEvent, len(events))
+	for i, e := range events {
+		tracked[i] = &entities.TrackingEvent{
+			Status:      e.Status,
+			Description: e.Description,
+			Location:    e.Location,
+			OccurredAt:  e.OccurredAt,
+		}
+	}
+
+	return uc.repo.RecordEvents(ctx, s, tracked)
+}
@@ -0,0 +1,388 @@
+package usecases
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/codepnw/react_go_ecom/internal/entities"
+	"github.com/codepnw/react_go_ecom/internal/errs"
+	"github.com/codepnw/react_go_ecom/internal/repositories"
+	"github.com/codepnw/react_go_ecom/pkg/money"
+)
+
+// Shipper prices the shipping of a priced and discounted cart to the
+// country and region the goods go to. Rates are converted into the cart
+// currency at rate, the one the cart was priced at.
+type Shipper interface {
+	QuoteShipping(ctx context.Context, cart *entities.Cart, rate *entities.ExchangeRate, country, region string) (*entities.ShippingQuotes, error)
+	ApplyShipping(ctx context.Context, cart *entities.Cart, rate *entities.ExchangeRate, country, region string, methodID int) error
+}
+
+type ShippingUsecase interface {
+	Shipper
+	CreateZone(ctx context.Context, req *entities.ShippingZoneReq) (*entities.ShippingZone, error)
+	ListZones(ctx context.Context) ([]*entities.ShippingZone, error)
+	GetZone(ctx context.Context, id int) (*entities.ShippingZone, error)
+	UpdateZone(ctx context.Context, id int, req *entities.ShippingZoneReq) (*entities.ShippingZone, error)
+	DeleteZone(ctx context.Context, id int) error
+	CreateMethod(ctx context.Context, zoneID int, req *entities.ShippingMethodReq) (*entities.ShippingMethod, error)
+	GetMethod(ctx context.Context, id int) (*entities.ShippingMethod, error)
+	UpdateMethod(ctx context.Context, id int, req *entities.ShippingMethodReq) (*entities.ShippingMethod, error)
+	DeleteMethod(ctx context.Context, id int) error
+}
+
+type shippingUsecase struct {
+	repo           repositories.ShippingRepository
+	defaultCountry string
+}
+
+// NewShippingUsecase ships carts without a country to defaultCountry
+func NewShippingUsecase(repo repositories.ShippingRepository, defaultCountry string) ShippingUsecase {
+	return &shippingUsecase{
+		repo:           repo,
+		defaultCountry: strings.ToUpper(defaultCountry),
+	}
+}
+
+// QuoteShipping lists what every method able to ship the cart costs, none
+// when no zone covers the destination
+func (uc *shippingUsecase) QuoteShipping(ctx context.Context, cart *entities.Cart, rate *entities.ExchangeRate, country, region string) (*entities.ShippingQuotes, error) {
+	if country == "" {
+		country = uc.defaultCountry
+	}
+
+	dest, err := parseDestination(country, region)
+	if err != nil {
+		return nil, err
+	}
+
+	zone, err := uc.repo.FindZone(ctx, dest)
+	if err != nil {
+		return nil, err
+	}
+
+	quotes := &entities.ShippingQuotes{
+		Country:     dest.Country,
+		Region:      dest.Region,
+		Currency:    cart.Currency,
+		WeightGrams: cart.WeightGrams,
+		Methods:     []*entities.ShippingQuote{},
+	}
+
+	if zone == nil {
+		return quotes, nil
+	}
+
+	for _, method := range zone.Methods {
+		quote, err := quoteMethod(cart, rate, method)
+		if err != nil {
+			return nil, err
+		}
+		if quote != nil {
+			quotes.Methods = append(quotes.Methods, quote)
+		}
+	}
+
+	return quotes, nil
+}
+
+// ApplyShipping charges the method to the cart, it must be one of the
+// quoted methods
+func (uc *shippingUsecase) ApplyShipping(ctx context.Context, cart *entities.Cart, rate *entities.ExchangeRate, country, region string, methodID int) error {
+	quotes, err := uc.QuoteShipping(ctx, cart, rate, country, region)
+	if err != nil {
+		return err
+	}
+
+	for _, quote := range quotes.Methods {
+		if quote.MethodID == methodID {
+			cart.Shipping = quote
+			cart.ShippingTotal = quote.Cost
+			cart.Total = cart.Total.Add(quote.Cost)
+			return nil
+		}
+	}
+
+	return errs.Validation("shipping_method_unavailable", "shipping method is not available for this cart and destination")
+}
+
+func (uc *shippingUsecase) CreateZone(ctx context.Context, req *entities.ShippingZoneReq) (*entities.ShippingZone, error) {
+	zone, err := buildShippingZone(req)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, contextTimeoutQuery)
+	defer cancel()
+
+	if err := uc.repo.CreateZone(ctx, zone); err != nil {
+		return nil, err
+	}
+
+	return zone, nil
+}
+
+func (uc *shippingUsecase) ListZones(ctx context.Context) ([]*entities.ShippingZone, error) {
+	ctx, cancel := context.WithTimeout(ctx, contextTimeoutQuery)
+	defer cancel()
+
+	return uc.repo.ListZones(ctx)
+}
+
+func (uc *shippingUsecase) GetZone(ctx context.Context, id int) (*entities.ShippingZone, error) {
+	ctx, cancel := context.WithTimeout(ctx, contextTimeoutQuery)
+	defer cancel()
+
+	return uc.repo.GetZone(ctx, id)
+}
+
+func (uc *shippingUsecase) UpdateZone(ctx context.Context, id int, req *entities.ShippingZoneReq) (*entities.ShippingZone, error) {
+	zone, err := buildShippingZone(req)
+	if err != nil {
+		return nil, err
+	}
+	zone.ID = id
+
+	ctx, cancel := context.WithTimeout(ctx, contextTimeoutQuery)
+	defer cancel()
+
+	if err := uc.repo.UpdateZone(ctx, zone); err != nil {
+		return nil, err
+	}
+
+	return zone, nil
+}
+
+func (uc *shippingUsecase) DeleteZone(ctx context.Context, id int) error {
+	ctx, cancel := context.WithTimeout(ctx, contextTimeoutQuery)
+	defer cancel()
+
+	return uc.repo.DeleteZone(ctx, id)
+}
+
+func (uc *shippingUsecase) CreateMethod(ctx context.Context, zoneID int, req *entities.ShippingMethodReq) (*entities.ShippingMethod, error) {
+	method, err := buildShippingMethod(req)
+	if err != nil {
+		return nil, err
+	}
+	method.ZoneID = zoneID
+
+	ctx, cancel := context.WithTimeout(ctx, contextTimeoutQuery)
+	defer cancel()
+
+	if err := uc.repo.CreateMethod(ctx, method); err != nil {
+		return nil, err
+	}
+
+	return method, nil
+}
+
+func (uc *shippingUsecase) GetMethod(ctx context.Context, id int) (*entities.ShippingMethod, error) {
+	ctx, cancel := context.WithTimeout(ctx, contextTimeoutQuery)
+	defer cancel()
+
+	return uc.repo.GetMethod(ctx, id)
+}
+
+func (uc *shippingUsecase) UpdateMethod(ctx context.Context, id int, req *entities.ShippingMethodReq) (*entities.ShippingMethod, error) {
+	method, err := buildShippingMethod(req)
+	if err != nil {
+		return nil, err
+	}
+	method.ID = id
+
+	ctx, cancel := context.WithTimeout(ctx, contextTimeoutQuery)
+	defer cancel()
+
+	if err := uc.repo.UpdateMethod(ctx, method); err != nil {
+		return nil, err
+	}
+
+	return method, nil
+}
+
+func (uc *shippingUsecase) DeleteMethod(ctx context.Context, id int) error {
+	ctx, cancel := context.WithTimeout(ctx, contextTimeoutQuery)
+	defer cancel()
+
+	return uc.repo.DeleteMethod(ctx, id)
+}
+
+// quoteMethod is the cost of shipping the cart with the method, nil when
+// the method cannot ship it. A free shipping promotion waives every method.
+func quoteMethod(cart *entities.Cart, rate *entities.ExchangeRate, method *entities.ShippingMethod) (*entities.ShippingQuote, error) {
+	if !method.Active {
+		return nil, nil
+	}
+
+	var cost money.Money
+
+	switch method.Kind {
+	case entities.ShippingFlatRate, entities.ShippingLocalPickup:
+		cost = method.Rate
+
+	case entities.ShippingWeightTable:
+		bracket := -1
+		for i, w := range method.WeightRates {
+			if cart.WeightGrams <= w.UpToGrams {
+				bracket = i
+				break
+			}
+		}
+		if bracket < 0 {
+			return nil, nil
+		}
+		cost = method.WeightRates[bracket].Rate
+
+	case entities.ShippingFreeOver:
+		if method.Threshold == nil {
+			return nil, nil
+		}
+
+		threshold, err := convertAmount(*method.Threshold, cart.Currency, rate)
+		if err != nil {
+			return nil, err
+		}
+		if cart.Subtotal.Sub(cart.DiscountTotal).Cmp(threshold) < 0 {
+			return nil, nil
+		}
+		cost = money.New(0, money.DefaultCurrency)
+
+	default:
+		return nil, nil
+	}
+
+	if cart.FreeShipping {
+		cost = money.New(0, money.DefaultCurrency)
+	}
+
+	converted, err := convertAmount(cost, cart.Currency, rate)
+	if err != nil {
+		return nil, err
+	}
+
+	return &entities.ShippingQuote{
+		MethodID: method.ID,
+		Name:     method.Name,
+		Kind:     method.Kind,
+		Cost:     converted,
+	}, nil
+}
+
+// convertAmount turns a base currency amount into currency at rate
+func convertAmount(amount money.Money, currency string, rate *entities.ExchangeRate) (money.Money, error) {
+	if currency == amount.Currency {
+		return amount, nil
+	}
+
+	if rate == nil {
+		return money.Money{}, errs.Validation("currency_unavailable", "prices in %s are not available", currency)
+	}
+
+	r, err := money.ParseRate(rate.Rate)
+	if err != nil {
+		return money.Money{}, err
+	}
+
+	return amount.Convert(currency, r), nil
+}
+
+func buildShippingZone(req *entities.ShippingZoneReq) (*entities.ShippingZone, error) {
+	zone := &entities.ShippingZone{
+		Name:    req.Name,
+		Methods: []*entities.ShippingMethod{},
+	}
+
+	seen := make(map[entities.Destination]bool)
+	for _, l := range req.Locations {
+		dest, err := parseDestination(l.Country, l.Region)
+		if err != nil {
+			return nil, err
+		}
+
+		if seen[dest] {
+			continue
+		}
+		seen[dest] = true
+
+		zone.Locations = append(zone.Locations, &entities.ShippingLocation{Country: dest.Country, Region: dest.Region})
+	}
+
+	return zone, nil
+}
+
+// buildShippingMethod checks the amounts are in the base currency and that
+// the kind has what it charges by, settings of other kinds are dropped
+func buildShippingMethod(req *entities.ShippingMethodReq) (*entities.ShippingMethod, error) {
+	method := &entities.ShippingMethod{
+		Name:        req.Name,
+		Kind:        req.Kind,
+		Rate:        money.New(0, money.DefaultCurrency),
+		WeightRates: []*entities.WeightRate{},
+		Active:      req.Active == nil || *req.Active,
+		Position:    req.Position,
+	}
+
+	switch req.Kind {
+	case entities.ShippingFlatRate, entities.ShippingLocalPickup:
+		rate, err := shippingAmount(req.Rate)
+		if err != nil {
+			return nil, err
+		}
+		method.Rate = rate
+
+	case entities.ShippingWeightTable:
+		if len(req.WeightRates) == 0 {
+			return nil, errs.Validation("invalid_weight_rates", "a weight table needs brackets with distinct up_to_grams")
+		}
+
+		brackets := make(map[int]bool)
+		for _, w := range req.WeightRates {
+			if brackets[w.UpToGrams] {
+				return nil, errs.Validation("invalid_weight_rates", "a weight table needs brackets with distinct up_to_grams")
+			}
+			brackets[w.UpToGrams] = true
+
+			rate, err := shippingAmount(w.Rate)
+			if err != nil {
+				return nil, err
+			}
+			method.WeightRates = append(method.WeightRates, &entities.WeightRate{UpToGrams: w.UpToGrams, Rate: rate})
+		}
+
+		sort.Slice(method.WeightRates, func(i, j int) bool {
+			return method.WeightRates[i].UpToGrams < method.WeightRates[j].UpToGrams
+		})
+
+	case entities.ShippingFreeOver:
+		if req.Threshold == nil || req.Threshold.Amount <= 0 {
+			return nil, errs.Validation("invalid_shipping_threshold", "free over shipping needs a threshold greater than zero")
+		}
+		threshold, err := shippingAmount(*req.Threshold)
+		if err != nil {
+			return nil, err
+		}
+		method.Threshold = &threshold
+	}
+
+	return method, nil
+}
+
+// shippingAmount accepts amounts of zero or more in the base currency, a
+// missing amount is zero
+func shippingAmount(amount money.Money) (money.Money, error) {
+	if amount.Currency == "" {
+		return money.New(0, money.DefaultCurrency), nil
+	}
+
+	if amount.Currency != money.DefaultCurrency {
+		return money.Money{}, errs.Validation("shipping_currency", "shipping rates must be in %s", money.DefaultCurrency)
+	}
+
+	if amount.IsNegative() {
+		return money.Money{}, errs.Validation("invalid_shipping_rate", "shipping rates cannot be negative")
+	}
+
+	return amount, nil
+}
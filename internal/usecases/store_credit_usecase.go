@@ -0,0 +1,67 @@
+package usecases
+
+import (
+	"context"
+
+	"github.com/codepnw/react_go_ecom/internal/entities"
+	"github.com/codepnw/react_go_ecom/internal/errs"
+	"github.com/codepnw/react_go_ecom/internal/repositories"
+	"github.com/codepnw/react_go_ecom/pkg/money"
+)
+
+type StoreCreditUsecase interface {
+	GetStoreCredit(ctx context.Context, userID string) (*entities.StoreCredit, error)
+	AdjustStoreCredit(ctx context.Context, userID string, req *entities.StoreCreditAdjustReq) (*entities.StoreCredit, error)
+}
+
+type storeCreditUsecase struct {
+	repo repositories.StoreCreditRepository
+}
+
+func NewStoreCreditUsecase(repo repositories.StoreCreditRepository) StoreCreditUsecase {
+	return &storeCreditUsecase{repo: repo}
+}
+
+// GetStoreCredit reads the balances of a user with the full ledger
+func (uc *storeCreditUsecase) GetStoreCredit(ctx context.Context, userID string) (*entities.StoreCredit, error) {
+	ctx, cancel := context.WithTimeout(ctx, contextTimeoutQuery)
+	defer cancel()
+
+	balances, err := uc.repo.Balances(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := uc.repo.ListEntries(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &entities.StoreCredit{UserID: userID, Balances: balances, Entries: entries}, nil
+}
+
+// AdjustStoreCredit books a manual correction, a negative amount takes
+// credit off but never more than the balance
+func (uc *storeCreditUsecase) AdjustStoreCredit(ctx context.Context, userID string, req *entities.StoreCreditAdjustReq) (*entities.StoreCredit, error) {
+	if !money.Known(req.Amount.Currency) {
+		return nil, errs.Validation("unknown_currency", "currency is not supported")
+	}
+	if req.Amount.IsZero() {
+		return nil, errs.Validation("invalid_adjustment", "adjustment amount must not be zero")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, contextTimeoutQuery)
+	defer cancel()
+
+	entry := &entities.StoreCreditEntry{
+		UserID: userID,
+		Kind:   entities.CreditAdjustment,
+		Amount: req.Amount,
+		Note:   req.Note,
+	}
+	if err := uc.repo.AddEntry(ctx, entry); err != nil {
+		return nil, err
+	}
+
+	return uc.GetStoreCredit(ctx, userID)
+}
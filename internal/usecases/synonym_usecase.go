@@ -0,0 +1,227 @@
+package usecases
+
+import (
+	"context"
+	"log"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/codepnw/react_go_ecom/internal/entities"
+	"github.com/codepnw/react_go_ecom/internal/errs"
+	"github.com/codepnw/react_go_ecom/internal/repositories"
+	"github.com/codepnw/react_go_ecom/pkg/synonym"
+)
+
+// Other instances pick up admin changes within this interval
+const synonymRefreshInterval = time.Minute
+
+// QueryExpander rewrites a search query with synonyms and without stop
+// words before it reaches the database.
+type QueryExpander interface {
+	ExpandQuery(ctx context.Context, query string) []*entities.SearchTerm
+}
+
+type SynonymUsecase interface {
+	QueryExpander
+	ListSynonyms(ctx context.Context) ([]*entities.SynonymGroup, error)
+	CreateSynonym(ctx context.Context, req *entities.SynonymGroupReq) (*entities.SynonymGroup, error)
+	UpdateSynonym(ctx context.Context, id int, req *entities.SynonymGroupReq) (*entities.SynonymGroup, error)
+	DeleteSynonym(ctx context.Context, id int) error
+	ListStopWords(ctx context.Context) ([]*entities.StopWord, error)
+	AddStopWord(ctx context.Context, req *entities.StopWordReq) (*entities.StopWord, error)
+	DeleteStopWord(ctx context.Context, word string) error
+}
+
+type synonymUsecase struct {
+	repo repositories.SynonymRepository
+	dict *synonym.Dictionary
+
+	mu       sync.Mutex
+	loadedAt time.Time
+}
+
+func NewSynonymUsecase(repo repositories.SynonymRepository) SynonymUsecase {
+	return &synonymUsecase{
+		repo: repo,
+		dict: synonym.NewDictionary(),
+	}
+}
+
+// ExpandQuery never fails a search, when the dictionary cannot be loaded the
+// last loaded one is used.
+func (uc *synonymUsecase) ExpandQuery(ctx context.Context, query string) []*entities.SearchTerm {
+	if err := uc.reload(ctx, false); err != nil {
+		log.Println("load synonyms:", err)
+	}
+
+	expanded := uc.dict.Expand(query)
+	terms := make([]*entities.SearchTerm, len(expanded))
+	for i, t := range expanded {
+		terms[i] = &entities.SearchTerm{
+			Alternatives: t.Alternatives,
+			Negate:       t.Negate,
+		}
+	}
+
+	return terms
+}
+
+func (uc *synonymUsecase) reload(ctx context.Context, force bool) error {
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+
+	if !force && time.Since(uc.loadedAt) < synonymRefreshInterval {
+		return nil
+	}
+
+	groups, err := uc.repo.ListSynonyms(ctx)
+	if err != nil {
+		return err
+	}
+
+	stopWords, err := uc.repo.ListStopWords(ctx)
+	if err != nil {
+		return err
+	}
+
+	terms := make([][]string, len(groups))
+	for i, g := range groups {
+		terms[i] = g.Terms
+	}
+
+	words := make([]string, len(stopWords))
+	for i, w := range stopWords {
+		words[i] = w.Word
+	}
+
+	uc.dict.Load(terms, words)
+	uc.loadedAt = time.Now()
+
+	return nil
+}
+
+// applied reloads the dictionary after an admin change so it takes effect
+// on the next search
+func (uc *synonymUsecase) applied(ctx context.Context) {
+	if err := uc.reload(ctx, true); err != nil {
+		log.Println("reload synonyms:", err)
+	}
+}
+
+func (uc *synonymUsecase) ListSynonyms(ctx context.Context) ([]*entities.SynonymGroup, error) {
+	ctx, cancel := context.WithTimeout(ctx, contextTimeoutQuery)
+	defer cancel()
+
+	return uc.repo.ListSynonyms(ctx)
+}
+
+func (uc *synonymUsecase) CreateSynonym(ctx context.Context, req *entities.SynonymGroupReq) (*entities.SynonymGroup, error) {
+	terms, err := synonymTerms(req.Terms)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, contextTimeoutQuery)
+	defer cancel()
+
+	group := &entities.SynonymGroup{Terms: terms}
+	if err := uc.repo.CreateSynonym(ctx, group); err != nil {
+		return nil, err
+	}
+	uc.applied(ctx)
+
+	return group, nil
+}
+
+func (uc *synonymUsecase) UpdateSynonym(ctx context.Context, id int, req *entities.SynonymGroupReq) (*entities.SynonymGroup, error) {
+	terms, err := synonymTerms(req.Terms)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, contextTimeoutQuery)
+	defer cancel()
+
+	group := &entities.SynonymGroup{ID: id, Terms: terms}
+	if err := uc.repo.UpdateSynonym(ctx, group); err != nil {
+		return nil, err
+	}
+	uc.applied(ctx)
+
+	return group, nil
+}
+
+func (uc *synonymUsecase) DeleteSynonym(ctx context.Context, id int) error {
+	ctx, cancel := context.WithTimeout(ctx, contextTimeoutQuery)
+	defer cancel()
+
+	if err := uc.repo.DeleteSynonym(ctx, id); err != nil {
+		return err
+	}
+	uc.applied(ctx)
+
+	return nil
+}
+
+func (uc *synonymUsecase) ListStopWords(ctx context.Context) ([]*entities.StopWord, error) {
+	ctx, cancel := context.WithTimeout(ctx, contextTimeoutQuery)
+	defer cancel()
+
+	return uc.repo.ListStopWords(ctx)
+}
+
+func (uc *synonymUsecase) AddStopWord(ctx context.Context, req *entities.StopWordReq) (*entities.StopWord, error) {
+	word := synonym.Normalize(req.Word)
+	if word == "" || strings.Contains(word, " ") {
+		return nil, errs.Validation("invalid_stop_word", "stop word must be a single word")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, contextTimeoutQuery)
+	defer cancel()
+
+	stopWord := &entities.StopWord{Word: word}
+	if err := uc.repo.AddStopWord(ctx, stopWord); err != nil {
+		return nil, err
+	}
+	uc.applied(ctx)
+
+	return stopWord, nil
+}
+
+func (uc *synonymUsecase) DeleteStopWord(ctx context.Context, word string) error {
+	ctx, cancel := context.WithTimeout(ctx, contextTimeoutQuery)
+	defer cancel()
+
+	if err := uc.repo.DeleteStopWord(ctx, synonym.Normalize(word)); err != nil {
+		return err
+	}
+	uc.applied(ctx)
+
+	return nil
+}
+
+// synonymTerms normalises the terms and drops duplicates, a group needs at
+// least two distinct terms to mean anything
+func synonymTerms(raw []string) ([]string, error) {
+	var terms []string
+	seen := make(map[string]bool)
+
+	for _, t := range raw {
+		t = synonym.Normalize(t)
+		if t == "" || seen[t] {
+			continue
+		}
+		if strings.ContainsAny(t, `"`) || strings.HasPrefix(t, "-") {
+			return nil, errs.Validation("invalid_synonym_term", "synonym terms cannot contain quotes or start with -")
+		}
+		seen[t] = true
+		terms = append(terms, t)
+	}
+
+	if len(terms) < 2 {
+		return nil, errs.Validation("synonym_terms_required", "synonym group needs at least two different terms")
+	}
+
+	return terms, nil
+}
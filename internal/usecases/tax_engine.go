@@ -0,0 +1,103 @@
+package usecases
+
+import (
+	"math/big"
+
+	"github.com/codepnw/react_go_ecom/internal/entities"
+	"github.com/codepnw/react_go_ecom/pkg/money"
+)
+
+// applyTaxes taxes every line of a discounted cart at the rate of its tax
+// class in the zone. Tax is rounded per line and the totals add up the
+// lines, so they always match an invoice listing them. Inclusive prices
+// keep the total, exclusive ones add the tax on top. A nil zone taxes
+// nothing.
+func applyTaxes(cart *entities.Cart, zone *entities.TaxZone, dest entities.Destination) {
+	cart.TaxCountry, cart.TaxRegion = dest.Country, dest.Region
+	cart.PricesIncludeTax = zone != nil && zone.PricesIncludeTax
+	cart.Taxes = []*entities.TaxSummary{}
+	cart.TaxTotal = money.New(0, cart.Currency)
+
+	for _, item := range cart.Items {
+		item.Tax = nil
+	}
+
+	if zone == nil {
+		return
+	}
+
+	discounts := make(map[string]money.Money)
+	for _, d := range cart.Discounts {
+		if d.ProductID != "" {
+			discounts[d.ProductID] = discounts[d.ProductID].Add(d.Amount)
+		}
+	}
+
+	type taxKey struct{ name, rate string }
+	summaries := make(map[taxKey]*entities.TaxSummary)
+	for _, item := range cart.Items {
+		rate := zoneRate(zone, item.TaxClassID)
+		if rate == nil {
+			continue
+		}
+
+		percent, ok := new(big.Rat).SetString(rate.Rate)
+		if !ok {
+			continue
+		}
+
+		amount := item.LineTotal.Sub(discounts[item.ProductID])
+		tax, taxable := lineTax(amount, percent, zone.PricesIncludeTax)
+
+		item.Tax = &entities.LineTax{
+			Name:    rate.Name,
+			Rate:    rate.Rate,
+			Taxable: taxable,
+			Amount:  tax,
+		}
+		cart.TaxTotal = cart.TaxTotal.Add(tax)
+
+		key := taxKey{rate.Name, rate.Rate}
+		summary, ok := summaries[key]
+		if !ok {
+			summary = &entities.TaxSummary{
+				Name:    rate.Name,
+				Rate:    rate.Rate,
+				Taxable: money.New(0, cart.Currency),
+				Amount:  money.New(0, cart.Currency),
+			}
+			summaries[key] = summary
+			cart.Taxes = append(cart.Taxes, summary)
+		}
+		summary.Taxable = summary.Taxable.Add(taxable)
+		summary.Amount = summary.Amount.Add(tax)
+	}
+
+	if !zone.PricesIncludeTax {
+		cart.Total = cart.Total.Add(cart.TaxTotal)
+	}
+}
+
+// lineTax returns the tax in or on amount at percent and the net amount
+// it is charged on
+func lineTax(amount money.Money, percent *big.Rat, inclusive bool) (money.Money, money.Money) {
+	hundred := big.NewRat(100, 1)
+
+	if inclusive {
+		tax := amount.Mul(new(big.Rat).Quo(percent, new(big.Rat).Add(hundred, percent)))
+		return tax, amount.Sub(tax)
+	}
+
+	return amount.Mul(new(big.Rat).Quo(percent, hundred)), amount
+}
+
+// zoneRate is the rate of the tax class in the zone, products without a
+// class pay the standard rate. Nil means the class is not taxed there.
+func zoneRate(zone *entities.TaxZone, classID *int) *entities.TaxRate {
+	for _, r := range zone.Rates {
+		if (classID != nil && r.TaxClassID == *classID) || (classID == nil && r.TaxClassCode == entities.TaxClassStandard) {
+			return r
+		}
+	}
+	return nil
+}
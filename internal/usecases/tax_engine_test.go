@@ -0,0 +1,99 @@
+package usecases
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/codepnw/react_go_ecom/internal/entities"
+)
+
+func vatZone(inclusive bool) *entities.TaxZone {
+	return &entities.TaxZone{
+		Country:          "TH",
+		PricesIncludeTax: inclusive,
+		Rates: []*entities.TaxRate{
+			{TaxClassID: 1, TaxClassCode: entities.TaxClassStandard, Name: "VAT", Rate: "7.0000"},
+		},
+	}
+}
+
+func taxCart(lines ...int64) *entities.Cart {
+	cart := &entities.Cart{Currency: "THB"}
+	for i, amount := range lines {
+		cart.Items = append(cart.Items, &entities.CartItem{
+			ProductID: string(rune('a' + i)),
+			Quantity:  1,
+			LineTotal: thb(amount),
+		})
+		cart.Subtotal = cart.Subtotal.Add(thb(amount))
+	}
+	cart.Total = cart.Subtotal
+	return cart
+}
+
+func TestLineTax(t *testing.T) {
+	seven := big.NewRat(7, 1)
+
+	tax, net := lineTax(thb(10700), seven, true)
+	if tax != thb(700) || net != thb(10000) {
+		t.Errorf("inclusive = %v on %v, want 7.00 on 100.00", tax, net)
+	}
+
+	tax, net = lineTax(thb(10000), seven, false)
+	if tax != thb(700) || net != thb(10000) {
+		t.Errorf("exclusive = %v on %v, want 7.00 on 100.00", tax, net)
+	}
+}
+
+func TestApplyTaxesRoundsPerLine(t *testing.T) {
+	// 7% of 0.10 is 0.007 and rounds to 0.01 on each line. Rounding the sum
+	// once would give 0.02 and no longer match the lines.
+	cart := taxCart(10, 10, 10)
+	applyTaxes(cart, vatZone(false), entities.Destination{Country: "TH"})
+
+	for _, item := range cart.Items {
+		if item.Tax == nil || item.Tax.Amount != thb(1) {
+			t.Fatalf("line tax = %+v, want 0.01", item.Tax)
+		}
+	}
+
+	if cart.TaxTotal != thb(3) || cart.Total != thb(33) {
+		t.Errorf("tax %v, total %v, want 0.03 and 0.33", cart.TaxTotal, cart.Total)
+	}
+	if len(cart.Taxes) != 1 || cart.Taxes[0].Amount != thb(3) || cart.Taxes[0].Taxable != thb(30) {
+		t.Errorf("summary = %+v", cart.Taxes)
+	}
+}
+
+func TestApplyTaxesInclusiveAfterDiscount(t *testing.T) {
+	cart := taxCart(11700)
+	cart.Discounts = []*entities.Discount{{ProductID: "a", Amount: thb(1000)}}
+	cart.Total = thb(10700)
+
+	applyTaxes(cart, vatZone(true), entities.Destination{Country: "TH"})
+
+	tax := cart.Items[0].Tax
+	if tax == nil || tax.Amount != thb(700) || tax.Taxable != thb(10000) {
+		t.Fatalf("line tax = %+v, want 7.00 on 100.00", tax)
+	}
+	if cart.Total != thb(10700) || !cart.PricesIncludeTax {
+		t.Errorf("total %v changed by inclusive tax", cart.Total)
+	}
+}
+
+func TestApplyTaxesUntaxedClassAndNoZone(t *testing.T) {
+	exempt := 2
+	cart := taxCart(10000)
+	cart.Items[0].TaxClassID = &exempt
+
+	applyTaxes(cart, vatZone(false), entities.Destination{Country: "TH"})
+	if cart.Items[0].Tax != nil || !cart.TaxTotal.IsZero() {
+		t.Errorf("class without a rate was taxed: %+v", cart.Items[0].Tax)
+	}
+
+	cart = taxCart(10000)
+	applyTaxes(cart, nil, entities.Destination{Country: "US"})
+	if !cart.TaxTotal.IsZero() || cart.Total != thb(10000) || cart.TaxCountry != "US" {
+		t.Errorf("nil zone taxed %v, total %v", cart.TaxTotal, cart.Total)
+	}
+}
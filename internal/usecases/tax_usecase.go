@@ -0,0 +1,212 @@
+package usecases
+
+import (
+	"context"
+	"math/big"
+	"regexp"
+	"strings"
+
+	"github.com/codepnw/react_go_ecom/internal/entities"
+	"github.com/codepnw/react_go_ecom/internal/errs"
+	"github.com/codepnw/react_go_ecom/internal/repositories"
+)
+
+var (
+	countryRegex      = regexp.MustCompile(`^[A-Z]{2}$`)
+	taxClassCodeRegex = regexp.MustCompile(`^[a-z0-9_]{2,30}$`)
+)
+
+// Taxer works out the tax of a priced and discounted cart for the country
+// and region the goods go to
+type Taxer interface {
+	ApplyTaxes(ctx context.Context, cart *entities.Cart, country, region string) error
+}
+
+type TaxUsecase interface {
+	Taxer
+	CreateClass(ctx context.Context, req *entities.TaxClassReq) (*entities.TaxClass, error)
+	ListClasses(ctx context.Context) ([]*entities.TaxClass, error)
+	DeleteClass(ctx context.Context, id int) error
+	CreateZone(ctx context.Context, req *entities.TaxZoneReq) (*entities.TaxZone, error)
+	ListZones(ctx context.Context) ([]*entities.TaxZone, error)
+	GetZone(ctx context.Context, id int) (*entities.TaxZone, error)
+	UpdateZone(ctx context.Context, id int, req *entities.TaxZoneReq) (*entities.TaxZone, error)
+	DeleteZone(ctx context.Context, id int) error
+	SetRate(ctx context.Context, zoneID, classID int, req *entities.TaxRateReq) (*entities.TaxZone, error)
+	DeleteRate(ctx context.Context, zoneID, classID int) error
+}
+
+type taxUsecase struct {
+	repo           repositories.TaxRepository
+	defaultCountry string
+}
+
+// NewTaxUsecase taxes carts without a country as sold in defaultCountry
+func NewTaxUsecase(repo repositories.TaxRepository, defaultCountry string) TaxUsecase {
+	return &taxUsecase{
+		repo:           repo,
+		defaultCountry: strings.ToUpper(defaultCountry),
+	}
+}
+
+func (uc *taxUsecase) ApplyTaxes(ctx context.Context, cart *entities.Cart, country, region string) error {
+	if country == "" {
+		country = uc.defaultCountry
+	}
+
+	dest, err := parseDestination(country, region)
+	if err != nil {
+		return err
+	}
+
+	zone, err := uc.repo.FindZone(ctx, dest)
+	if err != nil {
+		return err
+	}
+
+	applyTaxes(cart, zone, dest)
+
+	return nil
+}
+
+func (uc *taxUsecase) CreateClass(ctx context.Context, req *entities.TaxClassReq) (*entities.TaxClass, error) {
+	code := strings.ToLower(strings.TrimSpace(req.Code))
+	if !taxClassCodeRegex.MatchString(code) {
+		return nil, errs.Validation("invalid_tax_class_code", "tax class code must be 2 to 30 lowercase letters, digits or _")
+	}
+
+	class := &entities.TaxClass{Code: code, Name: req.Name}
+
+	ctx, cancel := context.WithTimeout(ctx, contextTimeoutQuery)
+	defer cancel()
+
+	if err := uc.repo.CreateClass(ctx, class); err != nil {
+		return nil, err
+	}
+
+	return class, nil
+}
+
+func (uc *taxUsecase) ListClasses(ctx context.Context) ([]*entities.TaxClass, error) {
+	ctx, cancel := context.WithTimeout(ctx, contextTimeoutQuery)
+	defer cancel()
+
+	return uc.repo.ListClasses(ctx)
+}
+
+func (uc *taxUsecase) DeleteClass(ctx context.Context, id int) error {
+	ctx, cancel := context.WithTimeout(ctx, contextTimeoutQuery)
+	defer cancel()
+
+	return uc.repo.DeleteClass(ctx, id)
+}
+
+func (uc *taxUsecase) CreateZone(ctx context.Context, req *entities.TaxZoneReq) (*entities.TaxZone, error) {
+	zone, err := buildTaxZone(req)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, contextTimeoutQuery)
+	defer cancel()
+
+	if err := uc.repo.CreateZone(ctx, zone); err != nil {
+		return nil, err
+	}
+
+	return zone, nil
+}
+
+func (uc *taxUsecase) ListZones(ctx context.Context) ([]*entities.TaxZone, error) {
+	ctx, cancel := context.WithTimeout(ctx, contextTimeoutQuery)
+	defer cancel()
+
+	return uc.repo.ListZones(ctx)
+}
+
+func (uc *taxUsecase) GetZone(ctx context.Context, id int) (*entities.TaxZone, error) {
+	ctx, cancel := context.WithTimeout(ctx, contextTimeoutQuery)
+	defer cancel()
+
+	return uc.repo.GetZone(ctx, id)
+}
+
+func (uc *taxUsecase) UpdateZone(ctx context.Context, id int, req *entities.TaxZoneReq) (*entities.TaxZone, error) {
+	zone, err := buildTaxZone(req)
+	if err != nil {
+		return nil, err
+	}
+	zone.ID = id
+
+	ctx, cancel := context.WithTimeout(ctx, contextTimeoutQuery)
+	defer cancel()
+
+	if err := uc.repo.UpdateZone(ctx, zone); err != nil {
+		return nil, err
+	}
+
+	return zone, nil
+}
+
+func (uc *taxUsecase) DeleteZone(ctx context.Context, id int) error {
+	ctx, cancel := context.WithTimeout(ctx, contextTimeoutQuery)
+	defer cancel()
+
+	return uc.repo.DeleteZone(ctx, id)
+}
+
+// SetRate sets the percentage the class pays in the zone and returns the
+// zone with its rates
+func (uc *taxUsecase) SetRate(ctx context.Context, zoneID, classID int, req *entities.TaxRateReq) (*entities.TaxZone, error) {
+	rate, ok := new(big.Rat).SetString(strings.TrimSpace(req.Rate))
+	if !ok || strings.ContainsAny(req.Rate, "/eE") || rate.Sign() < 0 || rate.Cmp(big.NewRat(100, 1)) > 0 ||
+		!new(big.Rat).Mul(rate, big.NewRat(10000, 1)).IsInt() {
+		return nil, errs.Validation("invalid_tax_rate", "rate must be a percentage from 0 to 100 with up to 4 decimals")
+	}
+	req.Rate = rate.FloatString(4)
+
+	ctx, cancel := context.WithTimeout(ctx, contextTimeoutQuery)
+	defer cancel()
+
+	if err := uc.repo.SetRate(ctx, zoneID, classID, req); err != nil {
+		return nil, err
+	}
+
+	return uc.repo.GetZone(ctx, zoneID)
+}
+
+func (uc *taxUsecase) DeleteRate(ctx context.Context, zoneID, classID int) error {
+	ctx, cancel := context.WithTimeout(ctx, contextTimeoutQuery)
+	defer cancel()
+
+	return uc.repo.DeleteRate(ctx, zoneID, classID)
+}
+
+func buildTaxZone(req *entities.TaxZoneReq) (*entities.TaxZone, error) {
+	dest, err := parseDestination(req.Country, req.Region)
+	if err != nil {
+		return nil, err
+	}
+
+	return &entities.TaxZone{
+		Name:             req.Name,
+		Country:          dest.Country,
+		Region:           dest.Region,
+		PricesIncludeTax: req.PricesIncludeTax,
+		Rates:            []*entities.TaxRate{},
+	}, nil
+}
+
+// parseDestination upper cases the ISO 3166-1 country code and the region
+// so tax and shipping zones match however they were typed
+func parseDestination(country, region string) (entities.Destination, error) {
+	country = strings.ToUpper(strings.TrimSpace(country))
+	if !countryRegex.MatchString(country) {
+		return entities.Destination{}, errs.Validation("invalid_country", "country must be a 2 letter ISO 3166-1 code")
+	}
+
+	return entities.Destination{
+		Country: country,
+		Region:  strings.ToUpper(strings.TrimSpace(region)),
+	}, nil
+}
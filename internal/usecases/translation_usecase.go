@@ -0,0 +1,153 @@
+package usecases
+
+import (
+	"context"
+	"strings"
+
+	"github.com/codepnw/react_go_ecom/internal/entities"
+	"github.com/codepnw/react_go_ecom/internal/errs"
+	"github.com/codepnw/react_go_ecom/internal/i18n"
+	"github.com/codepnw/react_go_ecom/internal/repositories"
+)
+
+type TranslationUsecase interface {
+	ListProductTranslations(ctx context.Context, productID string) ([]*entities.ProductTranslation, error)
+	SetProductTranslation(ctx context.Context, productID, locale string, req *entities.ProductTranslationReq) (*entities.ProductTranslation, error)
+	DeleteProductTranslation(ctx context.Context, productID, locale string) error
+	ListCategoryTranslations(ctx context.Context, categoryID int) ([]*entities.CategoryTranslation, error)
+	SetCategoryTranslation(ctx context.Context, categoryID int, locale string, req *entities.CategoryTranslationReq) (*entities.CategoryTranslation, error)
+	DeleteCategoryTranslation(ctx context.Context, categoryID int, locale string) error
+	ListAttributeTranslations(ctx context.Context, attributeID int) ([]*entities.AttributeTranslation, error)
+	SetAttributeTranslation(ctx context.Context, attributeID int, locale string, req *entities.AttributeTranslationReq) (*entities.AttributeTranslation, error)
+	DeleteAttributeTranslation(ctx context.Context, attributeID int, locale string) error
+}
+
+type translationUsecase struct {
+	repo repositories.TranslationRepository
+}
+
+func NewTranslationUsecase(repo repositories.TranslationRepository) TranslationUsecase {
+	return &translationUsecase{repo: repo}
+}
+
+func (uc *translationUsecase) ListProductTranslations(ctx context.Context, productID string) ([]*entities.ProductTranslation, error) {
+	ctx, cancel := context.WithTimeout(ctx, contextTimeoutQuery)
+	defer cancel()
+
+	return uc.repo.ListProductTranslations(ctx, productID)
+}
+
+func (uc *translationUsecase) SetProductTranslation(ctx context.Context, productID, locale string, req *entities.ProductTranslationReq) (*entities.ProductTranslation, error) {
+	locale, err := translationLocale(locale)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, contextTimeoutQuery)
+	defer cancel()
+
+	t := &entities.ProductTranslation{
+		Locale:      locale,
+		Title:       strings.TrimSpace(req.Title),
+		Description: req.Description,
+	}
+
+	if err := uc.repo.UpsertProductTranslation(ctx, productID, t); err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
+
+func (uc *translationUsecase) DeleteProductTranslation(ctx context.Context, productID, locale string) error {
+	ctx, cancel := context.WithTimeout(ctx, contextTimeoutQuery)
+	defer cancel()
+
+	return uc.repo.DeleteProductTranslation(ctx, productID, strings.ToLower(locale))
+}
+
+func (uc *translationUsecase) ListCategoryTranslations(ctx context.Context, categoryID int) ([]*entities.CategoryTranslation, error) {
+	ctx, cancel := context.WithTimeout(ctx, contextTimeoutQuery)
+	defer cancel()
+
+	return uc.repo.ListCategoryTranslations(ctx, categoryID)
+}
+
+func (uc *translationUsecase) SetCategoryTranslation(ctx context.Context, categoryID int, locale string, req *entities.CategoryTranslationReq) (*entities.CategoryTranslation, error) {
+	locale, err := translationLocale(locale)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, contextTimeoutQuery)
+	defer cancel()
+
+	t := &entities.CategoryTranslation{
+		Locale: locale,
+		Title:  strings.TrimSpace(req.Title),
+	}
+
+	if err := uc.repo.UpsertCategoryTranslation(ctx, categoryID, t); err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
+
+func (uc *translationUsecase) DeleteCategoryTranslation(ctx context.Context, categoryID int, locale string) error {
+	ctx, cancel := context.WithTimeout(ctx, contextTimeoutQuery)
+	defer cancel()
+
+	return uc.repo.DeleteCategoryTranslation(ctx, categoryID, strings.ToLower(locale))
+}
+
+func (uc *translationUsecase) ListAttributeTranslations(ctx context.Context, attributeID int) ([]*entities.AttributeTranslation, error) {
+	ctx, cancel := context.WithTimeout(ctx, contextTimeoutQuery)
+	defer cancel()
+
+	return uc.repo.ListAttributeTranslations(ctx, attributeID)
+}
+
+func (uc *translationUsecase) SetAttributeTranslation(ctx context.Context, attributeID int, locale string, req *entities.AttributeTranslationReq) (*entities.AttributeTranslation, error) {
+	locale, err := translationLocale(locale)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, contextTimeoutQuery)
+	defer cancel()
+
+	t := &entities.AttributeTranslation{
+		Locale: locale,
+		Label:  strings.TrimSpace(req.Label),
+	}
+
+	if err := uc.repo.UpsertAttributeTranslation(ctx, attributeID, t); err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
+
+func (uc *translationUsecase) DeleteAttributeTranslation(ctx context.Context, attributeID int, locale string) error {
+	ctx, cancel := context.WithTimeout(ctx, contextTimeoutQuery)
+	defer cancel()
+
+	return uc.repo.DeleteAttributeTranslation(ctx, attributeID, strings.ToLower(locale))
+}
+
+// translationLocale accepts the supported locales except the default one,
+// whose content lives on the product, category or attribute itself
+func translationLocale(locale string) (string, error) {
+	locale = strings.ToLower(locale)
+
+	if !i18n.Supported(locale) {
+		return "", errs.Validation("unsupported_locale", "locale %s is not supported", locale)
+	}
+
+	if locale == i18n.Default {
+		return "", errs.Validation("default_locale_translation", "content in %s is edited on the item itself", locale)
+	}
+
+	return locale, nil
+}
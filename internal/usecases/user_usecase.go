@@ -2,7 +2,6 @@ package usecases
 
 import (
 	"context"
-	"database/sql"
 	"errors"
 	"log"
 	"strconv"
@@ -10,9 +9,11 @@ import (
 
 	"github.com/codepnw/react_go_ecom/config"
 	"github.com/codepnw/react_go_ecom/internal/entities"
+	"github.com/codepnw/react_go_ecom/internal/errs"
 	"github.com/codepnw/react_go_ecom/internal/repositories"
 	"github.com/codepnw/react_go_ecom/internal/utils"
 	"github.com/codepnw/react_go_ecom/pkg/auth"
+	"github.com/codepnw/react_go_ecom/pkg/media"
 )
 
 type UserUsecase interface {
@@ -23,15 +24,19 @@ type UserUsecase interface {
 	Logout(token string) error
 }
 
+var errInvalidCredentials = errs.Unauthorized("invalid_credentials", "email or password is invalid")
+
 type userUsecase struct {
-	repo repositories.UserRepository
-	cfg  config.JWTConfig
+	repo  repositories.UserRepository
+	cfg   config.JWTConfig
+	blobs media.BlobStore
 }
 
-func NewUserUsecase(repo repositories.UserRepository, cfg config.JWTConfig) UserUsecase {
+func NewUserUsecase(repo repositories.UserRepository, cfg config.JWTConfig, blobs media.BlobStore) UserUsecase {
 	return &userUsecase{
-		repo: repo,
-		cfg:  cfg,
+		repo:  repo,
+		cfg:   cfg,
+		blobs: blobs,
 	}
 }
 
@@ -39,11 +44,11 @@ func (uc *userUsecase) Register(ctx context.Context, req *entities.UserRegisterR
 	var user entities.User
 
 	if !user.ValidateEmail(req.Email) {
-		return nil, errors.New("invalid email address")
+		return nil, errs.Validation("invalid_email", "invalid email address")
 	}
 
 	if !user.ValidatePassword(req.Password) {
-		return nil, errors.New("password least 6 character")
+		return nil, errs.Validation("password_too_short", "password must be at least 6 characters")
 	}
 
 	hashedPassword, err := user.HashedPassword(req.Password)
@@ -56,7 +61,7 @@ func (uc *userUsecase) Register(ctx context.Context, req *entities.UserRegisterR
 		Password:  hashedPassword,
 		FirstName: req.FirstName,
 		LastName:  req.LastName,
-		RoleID:    3,
+		RoleID:    entities.RoleCustomer,
 		Enabled:   true,
 		Address:   req.Address,
 		CreatedAt: utils.ThaiTime,
@@ -67,18 +72,14 @@ func (uc *userUsecase) Register(ctx context.Context, req *entities.UserRegisterR
 
 	userID, err := uc.repo.Create(ctx, &user)
 	if err != nil {
-		switch {
-		case err.Error() == "pq: duplicate key value violates unique constraint \"users_email_key\"":
-			return nil, errors.New("email is already exists")
-		default:
-			return nil, err
-		}
+		return nil, err
 	}
 
 	u, err := uc.repo.GetByID(ctx, userID)
 	if err != nil {
 		return nil, err
 	}
+	uc.avatarURL(u)
 
 	return u, nil
 }
@@ -87,11 +88,11 @@ func (uc *userUsecase) Login(ctx context.Context, req *entities.UserLoginReq) (s
 	var u entities.User
 
 	if !u.ValidateEmail(req.Email) {
-		return "", "", errors.New("invalid email address")
+		return "", "", errs.Validation("invalid_email", "invalid email address")
 	}
 
 	if !u.ValidatePassword(req.Password) {
-		return "", "", errors.New("password least 6 character")
+		return "", "", errs.Validation("password_too_short", "password must be at least 6 characters")
 	}
 
 	ctx, cancel := context.WithTimeout(ctx, contextTimeoutQuery)
@@ -99,14 +100,14 @@ func (uc *userUsecase) Login(ctx context.Context, req *entities.UserLoginReq) (s
 
 	user, err := uc.repo.GetByEmail(ctx, req.Email)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return "", "", errors.New("email or password is invalid")
+		if errors.Is(err, errs.ErrNotFound) {
+			return "", "", errInvalidCredentials
 		}
 		return "", "", err
 	}
 
 	if err := user.CompareHashedPassword(user.Password, req.Password); err != nil {
-		return "", "", err
+		return "", "", errInvalidCredentials
 	}
 
 	accessToken, refreshToken, err := auth.GenerateToken(user.ID, uc.cfg)
@@ -134,7 +135,7 @@ func (uc *userUsecase) RefreshToken(refreshToken string) (string, error) {
 	// Check Refresh Token
 	userID, err := uc.repo.ValidateRefreshToken(refreshToken)
 	if err != nil {
-		return "", errors.New("invalid or expires refresh token")
+		return "", err
 	}
 
 	// New Access Token
@@ -154,9 +155,22 @@ func (uc *userUsecase) GetProfile(ctx context.Context, id string) (*entities.Use
 
 	log.Println("user_id:", id)
 
-	return uc.repo.GetByID(ctx, id)
+	user, err := uc.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	uc.avatarURL(user)
+
+	return user, nil
 }
 
 func (uc *userUsecase) Logout(token string) error {
 	return uc.repo.DeleteRefreshToken(token)
 }
+
+// avatarURL turns the stored media key into a public URL
+func (uc *userUsecase) avatarURL(user *entities.User) {
+	if user.Avatar != "" {
+		user.Avatar = uc.blobs.URL(user.Avatar)
+	}
+}
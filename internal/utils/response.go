@@ -1,11 +1,22 @@
 package utils
 
 import (
+	"fmt"
+	"log"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/codepnw/react_go_ecom/internal/entities"
+	"github.com/codepnw/react_go_ecom/internal/errs"
+	"github.com/codepnw/react_go_ecom/internal/i18n"
 	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/render"
 )
 
 type IResponse interface {
 	Success(code int, data any)
+	List(code int, items any, page *entities.PageInfo, meta any)
 	Error(code int, err error)
 }
 
@@ -26,6 +37,187 @@ func (r *Response) Success(code int, data any) {
 	}
 }
 
+// PageLinks are the RFC 8288 relations of a page, also sent as Link header
+type PageLinks struct {
+	Self  string `json:"self"`
+	First string `json:"first"`
+	Next  string `json:"next,omitempty"`
+	Prev  string `json:"prev,omitempty"`
+}
+
+type Pagination struct {
+	*entities.PageInfo
+	Links *PageLinks `json:"links"`
+}
+
+// List writes a collection as {"data": [...], "pagination": {...}} with an
+// optional "meta" object. A nil page means the whole collection fits in one
+// response, it is reported as a single page with its total.
+func (r *Response) List(code int, items any, page *entities.PageInfo, meta any) {
+	if page == nil {
+		n := 0
+		if v := reflect.ValueOf(items); v.Kind() == reflect.Slice {
+			n = v.Len()
+		}
+		page = &entities.PageInfo{Limit: n, Total: &n}
+	}
+
+	links := r.pageLinks(page)
+
+	var header []string
+	for _, l := range []struct{ rel, url string }{
+		{"first", links.First},
+		{"prev", links.Prev},
+		{"next", links.Next},
+	} {
+		if l.url != "" {
+			header = append(header, fmt.Sprintf(`<%s>; rel="%s"`, l.url, l.rel))
+		}
+	}
+	r.Context.Header("Link", strings.Join(header, ", "))
+
+	body := gin.H{
+		"data":       items,
+		"pagination": &Pagination{PageInfo: page, Links: links},
+	}
+	if meta != nil {
+		body["meta"] = meta
+	}
+
+	r.Context.JSON(code, body)
+}
+
+// pageLinks keeps the request path and query, only the cursor changes
+func (r *Response) pageLinks(page *entities.PageInfo) *PageLinks {
+	u := *r.Context.Request.URL
+
+	withCursor := func(cursor string) string {
+		q := u.Query()
+		q.Del("cursor")
+		if cursor != "" {
+			q.Set("cursor", cursor)
+		}
+
+		link := u
+		link.RawQuery = q.Encode()
+		return link.RequestURI()
+	}
+
+	links := &PageLinks{
+		Self:  u.RequestURI(),
+		First: withCursor(""),
+	}
+	if page.NextCursor != "" {
+		links.Next = withCursor(page.NextCursor)
+	}
+	if page.PrevCursor != "" {
+		links.Prev = withCursor(page.PrevCursor)
+	}
+
+	return links
+}
+
+var errorStatus = map[errs.Kind]int{
+	errs.KindNotFound:          http.StatusNotFound,
+	errs.KindConflict:          http.StatusConflict,
+	errs.KindValidation:        http.StatusBadRequest,
+	errs.KindUnauthorized:      http.StatusUnauthorized,
+	errs.KindForbidden:         http.StatusForbidden,
+	errs.KindInsufficientStock: http.StatusConflict,
+	errs.KindTooManyRequests:   http.StatusTooManyRequests,
+}
+
+// Problem is an RFC 9457 problem details body
+type Problem struct {
+	Type      string            `json:"type"`
+	Title     string            `json:"title"`
+	Status    int               `json:"status"`
+	Detail    string            `json:"detail"`
+	Code      string            `json:"code"`
+	RequestID string            `json:"request_id,omitempty"`
+	Errors    []errs.FieldError `json:"errors,omitempty"`
+}
+
+// Error writes the error as application/problem+json. A domain error decides
+// the status itself, code is the status for anything else. Only domain
+// errors show their message, others are logged and reported generically so
+// driver and validator internals never reach the client. Details are in the
+// language negotiated for the request.
 func (r *Response) Error(code int, err error) {
-	r.Context.JSON(code, gin.H{"error": err.Error()})
+	lang := Language(r.Context)
+
+	e, ok := errs.As(err)
+	if !ok {
+		e = bindingError(err, lang)
+	}
+
+	requestID := r.Context.GetString("request_id")
+
+	problem := &Problem{
+		Status:    code,
+		RequestID: requestID,
+	}
+
+	if e != nil {
+		if status, ok := errorStatus[e.Kind]; ok {
+			problem.Status = status
+		}
+		problem.Code = e.Code
+		problem.Detail = i18n.Error(lang, e)
+		problem.Errors = e.Fields
+	} else {
+		log.Printf("request %s: %v", requestID, err)
+		problem.Code = statusCode(code)
+		problem.Detail, _ = i18n.Message(lang, "request_failed")
+		if code >= http.StatusInternalServerError {
+			problem.Detail, _ = i18n.Message(lang, "unexpected_error")
+		}
+	}
+
+	problem.Type = "/problems/" + strings.ReplaceAll(problem.Code, "_", "-")
+	problem.Title = http.StatusText(problem.Status)
+
+	// Render keeps a content type that is already set
+	r.Context.Header("Content-Type", "application/problem+json")
+	r.Context.Header("Content-Language", lang)
+	r.Context.Render(problem.Status, render.JSON{Data: problem})
+}
+
+// Locale is the content locale of a catalog read. The locale query param
+// wins over Accept-Language, unsupported locales get the default content.
+func Locale(c *gin.Context) string {
+	if locale := c.Query("locale"); locale != "" {
+		return i18n.Negotiate(locale)
+	}
+	return Language(c)
+}
+
+// Language is the language set by the language middleware, negotiated from
+// the request when it did not run
+func Language(c *gin.Context) string {
+	if lang := c.GetString("lang"); lang != "" {
+		return lang
+	}
+	return i18n.Negotiate(c.GetHeader("Accept-Language"))
+}
+
+// IsAdmin reports whether the role middleware found an admin
+func IsAdmin(c *gin.Context) bool {
+	return c.GetInt("role_id") == entities.RoleAdmin
+}
+
+// Message is the catalog entry of code in the request's language, for
+// messages that are part of a successful response
+func Message(c *gin.Context, code string, args ...any) string {
+	msg, _ := i18n.Message(Language(c), code, args...)
+	return msg
+}
+
+// statusCode names a status for errors without a code, 404 is "not_found"
+func statusCode(status int) string {
+	text := http.StatusText(status)
+	if text == "" {
+		text = "error"
+	}
+	return strings.ReplaceAll(strings.ToLower(text), " ", "_")
 }
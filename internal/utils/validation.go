@@ -0,0 +1,116 @@
+package utils
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"log"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/codepnw/react_go_ecom/internal/errs"
+	"github.com/codepnw/react_go_ecom/internal/i18n"
+	"github.com/codepnw/react_go_ecom/pkg/money"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+var setupValidator sync.Once
+
+// SetupValidator makes validation errors name fields by their json or form
+// tag, the names clients actually send, and registers their translations
+func SetupValidator() {
+	setupValidator.Do(func() {
+		v, ok := binding.Validator.Engine().(*validator.Validate)
+		if !ok {
+			return
+		}
+
+		v.RegisterTagNameFunc(func(f reflect.StructField) string {
+			for _, tag := range []string{"json", "form"} {
+				name, _, _ := strings.Cut(f.Tag.Get(tag), ",")
+				if name == "-" {
+					return ""
+				}
+				if name != "" {
+					return name
+				}
+			}
+			return f.Name
+		})
+
+		if err := i18n.RegisterValidator(v); err != nil {
+			log.Println("register validation messages:", err)
+		}
+	})
+}
+
+// bindingError turns what ShouldBind returns into a validation error with
+// field details in lang, nil when err does not come from binding
+func bindingError(err error, lang string) *errs.Error {
+	var validationErrs validator.ValidationErrors
+	var typeErr *json.UnmarshalTypeError
+	var syntaxErr *json.SyntaxError
+	var numErr *strconv.NumError
+	var timeErr *time.ParseError
+
+	switch {
+	case errors.As(err, &validationErrs):
+		fields := make([]errs.FieldError, len(validationErrs))
+		for i, fe := range validationErrs {
+			fields[i] = errs.FieldError{Field: fieldPath(fe), Message: i18n.FieldMessage(lang, fe)}
+		}
+		return errs.Validation("validation_failed", "request has invalid fields").WithFields(fields...)
+	case errors.As(err, &typeErr):
+		msg, _ := i18n.Message(lang, "field_"+jsonType(typeErr.Type), typeErr.Field)
+		return errs.Validation("validation_failed", "request has invalid fields").WithFields(errs.FieldError{
+			Field:   typeErr.Field,
+			Message: msg,
+		})
+	case errors.As(err, &syntaxErr), errors.Is(err, io.ErrUnexpectedEOF):
+		return errs.Validation("malformed_body", "request body is not valid JSON")
+	case errors.Is(err, io.EOF):
+		return errs.Validation("empty_body", "request body is empty")
+	case errors.Is(err, money.ErrUnknownCurrency):
+		return errs.Validation("unknown_currency", "currency is not supported")
+	case errors.Is(err, money.ErrInvalidAmount):
+		return errs.Validation("invalid_amount", "amount is not a valid decimal for its currency")
+	case errors.As(err, &numErr), errors.As(err, &timeErr):
+		return errs.Validation("invalid_query", "query has a value of the wrong type")
+	}
+
+	return nil
+}
+
+// fieldPath drops the struct name, "ProductPayloadReq.title" becomes
+// "title" and nested fields keep their path
+func fieldPath(fe validator.FieldError) string {
+	_, path, found := strings.Cut(fe.Namespace(), ".")
+	if !found {
+		return fe.Field()
+	}
+	return path
+}
+
+// jsonType names the catalog message for a value of the wrong type
+func jsonType(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Slice, reflect.Array:
+		return "list"
+	case reflect.Map, reflect.Struct:
+		return "object"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	}
+	return "invalid"
+}
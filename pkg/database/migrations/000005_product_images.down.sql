@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS product_images CASCADE;
+
+UPDATE users SET images = 'later' WHERE images IS NULL;
+ALTER TABLE users ALTER COLUMN images SET NOT NULL;
@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS product_images (
+    id SERIAL PRIMARY KEY,
+    product_id VARCHAR(10) NOT NULL REFERENCES products(product_id) ON DELETE CASCADE,
+    storage_key TEXT UNIQUE NOT NULL,
+    content_type VARCHAR(50) NOT NULL,
+    size_bytes BIGINT NOT NULL,
+    position INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_product_images_product ON product_images (product_id, position);
+
+-- Users avatar stores a media key instead of a placeholder
+ALTER TABLE users ALTER COLUMN images TYPE TEXT;
+ALTER TABLE users ALTER COLUMN images DROP NOT NULL;
+UPDATE users SET images = NULL WHERE images = 'later';
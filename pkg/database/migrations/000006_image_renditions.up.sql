@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS product_image_renditions (
+    image_id INT NOT NULL REFERENCES product_images(id) ON DELETE CASCADE,
+    name VARCHAR(20) NOT NULL,
+    storage_key TEXT UNIQUE NOT NULL,
+    content_type VARCHAR(50) NOT NULL,
+    width INT NOT NULL,
+    height INT NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT now(),
+    PRIMARY KEY (image_id, name)
+);
@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS product_attribute_values CASCADE;
+
+DROP TABLE IF EXISTS category_attributes CASCADE;
+
+DROP TABLE IF EXISTS attributes CASCADE;
+
+DROP TYPE IF EXISTS attribute_type CASCADE;
@@ -0,0 +1,33 @@
+CREATE TYPE attribute_type AS ENUM ('text', 'number', 'boolean', 'enum');
+
+CREATE TABLE IF NOT EXISTS attributes (
+    id SERIAL PRIMARY KEY,
+    code VARCHAR(50) UNIQUE NOT NULL,
+    label VARCHAR(100) NOT NULL,
+    type attribute_type NOT NULL,
+    unit VARCHAR(20),
+    options TEXT[] NOT NULL DEFAULT '{}',
+    created_at TIMESTAMPTZ DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS category_attributes (
+    category_id INT NOT NULL REFERENCES categories(id) ON DELETE CASCADE,
+    attribute_id INT NOT NULL REFERENCES attributes(id) ON DELETE CASCADE,
+    required BOOLEAN NOT NULL DEFAULT false,
+    position INT NOT NULL DEFAULT 0,
+    PRIMARY KEY (category_id, attribute_id)
+);
+
+-- One typed column is set per row depending on the attribute type,
+-- enum values are stored in value_text
+CREATE TABLE IF NOT EXISTS product_attribute_values (
+    product_id VARCHAR(10) NOT NULL REFERENCES products(product_id) ON DELETE CASCADE,
+    attribute_id INT NOT NULL REFERENCES attributes(id) ON DELETE CASCADE,
+    value_text TEXT,
+    value_number NUMERIC,
+    value_bool BOOLEAN,
+    PRIMARY KEY (product_id, attribute_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_attribute_values_text ON product_attribute_values (attribute_id, value_text);
+CREATE INDEX IF NOT EXISTS idx_attribute_values_number ON product_attribute_values (attribute_id, value_number);
@@ -0,0 +1,11 @@
+DROP TRIGGER IF EXISTS attribute_values_search_vector_update ON product_attribute_values;
+DROP TRIGGER IF EXISTS products_search_vector_update ON products;
+
+DROP FUNCTION IF EXISTS attribute_values_search_vector_trigger();
+DROP FUNCTION IF EXISTS products_search_vector_trigger();
+DROP FUNCTION IF EXISTS product_search_vector(VARCHAR, TEXT, TEXT);
+
+DROP INDEX IF EXISTS idx_products_title_trgm;
+DROP INDEX IF EXISTS idx_products_search_vector;
+
+ALTER TABLE products DROP COLUMN IF EXISTS search_vector;
@@ -0,0 +1,50 @@
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+ALTER TABLE products ADD COLUMN search_vector tsvector;
+
+-- The 'simple' config is used because the catalog mixes Thai and English
+CREATE OR REPLACE FUNCTION product_search_vector(p_id VARCHAR, p_title TEXT, p_description TEXT)
+RETURNS tsvector AS $$
+    SELECT setweight(to_tsvector('simple', COALESCE(p_title, '')), 'A') ||
+        setweight(to_tsvector('simple', COALESCE(p_description, '')), 'B') ||
+        setweight(to_tsvector('simple', COALESCE((
+            SELECT string_agg(COALESCE(v.value_text, v.value_number::TEXT, ''), ' ')
+            FROM product_attribute_values v WHERE v.product_id = p_id
+        ), '')), 'C');
+$$ LANGUAGE sql STABLE;
+
+CREATE OR REPLACE FUNCTION products_search_vector_trigger() RETURNS trigger AS $$
+BEGIN
+    NEW.search_vector := product_search_vector(NEW.product_id, NEW.title, NEW.description);
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER products_search_vector_update
+BEFORE INSERT OR UPDATE OF title, description ON products
+FOR EACH ROW EXECUTE FUNCTION products_search_vector_trigger();
+
+CREATE OR REPLACE FUNCTION attribute_values_search_vector_trigger() RETURNS trigger AS $$
+DECLARE
+    pid VARCHAR;
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        pid := OLD.product_id;
+    ELSE
+        pid := NEW.product_id;
+    END IF;
+
+    UPDATE products SET search_vector = product_search_vector(product_id, title, description)
+    WHERE product_id = pid;
+    RETURN NULL;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER attribute_values_search_vector_update
+AFTER INSERT OR UPDATE OR DELETE ON product_attribute_values
+FOR EACH ROW EXECUTE FUNCTION attribute_values_search_vector_trigger();
+
+UPDATE products SET search_vector = product_search_vector(product_id, title, description);
+
+CREATE INDEX IF NOT EXISTS idx_products_search_vector ON products USING GIN (search_vector);
+CREATE INDEX IF NOT EXISTS idx_products_title_trgm ON products USING GIN (title gin_trgm_ops);
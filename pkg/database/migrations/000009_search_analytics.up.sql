@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS search_queries (
+    id BIGSERIAL PRIMARY KEY,
+    query TEXT NOT NULL,
+    raw_query TEXT NOT NULL,
+    result_count INT NOT NULL,
+    match_mode VARCHAR(20),
+    user_id VARCHAR(10),
+    session_id VARCHAR(100),
+    clicked_product_id VARCHAR(10) REFERENCES products(product_id) ON DELETE SET NULL,
+    clicked_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+-- Reports group by normalised query within a date range
+CREATE INDEX IF NOT EXISTS idx_search_queries_created ON search_queries (created_at);
+CREATE INDEX IF NOT EXISTS idx_search_queries_query ON search_queries (query, created_at);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS search_stop_words CASCADE;
+DROP TABLE IF EXISTS search_synonyms CASCADE;
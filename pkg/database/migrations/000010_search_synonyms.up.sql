@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS search_synonyms (
+    id SERIAL PRIMARY KEY,
+    terms TEXT[] NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS search_stop_words (
+    word VARCHAR(50) PRIMARY KEY,
+    created_at TIMESTAMPTZ DEFAULT now()
+);
+
+INSERT INTO search_synonyms (terms) VALUES ('{tee,t-shirt,t shirt}');
+
+INSERT INTO search_stop_words (word) VALUES
+    ('a'), ('an'), ('the'), ('and'), ('for'), ('of'), ('with'), ('in'), ('on'), ('to')
+ON CONFLICT DO NOTHING;
@@ -0,0 +1,18 @@
+DROP TRIGGER IF EXISTS product_translations_search_vector_update ON product_translations;
+DROP FUNCTION IF EXISTS product_translations_search_vector_trigger();
+
+CREATE OR REPLACE FUNCTION product_search_vector(p_id VARCHAR, p_title TEXT, p_description TEXT)
+RETURNS tsvector AS $$
+    SELECT setweight(to_tsvector('simple', COALESCE(p_title, '')), 'A') ||
+        setweight(to_tsvector('simple', COALESCE(p_description, '')), 'B') ||
+        setweight(to_tsvector('simple', COALESCE((
+            SELECT string_agg(COALESCE(v.value_text, v.value_number::TEXT, ''), ' ')
+            FROM product_attribute_values v WHERE v.product_id = p_id
+        ), '')), 'C');
+$$ LANGUAGE sql STABLE;
+
+DROP TABLE IF EXISTS attribute_translations;
+DROP TABLE IF EXISTS category_translations;
+DROP TABLE IF EXISTS product_translations;
+
+UPDATE products SET search_vector = product_search_vector(product_id, title, description);
@@ -0,0 +1,65 @@
+-- The columns of products, categories and attributes hold the default locale,
+-- these rows hold the other locales. A missing row falls back to the column.
+CREATE TABLE IF NOT EXISTS product_translations (
+    product_id VARCHAR(10) NOT NULL REFERENCES products(product_id) ON DELETE CASCADE,
+    locale VARCHAR(10) NOT NULL,
+    title VARCHAR(255) NOT NULL,
+    description TEXT,
+    updated_at TIMESTAMPTZ DEFAULT now(),
+    PRIMARY KEY (product_id, locale)
+);
+
+CREATE TABLE IF NOT EXISTS category_translations (
+    category_id INT NOT NULL REFERENCES categories(id) ON DELETE CASCADE,
+    locale VARCHAR(10) NOT NULL,
+    title VARCHAR(255) NOT NULL,
+    updated_at TIMESTAMPTZ DEFAULT now(),
+    PRIMARY KEY (category_id, locale)
+);
+
+CREATE TABLE IF NOT EXISTS attribute_translations (
+    attribute_id INT NOT NULL REFERENCES attributes(id) ON DELETE CASCADE,
+    locale VARCHAR(10) NOT NULL,
+    label VARCHAR(100) NOT NULL,
+    updated_at TIMESTAMPTZ DEFAULT now(),
+    PRIMARY KEY (attribute_id, locale)
+);
+
+CREATE INDEX IF NOT EXISTS idx_product_translations_title_trgm ON product_translations USING GIN (title gin_trgm_ops);
+
+-- Searches match a product in any locale
+CREATE OR REPLACE FUNCTION product_search_vector(p_id VARCHAR, p_title TEXT, p_description TEXT)
+RETURNS tsvector AS $$
+    SELECT setweight(to_tsvector('simple', COALESCE(p_title, '')), 'A') ||
+        setweight(to_tsvector('simple', COALESCE(p_description, '')), 'B') ||
+        setweight(to_tsvector('simple', COALESCE((
+            SELECT string_agg(t.title, ' ') FROM product_translations t WHERE t.product_id = p_id
+        ), '')), 'A') ||
+        setweight(to_tsvector('simple', COALESCE((
+            SELECT string_agg(COALESCE(t.description, ''), ' ') FROM product_translations t WHERE t.product_id = p_id
+        ), '')), 'B') ||
+        setweight(to_tsvector('simple', COALESCE((
+            SELECT string_agg(COALESCE(v.value_text, v.value_number::TEXT, ''), ' ')
+            FROM product_attribute_values v WHERE v.product_id = p_id
+        ), '')), 'C');
+$$ LANGUAGE sql STABLE;
+
+CREATE OR REPLACE FUNCTION product_translations_search_vector_trigger() RETURNS trigger AS $$
+DECLARE
+    pid VARCHAR;
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        pid := OLD.product_id;
+    ELSE
+        pid := NEW.product_id;
+    END IF;
+
+    UPDATE products SET search_vector = product_search_vector(product_id, title, description)
+    WHERE product_id = pid;
+    RETURN NULL;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER product_translations_search_vector_update
+AFTER INSERT OR UPDATE OR DELETE ON product_translations
+FOR EACH ROW EXECUTE FUNCTION product_translations_search_vector_trigger();
@@ -0,0 +1,7 @@
+ALTER TABLE product_cart ALTER COLUMN price TYPE FLOAT;
+ALTER TABLE carts ALTER COLUMN cart_total TYPE INT USING ROUND(cart_total);
+ALTER TABLE product_order ALTER COLUMN price TYPE FLOAT;
+ALTER TABLE orders ALTER COLUMN cart_total TYPE FLOAT;
+
+ALTER TABLE products DROP CONSTRAINT IF EXISTS products_price_check;
+ALTER TABLE products ALTER COLUMN price TYPE FLOAT;
@@ -0,0 +1,9 @@
+-- Money is exact: amounts are NUMERIC, never FLOAT. Four decimal places
+-- cover every supported currency's minor unit.
+ALTER TABLE products ALTER COLUMN price TYPE NUMERIC(19, 4) USING ROUND(price::NUMERIC, 2);
+ALTER TABLE products ADD CONSTRAINT products_price_check CHECK (price >= 0);
+
+ALTER TABLE orders ALTER COLUMN cart_total TYPE NUMERIC(19, 4) USING ROUND(cart_total::NUMERIC, 2);
+ALTER TABLE product_order ALTER COLUMN price TYPE NUMERIC(19, 4) USING ROUND(price::NUMERIC, 2);
+ALTER TABLE carts ALTER COLUMN cart_total TYPE NUMERIC(19, 4);
+ALTER TABLE product_cart ALTER COLUMN price TYPE NUMERIC(19, 4) USING ROUND(price::NUMERIC, 2);
@@ -0,0 +1,42 @@
+DROP TABLE IF EXISTS order_items;
+DROP TABLE IF EXISTS orders;
+DROP TYPE IF EXISTS order_status;
+DROP TABLE IF EXISTS cart_items;
+DROP TABLE IF EXISTS product_prices;
+DROP TABLE IF EXISTS exchange_rates;
+
+CREATE TYPE order_status AS ENUM ('waiting', 'shipping', 'completed', 'canceled');
+
+CREATE TABLE IF NOT EXISTS orders (
+    id SERIAL PRIMARY KEY,
+    cart_total NUMERIC(19, 4) NOT NULL,
+    order_by INT NOT NULL,
+    amount INT,
+    status order_status DEFAULT 'waiting',
+    currentcy VARCHAR(10) DEFAULT 'THB',
+    created_at TIMESTAMPTZ DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS product_order (
+    id SERIAL PRIMARY KEY,
+    product_id INT NULL,
+    order_id INT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    count INT,
+    price NUMERIC(19, 4)
+);
+
+CREATE TABLE IF NOT EXISTS carts (
+    id SERIAL PRIMARY KEY,
+    cart_total NUMERIC(19, 4) NOT NULL,
+    order_by INT NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT now(),
+    updated_at TIMESTAMPTZ DEFAULT now()
+);
+
+CREATE TABLE IF NOT EXISTS product_cart (
+    id SERIAL PRIMARY KEY,
+    cart_id INT NOT NULL REFERENCES carts(id) ON DELETE CASCADE,
+    product_id INT NOT NULL,
+    count INT NOT NULL,
+    price NUMERIC(19, 4) NOT NULL
+);
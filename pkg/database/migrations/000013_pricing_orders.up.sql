@@ -0,0 +1,72 @@
+-- Rates convert base into quote: 1 base = rate quote. A rate applies from
+-- its effective date until a later one for the same pair.
+CREATE TABLE IF NOT EXISTS exchange_rates (
+    id SERIAL PRIMARY KEY,
+    base CHAR(3) NOT NULL,
+    quote CHAR(3) NOT NULL,
+    rate NUMERIC(19, 8) NOT NULL CHECK (rate > 0),
+    effective_from TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT now(),
+    CHECK (base <> quote)
+);
+
+CREATE INDEX IF NOT EXISTS idx_exchange_rates_pair ON exchange_rates (base, quote, effective_from DESC);
+
+-- Explicit prices win over converted ones, the base price stays on products
+CREATE TABLE IF NOT EXISTS product_prices (
+    product_id VARCHAR(10) NOT NULL REFERENCES products(product_id) ON DELETE CASCADE,
+    currency CHAR(3) NOT NULL,
+    amount NUMERIC(19, 4) NOT NULL CHECK (amount >= 0),
+    updated_at TIMESTAMPTZ DEFAULT now(),
+    PRIMARY KEY (product_id, currency)
+);
+
+-- The cart and order tables of the first schema lost their keys when user
+-- and product ids changed and were never used
+DROP TABLE IF EXISTS product_cart;
+DROP TABLE IF EXISTS carts;
+DROP TABLE IF EXISTS product_order;
+DROP TABLE IF EXISTS orders;
+DROP TYPE IF EXISTS order_status;
+
+CREATE TABLE IF NOT EXISTS cart_items (
+    user_id VARCHAR(6) NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    product_id VARCHAR(10) NOT NULL REFERENCES products(product_id) ON DELETE CASCADE,
+    quantity INT NOT NULL CHECK (quantity > 0),
+    created_at TIMESTAMPTZ DEFAULT now(),
+    updated_at TIMESTAMPTZ DEFAULT now(),
+    PRIMARY KEY (user_id, product_id)
+);
+
+CREATE TYPE order_status AS ENUM ('pending', 'paid', 'shipped', 'completed', 'canceled');
+
+-- An order keeps the currency and rate of its checkout, later rate changes
+-- never touch it. The rate is 1 for the base currency and NULL when only
+-- explicit prices were used and no rate existed.
+CREATE TABLE IF NOT EXISTS orders (
+    id BIGSERIAL PRIMARY KEY,
+    user_id VARCHAR(6) REFERENCES users(user_id) ON DELETE SET NULL,
+    status order_status NOT NULL DEFAULT 'pending',
+    currency CHAR(3) NOT NULL,
+    exchange_rate NUMERIC(19, 8),
+    exchange_rate_id INT REFERENCES exchange_rates(id) ON DELETE SET NULL,
+    subtotal NUMERIC(19, 4) NOT NULL,
+    total NUMERIC(19, 4) NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT now(),
+    updated_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_orders_user ON orders (user_id, id DESC);
+
+CREATE TABLE IF NOT EXISTS order_items (
+    id BIGSERIAL PRIMARY KEY,
+    order_id BIGINT NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    product_id VARCHAR(10) REFERENCES products(product_id) ON DELETE SET NULL,
+    title VARCHAR(255) NOT NULL,
+    quantity INT NOT NULL CHECK (quantity > 0),
+    unit_price NUMERIC(19, 4) NOT NULL,
+    base_unit_price NUMERIC(19, 4) NOT NULL,
+    line_total NUMERIC(19, 4) NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_order_items_order ON order_items (order_id);
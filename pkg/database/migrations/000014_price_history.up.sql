@@ -0,0 +1,41 @@
+-- Future price changes, applied by the scheduler once effective_at passes.
+-- The currency is the base one for the product price, others set an
+-- explicit price.
+CREATE TABLE IF NOT EXISTS scheduled_prices (
+    id BIGSERIAL PRIMARY KEY,
+    product_id VARCHAR(10) NOT NULL REFERENCES products(product_id) ON DELETE CASCADE,
+    currency CHAR(3) NOT NULL,
+    amount NUMERIC(19, 4) NOT NULL CHECK (amount > 0),
+    effective_at TIMESTAMPTZ NOT NULL,
+    reason VARCHAR(255) NOT NULL DEFAULT '',
+    created_by VARCHAR(6) REFERENCES users(user_id) ON DELETE SET NULL,
+    status VARCHAR(10) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'applied', 'canceled')),
+    applied_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_scheduled_prices_due ON scheduled_prices (effective_at) WHERE status = 'pending';
+CREATE INDEX IF NOT EXISTS idx_scheduled_prices_product ON scheduled_prices (product_id, effective_at);
+
+-- Every price change with who made it and why. A NULL old amount is a first
+-- explicit price, a NULL new amount a removed one.
+CREATE TABLE IF NOT EXISTS price_history (
+    id BIGSERIAL PRIMARY KEY,
+    product_id VARCHAR(10) NOT NULL REFERENCES products(product_id) ON DELETE CASCADE,
+    currency CHAR(3) NOT NULL,
+    old_amount NUMERIC(19, 4),
+    new_amount NUMERIC(19, 4),
+    actor VARCHAR(6) REFERENCES users(user_id) ON DELETE SET NULL,
+    reason VARCHAR(255) NOT NULL DEFAULT '',
+    scheduled_price_id BIGINT REFERENCES scheduled_prices(id) ON DELETE SET NULL,
+    changed_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_price_history_product ON price_history (product_id, id DESC);
+
+-- Current prices become the first entry so "was" prices exist from the start
+INSERT INTO price_history (product_id, currency, new_amount, reason, changed_at)
+SELECT product_id, 'THB', price, 'initial price', COALESCE(updated_at, created_at, now()) FROM products;
+
+INSERT INTO price_history (product_id, currency, new_amount, reason, changed_at)
+SELECT product_id, currency, amount, 'initial price', COALESCE(updated_at, now()) FROM product_prices;
@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS promotion_redemptions;
+DROP TABLE IF EXISTS order_discounts;
+
+ALTER TABLE orders DROP COLUMN IF EXISTS free_shipping;
+ALTER TABLE orders DROP COLUMN IF EXISTS discount_total;
+
+DROP TABLE IF EXISTS promotions;
@@ -0,0 +1,59 @@
+-- A promotion without a code applies automatically, one with a code is a
+-- coupon. Fixed amounts and min spend are in the promotion's currency and
+-- only apply to carts in that currency, percentages apply in any currency.
+CREATE TABLE IF NOT EXISTS promotions (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    code VARCHAR(50) UNIQUE,
+    kind VARCHAR(20) NOT NULL CHECK (kind IN ('percentage', 'fixed_amount', 'free_shipping', 'buy_x_get_y')),
+    percent NUMERIC(5, 2) CHECK (percent > 0 AND percent <= 100),
+    amount NUMERIC(19, 4) CHECK (amount > 0),
+    buy_quantity INT CHECK (buy_quantity > 0),
+    get_quantity INT CHECK (get_quantity > 0),
+    currency CHAR(3) NOT NULL DEFAULT 'THB',
+    product_ids VARCHAR(10)[] NOT NULL DEFAULT '{}',
+    category_ids INT[] NOT NULL DEFAULT '{}',
+    min_spend NUMERIC(19, 4) CHECK (min_spend > 0),
+    starts_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    ends_at TIMESTAMPTZ,
+    usage_limit INT CHECK (usage_limit > 0),
+    per_user_limit INT CHECK (per_user_limit > 0),
+    used_count INT NOT NULL DEFAULT 0,
+    stackable BOOLEAN NOT NULL DEFAULT FALSE,
+    priority INT NOT NULL DEFAULT 0,
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMPTZ DEFAULT now(),
+    updated_at TIMESTAMPTZ,
+    CHECK (ends_at IS NULL OR ends_at > starts_at)
+);
+
+CREATE INDEX IF NOT EXISTS idx_promotions_automatic ON promotions (starts_at) WHERE code IS NULL AND active;
+
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS discount_total NUMERIC(19, 4) NOT NULL DEFAULT 0;
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS free_shipping BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- The itemised discounts of an order, product_id is NULL for a free
+-- shipping line
+CREATE TABLE IF NOT EXISTS order_discounts (
+    id BIGSERIAL PRIMARY KEY,
+    order_id BIGINT NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    promotion_id INT REFERENCES promotions(id) ON DELETE SET NULL,
+    code VARCHAR(50),
+    name VARCHAR(100) NOT NULL,
+    kind VARCHAR(20) NOT NULL,
+    product_id VARCHAR(10) REFERENCES products(product_id) ON DELETE SET NULL,
+    amount NUMERIC(19, 4) NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_order_discounts_order ON order_discounts (order_id);
+
+-- One row per promotion used by an order, the per-user limit counts them
+CREATE TABLE IF NOT EXISTS promotion_redemptions (
+    promotion_id INT NOT NULL REFERENCES promotions(id) ON DELETE CASCADE,
+    order_id BIGINT NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    user_id VARCHAR(6) REFERENCES users(user_id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ DEFAULT now(),
+    PRIMARY KEY (promotion_id, order_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_promotion_redemptions_user ON promotion_redemptions (promotion_id, user_id);
@@ -0,0 +1,8 @@
+-- Unsold allocations of open sales go back to their products
+UPDATE products p SET stock = p.stock + fs.allocation - fs.sold, quantity = p.quantity + fs.sold
+FROM flash_sales fs
+WHERE fs.product_id = p.product_id AND fs.closed_at IS NULL;
+
+DROP TABLE IF EXISTS flash_sale_purchases;
+DROP TABLE IF EXISTS flash_sale_customers;
+DROP TABLE IF EXISTS flash_sales;
@@ -0,0 +1,44 @@
+-- A flash sale holds its own stock: the allocation leaves the product when
+-- the sale is created and the unsold rest returns when it closes, so
+-- regular purchases never eat into it and sale purchases never touch the
+-- product row. The sale price is in the base currency.
+CREATE TABLE IF NOT EXISTS flash_sales (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    product_id VARCHAR(10) NOT NULL REFERENCES products(product_id) ON DELETE CASCADE,
+    sale_price NUMERIC(19, 4) NOT NULL CHECK (sale_price > 0),
+    currency CHAR(3) NOT NULL DEFAULT 'THB',
+    allocation INT NOT NULL CHECK (allocation > 0),
+    sold INT NOT NULL DEFAULT 0,
+    per_customer_limit INT NOT NULL CHECK (per_customer_limit > 0),
+    starts_at TIMESTAMPTZ NOT NULL,
+    ends_at TIMESTAMPTZ NOT NULL,
+    closed_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ DEFAULT now(),
+    CHECK (ends_at > starts_at),
+    CHECK (sold >= 0 AND sold <= allocation)
+);
+
+CREATE INDEX IF NOT EXISTS idx_flash_sales_open ON flash_sales (ends_at) WHERE closed_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_flash_sales_product ON flash_sales (product_id, starts_at);
+
+-- Units bought per customer, the per-customer limit is checked against it
+-- in the purchase transaction
+CREATE TABLE IF NOT EXISTS flash_sale_customers (
+    flash_sale_id INT NOT NULL REFERENCES flash_sales(id) ON DELETE CASCADE,
+    user_id VARCHAR(6) NOT NULL REFERENCES users(user_id) ON DELETE CASCADE,
+    quantity INT NOT NULL CHECK (quantity > 0),
+    PRIMARY KEY (flash_sale_id, user_id)
+);
+
+CREATE TABLE IF NOT EXISTS flash_sale_purchases (
+    id BIGSERIAL PRIMARY KEY,
+    flash_sale_id INT NOT NULL REFERENCES flash_sales(id) ON DELETE CASCADE,
+    user_id VARCHAR(6) REFERENCES users(user_id) ON DELETE SET NULL,
+    quantity INT NOT NULL CHECK (quantity > 0),
+    unit_price NUMERIC(19, 4) NOT NULL,
+    currency CHAR(3) NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_flash_sale_purchases_sale ON flash_sale_purchases (flash_sale_id, id DESC);
@@ -0,0 +1,59 @@
+-- Products without a tax class are taxed at the standard class
+CREATE TABLE IF NOT EXISTS tax_classes (
+    id SERIAL PRIMARY KEY,
+    code VARCHAR(30) NOT NULL UNIQUE,
+    name VARCHAR(100) NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT now()
+);
+
+INSERT INTO tax_classes (code, name) VALUES
+    ('standard', 'Standard rate'),
+    ('reduced', 'Reduced rate'),
+    ('exempt', 'Tax exempt')
+ON CONFLICT (code) DO NOTHING;
+
+ALTER TABLE products ADD COLUMN IF NOT EXISTS tax_class_id INT REFERENCES tax_classes(id) ON DELETE SET NULL;
+
+-- A zone covers a country, or one region of it when region is set. The
+-- region zone wins over the country one. With prices_include_tax the shown
+-- prices already contain the tax, otherwise it is added on top.
+CREATE TABLE IF NOT EXISTS tax_zones (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    country CHAR(2) NOT NULL,
+    region VARCHAR(50) NOT NULL DEFAULT '',
+    prices_include_tax BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMPTZ DEFAULT now(),
+    updated_at TIMESTAMPTZ,
+    UNIQUE (country, region)
+);
+
+-- The rate of a class in a zone as a percentage, a class without a rate is
+-- not taxed in the zone
+CREATE TABLE IF NOT EXISTS tax_rates (
+    zone_id INT NOT NULL REFERENCES tax_zones(id) ON DELETE CASCADE,
+    tax_class_id INT NOT NULL REFERENCES tax_classes(id) ON DELETE CASCADE,
+    name VARCHAR(50) NOT NULL,
+    rate NUMERIC(7, 4) NOT NULL CHECK (rate >= 0 AND rate <= 100),
+    PRIMARY KEY (zone_id, tax_class_id)
+);
+
+INSERT INTO tax_zones (name, country, prices_include_tax) VALUES ('Thailand', 'TH', TRUE)
+ON CONFLICT (country, region) DO NOTHING;
+
+INSERT INTO tax_rates (zone_id, tax_class_id, name, rate)
+SELECT z.id, c.id, 'VAT', CASE c.code WHEN 'exempt' THEN 0 ELSE 7 END
+FROM tax_zones z, tax_classes c
+WHERE z.country = 'TH' AND z.region = ''
+ON CONFLICT (zone_id, tax_class_id) DO NOTHING;
+
+-- The tax of the checkout is kept with the order for invoicing
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS tax_total NUMERIC(19, 4) NOT NULL DEFAULT 0;
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS prices_include_tax BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS tax_country CHAR(2);
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS tax_region VARCHAR(50) NOT NULL DEFAULT '';
+
+ALTER TABLE order_items ADD COLUMN IF NOT EXISTS tax_name VARCHAR(50);
+ALTER TABLE order_items ADD COLUMN IF NOT EXISTS tax_rate NUMERIC(7, 4);
+ALTER TABLE order_items ADD COLUMN IF NOT EXISTS taxable_amount NUMERIC(19, 4);
+ALTER TABLE order_items ADD COLUMN IF NOT EXISTS tax_amount NUMERIC(19, 4) NOT NULL DEFAULT 0;
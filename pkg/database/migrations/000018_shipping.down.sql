@@ -0,0 +1,21 @@
+ALTER TABLE orders DROP COLUMN IF EXISTS ship_postal_code;
+ALTER TABLE orders DROP COLUMN IF EXISTS ship_city;
+ALTER TABLE orders DROP COLUMN IF EXISTS ship_line2;
+ALTER TABLE orders DROP COLUMN IF EXISTS ship_line1;
+ALTER TABLE orders DROP COLUMN IF EXISTS ship_phone;
+ALTER TABLE orders DROP COLUMN IF EXISTS ship_name;
+ALTER TABLE orders DROP COLUMN IF EXISTS weight_grams;
+ALTER TABLE orders DROP COLUMN IF EXISTS shipping_total;
+ALTER TABLE orders DROP COLUMN IF EXISTS shipping_kind;
+ALTER TABLE orders DROP COLUMN IF EXISTS shipping_method;
+ALTER TABLE orders DROP COLUMN IF EXISTS shipping_method_id;
+
+DROP TABLE IF EXISTS shipping_weight_rates;
+DROP TABLE IF EXISTS shipping_methods;
+DROP TABLE IF EXISTS shipping_zone_locations;
+DROP TABLE IF EXISTS shipping_zones;
+
+ALTER TABLE products DROP COLUMN IF EXISTS height_mm;
+ALTER TABLE products DROP COLUMN IF EXISTS width_mm;
+ALTER TABLE products DROP COLUMN IF EXISTS length_mm;
+ALTER TABLE products DROP COLUMN IF EXISTS weight_grams;
@@ -0,0 +1,73 @@
+-- Weight in grams and dimensions in millimetres of one packed unit
+ALTER TABLE products ADD COLUMN IF NOT EXISTS weight_grams INT NOT NULL DEFAULT 0 CHECK (weight_grams >= 0);
+ALTER TABLE products ADD COLUMN IF NOT EXISTS length_mm INT NOT NULL DEFAULT 0 CHECK (length_mm >= 0);
+ALTER TABLE products ADD COLUMN IF NOT EXISTS width_mm INT NOT NULL DEFAULT 0 CHECK (width_mm >= 0);
+ALTER TABLE products ADD COLUMN IF NOT EXISTS height_mm INT NOT NULL DEFAULT 0 CHECK (height_mm >= 0);
+
+CREATE TABLE IF NOT EXISTS shipping_zones (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT now(),
+    updated_at TIMESTAMPTZ
+);
+
+-- A location is a country, or one region of it when region is set, and
+-- belongs to one zone. The region location wins over the country one.
+CREATE TABLE IF NOT EXISTS shipping_zone_locations (
+    zone_id INT NOT NULL REFERENCES shipping_zones(id) ON DELETE CASCADE,
+    country CHAR(2) NOT NULL,
+    region VARCHAR(50) NOT NULL DEFAULT '',
+    PRIMARY KEY (country, region)
+);
+
+CREATE INDEX IF NOT EXISTS idx_shipping_zone_locations_zone ON shipping_zone_locations(zone_id);
+
+-- Amounts are in the base currency. flat_rate and local_pickup charge rate,
+-- weight_table the first bracket holding the cart weight and free_over is
+-- free once the discounted subtotal reaches threshold.
+CREATE TABLE IF NOT EXISTS shipping_methods (
+    id SERIAL PRIMARY KEY,
+    zone_id INT NOT NULL REFERENCES shipping_zones(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    kind VARCHAR(20) NOT NULL CHECK (kind IN ('flat_rate', 'weight_table', 'free_over', 'local_pickup')),
+    rate NUMERIC(19, 4) NOT NULL DEFAULT 0 CHECK (rate >= 0),
+    threshold NUMERIC(19, 4) CHECK (threshold > 0),
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    position INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ DEFAULT now(),
+    updated_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_shipping_methods_zone ON shipping_methods(zone_id, position);
+
+-- A bracket charges rate for carts up to up_to_grams
+CREATE TABLE IF NOT EXISTS shipping_weight_rates (
+    method_id INT NOT NULL REFERENCES shipping_methods(id) ON DELETE CASCADE,
+    up_to_grams INT NOT NULL CHECK (up_to_grams > 0),
+    rate NUMERIC(19, 4) NOT NULL CHECK (rate >= 0),
+    PRIMARY KEY (method_id, up_to_grams)
+);
+
+WITH zone AS (
+    INSERT INTO shipping_zones (name) VALUES ('Thailand') RETURNING id
+), location AS (
+    INSERT INTO shipping_zone_locations (zone_id, country) SELECT id, 'TH' FROM zone
+    ON CONFLICT DO NOTHING
+)
+INSERT INTO shipping_methods (zone_id, name, kind, rate, position)
+SELECT id, 'Standard delivery', 'flat_rate', 50, 0 FROM zone
+UNION ALL
+SELECT id, 'Store pickup', 'local_pickup', 0, 1 FROM zone;
+
+-- The method and its cost are kept as chosen at checkout
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS shipping_method_id INT REFERENCES shipping_methods(id) ON DELETE SET NULL;
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS shipping_method VARCHAR(100);
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS shipping_kind VARCHAR(20);
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS shipping_total NUMERIC(19, 4) NOT NULL DEFAULT 0;
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS weight_grams INT NOT NULL DEFAULT 0;
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS ship_name VARCHAR(100) NOT NULL DEFAULT '';
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS ship_phone VARCHAR(30) NOT NULL DEFAULT '';
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS ship_line1 VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS ship_line2 VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS ship_city VARCHAR(100) NOT NULL DEFAULT '';
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS ship_postal_code VARCHAR(20) NOT NULL DEFAULT '';
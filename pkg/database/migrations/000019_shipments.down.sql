@@ -0,0 +1,13 @@
+DROP TABLE IF EXISTS tracking_events;
+DROP TABLE IF EXISTS shipment_items;
+DROP TABLE IF EXISTS shipments;
+
+-- Enum values cannot be dropped, the type is rebuilt without delivered
+UPDATE orders SET status = 'shipped' WHERE status = 'delivered';
+
+ALTER TABLE orders ALTER COLUMN status DROP DEFAULT;
+ALTER TYPE order_status RENAME TO order_status_old;
+CREATE TYPE order_status AS ENUM ('pending', 'paid', 'shipped', 'completed', 'canceled');
+ALTER TABLE orders ALTER COLUMN status TYPE order_status USING status::TEXT::order_status;
+ALTER TABLE orders ALTER COLUMN status SET DEFAULT 'pending';
+DROP TYPE order_status_old;
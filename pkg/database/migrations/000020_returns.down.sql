@@ -0,0 +1,20 @@
+DROP TABLE IF EXISTS refunds;
+DROP TABLE IF EXISTS return_photos;
+DROP TABLE IF EXISTS return_items;
+DROP TABLE IF EXISTS returns;
+
+DROP TRIGGER IF EXISTS order_status_history ON orders;
+DROP FUNCTION IF EXISTS order_status_history_trigger();
+DROP TABLE IF EXISTS order_history;
+
+ALTER TABLE orders DROP COLUMN IF EXISTS refunded_total;
+
+-- Enum values cannot be dropped, the type is rebuilt without the refund statuses
+UPDATE orders SET status = 'delivered' WHERE status IN ('partially_refunded', 'refunded');
+
+ALTER TABLE orders ALTER COLUMN status DROP DEFAULT;
+ALTER TYPE order_status RENAME TO order_status_old;
+CREATE TYPE order_status AS ENUM ('pending', 'paid', 'shipped', 'delivered', 'completed', 'canceled');
+ALTER TABLE orders ALTER COLUMN status TYPE order_status USING status::TEXT::order_status;
+ALTER TABLE orders ALTER COLUMN status SET DEFAULT 'pending';
+DROP TYPE order_status_old;
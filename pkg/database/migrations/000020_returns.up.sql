@@ -0,0 +1,91 @@
+ALTER TYPE order_status ADD VALUE IF NOT EXISTS 'partially_refunded' AFTER 'completed';
+ALTER TYPE order_status ADD VALUE IF NOT EXISTS 'refunded' AFTER 'partially_refunded';
+
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS refunded_total NUMERIC(12,2) NOT NULL DEFAULT 0;
+
+-- What happened to an order, status changes are written by the trigger
+-- below, returns and refunds add their own events
+CREATE TABLE IF NOT EXISTS order_history (
+    id BIGSERIAL PRIMARY KEY,
+    order_id BIGINT NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    event VARCHAR(30) NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    note TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_order_history_order ON order_history(order_id, id);
+
+CREATE OR REPLACE FUNCTION order_status_history_trigger() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP = 'INSERT' OR NEW.status IS DISTINCT FROM OLD.status THEN
+        INSERT INTO order_history (order_id, event, status) VALUES (NEW.id, 'status_changed', NEW.status::TEXT);
+    END IF;
+    RETURN NULL;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER order_status_history
+AFTER INSERT OR UPDATE OF status ON orders
+FOR EACH ROW EXECUTE FUNCTION order_status_history_trigger();
+
+INSERT INTO order_history (order_id, event, status, created_at)
+SELECT id, 'status_changed', status::TEXT, created_at FROM orders;
+
+-- A return moves from requested to approved or rejected, an approved
+-- return is received and inspected, then refunded or rejected
+CREATE TABLE IF NOT EXISTS returns (
+    id BIGSERIAL PRIMARY KEY,
+    order_id BIGINT NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    status VARCHAR(20) NOT NULL DEFAULT 'requested'
+        CHECK (status IN ('requested', 'approved', 'rejected', 'received', 'refunded')),
+    reason VARCHAR(30) NOT NULL,
+    comment TEXT NOT NULL DEFAULT '',
+    admin_note TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ DEFAULT now(),
+    updated_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_returns_order ON returns(order_id);
+CREATE INDEX IF NOT EXISTS idx_returns_status ON returns(status, id);
+
+-- accepted_quantity is set by the inspection, restocked_quantity is what
+-- went back into stock
+CREATE TABLE IF NOT EXISTS return_items (
+    return_id BIGINT NOT NULL REFERENCES returns(id) ON DELETE CASCADE,
+    order_item_id BIGINT NOT NULL REFERENCES order_items(id) ON DELETE CASCADE,
+    quantity INT NOT NULL CHECK (quantity > 0),
+    accepted_quantity INT CHECK (accepted_quantity BETWEEN 0 AND quantity),
+    restocked_quantity INT NOT NULL DEFAULT 0 CHECK (restocked_quantity BETWEEN 0 AND quantity),
+    PRIMARY KEY (return_id, order_item_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_return_items_order_item ON return_items(order_item_id);
+
+CREATE TABLE IF NOT EXISTS return_photos (
+    id BIGSERIAL PRIMARY KEY,
+    return_id BIGINT NOT NULL REFERENCES returns(id) ON DELETE CASCADE,
+    key VARCHAR(255) NOT NULL,
+    content_type VARCHAR(50) NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_return_photos_return ON return_photos(return_id);
+
+-- A refund is pending while the payment provider handles it, pending and
+-- succeeded refunds count against the order total
+CREATE TABLE IF NOT EXISTS refunds (
+    id BIGSERIAL PRIMARY KEY,
+    order_id BIGINT NOT NULL REFERENCES orders(id) ON DELETE CASCADE,
+    return_id BIGINT REFERENCES returns(id) ON DELETE SET NULL,
+    amount NUMERIC(12,2) NOT NULL CHECK (amount > 0),
+    currency CHAR(3) NOT NULL,
+    provider VARCHAR(30) NOT NULL,
+    provider_ref VARCHAR(100),
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'succeeded', 'failed')),
+    note TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ DEFAULT now(),
+    updated_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS idx_refunds_order ON refunds(order_id);
@@ -0,0 +1,54 @@
+-- The last number handed out per document kind and fiscal year, the row is
+-- locked while a document is issued so numbers have no gaps
+CREATE TABLE IF NOT EXISTS document_sequences (
+    kind VARCHAR(20) NOT NULL,
+    fiscal_year INT NOT NULL,
+    last_number INT NOT NULL DEFAULT 0,
+    PRIMARY KEY (kind, fiscal_year)
+);
+
+-- Tax invoices and the credit notes against them. Seller and buyer are
+-- copied at issue so a document never changes once numbered.
+CREATE TABLE IF NOT EXISTS invoices (
+    id BIGSERIAL PRIMARY KEY,
+    kind VARCHAR(20) NOT NULL CHECK (kind IN ('invoice', 'credit_note')),
+    number VARCHAR(30) NOT NULL UNIQUE,
+    fiscal_year INT NOT NULL,
+    sequence INT NOT NULL,
+    order_id BIGINT NOT NULL REFERENCES orders(id) ON DELETE RESTRICT,
+    invoice_id BIGINT REFERENCES invoices(id) ON DELETE RESTRICT,
+    refund_id BIGINT UNIQUE REFERENCES refunds(id) ON DELETE SET NULL,
+    currency CHAR(3) NOT NULL,
+    seller_name VARCHAR(255) NOT NULL,
+    seller_tax_id VARCHAR(20) NOT NULL DEFAULT '',
+    seller_branch VARCHAR(50) NOT NULL DEFAULT '',
+    seller_address TEXT NOT NULL DEFAULT '',
+    buyer_name VARCHAR(255) NOT NULL,
+    buyer_tax_id VARCHAR(20) NOT NULL DEFAULT '',
+    buyer_branch VARCHAR(50) NOT NULL DEFAULT '',
+    buyer_address TEXT NOT NULL DEFAULT '',
+    reason TEXT NOT NULL DEFAULT '',
+    subtotal NUMERIC(12,2) NOT NULL,
+    tax_total NUMERIC(12,2) NOT NULL,
+    total NUMERIC(12,2) NOT NULL,
+    pdf_key VARCHAR(255),
+    issued_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    UNIQUE (kind, fiscal_year, sequence),
+    CHECK ((kind = 'credit_note') = (invoice_id IS NOT NULL))
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_invoices_one_per_order ON invoices(order_id) WHERE kind = 'invoice';
+CREATE INDEX IF NOT EXISTS idx_invoices_invoice ON invoices(invoice_id);
+
+CREATE TABLE IF NOT EXISTS invoice_lines (
+    invoice_id BIGINT NOT NULL REFERENCES invoices(id) ON DELETE CASCADE,
+    position INT NOT NULL,
+    description VARCHAR(255) NOT NULL,
+    quantity INT NOT NULL,
+    unit_price NUMERIC(12,2) NOT NULL,
+    amount NUMERIC(12,2) NOT NULL,
+    tax_name VARCHAR(100) NOT NULL DEFAULT '',
+    tax_rate NUMERIC(7,4),
+    tax_amount NUMERIC(12,2) NOT NULL DEFAULT 0,
+    PRIMARY KEY (invoice_id, position)
+);
@@ -0,0 +1,9 @@
+ALTER TABLE orders DROP COLUMN IF EXISTS gateway_total;
+
+DROP TABLE IF EXISTS order_payments;
+DROP TABLE IF EXISTS store_credit_entries;
+DROP TABLE IF EXISTS store_credit_balances;
+DROP TABLE IF EXISTS gift_card_transactions;
+DROP TABLE IF EXISTS gift_cards;
+
+DROP FUNCTION IF EXISTS reject_ledger_change();
@@ -0,0 +1,88 @@
+-- Gift cards are redeemed at checkout until their balance runs out,
+-- codes are stored uppercase without separators
+CREATE TABLE IF NOT EXISTS gift_cards (
+    id BIGSERIAL PRIMARY KEY,
+    code VARCHAR(32) NOT NULL UNIQUE,
+    currency CHAR(3) NOT NULL,
+    initial_amount NUMERIC(12,2) NOT NULL CHECK (initial_amount > 0),
+    balance NUMERIC(12,2) NOT NULL CHECK (balance >= 0),
+    expires_at TIMESTAMPTZ,
+    note TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ DEFAULT now(),
+    updated_at TIMESTAMPTZ
+);
+
+-- Every change of a gift card balance, amounts are signed and
+-- balance_after is the balance the change left
+CREATE TABLE IF NOT EXISTS gift_card_transactions (
+    id BIGSERIAL PRIMARY KEY,
+    gift_card_id BIGINT NOT NULL REFERENCES gift_cards(id) ON DELETE RESTRICT,
+    kind VARCHAR(20) NOT NULL CHECK (kind IN ('issue', 'redeem', 'adjust')),
+    amount NUMERIC(12,2) NOT NULL,
+    balance_after NUMERIC(12,2) NOT NULL CHECK (balance_after >= 0),
+    order_id BIGINT REFERENCES orders(id) ON DELETE RESTRICT,
+    note TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_gift_card_transactions_card ON gift_card_transactions(gift_card_id, id);
+
+-- The current store credit of a user per currency, the row is locked
+-- while an entry is added
+CREATE TABLE IF NOT EXISTS store_credit_balances (
+    user_id VARCHAR(6) NOT NULL REFERENCES users(user_id) ON DELETE RESTRICT,
+    currency CHAR(3) NOT NULL,
+    balance NUMERIC(12,2) NOT NULL DEFAULT 0 CHECK (balance >= 0),
+    updated_at TIMESTAMPTZ DEFAULT now(),
+    PRIMARY KEY (user_id, currency)
+);
+
+-- The store credit ledger, a balance is the sum of its entries
+CREATE TABLE IF NOT EXISTS store_credit_entries (
+    id BIGSERIAL PRIMARY KEY,
+    user_id VARCHAR(6) NOT NULL REFERENCES users(user_id) ON DELETE RESTRICT,
+    currency CHAR(3) NOT NULL,
+    kind VARCHAR(20) NOT NULL CHECK (kind IN ('adjustment', 'refund', 'redemption')),
+    amount NUMERIC(12,2) NOT NULL CHECK (amount <> 0),
+    balance_after NUMERIC(12,2) NOT NULL CHECK (balance_after >= 0),
+    order_id BIGINT REFERENCES orders(id) ON DELETE RESTRICT,
+    refund_id BIGINT UNIQUE REFERENCES refunds(id) ON DELETE RESTRICT,
+    note TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS idx_store_credit_entries_user ON store_credit_entries(user_id, id);
+
+-- Ledger entries are never changed or removed, corrections are new entries
+CREATE OR REPLACE FUNCTION reject_ledger_change() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION '% entries are immutable', TG_TABLE_NAME;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER gift_card_transactions_immutable
+BEFORE UPDATE OR DELETE ON gift_card_transactions
+FOR EACH ROW EXECUTE FUNCTION reject_ledger_change();
+
+CREATE TRIGGER store_credit_entries_immutable
+BEFORE UPDATE OR DELETE ON store_credit_entries
+FOR EACH ROW EXECUTE FUNCTION reject_ledger_change();
+
+-- What paid an order besides the payment gateway
+CREATE TABLE IF NOT EXISTS order_payments (
+    id BIGSERIAL PRIMARY KEY,
+    order_id BIGINT NOT NULL REFERENCES orders(id) ON DELETE RESTRICT,
+    method VARCHAR(20) NOT NULL CHECK (method IN ('gift_card', 'store_credit')),
+    gift_card_id BIGINT REFERENCES gift_cards(id) ON DELETE RESTRICT,
+    amount NUMERIC(12,2) NOT NULL CHECK (amount > 0),
+    created_at TIMESTAMPTZ DEFAULT now(),
+    CHECK ((method = 'gift_card') = (gift_card_id IS NOT NULL))
+);
+
+CREATE INDEX IF NOT EXISTS idx_order_payments_order ON order_payments(order_id);
+
+-- The part of the total left to the payment gateway, orders placed before
+-- had no other way to pay
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS gateway_total NUMERIC(12,2);
+UPDATE orders SET gateway_total = total WHERE gateway_total IS NULL;
+ALTER TABLE orders ALTER COLUMN gateway_total SET NOT NULL;
@@ -0,0 +1,257 @@
+package media
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/draw"
+	_ "image/gif"
+	"image/jpeg"
+	"image/png"
+	"strconv"
+	"strings"
+)
+
+const jpegQuality = 85
+
+// Largest image decoded, about 160 MB as RGBA
+const maxPixels = 40_000_000
+
+// Rendition is a named output width, e.g. "thumbnail" at 160px.
+type Rendition struct {
+	Name  string
+	Width int
+}
+
+// ParseRenditions parses "thumbnail:160,card:480,zoom:1400".
+func ParseRenditions(spec string) ([]Rendition, error) {
+	var renditions []Rendition
+
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		name, width, ok := strings.Cut(part, ":")
+		w, err := strconv.Atoi(width)
+		if !ok || name == "" || err != nil || w <= 0 {
+			return nil, fmt.Errorf("invalid rendition %q", part)
+		}
+		renditions = append(renditions, Rendition{Name: name, Width: w})
+	}
+
+	return renditions, nil
+}
+
+// Encoded is an image ready to be stored.
+type Encoded struct {
+	Data        []byte
+	ContentType string
+	Ext         string
+	Width       int
+	Height      int
+}
+
+// StripMetadata removes EXIF and other metadata from an upload without
+// re-encoding it. JPEGs keep only their EXIF orientation, see Upright.
+func StripMetadata(contentType string, data []byte) ([]byte, error) {
+	return stripMetadata(contentType, data)
+}
+
+// Upright re-encodes a JPEG that relies on its EXIF orientation so the pixels
+// themselves are upright. Stored blobs never change, so this happens before
+// an upload is stored. Other images are returned as they are.
+func Upright(contentType string, data []byte) ([]byte, error) {
+	if contentType != "image/jpeg" || jpegOrientation(data) == 1 {
+		return data, nil
+	}
+
+	img, err := Decode(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: 92}); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// CheckBounds reads the dimensions from the image header and rejects images
+// too large to decode. WebP is never decoded and is not checked.
+func CheckBounds(contentType string, data []byte) error {
+	if contentType == "image/webp" {
+		return nil
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrUnsupportedType, err)
+	}
+
+	if cfg.Width <= 0 || cfg.Height <= 0 || int64(cfg.Width)*int64(cfg.Height) > maxPixels {
+		return ErrTooLarge
+	}
+
+	return nil
+}
+
+// Decode decodes a JPEG, PNG or GIF (first frame) and applies the EXIF
+// orientation so the result is upright. The bounds are checked before any
+// pixels are allocated.
+func Decode(data []byte) (image.Image, error) {
+	if err := CheckBounds("", data); err != nil {
+		return nil, err
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	return orient(toRGBA(img), jpegOrientation(data)), nil
+}
+
+// Render scales img down to the rendition width keeping the aspect ratio.
+// Images are never enlarged. Opaque images become JPEG, the rest PNG.
+func Render(img image.Image, width int) (*Encoded, error) {
+	src := toRGBA(img)
+	if width < src.Bounds().Dx() {
+		src = resize(src, width)
+	}
+
+	out := &Encoded{
+		Width:  src.Bounds().Dx(),
+		Height: src.Bounds().Dy(),
+	}
+
+	var buf bytes.Buffer
+	if src.Opaque() {
+		if err := jpeg.Encode(&buf, src, &jpeg.Options{Quality: jpegQuality}); err != nil {
+			return nil, err
+		}
+		out.ContentType, out.Ext = "image/jpeg", ".jpg"
+	} else {
+		enc := png.Encoder{CompressionLevel: png.BestCompression}
+		if err := enc.Encode(&buf, src); err != nil {
+			return nil, err
+		}
+		out.ContentType, out.Ext = "image/png", ".png"
+	}
+	out.Data = buf.Bytes()
+
+	return out, nil
+}
+
+func toRGBA(img image.Image) *image.RGBA {
+	if rgba, ok := img.(*image.RGBA); ok && rgba.Bounds().Min == (image.Point{}) {
+		return rgba
+	}
+
+	b := img.Bounds()
+	rgba := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(rgba, rgba.Bounds(), img, b.Min, draw.Src)
+
+	return rgba
+}
+
+// resize is an area-averaging downscale, done as two separable passes.
+func resize(src *image.RGBA, width int) *image.RGBA {
+	sw, sh := src.Bounds().Dx(), src.Bounds().Dy()
+	height := sh * width / sw
+	if height < 1 {
+		height = 1
+	}
+
+	tmp := image.NewRGBA(image.Rect(0, 0, width, sh))
+	for y := 0; y < sh; y++ {
+		for x := 0; x < width; x++ {
+			x0, x1 := span(x, width, sw)
+			var sum [4]int
+			for sx := x0; sx < x1; sx++ {
+				addPixel(&sum, src.Pix[src.PixOffset(sx, y):])
+			}
+			setPixel(tmp.Pix[tmp.PixOffset(x, y):], sum, x1-x0)
+		}
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	for x := 0; x < width; x++ {
+		for y := 0; y < height; y++ {
+			y0, y1 := span(y, height, sh)
+			var sum [4]int
+			for sy := y0; sy < y1; sy++ {
+				addPixel(&sum, tmp.Pix[tmp.PixOffset(x, sy):])
+			}
+			setPixel(dst.Pix[dst.PixOffset(x, y):], sum, y1-y0)
+		}
+	}
+
+	return dst
+}
+
+// span returns the source range covered by destination index i.
+func span(i, dst, src int) (int, int) {
+	start := i * src / dst
+	end := (i + 1) * src / dst
+	if end <= start {
+		end = start + 1
+	}
+	return start, end
+}
+
+func addPixel(sum *[4]int, p []uint8) {
+	sum[0] += int(p[0])
+	sum[1] += int(p[1])
+	sum[2] += int(p[2])
+	sum[3] += int(p[3])
+}
+
+func setPixel(p []uint8, sum [4]int, n int) {
+	p[0] = uint8(sum[0] / n)
+	p[1] = uint8(sum[1] / n)
+	p[2] = uint8(sum[2] / n)
+	p[3] = uint8(sum[3] / n)
+}
+
+// orient applies an EXIF orientation value (1-8) to img.
+func orient(img *image.RGBA, orientation int) *image.RGBA {
+	if orientation <= 1 || orientation > 8 {
+		return img
+	}
+
+	w, h := img.Bounds().Dx(), img.Bounds().Dy()
+	dw, dh := w, h
+	if orientation >= 5 {
+		dw, dh = h, w
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			var dx, dy int
+			switch orientation {
+			case 2: // mirror horizontal
+				dx, dy = w-1-x, y
+			case 3: // rotate 180
+				dx, dy = w-1-x, h-1-y
+			case 4: // mirror vertical
+				dx, dy = x, h-1-y
+			case 5: // transpose
+				dx, dy = y, x
+			case 6: // rotate 90 clockwise
+				dx, dy = h-1-y, x
+			case 7: // transverse
+				dx, dy = h-1-y, w-1-x
+			case 8: // rotate 90 counter clockwise
+				dx, dy = y, w-1-x
+			}
+			copy(dst.Pix[dst.PixOffset(dx, dy):dst.PixOffset(dx, dy)+4], img.Pix[img.PixOffset(x, y):])
+		}
+	}
+
+	return dst
+}
@@ -0,0 +1,95 @@
+package media
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"path"
+	"strings"
+	"testing"
+)
+
+func TestLocalStore(t *testing.T) {
+	ctx := context.Background()
+	store, err := NewLocalStore(t.TempDir(), "http://localhost/media/")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	key := "products/P00001/a.jpg"
+	if err := store.Put(ctx, key, strings.NewReader("image")); err != nil {
+		t.Fatal(err)
+	}
+
+	blob, err := store.Get(ctx, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := io.ReadAll(blob)
+	blob.Close()
+	if string(data) != "image" {
+		t.Errorf("Get = %q, want %q", data, "image")
+	}
+
+	if got := store.URL(key); got != "http://localhost/media/products/P00001/a.jpg" {
+		t.Errorf("URL = %s", got)
+	}
+
+	if err := store.Delete(ctx, key); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Delete(ctx, key); err != nil {
+		t.Errorf("Delete of a missing blob = %v", err)
+	}
+	if _, err := store.Get(ctx, key); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get after Delete = %v, want ErrNotFound", err)
+	}
+}
+
+func TestLocalStoreRejectsKeysOutsideRoot(t *testing.T) {
+	store, err := NewLocalStore(t.TempDir(), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, key := range []string{"", "../secret", "a/../../b", "/abs", "a//b", "a/./b"} {
+		if err := store.Put(context.Background(), key, strings.NewReader("x")); !errors.Is(err, ErrInvalidKey) {
+			t.Errorf("Put(%q) = %v, want ErrInvalidKey", key, err)
+		}
+	}
+}
+
+func TestNewKey(t *testing.T) {
+	a, err := NewKey("avatars/U1", ".png")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := NewKey("avatars/U1", ".png")
+
+	if a == b || path.Dir(a) != "avatars/U1" || path.Ext(a) != ".png" {
+		t.Errorf("NewKey = %s and %s", a, b)
+	}
+	if ContentType(a) != "image/png" || ContentType("x.bin") != "application/octet-stream" {
+		t.Errorf("ContentType(%s) = %s", a, ContentType(a))
+	}
+}
+
+func TestSniffImage(t *testing.T) {
+	png := append([]byte("\x89PNG\r\n\x1a\n"), make([]byte, 600)...)
+
+	contentType, ext, body, err := SniffImage(bytes.NewReader(png))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if contentType != "image/png" || ext != ".png" {
+		t.Errorf("SniffImage = %s %s", contentType, ext)
+	}
+	if data, _ := io.ReadAll(body); !bytes.Equal(data, png) {
+		t.Errorf("body lost %d bytes", len(png)-len(data))
+	}
+
+	if _, _, _, err := SniffImage(strings.NewReader("<svg></svg>")); !errors.Is(err, ErrUnsupportedType) {
+		t.Errorf("SniffImage(svg) = %v, want ErrUnsupportedType", err)
+	}
+}
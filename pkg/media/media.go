@@ -0,0 +1,75 @@
+package media
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"io"
+	"net/http"
+	"path"
+)
+
+var (
+	ErrNotFound        = errors.New("media not found")
+	ErrInvalidKey      = errors.New("invalid media key")
+	ErrUnsupportedType = errors.New("unsupported image type")
+	ErrTooLarge        = errors.New("image dimensions are too large")
+)
+
+// BlobStore keeps uploaded files under a key and exposes them through a URL
+// that stays the same for as long as the blob exists.
+type BlobStore interface {
+	Put(ctx context.Context, key string, r io.Reader) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+	URL(key string) string
+}
+
+var imageTypes = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+	"image/webp": ".webp",
+}
+
+// SniffImage detects the content type from the first bytes of r instead of
+// trusting the client supplied header. The returned reader still yields the
+// whole stream.
+func SniffImage(r io.Reader) (contentType, ext string, body io.Reader, err error) {
+	head := make([]byte, 512)
+	n, err := io.ReadFull(r, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return "", "", nil, err
+	}
+	head = head[:n]
+
+	contentType = http.DetectContentType(head)
+	ext, ok := imageTypes[contentType]
+	if !ok {
+		return "", "", nil, ErrUnsupportedType
+	}
+
+	return contentType, ext, io.MultiReader(bytes.NewReader(head), r), nil
+}
+
+// NewKey returns a random key under dir, e.g. "products/P00001/3f9a...c1.jpg".
+func NewKey(dir, ext string) (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return path.Join(dir, hex.EncodeToString(b)+ext), nil
+}
+
+// ContentType returns the image content type for a key based on its extension.
+func ContentType(key string) string {
+	ext := path.Ext(key)
+	for ct, e := range imageTypes {
+		if e == ext {
+			return ct
+		}
+	}
+	return "application/octet-stream"
+}
@@ -0,0 +1,277 @@
+package media
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"testing"
+)
+
+func testImage(w, h int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, color.RGBA{uint8(x * 40), uint8(y * 40), 0, 255})
+		}
+	}
+	return img
+}
+
+func encodeJPEG(t *testing.T, w, h int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, testImage(w, h), nil); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// withSegments inserts JPEG segments right after the start of image marker
+func withSegments(data []byte, segments ...[]byte) []byte {
+	out := append([]byte{}, data[:2]...)
+	for _, s := range segments {
+		out = append(out, s...)
+	}
+	return append(out, data[2:]...)
+}
+
+func segment(marker byte, payload string) []byte {
+	s := []byte{0xFF, marker, 0, 0}
+	binary.BigEndian.PutUint16(s[2:], uint16(len(payload)+2))
+	return append(s, payload...)
+}
+
+func TestStripJPEG(t *testing.T) {
+	data := withSegments(encodeJPEG(t, 4, 2),
+		orientationSegment(6),
+		segment(0xE1, "http://ns.adobe.com/xap/1.0/\x00<gps/>"),
+		segment(0xFE, "shot on a phone at home"),
+	)
+
+	stripped, err := StripMetadata("image/jpeg", data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if bytes.Contains(stripped, []byte("<gps/>")) || bytes.Contains(stripped, []byte("shot on a phone")) {
+		t.Error("metadata left in the JPEG")
+	}
+	if got := jpegOrientation(stripped); got != 6 {
+		t.Errorf("orientation = %d, want 6", got)
+	}
+
+	img, err := Decode(stripped)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b := img.Bounds(); b.Dx() != 2 || b.Dy() != 4 {
+		t.Errorf("decoded %dx%d, want the 4x2 image rotated to 2x4", b.Dx(), b.Dy())
+	}
+}
+
+func pngChunk(kind, payload string) []byte {
+	c := make([]byte, 8, 12+len(payload))
+	binary.BigEndian.PutUint32(c, uint32(len(payload)))
+	copy(c[4:], kind)
+	c = append(c, payload...)
+	return binary.BigEndian.AppendUint32(c, crc32.ChecksumIEEE(c[4:]))
+}
+
+func TestStripPNG(t *testing.T) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, testImage(3, 3)); err != nil {
+		t.Fatal(err)
+	}
+	clean := buf.Bytes()
+
+	// Text chunks go right after the header chunk
+	ihdr := len(pngSignature) + 25
+	data := append([]byte{}, clean[:ihdr]...)
+	data = append(data, pngChunk("tEXt", "Author\x00someone")...)
+	data = append(data, pngChunk("eXIf", "MM\x00\x2a")...)
+	data = append(data, clean[ihdr:]...)
+
+	stripped, err := StripMetadata("image/png", data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(stripped, clean) {
+		t.Errorf("stripped PNG has %d bytes, want the %d of the clean one", len(stripped), len(clean))
+	}
+}
+
+func TestStripGIF(t *testing.T) {
+	var buf bytes.Buffer
+	img := image.NewPaletted(image.Rect(0, 0, 2, 2), color.Palette{color.Black, color.White})
+	if err := gif.Encode(&buf, img, nil); err != nil {
+		t.Fatal(err)
+	}
+	clean := buf.Bytes()
+
+	header := 13
+	if clean[10]&0x80 != 0 {
+		header += 3 << (clean[10]&0x07 + 1)
+	}
+
+	loop := []byte("\x21\xFF\x0BNETSCAPE2.0\x03\x01\x00\x00\x00")
+	data := append([]byte{}, clean[:header]...)
+	data = append(data, "\x21\xFE\x05hello\x00"...)               // comment
+	data = append(data, "\x21\xFF\x0BXMP DataXMP\x04<x/>\x00"...) // XMP
+	data = append(data, loop...)
+	data = append(data, clean[header:]...)
+
+	stripped, err := StripMetadata("image/gif", data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := append(append(append([]byte{}, clean[:header]...), loop...), clean[header:]...)
+	if !bytes.Equal(stripped, want) {
+		t.Errorf("stripped GIF = %q", stripped)
+	}
+	if _, err := gif.Decode(bytes.NewReader(stripped)); err != nil {
+		t.Errorf("stripped GIF does not decode: %v", err)
+	}
+}
+
+func riffChunk(kind string, payload []byte) []byte {
+	c := []byte(kind)
+	c = binary.LittleEndian.AppendUint32(c, uint32(len(payload)))
+	c = append(c, payload...)
+	if len(payload)%2 == 1 {
+		c = append(c, 0)
+	}
+	return c
+}
+
+func webp(chunks ...[]byte) []byte {
+	data := []byte("RIFF\x00\x00\x00\x00WEBP")
+	for _, c := range chunks {
+		data = append(data, c...)
+	}
+	binary.LittleEndian.PutUint32(data[4:], uint32(len(data)-8))
+	return data
+}
+
+func TestStripWebP(t *testing.T) {
+	vp8x := make([]byte, 10)
+	vp8x[0] = 0x10 | 0x08 | 0x04 // alpha, EXIF, XMP
+	frame := riffChunk("VP8L", []byte{0x2f, 1, 2})
+
+	data := webp(riffChunk("VP8X", vp8x), frame, riffChunk("EXIF", []byte("MM\x00\x2a")), riffChunk("XMP ", []byte("<x/>")))
+
+	stripped, err := StripMetadata("image/webp", data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	vp8x[0] = 0x10
+	if want := webp(riffChunk("VP8X", vp8x), frame); !bytes.Equal(stripped, want) {
+		t.Errorf("stripped WebP = %q, want %q", stripped, want)
+	}
+}
+
+func TestStripMalformed(t *testing.T) {
+	tests := map[string][]byte{
+		"image/jpeg": {0xFF, 0xD8, 0xFF, 0xE1, 0xFF, 0xFF},
+		"image/png":  append(append([]byte{}, pngSignature...), 0, 0, 0, 99, 'I', 'D', 'A', 'T', 0, 0, 0, 0),
+		"image/gif":  []byte("GIF89a\x01\x00\x01\x00\x00\x00\x00\x21\xFE\x09ab"),
+		"image/webp": []byte("RIFF\x10\x00\x00\x00WEBPEXIF\xFF\x00\x00\x00"),
+	}
+
+	for contentType, data := range tests {
+		if _, err := StripMetadata(contentType, data); err == nil {
+			t.Errorf("%s: malformed data was accepted", contentType)
+		}
+	}
+
+	if _, err := StripMetadata("image/bmp", nil); !errors.Is(err, ErrUnsupportedType) {
+		t.Errorf("bmp = %v, want ErrUnsupportedType", err)
+	}
+}
+
+func TestCheckBounds(t *testing.T) {
+	if err := CheckBounds("image/jpeg", encodeJPEG(t, 8, 8)); err != nil {
+		t.Errorf("small JPEG = %v", err)
+	}
+
+	// A PNG header claiming 100000x100000 pixels, the pixels are never read
+	ihdr := make([]byte, 13)
+	binary.BigEndian.PutUint32(ihdr, 100000)
+	binary.BigEndian.PutUint32(ihdr[4:], 100000)
+	ihdr[8], ihdr[9] = 8, 6 // 8 bit RGBA
+	bomb := append(append([]byte{}, pngSignature...), pngChunk("IHDR", string(ihdr))...)
+
+	if err := CheckBounds("image/png", bomb); !errors.Is(err, ErrTooLarge) {
+		t.Errorf("PNG bomb = %v, want ErrTooLarge", err)
+	}
+	if _, err := Decode(bomb); !errors.Is(err, ErrTooLarge) {
+		t.Errorf("Decode of PNG bomb = %v, want ErrTooLarge", err)
+	}
+
+	if err := CheckBounds("image/png", []byte("not an image")); !errors.Is(err, ErrUnsupportedType) {
+		t.Errorf("garbage = %v, want ErrUnsupportedType", err)
+	}
+	if err := CheckBounds("image/webp", nil); err != nil {
+		t.Errorf("WebP = %v, it is not decoded", err)
+	}
+}
+
+func TestUpright(t *testing.T) {
+	data := withSegments(encodeJPEG(t, 4, 2), orientationSegment(6))
+
+	upright, err := Upright("image/jpeg", data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if jpegOrientation(upright) != 1 {
+		t.Error("upright JPEG still carries its orientation")
+	}
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(upright))
+	if err != nil || cfg.Width != 2 || cfg.Height != 4 {
+		t.Errorf("upright JPEG is %dx%d (%v), want 2x4", cfg.Width, cfg.Height, err)
+	}
+
+	plain := encodeJPEG(t, 4, 2)
+	if got, err := Upright("image/jpeg", plain); err != nil || !bytes.Equal(got, plain) {
+		t.Errorf("JPEG without orientation was re-encoded (%v)", err)
+	}
+}
+
+func TestRender(t *testing.T) {
+	enc, err := Render(testImage(10, 5), 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if enc.Width != 4 || enc.Height != 2 || enc.ContentType != "image/jpeg" {
+		t.Errorf("Render = %dx%d %s", enc.Width, enc.Height, enc.ContentType)
+	}
+
+	// Never enlarged
+	enc, err = Render(image.NewRGBA(image.Rect(0, 0, 3, 3)), 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if enc.Width != 3 || enc.ContentType != "image/png" {
+		t.Errorf("Render = %dpx %s, want the transparent 3px image as PNG", enc.Width, enc.ContentType)
+	}
+}
+
+func TestParseRenditions(t *testing.T) {
+	got, err := ParseRenditions("thumbnail:160, card:480,")
+	if err != nil || len(got) != 2 || got[1] != (Rendition{Name: "card", Width: 480}) {
+		t.Errorf("ParseRenditions = %v, %v", got, err)
+	}
+
+	for _, spec := range []string{"thumb", ":100", "card:0", "card:wide"} {
+		if _, err := ParseRenditions(spec); err == nil {
+			t.Errorf("ParseRenditions(%q) accepted", spec)
+		}
+	}
+}
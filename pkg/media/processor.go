@@ -0,0 +1,119 @@
+package media
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+)
+
+// Output is a rendition that has been generated and stored.
+type Output struct {
+	Name        string
+	Key         string
+	ContentType string
+	Width       int
+	Height      int
+}
+
+// Processor generates the configured renditions of stored images in the
+// background and hands the results to a callback for persisting.
+type Processor struct {
+	blobs      BlobStore
+	renditions []Rendition
+	worker     *Worker
+}
+
+func NewProcessor(blobs BlobStore, renditions []Rendition, workers, queueSize int) *Processor {
+	return &Processor{
+		blobs:      blobs,
+		renditions: renditions,
+		worker:     NewWorker(workers, queueSize),
+	}
+}
+
+func (p *Processor) Start() {
+	p.worker.Start()
+}
+
+func (p *Processor) Stop() {
+	p.worker.Stop()
+}
+
+// Enqueue schedules rendition generation for the blob at key. If done fails
+// the generated blobs are removed again.
+func (p *Processor) Enqueue(key string, done func(ctx context.Context, outputs []Output) error) error {
+	if len(p.renditions) == 0 {
+		return nil
+	}
+
+	return p.worker.Submit(func(ctx context.Context) error {
+		outputs, err := p.render(ctx, key)
+		if err != nil {
+			return err
+		}
+
+		if err := done(ctx, outputs); err != nil {
+			for _, out := range outputs {
+				p.blobs.Delete(ctx, out.Key)
+			}
+			return err
+		}
+
+		return nil
+	})
+}
+
+func (p *Processor) render(ctx context.Context, key string) ([]Output, error) {
+	blob, err := p.blobs.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	defer blob.Close()
+
+	data, err := io.ReadAll(blob)
+	if err != nil {
+		return nil, err
+	}
+
+	// WebP cannot be decoded with the standard library, it is served as is
+	src, err := Decode(data)
+	if err != nil {
+		return nil, fmt.Errorf("decode %s: %w", key, err)
+	}
+
+	base := strings.TrimSuffix(key, path.Ext(key))
+
+	var outputs []Output
+	cleanup := func() {
+		for _, out := range outputs {
+			p.blobs.Delete(ctx, out.Key)
+		}
+	}
+
+	for _, r := range p.renditions {
+		enc, err := Render(src, r.Width)
+		if err != nil {
+			cleanup()
+			return nil, err
+		}
+
+		out := Output{
+			Name:        r.Name,
+			Key:         base + "_" + r.Name + enc.Ext,
+			ContentType: enc.ContentType,
+			Width:       enc.Width,
+			Height:      enc.Height,
+		}
+
+		if err := p.blobs.Put(ctx, out.Key, bytes.NewReader(enc.Data)); err != nil {
+			cleanup()
+			return nil, err
+		}
+		outputs = append(outputs, out)
+	}
+
+	return outputs, nil
+}
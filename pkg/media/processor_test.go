@@ -0,0 +1,41 @@
+package media
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+)
+
+func TestRenderLeavesOriginal(t *testing.T) {
+	ctx := context.Background()
+	store, err := NewLocalStore(t.TempDir(), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	key := "products/P1/a.jpg"
+	upload := withSegments(encodeJPEG(t, 4, 2), orientationSegment(6))
+	if err := store.Put(ctx, key, bytes.NewReader(upload)); err != nil {
+		t.Fatal(err)
+	}
+
+	p := NewProcessor(store, []Rendition{{Name: "thumbnail", Width: 1}}, 1, 1)
+	outputs, err := p.render(ctx, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(outputs) != 1 || outputs[0].Key != "products/P1/a_thumbnail.jpg" || outputs[0].Height != 2 {
+		t.Errorf("outputs = %+v", outputs)
+	}
+
+	// Blobs are served as immutable, the original must not change
+	blob, err := store.Get(ctx, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer blob.Close()
+	if original, _ := io.ReadAll(blob); !bytes.Equal(original, upload) {
+		t.Error("render rewrote the original")
+	}
+}
@@ -0,0 +1,78 @@
+package media
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+)
+
+var ErrQueueFull = errors.New("media queue is full")
+
+const taskTimeout = 2 * time.Minute
+
+// Task is a unit of background work such as generating renditions.
+type Task func(ctx context.Context) error
+
+// Worker runs tasks on a fixed pool of goroutines so image processing never
+// blocks an upload request.
+type Worker struct {
+	tasks   chan Task
+	workers int
+	wg      sync.WaitGroup
+}
+
+func NewWorker(workers, queueSize int) *Worker {
+	if workers < 1 {
+		workers = 1
+	}
+
+	return &Worker{
+		tasks:   make(chan Task, queueSize),
+		workers: workers,
+	}
+}
+
+func (w *Worker) Start() {
+	for i := 0; i < w.workers; i++ {
+		w.wg.Add(1)
+		go func() {
+			defer w.wg.Done()
+			for task := range w.tasks {
+				w.run(task)
+			}
+		}()
+	}
+}
+
+// Stop waits for queued tasks to finish. Submit must not be called after.
+func (w *Worker) Stop() {
+	close(w.tasks)
+	w.wg.Wait()
+}
+
+// Submit queues a task without blocking the caller.
+func (w *Worker) Submit(task Task) error {
+	select {
+	case w.tasks <- task:
+		return nil
+	default:
+		return ErrQueueFull
+	}
+}
+
+func (w *Worker) run(task Task) {
+	ctx, cancel := context.WithTimeout(context.Background(), taskTimeout)
+	defer cancel()
+
+	defer func() {
+		if r := recover(); r != nil {
+			log.Println("media task panic:", r)
+		}
+	}()
+
+	if err := task(ctx); err != nil {
+		log.Println("media task:", err)
+	}
+}
@@ -0,0 +1,321 @@
+package money
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+)
+
+// DefaultCurrency is the currency of the catalog's own prices
+const DefaultCurrency = "THB"
+
+var (
+	ErrInvalidAmount    = errors.New("invalid money amount")
+	ErrInvalidRate      = errors.New("invalid exchange rate")
+	ErrUnknownCurrency  = errors.New("unknown currency")
+	ErrCurrencyMismatch = errors.New("currencies do not match")
+	ErrOverflow         = errors.New("money amount out of range")
+)
+
+// Decimal places of the minor unit per ISO 4217 currency
+var exponents = map[string]int{
+	"THB": 2,
+	"USD": 2,
+	"EUR": 2,
+	"GBP": 2,
+	"SGD": 2,
+	"MYR": 2,
+	"AUD": 2,
+	"HKD": 2,
+	"CNY": 2,
+	"JPY": 0,
+	"KRW": 0,
+	"VND": 0,
+}
+
+// Money is an exact amount in the minor unit of its currency, 19.99 THB is
+// {1999, "THB"}. Arithmetic never goes through floats.
+type Money struct {
+	Amount   int64
+	Currency string
+}
+
+func New(amount int64, currency string) Money {
+	return Money{Amount: amount, Currency: currency}
+}
+
+// Known reports whether the currency is supported
+func Known(currency string) bool {
+	_, ok := exponents[currency]
+	return ok
+}
+
+// Exponent is the number of decimal places of the currency's minor unit
+func Exponent(currency string) int {
+	return exponents[currency]
+}
+
+// Parse reads a decimal such as "19.99" exactly. More decimal places than
+// the currency has are an error, not rounded away.
+func Parse(s, currency string) (Money, error) {
+	exp, ok := exponents[currency]
+	if !ok {
+		return Money{}, fmt.Errorf("%w: %q", ErrUnknownCurrency, currency)
+	}
+
+	r, ok := new(big.Rat).SetString(strings.TrimSpace(s))
+	if !ok || strings.ContainsAny(s, "/eE") {
+		return Money{}, fmt.Errorf("%w: %q", ErrInvalidAmount, s)
+	}
+
+	minor := r.Mul(r, scale(exp))
+	if !minor.IsInt() || !minor.Num().IsInt64() {
+		return Money{}, fmt.Errorf("%w: %q", ErrInvalidAmount, s)
+	}
+
+	return Money{Amount: minor.Num().Int64(), Currency: currency}, nil
+}
+
+// FromRat turns an amount in major units into money, rounded half to even.
+// It is the only place amounts are rounded.
+func FromRat(r *big.Rat, currency string) Money {
+	minor := new(big.Rat).Mul(r, scale(exponents[currency]))
+	return Money{Amount: roundHalfEven(minor), Currency: currency}
+}
+
+func roundHalfEven(r *big.Rat) int64 {
+	q, rem := new(big.Int).QuoRem(r.Num(), r.Denom(), new(big.Int))
+
+	// Compare twice the remainder with the denominator to find the side of
+	// the half
+	twice := new(big.Int).Abs(rem)
+	twice.Lsh(twice, 1)
+
+	switch twice.Cmp(r.Denom()) {
+	case 1:
+		q.Add(q, big.NewInt(int64(r.Sign())))
+	case 0:
+		if q.Bit(0) == 1 {
+			q.Add(q, big.NewInt(int64(r.Sign())))
+		}
+	}
+
+	return q.Int64()
+}
+
+func scale(exp int) *big.Rat {
+	return new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(exp)), nil))
+}
+
+// RateDecimals is the precision exchange rates are stored and applied at
+const RateDecimals = 8
+
+// ParseRate reads a positive decimal rate with at most RateDecimals places
+func ParseRate(s string) (*big.Rat, error) {
+	r, ok := new(big.Rat).SetString(strings.TrimSpace(s))
+	if !ok || strings.ContainsAny(s, "/eE") || r.Sign() <= 0 {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidRate, s)
+	}
+
+	if !new(big.Rat).Mul(r, scale(RateDecimals)).IsInt() {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidRate, s)
+	}
+
+	return r, nil
+}
+
+// InvertRate turns a quote per base rate around, rounded half to even to
+// RateDecimals places so the stored rate is the applied one
+func InvertRate(r *big.Rat) *big.Rat {
+	inverted := new(big.Rat).Inv(r)
+	places := roundHalfEven(new(big.Rat).Mul(inverted, scale(RateDecimals)))
+	return new(big.Rat).Quo(new(big.Rat).SetInt64(places), scale(RateDecimals))
+}
+
+// Rat is the amount in major units
+func (m Money) Rat() *big.Rat {
+	return new(big.Rat).Quo(new(big.Rat).SetInt64(m.Amount), scale(exponents[m.Currency]))
+}
+
+// Decimal formats the amount in major units, 1999 THB is "19.99"
+func (m Money) Decimal() string {
+	return m.Rat().FloatString(exponents[m.Currency])
+}
+
+func (m Money) String() string {
+	return m.Decimal() + " " + m.Currency
+}
+
+func (m Money) IsZero() bool {
+	return m.Amount == 0
+}
+
+func (m Money) IsNegative() bool {
+	return m.Amount < 0
+}
+
+// Add and Sub panic on different currencies, a mismatch is a bug and not a
+// bad request. Amounts of one cart or order share its currency, anything
+// that comes from elsewhere, a coupon, a gift card, store credit, a refund
+// or a shipping rate, is checked against that currency or converted by the
+// usecase before it is combined. A zero value takes the other operand's
+// currency.
+func (m Money) Add(o Money) Money {
+	return Money{Amount: m.Amount + o.Amount, Currency: m.sameCurrency(o)}
+}
+
+func (m Money) Sub(o Money) Money {
+	return Money{Amount: m.Amount - o.Amount, Currency: m.sameCurrency(o)}
+}
+
+func (m Money) sameCurrency(o Money) string {
+	switch {
+	case m.Currency == o.Currency:
+		return m.Currency
+	case m == Money{}:
+		return o.Currency
+	case o == Money{}:
+		return m.Currency
+	}
+	panic(fmt.Errorf("%w: %s and %s", ErrCurrencyMismatch, m.Currency, o.Currency))
+}
+
+func (m Money) Neg() Money {
+	return Money{Amount: -m.Amount, Currency: m.Currency}
+}
+
+// Times multiplies by a quantity, ErrOverflow when the result does not fit
+// in the minor unit
+func (m Money) Times(n int64) (Money, error) {
+	product := new(big.Int).Mul(big.NewInt(m.Amount), big.NewInt(n))
+	if !product.IsInt64() {
+		return Money{}, fmt.Errorf("%w: %s times %d", ErrOverflow, m, n)
+	}
+
+	return Money{Amount: product.Int64(), Currency: m.Currency}, nil
+}
+
+// Mul multiplies by a rate such as a tax or discount percentage, rounded
+// half to even
+func (m Money) Mul(rate *big.Rat) Money {
+	return FromRat(new(big.Rat).Mul(m.Rat(), rate), m.Currency)
+}
+
+// Convert changes the currency at rate units of to per unit of m's currency
+func (m Money) Convert(to string, rate *big.Rat) Money {
+	return FromRat(new(big.Rat).Mul(m.Rat(), rate), to)
+}
+
+// Cmp compares amounts of the same currency like Add
+func (m Money) Cmp(o Money) int {
+	m.sameCurrency(o)
+
+	switch {
+	case m.Amount < o.Amount:
+		return -1
+	case m.Amount > o.Amount:
+		return 1
+	}
+	return 0
+}
+
+// Value stores the amount in a NUMERIC column, the currency is stored
+// separately where it varies
+func (m Money) Value() (driver.Value, error) {
+	return m.Decimal(), nil
+}
+
+// Scan reads a NUMERIC amount in the currency already set, else the default
+// currency
+func (m *Money) Scan(src any) error {
+	currency := m.Currency
+	if currency == "" {
+		currency = DefaultCurrency
+	}
+
+	var s string
+	switch v := src.(type) {
+	case []byte:
+		s = string(v)
+	case string:
+		s = v
+	case int64:
+		s = fmt.Sprint(v)
+	default:
+		return fmt.Errorf("%w: cannot scan %T", ErrInvalidAmount, src)
+	}
+
+	parsed, err := Parse(s, currency)
+	if err != nil {
+		return err
+	}
+
+	*m = parsed
+	return nil
+}
+
+type moneyJSON struct {
+	Amount   json.RawMessage `json:"amount"`
+	Currency string          `json:"currency"`
+}
+
+// MarshalJSON writes {"amount": "19.99", "currency": "THB"}, the amount is
+// a string so clients never parse it as a float
+func (m Money) MarshalJSON() ([]byte, error) {
+	amount, _ := json.Marshal(m.Decimal())
+	return json.Marshal(moneyJSON{Amount: amount, Currency: m.Currency})
+}
+
+// UnmarshalJSON reads the object form with a string or number amount, or a
+// bare amount in the currency already set, else the default currency
+func (m *Money) UnmarshalJSON(data []byte) error {
+	currency := m.Currency
+	if currency == "" {
+		currency = DefaultCurrency
+	}
+
+	raw := json.RawMessage(data)
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("{")) {
+		var v moneyJSON
+		if err := json.Unmarshal(data, &v); err != nil {
+			return err
+		}
+		if v.Currency != "" {
+			currency = strings.ToUpper(v.Currency)
+		}
+		raw = v.Amount
+	}
+
+	var amount string
+	if err := json.Unmarshal(raw, &amount); err != nil {
+		var n json.Number
+		if err := json.Unmarshal(raw, &n); err != nil {
+			return ErrInvalidAmount
+		}
+		amount = n.String()
+	}
+
+	parsed, err := Parse(amount, currency)
+	if err != nil {
+		return err
+	}
+
+	*m = parsed
+	return nil
+}
+
+// UnmarshalParam binds a query param such as min_price=100.50 in the
+// default currency
+func (m *Money) UnmarshalParam(param string) error {
+	parsed, err := Parse(param, DefaultCurrency)
+	if err != nil {
+		return err
+	}
+
+	*m = parsed
+	return nil
+}
@@ -0,0 +1,171 @@
+package money
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"math/big"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in       string
+		currency string
+		want     int64
+		err      error
+	}{
+		{"19.99", "THB", 1999, nil},
+		{" 0.5 ", "THB", 50, nil},
+		{"-3", "USD", -300, nil},
+		{"1500", "JPY", 1500, nil},
+		{"19.999", "THB", 0, ErrInvalidAmount},
+		{"1.5", "JPY", 0, ErrInvalidAmount},
+		{"1e3", "THB", 0, ErrInvalidAmount},
+		{"1/2", "THB", 0, ErrInvalidAmount},
+		{"abc", "THB", 0, ErrInvalidAmount},
+		{"1", "XXX", 0, ErrUnknownCurrency},
+	}
+
+	for _, tt := range tests {
+		got, err := Parse(tt.in, tt.currency)
+		if !errors.Is(err, tt.err) {
+			t.Errorf("Parse(%q, %s) error = %v, want %v", tt.in, tt.currency, err, tt.err)
+			continue
+		}
+		if err == nil && (got.Amount != tt.want || got.Currency != tt.currency) {
+			t.Errorf("Parse(%q, %s) = %v, want %d", tt.in, tt.currency, got, tt.want)
+		}
+	}
+}
+
+func TestFromRatRoundsHalfToEven(t *testing.T) {
+	tests := []struct {
+		in   string
+		want int64
+	}{
+		{"0.125", 12},
+		{"0.135", 14},
+		{"0.1251", 13},
+		{"-0.125", -12},
+		{"-0.135", -14},
+		{"2.004", 200},
+		{"2.006", 201},
+	}
+
+	for _, tt := range tests {
+		r, _ := new(big.Rat).SetString(tt.in)
+		if got := FromRat(r, "THB"); got.Amount != tt.want {
+			t.Errorf("FromRat(%s) = %d, want %d", tt.in, got.Amount, tt.want)
+		}
+	}
+
+	if got := FromRat(big.NewRat(5, 2), "JPY"); got.Amount != 2 {
+		t.Errorf("FromRat(2.5 JPY) = %d, want 2", got.Amount)
+	}
+}
+
+func TestMul(t *testing.T) {
+	// 7% of 10.05 is 0.7035
+	if got := New(1005, "THB").Mul(big.NewRat(7, 100)); got.Amount != 70 {
+		t.Errorf("Mul = %d, want 70", got.Amount)
+	}
+}
+
+func TestConvert(t *testing.T) {
+	rate, err := ParseRate("0.02857143")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := New(100000, "THB").Convert("USD", rate)
+	if got != New(2857, "USD") {
+		t.Errorf("Convert = %v, want 28.57 USD", got)
+	}
+
+	got = New(100000, "THB").Convert("JPY", big.NewRat(4, 1))
+	if got != New(4000, "JPY") {
+		t.Errorf("Convert = %v, want 4000 JPY", got)
+	}
+}
+
+func TestParseRate(t *testing.T) {
+	for _, s := range []string{"0", "-1", "0.000000001", "1e2", "x"} {
+		if _, err := ParseRate(s); !errors.Is(err, ErrInvalidRate) {
+			t.Errorf("ParseRate(%q) error = %v, want ErrInvalidRate", s, err)
+		}
+	}
+
+	if got := InvertRate(big.NewRat(35, 1)); got.FloatString(8) != "0.02857143" {
+		t.Errorf("InvertRate(35) = %s", got.FloatString(8))
+	}
+}
+
+func TestTimes(t *testing.T) {
+	got, err := New(1999, "THB").Times(3)
+	if err != nil || got != New(5997, "THB") {
+		t.Errorf("Times(3) = %v, %v", got, err)
+	}
+
+	if _, err := New(math.MaxInt64/2+1, "THB").Times(2); !errors.Is(err, ErrOverflow) {
+		t.Errorf("Times overflow error = %v, want ErrOverflow", err)
+	}
+}
+
+func TestAddZeroValueTakesCurrency(t *testing.T) {
+	var total Money
+	total = total.Add(New(150, "USD"))
+	if total != New(150, "USD") {
+		t.Errorf("Add = %v", total)
+	}
+}
+
+func TestAddPanicsOnMismatch(t *testing.T) {
+	defer func() {
+		err, _ := recover().(error)
+		if !errors.Is(err, ErrCurrencyMismatch) {
+			t.Errorf("recover() = %v, want ErrCurrencyMismatch", err)
+		}
+	}()
+
+	New(1, "THB").Add(New(1, "USD"))
+}
+
+func TestCmp(t *testing.T) {
+	a, b := New(100, "THB"), New(200, "THB")
+	if a.Cmp(b) != -1 || b.Cmp(a) != 1 || a.Cmp(a) != 0 {
+		t.Error("Cmp orders amounts wrongly")
+	}
+}
+
+func TestJSON(t *testing.T) {
+	data, err := json.Marshal(New(1999, "THB"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `{"amount":"19.99","currency":"THB"}` {
+		t.Errorf("Marshal = %s", data)
+	}
+
+	tests := []struct {
+		in   string
+		want Money
+	}{
+		{`{"amount":"5.50","currency":"usd"}`, New(550, "USD")},
+		{`{"amount":12}`, New(1200, "THB")},
+		{`"7.25"`, New(725, "THB")},
+		{`3`, New(300, "THB")},
+	}
+
+	for _, tt := range tests {
+		var got Money
+		if err := json.Unmarshal([]byte(tt.in), &got); err != nil || got != tt.want {
+			t.Errorf("Unmarshal(%s) = %v, %v, want %v", tt.in, got, err, tt.want)
+		}
+	}
+
+	var bad Money
+	if err := json.Unmarshal([]byte(`"1.001"`), &bad); err == nil {
+		t.Error("Unmarshal accepted more decimals than THB has")
+	}
+}
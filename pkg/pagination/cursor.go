@@ -0,0 +1,124 @@
+package pagination
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// Cursor points between two rows of a keyset ordered listing. Values hold
+// the sort key of the row it was taken from, as text so they round trip
+// exactly through Postgres casts.
+type Cursor struct {
+	Sort     string   `json:"s"`
+	Values   []string `json:"v"`
+	Backward bool     `json:"b,omitempty"`
+	Mode     string   `json:"m,omitempty"`
+}
+
+// Encode returns the opaque form handed to clients
+func Encode(c *Cursor) string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// Decode parses a cursor and checks it was issued for the same ordering,
+// a cursor of another sort would silently skip or repeat rows.
+func Decode(s, sort string, columns int) (*Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	var c Cursor
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	if c.Sort != sort || len(c.Values) != columns {
+		return nil, ErrInvalidCursor
+	}
+
+	return &c, nil
+}
+
+// Column is one key of the ordering. Type is the Postgres type the cursor
+// value is cast to, the last column must be unique.
+type Column struct {
+	Expr string
+	Type string
+	Desc bool
+}
+
+// Keys selects the sort key of every row as text for the next cursor
+func Keys(columns []Column) string {
+	parts := make([]string, len(columns))
+	for i, col := range columns {
+		parts[i] = fmt.Sprintf("(%s)::TEXT", col.Expr)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Condition builds the predicate for rows after the cursor, or before it
+// when paging backward. Mixed directions need the expanded form
+// (a > x) OR (a = x AND b < y) OR ... instead of a row comparison.
+func Condition(columns []Column, c *Cursor, arg func(any) string) string {
+	values := make([]string, len(columns))
+	for i, col := range columns {
+		values[i] = fmt.Sprintf("%s::%s", arg(c.Values[i]), col.Type)
+	}
+
+	var or []string
+	for i, col := range columns {
+		var and []string
+		for j := 0; j < i; j++ {
+			and = append(and, fmt.Sprintf("%s = %s", columns[j].Expr, values[j]))
+		}
+
+		op := ">"
+		if col.Desc != c.Backward {
+			op = "<"
+		}
+		and = append(and, fmt.Sprintf("%s %s %s", col.Expr, op, values[i]))
+
+		or = append(or, "("+strings.Join(and, " AND ")+")")
+	}
+
+	return "(" + strings.Join(or, " OR ") + ")"
+}
+
+// OrderBy sorts by the columns, reversed when paging backward. The rows
+// are read in reverse and flipped back by Trim.
+func OrderBy(columns []Column, backward bool) string {
+	parts := make([]string, len(columns))
+	for i, col := range columns {
+		dir := "ASC"
+		if col.Desc != backward {
+			dir = "DESC"
+		}
+		parts[i] = col.Expr + " " + dir
+	}
+	return "ORDER BY " + strings.Join(parts, ", ")
+}
+
+// Trim takes the limit+1 rows a query fetched, drops the extra one and
+// restores display order. It reports whether more rows exist in the
+// direction of travel.
+func Trim[T any](rows []T, limit int, backward bool) ([]T, bool) {
+	more := len(rows) > limit
+	if more {
+		rows = rows[:limit]
+	}
+
+	if backward {
+		for i, j := 0, len(rows)-1; i < j; i, j = i+1, j-1 {
+			rows[i], rows[j] = rows[j], rows[i]
+		}
+	}
+
+	return rows, more
+}
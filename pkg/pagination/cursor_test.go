@@ -0,0 +1,93 @@
+package pagination
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func TestEncodeDecode(t *testing.T) {
+	c := &Cursor{Sort: "price_desc", Values: []string{"19.99", "42"}, Backward: true}
+
+	got, err := Decode(Encode(c), "price_desc", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, c) {
+		t.Errorf("Decode = %+v, want %+v", got, c)
+	}
+}
+
+func TestDecodeRejects(t *testing.T) {
+	valid := Encode(&Cursor{Sort: "newest", Values: []string{"2024-01-01", "7"}})
+
+	tests := []struct {
+		name    string
+		cursor  string
+		sort    string
+		columns int
+	}{
+		{"not base64", "!!!", "newest", 2},
+		{"not json", "ew", "newest", 2}, // "{"
+		{"other sort", valid, "price_asc", 2},
+		{"other columns", valid, "newest", 3},
+	}
+
+	for _, tt := range tests {
+		if _, err := Decode(tt.cursor, tt.sort, tt.columns); !errors.Is(err, ErrInvalidCursor) {
+			t.Errorf("%s: error = %v, want ErrInvalidCursor", tt.name, err)
+		}
+	}
+}
+
+func TestCondition(t *testing.T) {
+	columns := []Column{
+		{Expr: "p.price", Type: "NUMERIC", Desc: true},
+		{Expr: "p.product_id", Type: "TEXT"},
+	}
+
+	var args []any
+	arg := func(v any) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	got := Condition(columns, &Cursor{Values: []string{"10.00", "abc"}}, arg)
+	// Each value is bound once and referenced in every term
+	want := "((p.price < $1::NUMERIC) OR (p.price = $1::NUMERIC AND p.product_id > $2::TEXT))"
+	if got != want {
+		t.Errorf("Condition = %s", got)
+	}
+
+	args = nil
+	got = Condition(columns, &Cursor{Values: []string{"10.00", "abc"}, Backward: true}, arg)
+	want = "((p.price > $1::NUMERIC) OR (p.price = $1::NUMERIC AND p.product_id < $2::TEXT))"
+	if got != want {
+		t.Errorf("backward Condition = %s", got)
+	}
+}
+
+func TestOrderBy(t *testing.T) {
+	columns := []Column{{Expr: "created_at", Desc: true}, {Expr: "id"}}
+
+	if got := OrderBy(columns, false); got != "ORDER BY created_at DESC, id ASC" {
+		t.Errorf("OrderBy = %s", got)
+	}
+	if got := OrderBy(columns, true); got != "ORDER BY created_at ASC, id DESC" {
+		t.Errorf("backward OrderBy = %s", got)
+	}
+}
+
+func TestTrim(t *testing.T) {
+	rows, more := Trim([]int{1, 2, 3}, 2, false)
+	if !reflect.DeepEqual(rows, []int{1, 2}) || !more {
+		t.Errorf("Trim = %v, %v", rows, more)
+	}
+
+	// Backward pages are read in reverse
+	rows, more = Trim([]int{5, 4}, 2, true)
+	if !reflect.DeepEqual(rows, []int{4, 5}) || more {
+		t.Errorf("backward Trim = %v, %v", rows, more)
+	}
+}
@@ -0,0 +1,31 @@
+package payment
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+)
+
+// FakeProvider accepts every refund, for development and tests
+type FakeProvider struct{}
+
+func NewFakeProvider() *FakeProvider {
+	return &FakeProvider{}
+}
+
+func (p *FakeProvider) Code() string {
+	return "fake"
+}
+
+func (p *FakeProvider) Refund(ctx context.Context, req *RefundRequest) (*Refund, error) {
+	if req.Amount.Amount <= 0 {
+		return nil, ErrRefundDeclined
+	}
+
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return nil, err
+	}
+
+	return &Refund{ID: "re_" + hex.EncodeToString(b)}, nil
+}
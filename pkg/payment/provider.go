@@ -0,0 +1,30 @@
+package payment
+
+import (
+	"context"
+	"errors"
+
+	"github.com/codepnw/react_go_ecom/pkg/money"
+)
+
+var ErrRefundDeclined = errors.New("refund declined")
+
+// RefundRequest pays back part or all of what was charged for Reference,
+// the store's own order id
+type RefundRequest struct {
+	Reference string
+	Amount    money.Money
+	Reason    string
+}
+
+// Refund is a refund the provider accepted, ID is its own reference
+type Refund struct {
+	ID string
+}
+
+// Provider issues refunds through a payment gateway. A refund the provider
+// turns down is ErrRefundDeclined, other errors may be retried.
+type Provider interface {
+	Code() string
+	Refund(ctx context.Context, req *RefundRequest) (*Refund, error)
+}
@@ -0,0 +1,240 @@
+package pdf
+
+import (
+	"encoding/binary"
+	"errors"
+	"os"
+	"strings"
+	"unicode/utf16"
+)
+
+var ErrInvalidFont = errors.New("invalid or unsupported TrueType font")
+
+// Font is a TrueType font embedded whole into the document
+type Font struct {
+	name       string
+	data       []byte
+	unitsPerEm int
+	bbox       [4]int
+	ascent     int
+	descent    int
+	advances   []uint16
+	glyphs     map[rune]uint16
+}
+
+func LoadFont(path string) (*Font, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseFont(data)
+}
+
+// ParseFont reads the metrics and character map of a TrueType font,
+// fonts with CFF outlines are not supported
+func ParseFont(data []byte) (*Font, error) {
+	if len(data) < 12 || string(data[:4]) == "OTTO" {
+		return nil, ErrInvalidFont
+	}
+
+	tables := map[string][]byte{}
+	numTables := int(u16(data, 4))
+	for i := 0; i < numTables; i++ {
+		rec := 12 + 16*i
+		if rec+16 > len(data) {
+			return nil, ErrInvalidFont
+		}
+		off, length := int(u32(data, rec+8)), int(u32(data, rec+12))
+		if off+length > len(data) {
+			return nil, ErrInvalidFont
+		}
+		tables[string(data[rec:rec+4])] = data[off : off+length]
+	}
+
+	head, hhea, hmtx, cmap := tables["head"], tables["hhea"], tables["hmtx"], tables["cmap"]
+	if len(head) < 54 || len(hhea) < 36 || hmtx == nil || cmap == nil || tables["glyf"] == nil {
+		return nil, ErrInvalidFont
+	}
+
+	f := &Font{
+		name:       fontName(tables["name"]),
+		data:       data,
+		unitsPerEm: int(u16(head, 18)),
+		bbox:       [4]int{int(i16(head, 36)), int(i16(head, 38)), int(i16(head, 40)), int(i16(head, 42))},
+		ascent:     int(i16(hhea, 4)),
+		descent:    int(i16(hhea, 6)),
+	}
+	if f.unitsPerEm == 0 {
+		return nil, ErrInvalidFont
+	}
+
+	metrics := int(u16(hhea, 34))
+	if metrics == 0 || len(hmtx) < 4*metrics {
+		return nil, ErrInvalidFont
+	}
+	f.advances = make([]uint16, metrics)
+	for i := range f.advances {
+		f.advances[i] = u16(hmtx, 4*i)
+	}
+
+	var err error
+	if f.glyphs, err = parseCmap(cmap); err != nil {
+		return nil, err
+	}
+
+	return f, nil
+}
+
+// glyph is the glyph of r, 0 is the font's missing glyph
+func (f *Font) glyph(r rune) uint16 {
+	return f.glyphs[r]
+}
+
+// advance is the width of r in thousandths of the font size, glyphs past
+// the metrics share the last width
+func (f *Font) advance(r rune) int {
+	gid := int(f.glyph(r))
+	if gid >= len(f.advances) {
+		gid = len(f.advances) - 1
+	}
+	return f.scale(int(f.advances[gid]))
+}
+
+func (f *Font) scale(v int) int {
+	return v * 1000 / f.unitsPerEm
+}
+
+// parseCmap reads the Unicode character map, the full repertoire subtable
+// is preferred over the basic plane one
+func parseCmap(cmap []byte) (map[rune]uint16, error) {
+	if len(cmap) < 4 {
+		return nil, ErrInvalidFont
+	}
+
+	var format4, format12 []byte
+	for i := 0; i < int(u16(cmap, 2)); i++ {
+		rec := 4 + 8*i
+		if rec+8 > len(cmap) {
+			return nil, ErrInvalidFont
+		}
+		platform, encoding, off := u16(cmap, rec), u16(cmap, rec+2), int(u32(cmap, rec+4))
+		if off+4 > len(cmap) || !(platform == 0 || platform == 3 && (encoding == 1 || encoding == 10)) {
+			continue
+		}
+		switch u16(cmap, off) {
+		case 4:
+			format4 = cmap[off:]
+		case 12:
+			format12 = cmap[off:]
+		}
+	}
+
+	glyphs := map[rune]uint16{}
+
+	if t := format12; len(t) >= 16 {
+		groups := int(u32(t, 12))
+		for i := 0; i < groups && 16+12*i+12 <= len(t); i++ {
+			g := t[16+12*i:]
+			start, end, gid := u32(g, 0), u32(g, 4), u32(g, 8)
+			for c := start; c <= end && c-start < 0x10000; c++ {
+				glyphs[rune(c)] = uint16(gid + c - start)
+			}
+		}
+		return glyphs, nil
+	}
+
+	t := format4
+	if len(t) < 14 {
+		return nil, ErrInvalidFont
+	}
+
+	segs := int(u16(t, 6)) / 2
+	ends, starts := 14, 16+2*segs
+	deltas, ranges := starts+2*segs, starts+4*segs
+	if ranges+2*segs > len(t) {
+		return nil, ErrInvalidFont
+	}
+
+	for s := 0; s < segs; s++ {
+		end, start := u16(t, ends+2*s), u16(t, starts+2*s)
+		delta, rangeOff := u16(t, deltas+2*s), int(u16(t, ranges+2*s))
+		if start == 0xFFFF {
+			continue
+		}
+		for c := int(start); c <= int(end); c++ {
+			var gid uint16
+			if rangeOff == 0 {
+				gid = uint16(c) + delta
+			} else {
+				at := ranges + 2*s + rangeOff + 2*(c-int(start))
+				if at+2 > len(t) {
+					continue
+				}
+				if gid = u16(t, at); gid != 0 {
+					gid += delta
+				}
+			}
+			if gid != 0 {
+				glyphs[rune(c)] = gid
+			}
+		}
+	}
+
+	return glyphs, nil
+}
+
+// fontName is the PostScript name of the font, reduced to characters a
+// PDF name takes without escaping
+func fontName(name []byte) string {
+	fallback := "EmbeddedFont"
+	if len(name) < 6 {
+		return fallback
+	}
+
+	count, nameOffset := int(u16(name, 2)), int(u16(name, 4))
+	for i := 0; i < count; i++ {
+		rec := 6 + 12*i
+		if rec+12 > len(name) || u16(name, rec+6) != 6 {
+			continue
+		}
+		platform := u16(name, rec)
+		length, off := int(u16(name, rec+8)), nameOffset+int(u16(name, rec+10))
+		if off+length > len(name) {
+			continue
+		}
+
+		raw := name[off : off+length]
+		s := string(raw)
+		if platform == 0 || platform == 3 {
+			units := make([]uint16, len(raw)/2)
+			for j := range units {
+				units[j] = u16(raw, 2*j)
+			}
+			s = string(utf16.Decode(units))
+		}
+
+		s = strings.Map(func(r rune) rune {
+			if r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' {
+				return r
+			}
+			return -1
+		}, s)
+		if s != "" {
+			return s
+		}
+	}
+
+	return fallback
+}
+
+func u16(b []byte, off int) uint16 {
+	return binary.BigEndian.Uint16(b[off:])
+}
+
+func i16(b []byte, off int) int16 {
+	return int16(u16(b, off))
+}
+
+func u32(b []byte, off int) uint32 {
+	return binary.BigEndian.Uint32(b[off:])
+}
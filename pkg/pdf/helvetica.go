@@ -0,0 +1,40 @@
+package pdf
+
+// helveticaWidths are the advance widths of Helvetica for the printable
+// ASCII characters from space, in thousandths of the font size
+var helveticaWidths = [95]int{
+	278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+	1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+	333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+	556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+}
+
+// WinAnsi code points above 127 that differ from Latin-1
+var winAnsiExtra = map[rune]byte{
+	'€': 0x80, '‚': 0x82, '„': 0x84, '…': 0x85, '‘': 0x91, '’': 0x92,
+	'“': 0x93, '”': 0x94, '•': 0x95, '–': 0x96, '—': 0x97, '™': 0x99,
+}
+
+// winAnsi maps r to the Helvetica encoding, characters it lacks print as ?
+func winAnsi(r rune) byte {
+	switch {
+	case r >= 32 && r <= 126, r >= 160 && r <= 255:
+		return byte(r)
+	}
+	if c, ok := winAnsiExtra[r]; ok {
+		return c
+	}
+	return '?'
+}
+
+// helveticaWidth is exact for ASCII, other characters are taken as wide
+// as a digit
+func helveticaWidth(r rune) int {
+	c := winAnsi(r)
+	if c >= 32 && c <= 126 {
+		return helveticaWidths[c-32]
+	}
+	return 556
+}
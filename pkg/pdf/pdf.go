@@ -0,0 +1,277 @@
+// Package pdf writes simple single-font PDF documents: text, lines and
+// filled boxes on A4 pages. Text uses Helvetica unless a TrueType font is
+// embedded, which is needed for scripts outside Latin-1 such as Thai.
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// A4 in points, the origin of every position is the top left corner
+const (
+	PageWidth  = 595.28
+	PageHeight = 841.89
+)
+
+type Document struct {
+	font  *Font
+	pages []*Page
+	used  map[rune]bool
+}
+
+// New starts a document set in font, nil is Helvetica
+func New(font *Font) *Document {
+	return &Document{font: font, used: map[rune]bool{}}
+}
+
+type Page struct {
+	doc *Document
+	buf bytes.Buffer
+}
+
+func (d *Document) AddPage() *Page {
+	p := &Page{doc: d}
+	d.pages = append(d.pages, p)
+	return p
+}
+
+// TextWidth is the width of s set at size
+func (d *Document) TextWidth(s string, size float64) float64 {
+	var units int
+	for _, r := range s {
+		if d.font != nil {
+			units += d.font.advance(r)
+		} else {
+			units += helveticaWidth(r)
+		}
+	}
+	return float64(units) * size / 1000
+}
+
+// Text sets s with its baseline at y, bold text is drawn with an outline
+// so it keeps the regular widths
+func (p *Page) Text(x, y, size float64, bold bool, s string) {
+	if s == "" {
+		return
+	}
+
+	mode := 0
+	if bold {
+		mode = 2
+	}
+
+	fmt.Fprintf(&p.buf, "BT /F1 %s Tf %d Tr %s w %s %s Td %s Tj ET\n",
+		num(size), mode, num(size/30), num(x), num(PageHeight-y), p.doc.encode(s))
+}
+
+// TextRight sets s so that it ends at x
+func (p *Page) TextRight(x, y, size float64, bold bool, s string) {
+	p.Text(x-p.doc.TextWidth(s, size), y, size, bold, s)
+}
+
+func (p *Page) Line(x1, y1, x2, y2, width float64) {
+	fmt.Fprintf(&p.buf, "%s w %s %s m %s %s l S\n", num(width), num(x1), num(PageHeight-y1), num(x2), num(PageHeight-y2))
+}
+
+// Box fills a rectangle with a grey level, 0 is black and 1 white
+func (p *Page) Box(x, y, w, h, grey float64) {
+	fmt.Fprintf(&p.buf, "q %s g %s %s %s %s re f Q\n", num(grey), num(x), num(PageHeight-y-h), num(w), num(h))
+}
+
+// encode writes s as a PDF string in the encoding of the font
+func (d *Document) encode(s string) string {
+	if d.font != nil {
+		var b strings.Builder
+		b.WriteByte('<')
+		for _, r := range s {
+			d.used[r] = true
+			fmt.Fprintf(&b, "%04X", d.font.glyph(r))
+		}
+		b.WriteByte('>')
+		return b.String()
+	}
+
+	var b strings.Builder
+	b.WriteByte('(')
+	for _, r := range s {
+		c := winAnsi(r)
+		switch c {
+		case '(', ')', '\\':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		default:
+			if c < 32 || c > 126 {
+				fmt.Fprintf(&b, "\\%03o", c)
+			} else {
+				b.WriteByte(c)
+			}
+		}
+	}
+	b.WriteByte(')')
+	return b.String()
+}
+
+// Bytes renders the document
+func (d *Document) Bytes() ([]byte, error) {
+	var buf bytes.Buffer
+	if _, err := d.WriteTo(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (d *Document) WriteTo(w io.Writer) (int64, error) {
+	if len(d.pages) == 0 {
+		d.AddPage()
+	}
+
+	var out writer
+
+	// Objects 1 and 2 are the catalog and the page tree, the font follows,
+	// then a page and its content for every page
+	out.header()
+
+	catalog := out.reserve()
+	pages := out.reserve()
+	font := d.writeFont(&out)
+
+	kids := make([]string, len(d.pages))
+	for i, p := range d.pages {
+		content := out.stream("", p.buf.Bytes())
+		page := out.object(fmt.Sprintf("<< /Type /Page /Parent %d 0 R /MediaBox [0 0 %s %s] /Resources << /Font << /F1 %d 0 R >> >> /Contents %d 0 R >>",
+			pages, num(PageWidth), num(PageHeight), font, content))
+		kids[i] = fmt.Sprintf("%d 0 R", page)
+	}
+
+	out.objectAt(catalog, fmt.Sprintf("<< /Type /Catalog /Pages %d 0 R >>", pages))
+	out.objectAt(pages, fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(kids)))
+	out.trailer(catalog)
+
+	n, err := w.Write(out.buf.Bytes())
+	return int64(n), err
+}
+
+func (d *Document) writeFont(out *writer) int {
+	if d.font == nil {
+		return out.object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	}
+
+	f := d.font
+
+	runes := make([]rune, 0, len(d.used))
+	for r := range d.used {
+		runes = append(runes, r)
+	}
+	sort.Slice(runes, func(i, j int) bool { return f.glyph(runes[i]) < f.glyph(runes[j]) })
+
+	var widths, cmap strings.Builder
+	seen := map[uint16]bool{}
+	for _, r := range runes {
+		gid := f.glyph(r)
+		if seen[gid] {
+			continue
+		}
+		seen[gid] = true
+		fmt.Fprintf(&widths, "%d [%d] ", gid, f.advance(r))
+		fmt.Fprintf(&cmap, "<%04X> <%s>\n", gid, utf16Hex(r))
+	}
+
+	file := out.stream(fmt.Sprintf("/Length1 %d", len(f.data)), f.data)
+	descriptor := out.object(fmt.Sprintf("<< /Type /FontDescriptor /FontName /%s /Flags 4 /FontBBox [%d %d %d %d] /ItalicAngle 0 /Ascent %d /Descent %d /CapHeight %d /StemV 80 /FontFile2 %d 0 R >>",
+		f.name, f.scale(f.bbox[0]), f.scale(f.bbox[1]), f.scale(f.bbox[2]), f.scale(f.bbox[3]),
+		f.scale(f.ascent), f.scale(f.descent), f.scale(f.ascent), file))
+	cid := out.object(fmt.Sprintf("<< /Type /Font /Subtype /CIDFontType2 /BaseFont /%s /CIDSystemInfo << /Registry (Adobe) /Ordering (Identity) /Supplement 0 >> /FontDescriptor %d 0 R /CIDToGIDMap /Identity /DW 1000 /W [%s] >>",
+		f.name, descriptor, widths.String()))
+
+	var unicode strings.Builder
+	unicode.WriteString("/CIDInit /ProcSet findresource begin 12 dict begin begincmap\n")
+	unicode.WriteString("/CIDSystemInfo << /Registry (Adobe) /Ordering (UCS) /Supplement 0 >> def\n")
+	unicode.WriteString("/CMapName /Adobe-Identity-UCS def /CMapType 2 def\n")
+	unicode.WriteString("1 begincodespacerange <0000> <FFFF> endcodespacerange\n")
+	// bfchar blocks hold at most 100 entries
+	lines := strings.Split(strings.TrimSuffix(cmap.String(), "\n"), "\n")
+	for len(lines) > 0 && lines[0] != "" {
+		n := min(len(lines), 100)
+		fmt.Fprintf(&unicode, "%d beginbfchar\n%s\nendbfchar\n", n, strings.Join(lines[:n], "\n"))
+		lines = lines[n:]
+	}
+	unicode.WriteString("endcmap CMapName currentdict /CMap defineresource pop end end\n")
+	toUnicode := out.stream("", []byte(unicode.String()))
+
+	return out.object(fmt.Sprintf("<< /Type /Font /Subtype /Type0 /BaseFont /%s /Encoding /Identity-H /DescendantFonts [%d 0 R] /ToUnicode %d 0 R >>",
+		f.name, cid, toUnicode))
+}
+
+func utf16Hex(r rune) string {
+	if r < 0x10000 {
+		return fmt.Sprintf("%04X", r)
+	}
+	r -= 0x10000
+	return fmt.Sprintf("%04X%04X", 0xD800+(r>>10), 0xDC00+(r&0x3FF))
+}
+
+// writer numbers the objects and keeps their offsets for the xref table
+type writer struct {
+	buf     bytes.Buffer
+	offsets []int
+}
+
+func (w *writer) header() {
+	w.buf.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+}
+
+// reserve takes an object number to be written later
+func (w *writer) reserve() int {
+	w.offsets = append(w.offsets, 0)
+	return len(w.offsets)
+}
+
+func (w *writer) object(body string) int {
+	id := w.reserve()
+	w.objectAt(id, body)
+	return id
+}
+
+func (w *writer) objectAt(id int, body string) {
+	w.offsets[id-1] = w.buf.Len()
+	fmt.Fprintf(&w.buf, "%d 0 obj\n%s\nendobj\n", id, body)
+}
+
+// stream writes data compressed, extra goes into the stream dictionary
+func (w *writer) stream(extra string, data []byte) int {
+	var z bytes.Buffer
+	zw := zlib.NewWriter(&z)
+	zw.Write(data)
+	zw.Close()
+
+	id := w.reserve()
+	w.offsets[id-1] = w.buf.Len()
+	fmt.Fprintf(&w.buf, "%d 0 obj\n<< /Length %d /Filter /FlateDecode %s>>\nstream\n", id, z.Len(), extra)
+	w.buf.Write(z.Bytes())
+	w.buf.WriteString("\nendstream\nendobj\n")
+	return id
+}
+
+func (w *writer) trailer(root int) {
+	xref := w.buf.Len()
+	fmt.Fprintf(&w.buf, "xref\n0 %d\n0000000000 65535 f \n", len(w.offsets)+1)
+	for _, off := range w.offsets {
+		fmt.Fprintf(&w.buf, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&w.buf, "trailer\n<< /Size %d /Root %d 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(w.offsets)+1, root, xref)
+}
+
+// num formats a coordinate with at most two decimals
+func num(f float64) string {
+	s := fmt.Sprintf("%.2f", f)
+	s = strings.TrimRight(strings.TrimRight(s, "0"), ".")
+	if s == "-0" || s == "" {
+		return "0"
+	}
+	return s
+}
@@ -0,0 +1,85 @@
+package scheduler
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+const jobTimeout = time.Minute
+
+// Job is periodic background work such as applying scheduled price changes.
+type Job func(ctx context.Context) error
+
+type job struct {
+	name     string
+	interval time.Duration
+	run      Job
+}
+
+// Scheduler runs every job on its own ticker. A run that takes longer than
+// the interval delays the next one instead of overlapping it.
+type Scheduler struct {
+	jobs []job
+	stop chan struct{}
+	wg   sync.WaitGroup
+}
+
+func New() *Scheduler {
+	return &Scheduler{stop: make(chan struct{})}
+}
+
+// Every registers a job before Start, a zero interval disables it.
+func (s *Scheduler) Every(name string, interval time.Duration, run Job) {
+	if interval <= 0 {
+		log.Printf("scheduler: %s disabled", name)
+		return
+	}
+
+	s.jobs = append(s.jobs, job{name: name, interval: interval, run: run})
+}
+
+// Start runs every job once right away, then on its interval.
+func (s *Scheduler) Start() {
+	for _, j := range s.jobs {
+		s.wg.Add(1)
+		go func(j job) {
+			defer s.wg.Done()
+
+			ticker := time.NewTicker(j.interval)
+			defer ticker.Stop()
+
+			for {
+				s.run(j)
+
+				select {
+				case <-s.stop:
+					return
+				case <-ticker.C:
+				}
+			}
+		}(j)
+	}
+}
+
+// Stop waits for running jobs to finish.
+func (s *Scheduler) Stop() {
+	close(s.stop)
+	s.wg.Wait()
+}
+
+func (s *Scheduler) run(j job) {
+	ctx, cancel := context.WithTimeout(context.Background(), jobTimeout)
+	defer cancel()
+
+	defer func() {
+		if r := recover(); r != nil {
+			log.Printf("scheduler: %s panic: %v", j.name, r)
+		}
+	}()
+
+	if err := j.run(ctx); err != nil {
+		log.Printf("scheduler: %s: %v", j.name, err)
+	}
+}
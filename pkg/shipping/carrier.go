@@ -0,0 +1,83 @@
+package shipping
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// Tracking statuses every carrier's own codes are mapped to
+const (
+	StatusLabelCreated   = "label_created"
+	StatusInTransit      = "in_transit"
+	StatusOutForDelivery = "out_for_delivery"
+	StatusDelivered      = "delivered"
+	StatusException      = "exception"
+)
+
+var (
+	ErrUnknownCarrier  = errors.New("unknown carrier")
+	ErrUnknownTracking = errors.New("unknown tracking number")
+)
+
+type Address struct {
+	Name       string
+	Phone      string
+	Line1      string
+	Line2      string
+	City       string
+	Region     string
+	PostalCode string
+	Country    string
+}
+
+// LabelRequest books a parcel, Reference is the store's own id for it and
+// shows on the label
+type LabelRequest struct {
+	Reference   string
+	To          Address
+	WeightGrams int
+}
+
+// Label is a booked parcel, Data is the printable label
+type Label struct {
+	TrackingNumber string
+	ContentType    string
+	Data           []byte
+}
+
+// Event is one step of a parcel's journey
+type Event struct {
+	Status      string
+	Description string
+	Location    string
+	OccurredAt  time.Time
+}
+
+// CarrierAdapter books parcels with a carrier and reads back their
+// tracking. Track returns every event so far, oldest first, calling it
+// again is safe.
+type CarrierAdapter interface {
+	Code() string
+	CreateLabel(ctx context.Context, req *LabelRequest) (*Label, error)
+	Track(ctx context.Context, trackingNumber string) ([]*Event, error)
+}
+
+// Carriers holds the configured carriers by code
+type Carriers map[string]CarrierAdapter
+
+func NewCarriers(adapters ...CarrierAdapter) Carriers {
+	carriers := make(Carriers, len(adapters))
+	for _, a := range adapters {
+		carriers[a.Code()] = a
+	}
+	return carriers
+}
+
+func (c Carriers) Get(code string) (CarrierAdapter, error) {
+	a, ok := c[code]
+	if !ok {
+		return nil, ErrUnknownCarrier
+	}
+	return a, nil
+}
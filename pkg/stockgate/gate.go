@@ -0,0 +1,154 @@
+package stockgate
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	// ErrSoldOut is returned without waiting once the units are gone
+	ErrSoldOut = errors.New("stockgate: sold out")
+	// ErrBusy is returned when no slot frees up within the wait
+	ErrBusy = errors.New("stockgate: busy")
+)
+
+// Gate fronts stock that is held in the database. A counter per key turns
+// away requests for units already claimed on this instance without a
+// round trip, and a fixed number of slots bounds the transactions that
+// queue on the same row. The database stays the authority: a counter only
+// overestimates what is left, it never refuses units the database would
+// still sell.
+type Gate struct {
+	slots    chan struct{}
+	wait     time.Duration
+	mu       sync.Mutex
+	counters map[int]*atomic.Int64
+}
+
+// New allows slots concurrent claims, a claim waits at most wait for one
+func New(slots int, wait time.Duration) *Gate {
+	if slots < 1 {
+		slots = 1
+	}
+
+	return &Gate{
+		slots:    make(chan struct{}, slots),
+		wait:     wait,
+		counters: make(map[int]*atomic.Int64),
+	}
+}
+
+// Known reports whether the key has a counter
+func (g *Gate) Known(key int) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	_, ok := g.counters[key]
+	return ok
+}
+
+// Remaining is the units left for the key on this instance, zero when it
+// has no counter
+func (g *Gate) Remaining(key int) int64 {
+	if c := g.counter(key); c != nil {
+		return c.Load()
+	}
+	return 0
+}
+
+// Seed sets the units left for a key that has no counter yet, a counter
+// seeded concurrently is kept
+func (g *Gate) Seed(key int, remaining int64) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if _, ok := g.counters[key]; !ok {
+		c := new(atomic.Int64)
+		c.Store(remaining)
+		g.counters[key] = c
+	}
+}
+
+// SoldOut records that the database has no units left for the key
+func (g *Gate) SoldOut(key int) {
+	if c := g.counter(key); c != nil {
+		c.Store(0)
+	}
+}
+
+// Forget drops the counter, the next claim seeds it again
+func (g *Gate) Forget(key int) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	delete(g.counters, key)
+}
+
+// Enter claims n units of a seeded key and a slot. The ticket must be
+// committed once the database sold the units or canceled otherwise.
+func (g *Gate) Enter(ctx context.Context, key int, n int64) (*Ticket, error) {
+	c := g.counter(key)
+	if c == nil {
+		return nil, errors.New("stockgate: key not seeded")
+	}
+
+	if c.Load() < n {
+		return nil, ErrSoldOut
+	}
+
+	timer := time.NewTimer(g.wait)
+	defer timer.Stop()
+
+	select {
+	case g.slots <- struct{}{}:
+	case <-timer.C:
+		return nil, ErrBusy
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+
+	// Others may have claimed the units while this one queued
+	for {
+		left := c.Load()
+		if left < n {
+			<-g.slots
+			return nil, ErrSoldOut
+		}
+		if c.CompareAndSwap(left, left-n) {
+			break
+		}
+	}
+
+	return &Ticket{gate: g, counter: c, n: n}, nil
+}
+
+func (g *Gate) counter(key int) *atomic.Int64 {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	return g.counters[key]
+}
+
+// Ticket is a claim in flight, it holds a slot until it is done
+type Ticket struct {
+	gate    *Gate
+	counter *atomic.Int64
+	n       int64
+	once    sync.Once
+}
+
+// Commit keeps the units claimed and frees the slot
+func (t *Ticket) Commit() {
+	t.once.Do(func() { <-t.gate.slots })
+}
+
+// Cancel gives the units back and frees the slot
+func (t *Ticket) Cancel() {
+	t.once.Do(func() {
+		t.counter.Add(t.n)
+		<-t.gate.slots
+	})
+}
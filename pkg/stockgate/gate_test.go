@@ -0,0 +1,125 @@
+package stockgate
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestEnterUnseeded(t *testing.T) {
+	g := New(1, time.Second)
+
+	if _, err := g.Enter(context.Background(), 1, 1); err == nil {
+		t.Error("Enter on an unseeded key succeeded")
+	}
+}
+
+func TestSeedKeepsExisting(t *testing.T) {
+	g := New(1, time.Second)
+	g.Seed(1, 5)
+	g.Seed(1, 100)
+
+	if got := g.Remaining(1); got != 5 {
+		t.Errorf("Remaining = %d, want 5", got)
+	}
+}
+
+func TestCommitAndCancel(t *testing.T) {
+	g := New(1, time.Second)
+	g.Seed(1, 3)
+
+	ticket, err := g.Enter(context.Background(), 1, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ticket.Cancel()
+	ticket.Commit() // done once, the units stay given back
+
+	if got := g.Remaining(1); got != 3 {
+		t.Errorf("Remaining after cancel = %d, want 3", got)
+	}
+
+	ticket, err = g.Enter(context.Background(), 1, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ticket.Commit()
+
+	if got := g.Remaining(1); got != 1 {
+		t.Errorf("Remaining after commit = %d, want 1", got)
+	}
+
+	if _, err := g.Enter(context.Background(), 1, 2); !errors.Is(err, ErrSoldOut) {
+		t.Errorf("Enter over stock error = %v, want ErrSoldOut", err)
+	}
+}
+
+func TestSoldOutAndForget(t *testing.T) {
+	g := New(1, time.Second)
+	g.Seed(1, 10)
+	g.SoldOut(1)
+
+	if _, err := g.Enter(context.Background(), 1, 1); !errors.Is(err, ErrSoldOut) {
+		t.Errorf("Enter after SoldOut error = %v, want ErrSoldOut", err)
+	}
+
+	g.Forget(1)
+	if g.Known(1) {
+		t.Error("Known after Forget")
+	}
+}
+
+func TestBusy(t *testing.T) {
+	g := New(1, 10*time.Millisecond)
+	g.Seed(1, 10)
+
+	held, err := g.Enter(context.Background(), 1, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer held.Commit()
+
+	if _, err := g.Enter(context.Background(), 1, 1); !errors.Is(err, ErrBusy) {
+		t.Errorf("Enter without a free slot error = %v, want ErrBusy", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	g.wait = time.Second
+	if _, err := g.Enter(ctx, 1, 1); !errors.Is(err, context.Canceled) {
+		t.Errorf("Enter with a canceled context error = %v, want context.Canceled", err)
+	}
+}
+
+func TestConcurrentClaimsNeverOversell(t *testing.T) {
+	g := New(4, time.Second)
+	g.Seed(1, 50)
+
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		sold int
+	)
+	for i := 0; i < 200; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			ticket, err := g.Enter(context.Background(), 1, 1)
+			if err != nil {
+				return
+			}
+			mu.Lock()
+			sold++
+			mu.Unlock()
+			ticket.Commit()
+		}()
+	}
+	wg.Wait()
+
+	if sold != 50 || g.Remaining(1) != 0 {
+		t.Errorf("sold %d with %d remaining, want 50 and 0", sold, g.Remaining(1))
+	}
+}
@@ -0,0 +1,203 @@
+package suggest
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+type Kind string
+
+const (
+	KindProduct  Kind = "product"
+	KindCategory Kind = "category"
+	KindQuery    Kind = "query"
+)
+
+// maxScan bounds the keys visited per lookup so short prefixes such as a
+// single letter stay fast on a large catalog.
+const maxScan = 2000
+
+type Entry struct {
+	Kind   Kind
+	ID     string
+	Text   string
+	Weight int
+}
+
+type term struct {
+	key string
+	ref string
+}
+
+// Index is an in-memory prefix index. Every word of an entry starts a key
+// so "pro" finds "iPhone 15 Pro" and "iphone 15" still matches as typed.
+type Index struct {
+	mu      sync.RWMutex
+	entries map[string]*Entry
+	terms   []term
+}
+
+func NewIndex() *Index {
+	return &Index{entries: make(map[string]*Entry)}
+}
+
+func ref(kind Kind, id string) string {
+	return string(kind) + ":" + id
+}
+
+// Normalize lowercases and collapses whitespace, it is applied to both the
+// indexed text and the typed prefix.
+func Normalize(s string) string {
+	return strings.Join(strings.FieldsFunc(strings.ToLower(s), unicode.IsSpace), " ")
+}
+
+func keys(text string) []string {
+	norm := Normalize(text)
+	if norm == "" {
+		return nil
+	}
+
+	out := []string{norm}
+	for i, r := range norm {
+		if r == ' ' {
+			out = append(out, norm[i+1:])
+		}
+	}
+	return out
+}
+
+// Upsert adds or replaces a single entry.
+func (ix *Index) Upsert(e Entry) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+
+	ix.upsert(e)
+}
+
+func (ix *Index) upsert(e Entry) {
+	r := ref(e.Kind, e.ID)
+	if old, ok := ix.entries[r]; ok {
+		ix.removeTerms(r, old.Text)
+	}
+
+	ix.entries[r] = &e
+	for _, k := range keys(e.Text) {
+		i := sort.Search(len(ix.terms), func(i int) bool { return ix.terms[i].key >= k })
+		ix.terms = append(ix.terms, term{})
+		copy(ix.terms[i+1:], ix.terms[i:])
+		ix.terms[i] = term{key: k, ref: r}
+	}
+}
+
+func (ix *Index) Remove(kind Kind, id string) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+
+	r := ref(kind, id)
+	if old, ok := ix.entries[r]; ok {
+		ix.removeTerms(r, old.Text)
+		delete(ix.entries, r)
+	}
+}
+
+func (ix *Index) removeTerms(r, text string) {
+	for _, k := range keys(text) {
+		i := sort.Search(len(ix.terms), func(i int) bool { return ix.terms[i].key >= k })
+		for ; i < len(ix.terms) && ix.terms[i].key == k; i++ {
+			if ix.terms[i].ref == r {
+				ix.terms = append(ix.terms[:i], ix.terms[i+1:]...)
+				break
+			}
+		}
+	}
+}
+
+// Replace swaps every entry of a kind at once, used for full rebuilds.
+func (ix *Index) Replace(kind Kind, entries []Entry) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+
+	for r, e := range ix.entries {
+		if e.Kind == kind {
+			delete(ix.entries, r)
+		}
+	}
+
+	terms := ix.terms[:0:0]
+	for _, t := range ix.terms {
+		if _, ok := ix.entries[t.ref]; ok {
+			terms = append(terms, t)
+		}
+	}
+
+	for i := range entries {
+		e := entries[i]
+		r := ref(e.Kind, e.ID)
+		ix.entries[r] = &e
+		for _, k := range keys(e.Text) {
+			terms = append(terms, term{key: k, ref: r})
+		}
+	}
+
+	sort.Slice(terms, func(i, j int) bool { return terms[i].key < terms[j].key })
+	ix.terms = terms
+}
+
+// Bump adds delta to the weight of an entry, creating it when missing.
+// It reports whether the entry is new.
+func (ix *Index) Bump(kind Kind, id, text string, delta int) bool {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+
+	if old, ok := ix.entries[ref(kind, id)]; ok {
+		old.Weight += delta
+		return false
+	}
+
+	ix.upsert(Entry{Kind: kind, ID: id, Text: text, Weight: delta})
+	return true
+}
+
+// Search returns up to limit entries per kind whose text has a word
+// starting with prefix, heaviest first.
+func (ix *Index) Search(prefix string, limit int) map[Kind][]Entry {
+	prefix = Normalize(prefix)
+	result := make(map[Kind][]Entry)
+	if prefix == "" || limit <= 0 {
+		return result
+	}
+
+	ix.mu.RLock()
+	seen := make(map[string]bool)
+	i := sort.Search(len(ix.terms), func(i int) bool { return ix.terms[i].key >= prefix })
+	for n := 0; i < len(ix.terms) && n < maxScan; i, n = i+1, n+1 {
+		t := ix.terms[i]
+		if !strings.HasPrefix(t.key, prefix) {
+			break
+		}
+		if seen[t.ref] {
+			continue
+		}
+		seen[t.ref] = true
+
+		e := ix.entries[t.ref]
+		result[e.Kind] = append(result[e.Kind], *e)
+	}
+	ix.mu.RUnlock()
+
+	for kind, entries := range result {
+		sort.SliceStable(entries, func(i, j int) bool {
+			if entries[i].Weight != entries[j].Weight {
+				return entries[i].Weight > entries[j].Weight
+			}
+			return entries[i].Text < entries[j].Text
+		})
+		if len(entries) > limit {
+			result[kind] = entries[:limit]
+		}
+	}
+
+	return result
+}
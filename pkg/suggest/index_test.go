@@ -0,0 +1,179 @@
+package suggest
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func ids(entries []Entry) []string {
+	out := []string{}
+	for _, e := range entries {
+		out = append(out, e.ID)
+	}
+	return out
+}
+
+// termCount is the number of keys the live entries should have indexed
+func termCount(ix *Index) int {
+	n := 0
+	for _, e := range ix.entries {
+		n += len(keys(e.Text))
+	}
+	return n
+}
+
+func catalog() *Index {
+	ix := NewIndex()
+	ix.Replace(KindProduct, []Entry{
+		{Kind: KindProduct, ID: "P1", Text: "iPhone 15 Pro", Weight: 5},
+		{Kind: KindProduct, ID: "P2", Text: "iPhone 15", Weight: 9},
+		{Kind: KindProduct, ID: "P3", Text: "Propeller  Hat", Weight: 1},
+		{Kind: KindProduct, ID: "P4", Text: "Approved Case", Weight: 7},
+	})
+	ix.Upsert(Entry{Kind: KindCategory, ID: "1", Text: "Phones", Weight: 3})
+	return ix
+}
+
+func TestSearch(t *testing.T) {
+	tests := []struct {
+		name   string
+		prefix string
+		limit  int
+		want   map[Kind][]string
+	}{
+		{
+			name:   "whole text as typed",
+			prefix: "iphone 15",
+			limit:  5,
+			want:   map[Kind][]string{KindProduct: {"P2", "P1"}},
+		},
+		{
+			name:   "start of a later word",
+			prefix: "PRO",
+			limit:  5,
+			want:   map[Kind][]string{KindProduct: {"P1", "P3"}},
+		},
+		{
+			name:   "not inside a word",
+			prefix: "roved",
+			limit:  5,
+			want:   map[Kind][]string{},
+		},
+		{
+			name:   "grouped by kind",
+			prefix: "ph",
+			limit:  5,
+			want:   map[Kind][]string{KindCategory: {"1"}},
+		},
+		{
+			name:   "heaviest first within the limit",
+			prefix: "i",
+			limit:  1,
+			want:   map[Kind][]string{KindProduct: {"P2"}},
+		},
+		{
+			name:   "blank prefix",
+			prefix: "  ",
+			limit:  5,
+			want:   map[Kind][]string{},
+		},
+	}
+
+	ix := catalog()
+	for _, tt := range tests {
+		got := make(map[Kind][]string)
+		for kind, entries := range ix.Search(tt.prefix, tt.limit) {
+			got[kind] = ids(entries)
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: Search(%q) = %v, want %v", tt.name, tt.prefix, got, tt.want)
+		}
+	}
+}
+
+func TestIndexLeavesNoStaleTerms(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(ix *Index)
+		prefix string
+		want   []string
+	}{
+		{
+			name:   "upsert renames",
+			change: func(ix *Index) { ix.Upsert(Entry{Kind: KindProduct, ID: "P3", Text: "Bucket Hat"}) },
+			prefix: "propeller",
+			want:   []string{},
+		},
+		{
+			name:   "remove",
+			change: func(ix *Index) { ix.Remove(KindProduct, "P2") },
+			prefix: "iphone",
+			want:   []string{"P1"},
+		},
+		{
+			name: "replace drops missing entries",
+			change: func(ix *Index) {
+				ix.Replace(KindProduct, []Entry{{Kind: KindProduct, ID: "P9", Text: "Pixel 9"}})
+			},
+			prefix: "p",
+			want:   []string{"P9"},
+		},
+		{
+			name:   "remove of a missing entry",
+			change: func(ix *Index) { ix.Remove(KindProduct, "P404") },
+			prefix: "hat",
+			want:   []string{"P3"},
+		},
+	}
+
+	for _, tt := range tests {
+		ix := catalog()
+		tt.change(ix)
+
+		if got := ids(ix.Search(tt.prefix, 10)[KindProduct]); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: Search(%q) = %v, want %v", tt.name, tt.prefix, got, tt.want)
+		}
+		if len(ix.terms) != termCount(ix) {
+			t.Errorf("%s: %d terms indexed, want %d", tt.name, len(ix.terms), termCount(ix))
+		}
+		if got := ix.Search("phones", 10)[KindCategory]; len(got) != 1 {
+			t.Errorf("%s: category entry lost", tt.name)
+		}
+	}
+}
+
+func TestBump(t *testing.T) {
+	ix := catalog()
+
+	if !ix.Bump(KindQuery, "case", "case", 1) {
+		t.Error("Bump of a new query reported it as existing")
+	}
+	if ix.Bump(KindProduct, "P1", "ignored", 10) {
+		t.Error("Bump of an existing product reported it as new")
+	}
+
+	if got := ids(ix.Search("iphone", 5)[KindProduct]); !reflect.DeepEqual(got, []string{"P1", "P2"}) {
+		t.Errorf("after Bump = %v, want P1 first", got)
+	}
+	if got := ix.Search("ignored", 5); len(got) != 0 {
+		t.Errorf("Bump reindexed an existing entry: %v", got)
+	}
+	if got := ids(ix.Search("cas", 5)[KindQuery]); !reflect.DeepEqual(got, []string{"case"}) {
+		t.Errorf("bumped query = %v", got)
+	}
+}
+
+func TestSearchScanIsBounded(t *testing.T) {
+	ix := NewIndex()
+
+	entries := make([]Entry, maxScan+50)
+	for i := range entries {
+		entries[i] = Entry{Kind: KindProduct, ID: fmt.Sprint(i), Text: fmt.Sprintf("shirt %04d", i)}
+	}
+	ix.Replace(KindProduct, entries)
+
+	if got := len(ix.Search("s", len(entries))[KindProduct]); got != maxScan {
+		t.Errorf("Search visited %d entries, want at most %d", got, maxScan)
+	}
+}
@@ -0,0 +1,186 @@
+package synonym
+
+import (
+	"slices"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// Term is one position of an expanded query. Any of the alternatives may
+// match, an alternative with several words is matched as a phrase.
+type Term struct {
+	Alternatives []string
+	Negate       bool
+}
+
+// Dictionary holds groups of equivalent terms and words ignored in queries.
+// It is swapped as a whole by Load so lookups never see a partial update.
+type Dictionary struct {
+	mu       sync.RWMutex
+	synonyms map[string][]string
+	stop     map[string]bool
+	maxWords int
+}
+
+func NewDictionary() *Dictionary {
+	return &Dictionary{
+		synonyms: make(map[string][]string),
+		stop:     make(map[string]bool),
+		maxWords: 1,
+	}
+}
+
+// Normalize lowercases and collapses whitespace
+func Normalize(s string) string {
+	return strings.Join(strings.FieldsFunc(strings.ToLower(s), unicode.IsSpace), " ")
+}
+
+// Load replaces the dictionary. Every term of a group expands to all the
+// others, a term in several groups gets the union.
+func (d *Dictionary) Load(groups [][]string, stopWords []string) {
+	synonyms := make(map[string][]string)
+	maxWords := 1
+
+	for _, group := range groups {
+		var terms []string
+		for _, t := range group {
+			if t = Normalize(t); t != "" {
+				terms = append(terms, t)
+			}
+		}
+
+		for _, t := range terms {
+			for _, other := range terms {
+				if other != t && !slices.Contains(synonyms[t], other) {
+					synonyms[t] = append(synonyms[t], other)
+				}
+			}
+			if n := len(strings.Fields(t)); n > maxWords {
+				maxWords = n
+			}
+		}
+	}
+
+	stop := make(map[string]bool, len(stopWords))
+	for _, w := range stopWords {
+		if w = Normalize(w); w != "" {
+			stop[w] = true
+		}
+	}
+
+	d.mu.Lock()
+	d.synonyms, d.stop, d.maxWords = synonyms, stop, maxWords
+	d.mu.Unlock()
+}
+
+type token struct {
+	text   string
+	phrase bool
+	negate bool
+}
+
+// tokenize follows the web search syntax: "quoted phrases", -excluded
+// words and OR between alternatives.
+func tokenize(query string) []token {
+	var tokens []token
+
+	for rest := strings.TrimSpace(query); rest != ""; rest = strings.TrimSpace(rest) {
+		var t token
+
+		if strings.HasPrefix(rest, "-") {
+			t.negate = true
+			rest = rest[1:]
+		}
+
+		if strings.HasPrefix(rest, `"`) {
+			end := strings.Index(rest[1:], `"`)
+			if end < 0 {
+				end = len(rest) - 1
+			}
+			t.text, t.phrase = rest[1:end+1], true
+			rest = rest[min(end+2, len(rest)):]
+		} else {
+			end := strings.IndexFunc(rest, unicode.IsSpace)
+			if end < 0 {
+				end = len(rest)
+			}
+			t.text, rest = rest[:end], rest[end:]
+		}
+
+		if t.text = Normalize(t.text); t.text != "" {
+			tokens = append(tokens, t)
+		}
+	}
+
+	return tokens
+}
+
+// Expand drops stop words and adds synonyms to the query terms. Runs of
+// words are matched longest first so multi word synonyms such as "t shirt"
+// are found. It returns nil when nothing is left to search for.
+func (d *Dictionary) Expand(query string) []*Term {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	tokens := tokenize(query)
+	var terms []*Term
+	orNext := false
+
+	for i := 0; i < len(tokens); i++ {
+		t := tokens[i]
+
+		if t.text == "or" && !t.phrase && !t.negate && len(terms) > 0 {
+			orNext = true
+			continue
+		}
+
+		text, used := t.text, 1
+		if !t.phrase && !t.negate {
+			text, used = d.longestMatch(tokens[i:])
+		}
+		i += used - 1
+
+		if used == 1 && !t.phrase && !t.negate && d.stop[text] {
+			continue
+		}
+
+		alternatives := append([]string{text}, d.synonyms[text]...)
+
+		if orNext && !t.negate && !terms[len(terms)-1].Negate {
+			last := terms[len(terms)-1]
+			for _, a := range alternatives {
+				if !slices.Contains(last.Alternatives, a) {
+					last.Alternatives = append(last.Alternatives, a)
+				}
+			}
+		} else {
+			terms = append(terms, &Term{Alternatives: alternatives, Negate: t.negate})
+		}
+		orNext = false
+	}
+
+	return terms
+}
+
+// longestMatch joins up to maxWords plain words into a known synonym,
+// falling back to the single word
+func (d *Dictionary) longestMatch(tokens []token) (string, int) {
+	for n := min(d.maxWords, len(tokens)); n > 1; n-- {
+		words := make([]string, 0, n)
+		for _, t := range tokens[:n] {
+			if t.phrase || t.negate {
+				break
+			}
+			words = append(words, t.text)
+		}
+
+		if len(words) == n {
+			if text := strings.Join(words, " "); len(d.synonyms[text]) > 0 {
+				return text, n
+			}
+		}
+	}
+
+	return tokens[0].text, 1
+}
@@ -0,0 +1,108 @@
+package synonym
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// format writes each term as its alternatives joined by |, negated terms
+// start with -
+func format(terms []*Term) []string {
+	var out []string
+	for _, t := range terms {
+		s := strings.Join(t.Alternatives, "|")
+		if t.Negate {
+			s = "-" + s
+		}
+		out = append(out, s)
+	}
+	return out
+}
+
+func dictionary() *Dictionary {
+	d := NewDictionary()
+	d.Load(
+		[][]string{{"T-Shirt", "t shirt", "tee"}, {"phone", "Mobile"}, {"mobile", "cell", " "}},
+		[]string{"the", "A", "for"},
+	)
+	return d
+}
+
+func TestExpand(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  []string
+	}{
+		{
+			name:  "synonyms added",
+			query: "phone",
+			want:  []string{"phone|mobile"},
+		},
+		{
+			name:  "union of several groups",
+			query: "MOBILE",
+			want:  []string{"mobile|phone|cell"},
+		},
+		{
+			name:  "multi word synonym matched first",
+			query: "red t  shirt",
+			want:  []string{"red", "t shirt|t-shirt|tee"},
+		},
+		{
+			name:  "stop words dropped",
+			query: "a tee for the beach",
+			want:  []string{"tee|t-shirt|t shirt", "beach"},
+		},
+		{
+			name:  "quoted stop word kept",
+			query: `"the" beach`,
+			want:  []string{"the", "beach"},
+		},
+		{
+			name:  "phrase kept whole",
+			query: `"t shirt" -cell`,
+			want:  []string{"t shirt|t-shirt|tee", "-cell|mobile"},
+		},
+		{
+			name:  "negated stop word kept",
+			query: "-the phone",
+			want:  []string{"-the", "phone|mobile"},
+		},
+		{
+			name:  "or merges alternatives",
+			query: "phone OR tee",
+			want:  []string{"phone|mobile|tee|t-shirt|t shirt"},
+		},
+		{
+			name:  "or does not merge into a negated term",
+			query: "-cell or tee",
+			want:  []string{"-cell|mobile", "tee|t-shirt|t shirt"},
+		},
+		{
+			name:  "only stop words",
+			query: "the a  for",
+			want:  nil,
+		},
+	}
+
+	d := dictionary()
+	for _, tt := range tests {
+		if got := format(d.Expand(tt.query)); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: Expand(%q) = %q, want %q", tt.name, tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestLoadReplaces(t *testing.T) {
+	d := dictionary()
+	d.Load([][]string{{"sofa", "couch"}}, nil)
+
+	if got := format(d.Expand("the phone")); !reflect.DeepEqual(got, []string{"the", "phone"}) {
+		t.Errorf("old entries kept: %q", got)
+	}
+	if got := format(d.Expand("couch")); !reflect.DeepEqual(got, []string{"couch|sofa"}) {
+		t.Errorf("Expand(couch) = %q", got)
+	}
+}